@@ -196,10 +196,13 @@ FROM cliente c
 }
 
 func (c ClienteRepository) ObtenerClienteDetailById(ctx context.Context, id *int) (*domain.ClienteDetail, error) {
-	query := `SELECT c.id,c.nombres,c.apellidos,c.codigo_pais,c.celular,c.fecha_nacimiento,c.estado,c.creado_en,c.actualizado_en,c.eliminado_en FROM cliente c WHERE c.id=$1 AND c.eliminado_en IS NULL LIMIT 1`
+	// El saldo de puntos es la suma del libro movimiento_puntos que mantiene sucursal_service
+	query := `SELECT c.id,c.nombres,c.apellidos,c.codigo_pais,c.celular,c.fecha_nacimiento,c.estado,c.creado_en,c.actualizado_en,c.eliminado_en,
+	COALESCE((SELECT SUM(mp.puntos) FROM movimiento_puntos mp WHERE mp.cliente_id=c.id),0)
+	FROM cliente c WHERE c.id=$1 AND c.eliminado_en IS NULL LIMIT 1`
 	var cliente domain.ClienteDetail
 	err := c.pool.QueryRow(ctx, query, *id).
-		Scan(&cliente.Id, &cliente.Nombres, &cliente.Apellidos, &cliente.CodigoPais, &cliente.Celular, &cliente.FechaNacimiento, &cliente.Estado, &cliente.CreadoEn, &cliente.ActualizadoEn, &cliente.EliminadoEn)
+		Scan(&cliente.Id, &cliente.Nombres, &cliente.Apellidos, &cliente.CodigoPais, &cliente.Celular, &cliente.FechaNacimiento, &cliente.Estado, &cliente.CreadoEn, &cliente.ActualizadoEn, &cliente.EliminadoEn, &cliente.SaldoPuntos)
 	if err != nil {
 		log.Println("Error al obtener cliente", err)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -40,4 +40,5 @@ type ClienteDetail struct {
 	CreadoEn        time.Time   `json:"creadoEn"`
 	ActualizadoEn   time.Time   `json:"actualizadoEn"`
 	EliminadoEn     *time.Time  `json:"eliminadoEn"`
+	SaldoPuntos     int         `json:"saldoPuntos"`
 }
@@ -0,0 +1,19 @@
+package fiscal
+
+import (
+	"log"
+	"multiroom/sucursal-service/internal/core/port"
+	"strings"
+)
+
+// NewProveedorFiscal elige el adaptador según FISCAL_PROVIDER; por ahora solo existe el simulado.
+// Un adaptador real se agrega aquí implementando port.ProveedorFiscal.
+func NewProveedorFiscal(nombre string, ventaRepository port.VentaRepository) port.ProveedorFiscal {
+	switch strings.ToLower(strings.TrimSpace(nombre)) {
+	case "", "mock":
+		return NewMockProveedorFiscal(ventaRepository)
+	default:
+		log.Printf("Proveedor fiscal '%s' no soportado; se usa el simulado\n", nombre)
+		return NewMockProveedorFiscal(ventaRepository)
+	}
+}
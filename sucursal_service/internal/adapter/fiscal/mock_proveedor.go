@@ -0,0 +1,78 @@
+package fiscal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/port"
+	"sync"
+	"time"
+)
+
+// MockProveedorFiscal simula la facturación electrónica en local: numera las facturas por sucursal y
+// genera un código de autorización determinístico. No debe usarse en producción.
+type MockProveedorFiscal struct {
+	ventaRepository port.VentaRepository
+	mu              sync.Mutex
+	secuencias      map[int]int64
+}
+
+func (m *MockProveedorFiscal) Nombre() string {
+	return "mock"
+}
+
+// siguienteNumero continúa la numeración de la sucursal; la primera vez la toma del mayor número ya
+// guardado para que un reinicio del servicio no repita facturas.
+func (m *MockProveedorFiscal) siguienteNumero(ctx context.Context, sucursalId int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ultimo, ok := m.secuencias[sucursalId]
+	if !ok {
+		var err error
+		ultimo, err = m.ventaRepository.ObtenerUltimoNumeroFiscal(ctx, sucursalId, m.Nombre())
+		if err != nil {
+			return 0, err
+		}
+	}
+	ultimo++
+	m.secuencias[sucursalId] = ultimo
+	return ultimo, nil
+}
+
+func (m *MockProveedorFiscal) EmitirFactura(ctx context.Context, factura *domain.FacturaFiscal) (*domain.DocumentoFiscal, error) {
+	siguiente, err := m.siguienteNumero(ctx, factura.SucursalId)
+	if err != nil {
+		return nil, err
+	}
+	numero := fmt.Sprintf("%08d", siguiente)
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%s|%.2f", factura.VentaId, factura.Nit, numero, factura.Total)))
+	autorizacion := hex.EncodeToString(hash[:])[:20]
+	ahora := time.Now()
+	log.Printf("[fiscal mock] Factura %s emitida para venta %d (NIT %s, total %.2f, impuesto %.2f)\n",
+		numero, factura.VentaId, factura.Nit, factura.Total, factura.Impuesto)
+	return &domain.DocumentoFiscal{
+		Proveedor:          m.Nombre(),
+		Estado:             domain.DocumentoFiscalEmitido,
+		Numero:             &numero,
+		CodigoAutorizacion: &autorizacion,
+		EmitidoEn:          &ahora,
+	}, nil
+}
+
+func (m *MockProveedorFiscal) AnularFactura(_ context.Context, documento *domain.DocumentoFiscal, motivo string) error {
+	numero := ""
+	if documento.Numero != nil {
+		numero = *documento.Numero
+	}
+	log.Printf("[fiscal mock] Factura %s anulada: %s\n", numero, motivo)
+	return nil
+}
+
+func NewMockProveedorFiscal(ventaRepository port.VentaRepository) *MockProveedorFiscal {
+	return &MockProveedorFiscal{ventaRepository: ventaRepository, secuencias: make(map[int]int64)}
+}
+
+var _ port.ProveedorFiscal = (*MockProveedorFiscal)(nil)
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AutorizacionHandler struct {
+	autorizacionService port.AutorizacionService
+}
+
+func (a AutorizacionHandler) RegistrarPinSupervisor(c *fiber.Ctx) error {
+	var request domain.PinSupervisorRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err := a.autorizacionService.RegistrarPinSupervisor(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("PIN de supervisor guardado correctamente"))
+}
+
+func (a AutorizacionHandler) GenerarTokenAutorizacion(c *fiber.Ctx) error {
+	var request domain.TokenAutorizacionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	token, err := a.autorizacionService.GenerarTokenAutorizacion(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(token)
+}
+
+func (a AutorizacionHandler) ListarAutorizacionesVenta(c *fiber.Ctx) error {
+	list, err := a.autorizacionService.ListarAutorizacionesVenta(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func NewAutorizacionHandler(autorizacionService port.AutorizacionService) *AutorizacionHandler {
+	return &AutorizacionHandler{autorizacionService: autorizacionService}
+}
+
+var _ port.AutorizacionHandler = (*AutorizacionHandler)(nil)
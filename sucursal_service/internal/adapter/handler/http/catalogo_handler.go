@@ -0,0 +1,40 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CatalogoHandler struct {
+	catalogoService port.CatalogoService
+}
+
+func (ca CatalogoHandler) ObtenerCambiosCatalogo(c *fiber.Ctx) error {
+	sucursalId, err := c.ParamsInt("sucursalId", 0)
+	if err != nil || sucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la sucursal debe ser un número válido mayor a 0"))
+	}
+	cursor := c.Query("cursor")
+	cambios, err := ca.catalogoService.ObtenerCambiosCatalogo(c.UserContext(), &sucursalId, &cursor)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(cambios)
+}
+
+func NewCatalogoHandler(catalogoService port.CatalogoService) *CatalogoHandler {
+	return &CatalogoHandler{catalogoService: catalogoService}
+}
+
+var _ port.CatalogoHandler = (*CatalogoHandler)(nil)
@@ -65,6 +65,37 @@ func (c2 CompraHandler) RegistrarOrdenCompra(c *fiber.Ctx) error {
 	return c.JSON(util.NewMessageData(domain.CompraId{Id: *compraId}, "Orden de compra registrada correctamente"))
 }
 
+func (c2 CompraHandler) ListarSugerenciasCompra(c *fiber.Ctx) error {
+	sugerencias, err := c2.compraService.ListarSugerenciasCompra(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(sugerencias)
+}
+
+func (c2 CompraHandler) RegistrarOrdenSugerida(c *fiber.Ctx) error {
+	var request domain.OrdenSugeridaRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+
+	compraId, err := c2.compraService.RegistrarOrdenSugerida(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessageData(domain.CompraId{Id: *compraId}, "Orden de compra sugerida registrada correctamente"))
+}
+
 func (c2 CompraHandler) ModificarOrdenCompra(c *fiber.Ctx) error {
 	compraId, err := c.ParamsInt("compraId", 0)
 	if err != nil || compraId <= 0 {
@@ -104,6 +135,68 @@ func (c2 CompraHandler) ConfirmarRecepcionCompra(c *fiber.Ctx) error {
 	return c.JSON(util.NewMessage("Compra recepcionada correctamente"))
 }
 
+func (c2 CompraHandler) RegistrarRecepcionCompra(c *fiber.Ctx) error {
+	compraId, err := c.ParamsInt("compraId", 0)
+	if err != nil || compraId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la compra debe ser un número válido mayor a 0"))
+	}
+	var request domain.RecepcionCompraRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	recepcionId, err := c2.compraService.RegistrarRecepcionCompra(c.UserContext(), compraId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(util.NewMessageData(domain.RecepcionCompraId{Id: *recepcionId}, "Recepción de compra registrada correctamente"))
+}
+
+func (c2 CompraHandler) ListarRecepcionesCompra(c *fiber.Ctx) error {
+	compraId, err := c.ParamsInt("compraId", 0)
+	if err != nil || compraId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la compra debe ser un número válido mayor a 0"))
+	}
+	list, err := c2.compraService.ListarRecepcionesCompra(c.UserContext(), compraId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (c2 CompraHandler) CancelarSaldoCompra(c *fiber.Ctx) error {
+	compraId, err := c.ParamsInt("compraId", 0)
+	if err != nil || compraId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la compra debe ser un número válido mayor a 0"))
+	}
+	var request domain.CancelacionSaldoCompraRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	estado, err := c2.compraService.CancelarSaldoCompra(c.UserContext(), compraId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if estado == domain.CompraCancelada {
+		return c.JSON(util.NewMessage("Compra cancelada; no se había recibido nada"))
+	}
+	return c.JSON(util.NewMessage("Saldo pendiente cancelado; la compra quedó completada con lo recibido"))
+}
+
 func NewCompraHandler(compraService port.CompraService) *CompraHandler {
 	return &CompraHandler{compraService: compraService}
 }
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConfiguracionSucursalHandler struct {
+	configuracionSucursalService port.ConfiguracionSucursalService
+}
+
+func (cs ConfiguracionSucursalHandler) ObtenerConfiguracionSucursal(c *fiber.Ctx) error {
+	sucursalId, err := c.ParamsInt("sucursalId", 0)
+	if err != nil || sucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la sucursal debe ser un número válido mayor a 0"))
+	}
+	configuracion, err := cs.configuracionSucursalService.ObtenerConfiguracionSucursal(c.UserContext(), &sucursalId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(configuracion)
+}
+
+func (cs ConfiguracionSucursalHandler) ModificarConfiguracionSucursal(c *fiber.Ctx) error {
+	sucursalId, err := c.ParamsInt("sucursalId", 0)
+	if err != nil || sucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la sucursal debe ser un número válido mayor a 0"))
+	}
+	var request domain.ConfiguracionSucursalRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = cs.configuracionSucursalService.ModificarConfiguracionSucursal(c.UserContext(), &sucursalId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Configuración de la sucursal guardada correctamente"))
+}
+
+func NewConfiguracionSucursalHandler(configuracionSucursalService port.ConfiguracionSucursalService) *ConfiguracionSucursalHandler {
+	return &ConfiguracionSucursalHandler{configuracionSucursalService: configuracionSucursalService}
+}
+
+var _ port.ConfiguracionSucursalHandler = (*ConfiguracionSucursalHandler)(nil)
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConteoInventarioHandler struct {
+	conteoInventarioService port.ConteoInventarioService
+}
+
+func (h ConteoInventarioHandler) AbrirConteo(c *fiber.Ctx) error {
+	var request domain.ConteoInventarioRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	id, err := h.conteoInventarioService.AbrirConteo(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(util.NewMessageData(domain.ConteoInventarioId{Id: *id}, "Conteo de inventario abierto correctamente"))
+}
+
+func (h ConteoInventarioHandler) RegistrarCapturasConteo(c *fiber.Ctx) error {
+	conteoId, err := c.ParamsInt("conteoId", 0)
+	if err != nil || conteoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del conteo debe ser un número válido mayor a 0"))
+	}
+	var request domain.CapturaConteoRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	if err := h.conteoInventarioService.RegistrarCapturasConteo(c.UserContext(), conteoId, &request); err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Capturas registradas correctamente"))
+}
+
+func (h ConteoInventarioHandler) CerrarConteo(c *fiber.Ctx) error {
+	return h.cambiarEstado(c, h.conteoInventarioService.CerrarConteo, "Conteo cerrado; queda en revisión")
+}
+
+func (h ConteoInventarioHandler) ReabrirConteo(c *fiber.Ctx) error {
+	return h.cambiarEstado(c, h.conteoInventarioService.ReabrirConteo, "Conteo reabierto correctamente")
+}
+
+func (h ConteoInventarioHandler) CancelarConteo(c *fiber.Ctx) error {
+	return h.cambiarEstado(c, h.conteoInventarioService.CancelarConteo, "Conteo cancelado correctamente")
+}
+
+func (h ConteoInventarioHandler) cambiarEstado(c *fiber.Ctx, accion func(ctx context.Context, conteoId int) error, mensaje string) error {
+	conteoId, err := c.ParamsInt("conteoId", 0)
+	if err != nil || conteoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del conteo debe ser un número válido mayor a 0"))
+	}
+	if err := accion(c.UserContext(), conteoId); err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage(mensaje))
+}
+
+func (h ConteoInventarioHandler) ContabilizarConteo(c *fiber.Ctx) error {
+	conteoId, err := c.ParamsInt("conteoId", 0)
+	if err != nil || conteoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del conteo debe ser un número válido mayor a 0"))
+	}
+	var request domain.ContabilizarConteoRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+		}
+	}
+	ajusteId, err := h.conteoInventarioService.ContabilizarConteo(c.UserContext(), conteoId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if ajusteId == nil {
+		return c.JSON(util.NewMessage("Conteo contabilizado sin diferencias"))
+	}
+	return c.JSON(util.NewMessageData(domain.AjusteId{Id: *ajusteId}, "Conteo contabilizado; se registró el ajuste de las diferencias"))
+}
+
+func (h ConteoInventarioHandler) ListarConteos(c *fiber.Ctx) error {
+	list, err := h.conteoInventarioService.ListarConteos(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (h ConteoInventarioHandler) ObtenerConteo(c *fiber.Ctx) error {
+	conteoId, err := c.ParamsInt("conteoId", 0)
+	if err != nil || conteoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del conteo debe ser un número válido mayor a 0"))
+	}
+	conteo, err := h.conteoInventarioService.ObtenerConteo(c.UserContext(), conteoId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(conteo)
+}
+
+func NewConteoInventarioHandler(conteoInventarioService port.ConteoInventarioService) *ConteoInventarioHandler {
+	return &ConteoInventarioHandler{conteoInventarioService: conteoInventarioService}
+}
+
+var _ port.ConteoInventarioHandler = (*ConteoInventarioHandler)(nil)
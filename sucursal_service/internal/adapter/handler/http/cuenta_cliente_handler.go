@@ -0,0 +1,129 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CuentaClienteHandler struct {
+	cuentaClienteService port.CuentaClienteService
+}
+
+func (c2 CuentaClienteHandler) RegistrarCuentaCliente(c *fiber.Ctx) error {
+	var request domain.CuentaClienteRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	id, err := c2.cuentaClienteService.RegistrarCuentaCliente(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(util.NewMessageData(domain.CuentaClienteId{Id: *id}, "Cuenta de cliente registrada correctamente"))
+}
+
+func (c2 CuentaClienteHandler) ModificarCuentaCliente(c *fiber.Ctx) error {
+	cuentaId, err := c.ParamsInt("cuentaId", 0)
+	if err != nil || cuentaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la cuenta debe ser un número válido mayor a 0"))
+	}
+	var request domain.CuentaClienteRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = c2.cuentaClienteService.ModificarCuentaCliente(c.UserContext(), &cuentaId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Cuenta de cliente modificada correctamente"))
+}
+
+func (c2 CuentaClienteHandler) ListarCuentasCliente(c *fiber.Ctx) error {
+	list, err := c2.cuentaClienteService.ListarCuentasCliente(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (c2 CuentaClienteHandler) ObtenerCuentaClienteById(c *fiber.Ctx) error {
+	cuentaId, err := c.ParamsInt("cuentaId", 0)
+	if err != nil || cuentaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la cuenta debe ser un número válido mayor a 0"))
+	}
+	cuenta, err := c2.cuentaClienteService.ObtenerCuentaClienteById(c.UserContext(), &cuentaId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(cuenta)
+}
+
+func (c2 CuentaClienteHandler) RecargarCuentaCliente(c *fiber.Ctx) error {
+	cuentaId, err := c.ParamsInt("cuentaId", 0)
+	if err != nil || cuentaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la cuenta debe ser un número válido mayor a 0"))
+	}
+	var request domain.RecargaCuentaRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	movimientoId, err := c2.cuentaClienteService.RecargarCuentaCliente(c.UserContext(), &cuentaId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(util.NewMessageData(domain.CuentaClienteId{Id: *movimientoId}, "Recarga registrada correctamente"))
+}
+
+func (c2 CuentaClienteHandler) ObtenerEstadoCuentaCliente(c *fiber.Ctx) error {
+	cuentaId, err := c.ParamsInt("cuentaId", 0)
+	if err != nil || cuentaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la cuenta debe ser un número válido mayor a 0"))
+	}
+	estadoCuenta, err := c2.cuentaClienteService.ObtenerEstadoCuentaCliente(c.UserContext(), &cuentaId, c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(estadoCuenta)
+}
+
+func NewCuentaClienteHandler(cuentaClienteService port.CuentaClienteService) *CuentaClienteHandler {
+	return &CuentaClienteHandler{cuentaClienteService: cuentaClienteService}
+}
+
+var _ port.CuentaClienteHandler = (*CuentaClienteHandler)(nil)
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImpresoraHandler struct {
+	impresoraService port.ImpresoraService
+}
+
+func (i ImpresoraHandler) RegistrarImpresora(c *fiber.Ctx) error {
+	var request domain.ImpresoraRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	id, err := i.impresoraService.RegistrarImpresora(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(util.NewMessageData(domain.ImpresoraId{Id: *id}, "Impresora registrada correctamente"))
+}
+
+func (i ImpresoraHandler) ModificarImpresora(c *fiber.Ctx) error {
+	impresoraId, err := c.ParamsInt("impresoraId", 0)
+	if err != nil || impresoraId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la impresora debe ser un número válido mayor a 0"))
+	}
+	var request domain.ImpresoraRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = i.impresoraService.ModificarImpresora(c.UserContext(), &impresoraId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Impresora modificada correctamente"))
+}
+
+func (i ImpresoraHandler) ListarImpresoras(c *fiber.Ctx) error {
+	list, err := i.impresoraService.ListarImpresoras(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (i ImpresoraHandler) ObtenerImpresoraById(c *fiber.Ctx) error {
+	impresoraId, err := c.ParamsInt("impresoraId", 0)
+	if err != nil || impresoraId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la impresora debe ser un número válido mayor a 0"))
+	}
+	impresora, err := i.impresoraService.ObtenerImpresoraById(c.UserContext(), &impresoraId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(impresora)
+}
+
+func (i ImpresoraHandler) ProbarImpresora(c *fiber.Ctx) error {
+	impresoraId, err := c.ParamsInt("impresoraId", 0)
+	if err != nil || impresoraId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la impresora debe ser un número válido mayor a 0"))
+	}
+	err = i.impresoraService.ProbarImpresora(c.UserContext(), &impresoraId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Página de prueba enviada correctamente"))
+}
+
+func (i ImpresoraHandler) ImprimirVenta(c *fiber.Ctx) error {
+	ventaId, err := c.ParamsInt("ventaId", 0)
+	if err != nil || ventaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la venta debe ser un número válido mayor a 0"))
+	}
+	var request domain.ImprimirVentaRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+		}
+	}
+	id, err := i.impresoraService.ImprimirVenta(c.UserContext(), &ventaId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusAccepted).JSON(util.NewMessageData(domain.ImpresoraId{Id: *id}, "Ticket enviado a la cola de impresión"))
+}
+
+func (i ImpresoraHandler) ListarTrabajosImpresion(c *fiber.Ctx) error {
+	list, err := i.impresoraService.ListarTrabajosImpresion(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (i ImpresoraHandler) ReintentarTrabajoImpresion(c *fiber.Ctx) error {
+	trabajoId, err := c.ParamsInt("trabajoId", 0)
+	if err != nil || trabajoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del trabajo debe ser un número válido mayor a 0"))
+	}
+	err = i.impresoraService.ReintentarTrabajoImpresion(c.UserContext(), &trabajoId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Trabajo de impresión reprogramado correctamente"))
+}
+
+func (i ImpresoraHandler) CancelarTrabajoImpresion(c *fiber.Ctx) error {
+	trabajoId, err := c.ParamsInt("trabajoId", 0)
+	if err != nil || trabajoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del trabajo debe ser un número válido mayor a 0"))
+	}
+	err = i.impresoraService.CancelarTrabajoImpresion(c.UserContext(), &trabajoId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Trabajo de impresión cancelado correctamente"))
+}
+
+func NewImpresoraHandler(impresoraService port.ImpresoraService) *ImpresoraHandler {
+	return &ImpresoraHandler{impresoraService: impresoraService}
+}
+
+var _ port.ImpresoraHandler = (*ImpresoraHandler)(nil)
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImpuestoHandler struct {
+	impuestoService port.ImpuestoService
+}
+
+func (i ImpuestoHandler) RegistrarImpuesto(c *fiber.Ctx) error {
+	var request domain.ImpuestoRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	id, err := i.impuestoService.RegistrarImpuesto(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(util.NewMessageData(domain.ImpuestoId{Id: *id}, "Impuesto registrado correctamente"))
+}
+
+func (i ImpuestoHandler) ModificarImpuesto(c *fiber.Ctx) error {
+	impuestoId, err := c.ParamsInt("impuestoId", 0)
+	if err != nil || impuestoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del impuesto debe ser un número válido mayor a 0"))
+	}
+	var request domain.ImpuestoRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = i.impuestoService.ModificarImpuesto(c.UserContext(), &impuestoId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Impuesto modificado correctamente"))
+}
+
+func (i ImpuestoHandler) ListarImpuestos(c *fiber.Ctx) error {
+	list, err := i.impuestoService.ListarImpuestos(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (i ImpuestoHandler) ObtenerImpuestoById(c *fiber.Ctx) error {
+	impuestoId, err := c.ParamsInt("impuestoId", 0)
+	if err != nil || impuestoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del impuesto debe ser un número válido mayor a 0"))
+	}
+	impuesto, err := i.impuestoService.ObtenerImpuestoById(c.UserContext(), &impuestoId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(impuesto)
+}
+
+func NewImpuestoHandler(impuestoService port.ImpuestoService) *ImpuestoHandler {
+	return &ImpuestoHandler{impuestoService: impuestoService}
+}
+
+var _ port.ImpuestoHandler = (*ImpuestoHandler)(nil)
@@ -76,6 +76,75 @@ func (i InventarioHandler) ListarAjustes(c *fiber.Ctx) error {
 	return c.JSON(list)
 }
 
+func (i InventarioHandler) ResumenAjustesPorTipo(c *fiber.Ctx) error {
+	list, err := i.inventarioService.ResumenAjustesPorTipo(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (i InventarioHandler) ObtenerKardex(c *fiber.Ctx) error {
+	kardex, err := i.inventarioService.ObtenerKardex(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(kardex)
+}
+
+func (i InventarioHandler) ListarProductosStockBajo(c *fiber.Ctx) error {
+	list, err := i.inventarioService.ListarProductosStockBajo(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (i InventarioHandler) ListarLotes(c *fiber.Ctx) error {
+	list, err := i.inventarioService.ListarLotes(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (i InventarioHandler) DarDeBajaLotes(c *fiber.Ctx) error {
+	var request domain.BajaLotesRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	id, err := i.inventarioService.DarDeBajaLotes(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessageData(domain.AjusteId{Id: *id}, "Lotes dados de baja correctamente"))
+}
+
 func (i InventarioHandler) RegistrarAjusteConDetalle(c *fiber.Ctx) error {
 	var request domain.AjusteInventarioRequest
 	if err := c.BodyParser(&request); err != nil {
@@ -110,6 +179,111 @@ func (i InventarioHandler) RegistrarTransferencia(c *fiber.Ctx) error {
 	return c.JSON(util.NewMessageData(domain.TransferenciaId{Id: *id}, "Transferencia registrada correctamente"))
 }
 
+func (i InventarioHandler) RegistrarSolicitudTransferencia(c *fiber.Ctx) error {
+	var request domain.TransferenciaRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	id, err := i.inventarioService.RegistrarSolicitudTransferencia(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessageData(domain.TransferenciaId{Id: *id}, "Solicitud de transferencia registrada; queda pendiente de aprobación"))
+}
+
+func (i InventarioHandler) AprobarTransferencia(c *fiber.Ctx) error {
+	transferenciaId, err := c.ParamsInt("transferenciaId", 0)
+	if err != nil || transferenciaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la transferencia debe ser un número válido mayor a 0"))
+	}
+	if err := i.inventarioService.AprobarTransferencia(c.UserContext(), transferenciaId); err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Transferencia aprobada correctamente"))
+}
+
+func (i InventarioHandler) RechazarTransferencia(c *fiber.Ctx) error {
+	transferenciaId, err := c.ParamsInt("transferenciaId", 0)
+	if err != nil || transferenciaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la transferencia debe ser un número válido mayor a 0"))
+	}
+	var request domain.RechazoTransferenciaRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	if err := i.inventarioService.RechazarTransferencia(c.UserContext(), transferenciaId, &request); err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Transferencia rechazada correctamente"))
+}
+
+func (i InventarioHandler) DespacharTransferencia(c *fiber.Ctx) error {
+	transferenciaId, err := c.ParamsInt("transferenciaId", 0)
+	if err != nil || transferenciaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la transferencia debe ser un número válido mayor a 0"))
+	}
+	var request domain.DespachoTransferenciaRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+		}
+	}
+	estado, err := i.inventarioService.DespacharTransferencia(c.UserContext(), transferenciaId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if estado == domain.TransferenciaEnTransito {
+		return c.JSON(util.NewMessage("Transferencia despachada; queda en tránsito hasta su recepción"))
+	}
+	return c.JSON(util.NewMessage("Transferencia despachada y completada"))
+}
+
+func (i InventarioHandler) RecibirTransferencia(c *fiber.Ctx) error {
+	transferenciaId, err := c.ParamsInt("transferenciaId", 0)
+	if err != nil || transferenciaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la transferencia debe ser un número válido mayor a 0"))
+	}
+	var request domain.RecepcionTransferenciaRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+		}
+	}
+	ajusteId, err := i.inventarioService.RecibirTransferencia(c.UserContext(), transferenciaId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if ajusteId == nil {
+		return c.JSON(util.NewMessage("Transferencia recibida completa"))
+	}
+	return c.JSON(util.NewMessageData(domain.AjusteId{Id: *ajusteId}, "Transferencia recibida; el faltante se registró como merma"))
+}
+
 func (i InventarioHandler) ListarInventario(c *fiber.Ctx) error {
 	list, err := i.inventarioService.ListarInventario(c.UserContext(), c.Queries())
 	if err != nil {
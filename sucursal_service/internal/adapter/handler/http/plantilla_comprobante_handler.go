@@ -0,0 +1,71 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime/multipart"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PlantillaComprobanteHandler struct {
+	plantillaComprobanteService port.PlantillaComprobanteService
+}
+
+func (p PlantillaComprobanteHandler) ObtenerPlantillaComprobante(c *fiber.Ctx) error {
+	sucursalId, err := c.ParamsInt("sucursalId", 0)
+	if err != nil || sucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la sucursal debe ser un número válido mayor a 0"))
+	}
+	plantilla, err := p.plantillaComprobanteService.ObtenerPlantillaComprobante(c.UserContext(), &sucursalId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(plantilla)
+}
+
+func (p PlantillaComprobanteHandler) ModificarPlantillaComprobante(c *fiber.Ctx) error {
+	sucursalId, err := c.ParamsInt("sucursalId", 0)
+	if err != nil || sucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la sucursal debe ser un número válido mayor a 0"))
+	}
+	var request domain.PlantillaComprobanteRequest
+	if err := json.Unmarshal([]byte(c.FormValue("body")), &request); err != nil {
+		log.Println("Error al deserializar body:", err)
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+
+	// El logo es opcional; sin archivo se conserva el actual
+	var fileHeader *multipart.FileHeader
+	if fh, err := c.FormFile("image"); err == nil {
+		fileHeader = fh
+	}
+
+	err = p.plantillaComprobanteService.ModificarPlantillaComprobante(c.UserContext(), &sucursalId, &request, fileHeader)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Plantilla de comprobante modificada correctamente"))
+}
+
+func NewPlantillaComprobanteHandler(plantillaComprobanteService port.PlantillaComprobanteService) *PlantillaComprobanteHandler {
+	return &PlantillaComprobanteHandler{plantillaComprobanteService: plantillaComprobanteService}
+}
+
+var _ port.PlantillaComprobanteHandler = (*PlantillaComprobanteHandler)(nil)
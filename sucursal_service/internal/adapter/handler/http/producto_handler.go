@@ -55,6 +55,27 @@ func (p ProductoHandler) ActualizarProductoSucursal(c *fiber.Ctx) error {
 	return c.JSON(util.NewMessage("Producto de sucursal actualizado correctamente"))
 }
 
+func (p ProductoHandler) ActualizarNivelesStock(c *fiber.Ctx) error {
+	productoSucursalId, err := c.ParamsInt("productoSucursalId", 0)
+	if err != nil || productoSucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' debe ser un número válido mayor a 0"))
+	}
+	var request domain.NivelesStockRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = p.productoService.ActualizarNivelesStock(c.UserContext(), &productoSucursalId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Niveles de stock actualizados correctamente"))
+}
+
 func (p ProductoHandler) ListarProductosPorSucursal(c *fiber.Ctx) error {
 	list, err := p.productoService.ListarProductosPorSucursal(c.UserContext(), c.Queries())
 	if err != nil {
@@ -117,6 +138,19 @@ func (p ProductoHandler) ListarProductosMasVendidos(c *fiber.Ctx) error {
 	return c.JSON(list)
 }
 
+func (p ProductoHandler) ObtenerProductoPorCodigo(c *fiber.Ctx) error {
+	producto, err := p.productoService.ObtenerProductoPorCodigo(c.UserContext(), c.Params("code"), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(producto)
+}
+
 func (p ProductoHandler) RegistrarProducto(c *fiber.Ctx) error {
 	var request domain.ProductoRequest
 	if err := json.Unmarshal([]byte(c.FormValue("body")), &request); err != nil {
@@ -198,6 +232,82 @@ func (p ProductoHandler) ObtenerProductoById(c *fiber.Ctx) error {
 	return c.JSON(producto)
 }
 
+func (p ProductoHandler) ListarComponentesProducto(c *fiber.Ctx) error {
+	productoId, err := c.ParamsInt("productoId", 0)
+	if err != nil || productoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del producto debe ser un número válido mayor a 0"))
+	}
+	list, err := p.productoService.ListarComponentesProducto(c.UserContext(), &productoId, c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (p ProductoHandler) ModificarComponentesProducto(c *fiber.Ctx) error {
+	productoId, err := c.ParamsInt("productoId", 0)
+	if err != nil || productoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del producto debe ser un número válido mayor a 0"))
+	}
+	var request domain.ProductoComponentesRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = p.productoService.ModificarComponentesProducto(c.UserContext(), &productoId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Componentes del producto actualizados correctamente"))
+}
+
+func (p ProductoHandler) ListarOpcionesProducto(c *fiber.Ctx) error {
+	productoId, err := c.ParamsInt("productoId", 0)
+	if err != nil || productoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del producto debe ser un número válido mayor a 0"))
+	}
+	list, err := p.productoService.ListarOpcionesProducto(c.UserContext(), &productoId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (p ProductoHandler) ModificarOpcionesProducto(c *fiber.Ctx) error {
+	productoId, err := c.ParamsInt("productoId", 0)
+	if err != nil || productoId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del producto debe ser un número válido mayor a 0"))
+	}
+	var request domain.ProductoOpcionesRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = p.productoService.ModificarOpcionesProducto(c.UserContext(), &productoId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Opciones del producto actualizadas correctamente"))
+}
+
 func NewProductoHandler(productoService port.ProductoService) *ProductoHandler {
 	return &ProductoHandler{productoService: productoService}
 }
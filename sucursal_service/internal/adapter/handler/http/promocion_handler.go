@@ -0,0 +1,138 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PromocionHandler struct {
+	promocionService port.PromocionService
+}
+
+func (p PromocionHandler) RegistrarPromocion(c *fiber.Ctx) error {
+	var request domain.PromocionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	promocionId, err := p.promocionService.RegistrarPromocion(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(util.NewMessageData(domain.PromocionId{Id: *promocionId}, "Promoción registrada correctamente"))
+}
+
+func (p PromocionHandler) ModificarPromocionById(c *fiber.Ctx) error {
+	promocionId, err := c.ParamsInt("promocionId", 0)
+	if err != nil || promocionId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la promoción debe ser un número válido mayor a 0"))
+	}
+	var request domain.PromocionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = p.promocionService.ModificarPromocionById(c.UserContext(), &promocionId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Promoción modificada correctamente"))
+}
+
+func (p PromocionHandler) HabilitarPromocionById(c *fiber.Ctx) error {
+	promocionId, err := c.ParamsInt("promocionId", 0)
+	if err != nil || promocionId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la promoción debe ser un número válido mayor a 0"))
+	}
+	err = p.promocionService.HabilitarPromocionById(c.UserContext(), &promocionId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Promoción habilitada correctamente"))
+}
+
+func (p PromocionHandler) DeshabilitarPromocionById(c *fiber.Ctx) error {
+	promocionId, err := c.ParamsInt("promocionId", 0)
+	if err != nil || promocionId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la promoción debe ser un número válido mayor a 0"))
+	}
+	err = p.promocionService.DeshabilitarPromocionById(c.UserContext(), &promocionId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Promoción deshabilitada correctamente"))
+}
+
+func (p PromocionHandler) ListarPromociones(c *fiber.Ctx) error {
+	list, err := p.promocionService.ListarPromociones(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (p PromocionHandler) ObtenerPromocionById(c *fiber.Ctx) error {
+	promocionId, err := c.ParamsInt("promocionId", 0)
+	if err != nil || promocionId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la promoción debe ser un número válido mayor a 0"))
+	}
+	promocion, err := p.promocionService.ObtenerPromocionById(c.UserContext(), &promocionId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(promocion)
+}
+
+func (p PromocionHandler) ListarDescuentosPorPromocion(c *fiber.Ctx) error {
+	list, err := p.promocionService.ListarDescuentosPorPromocion(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func NewPromocionHandler(promocionService port.PromocionService) *PromocionHandler {
+	return &PromocionHandler{promocionService: promocionService}
+}
+
+var _ port.PromocionHandler = (*PromocionHandler)(nil)
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PuntosHandler struct {
+	puntosService port.PuntosService
+}
+
+func (p PuntosHandler) ObtenerProgramaPuntos(c *fiber.Ctx) error {
+	sucursalId, err := c.ParamsInt("sucursalId", 0)
+	if err != nil || sucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la sucursal debe ser un número válido mayor a 0"))
+	}
+	programa, err := p.puntosService.ObtenerProgramaPuntos(c.UserContext(), &sucursalId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(programa)
+}
+
+func (p PuntosHandler) ModificarProgramaPuntos(c *fiber.Ctx) error {
+	sucursalId, err := c.ParamsInt("sucursalId", 0)
+	if err != nil || sucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la sucursal debe ser un número válido mayor a 0"))
+	}
+	var request domain.ProgramaPuntosRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	err = p.puntosService.ModificarProgramaPuntos(c.UserContext(), &sucursalId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Programa de puntos modificado correctamente"))
+}
+
+func (p PuntosHandler) ObtenerPuntosCliente(c *fiber.Ctx) error {
+	clienteId, err := c.ParamsInt("clienteId", 0)
+	if err != nil || clienteId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del cliente debe ser un número válido mayor a 0"))
+	}
+	id := int64(clienteId)
+	puntos, err := p.puntosService.ObtenerPuntosCliente(c.UserContext(), &id, c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(puntos)
+}
+
+func NewPuntosHandler(puntosService port.PuntosService) *PuntosHandler {
+	return &PuntosHandler{puntosService: puntosService}
+}
+
+var _ port.PuntosHandler = (*PuntosHandler)(nil)
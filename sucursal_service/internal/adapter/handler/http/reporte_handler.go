@@ -35,6 +35,132 @@ func (r ReporteHandler) ReportePDFProductosVendidos(c *fiber.Ctx) error {
 	return c.Send(doc.GetBytes())
 }
 
+func (r ReporteHandler) ReportePDFSaldosPendientes(c *fiber.Ctx) error {
+	doc, err := r.reporteService.ReportePDFSaldosPendientes(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(util.NewMessage(err.Error()))
+	}
+
+	c.Response().Header.Set("Content-Type", "application/pdf")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="reporte-saldos-pendientes-%s.pdf"`, time.Now().Format("2006-01-02 03-04-05")))
+	c.Response().Header.Set("Content-Transfer-Encoding", "binary")
+
+	return c.Send(doc.GetBytes())
+}
+
+func (r ReporteHandler) ReportePDFExcepciones(c *fiber.Ctx) error {
+	doc, err := r.reporteService.ReportePDFExcepciones(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(util.NewMessage(err.Error()))
+	}
+
+	c.Response().Header.Set("Content-Type", "application/pdf")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="reporte-excepciones-%s.pdf"`, time.Now().Format("2006-01-02 03-04-05")))
+	c.Response().Header.Set("Content-Transfer-Encoding", "binary")
+
+	return c.Send(doc.GetBytes())
+}
+
+func (r ReporteHandler) ReportePDFKardex(c *fiber.Ctx) error {
+	doc, err := r.reporteService.ReportePDFKardex(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(util.NewMessage(err.Error()))
+	}
+
+	c.Response().Header.Set("Content-Type", "application/pdf")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="kardex-%s.pdf"`, time.Now().Format("2006-01-02 03-04-05")))
+	c.Response().Header.Set("Content-Transfer-Encoding", "binary")
+
+	return c.Send(doc.GetBytes())
+}
+
+func (r ReporteHandler) ReportePDFLotesPorVencer(c *fiber.Ctx) error {
+	doc, err := r.reporteService.ReportePDFLotesPorVencer(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(util.NewMessage(err.Error()))
+	}
+
+	c.Response().Header.Set("Content-Type", "application/pdf")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="lotes-por-vencer-%s.pdf"`, time.Now().Format("2006-01-02 03-04-05")))
+	c.Response().Header.Set("Content-Transfer-Encoding", "binary")
+
+	return c.Send(doc.GetBytes())
+}
+
+func (r ReporteHandler) ReportePDFAjustesPorTipo(c *fiber.Ctx) error {
+	doc, err := r.reporteService.ReportePDFAjustesPorTipo(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(util.NewMessage(err.Error()))
+	}
+
+	c.Response().Header.Set("Content-Type", "application/pdf")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="ajustes-por-tipo-%s.pdf"`, time.Now().Format("2006-01-02 03-04-05")))
+	c.Response().Header.Set("Content-Transfer-Encoding", "binary")
+
+	return c.Send(doc.GetBytes())
+}
+
+func (r ReporteHandler) ReportePDFMargenes(c *fiber.Ctx) error {
+	doc, err := r.reporteService.ReportePDFMargenes(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(util.NewMessage(err.Error()))
+	}
+
+	c.Response().Header.Set("Content-Type", "application/pdf")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="margenes-%s.pdf"`, time.Now().Format("2006-01-02 03-04-05")))
+	c.Response().Header.Set("Content-Transfer-Encoding", "binary")
+
+	return c.Send(doc.GetBytes())
+}
+
+func (r ReporteHandler) ReportePDFEtiquetasProductos(c *fiber.Ctx) error {
+	doc, err := r.reporteService.ReportePDFEtiquetasProductos(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(util.NewMessage(err.Error()))
+	}
+
+	c.Response().Header.Set("Content-Type", "application/pdf")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="etiquetas-%s.pdf"`, time.Now().Format("2006-01-02 03-04-05")))
+	c.Response().Header.Set("Content-Transfer-Encoding", "binary")
+
+	return c.Send(doc.GetBytes())
+}
+
 func (r ReporteHandler) ReportePDFVentas(c *fiber.Ctx) error {
 	doc, err := r.reporteService.ReportePDFVentas(c.UserContext(), c.Queries())
 	if err != nil {
@@ -75,6 +201,28 @@ func (r ReporteHandler) ComprobantePDFVentaById(c *fiber.Ctx) error {
 	return c.Send(doc.GetBytes())
 }
 
+func (r ReporteHandler) VistaPreviaComprobante(c *fiber.Ctx) error {
+	sucursalId, err := c.ParamsInt("sucursalId", 0)
+	if err != nil || sucursalId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la sucursal debe ser un número válido mayor a 0"))
+	}
+	doc, err := r.reporteService.VistaPreviaComprobante(c.UserContext(), &sucursalId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return c.Status(http.StatusInternalServerError).JSON(util.NewMessage(err.Error()))
+	}
+
+	c.Response().Header.Set("Content-Type", "application/pdf")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf(`inline; filename="vista-previa-comprobante-%d.pdf"`, sucursalId))
+	c.Response().Header.Set("Content-Transfer-Encoding", "binary")
+
+	return c.Send(doc.GetBytes())
+}
+
 func NewReporteHandler(reporteService port.ReporteService) *ReporteHandler {
 	return &ReporteHandler{reporteService: reporteService}
 }
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TarjetaRegaloHandler struct {
+	tarjetaRegaloService port.TarjetaRegaloService
+}
+
+func (t TarjetaRegaloHandler) ListarTarjetasRegalo(c *fiber.Ctx) error {
+	list, err := t.tarjetaRegaloService.ListarTarjetasRegalo(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (t TarjetaRegaloHandler) ObtenerTarjetaRegaloByCodigo(c *fiber.Ctx) error {
+	codigo := c.Params("codigo")
+	if codigo == "" {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El código de la tarjeta de regalo es obligatorio"))
+	}
+	tarjeta, err := t.tarjetaRegaloService.ObtenerTarjetaRegaloByCodigo(c.UserContext(), &codigo)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(tarjeta)
+}
+
+func NewTarjetaRegaloHandler(tarjetaRegaloService port.TarjetaRegaloService) *TarjetaRegaloHandler {
+	return &TarjetaRegaloHandler{tarjetaRegaloService: tarjetaRegaloService}
+}
+
+var _ port.TarjetaRegaloHandler = (*TarjetaRegaloHandler)(nil)
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TipoAjusteHandler struct {
+	tipoAjusteService port.TipoAjusteService
+}
+
+func (t TipoAjusteHandler) HabilitarTipoAjuste(c *fiber.Ctx) error {
+	tipoAjusteId, err := c.ParamsInt("tipoAjusteId", 0)
+	if err != nil || tipoAjusteId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del tipo de ajuste debe ser un número válido mayor a 0"))
+	}
+	err = t.tipoAjusteService.HabilitarTipoAjuste(c.UserContext(), &tipoAjusteId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Tipo de ajuste habilitado correctamente"))
+}
+
+func (t TipoAjusteHandler) DeshabilitarTipoAjuste(c *fiber.Ctx) error {
+	tipoAjusteId, err := c.ParamsInt("tipoAjusteId", 0)
+	if err != nil || tipoAjusteId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del tipo de ajuste debe ser un número válido mayor a 0"))
+	}
+	err = t.tipoAjusteService.DeshabilitarTipoAjuste(c.UserContext(), &tipoAjusteId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Tipo de ajuste deshabilitado correctamente"))
+}
+
+func (t TipoAjusteHandler) RegistrarTipoAjuste(c *fiber.Ctx) error {
+	var request domain.TipoAjusteRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	tipoAjusteId, err := t.tipoAjusteService.RegistrarTipoAjuste(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.Status(http.StatusCreated).JSON(util.NewMessageData(domain.TipoAjusteId{Id: *tipoAjusteId}, "Tipo de ajuste registrado correctamente"))
+}
+
+func (t TipoAjusteHandler) ModificarTipoAjusteById(c *fiber.Ctx) error {
+	var request domain.TipoAjusteRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	tipoAjusteId, err := c.ParamsInt("tipoAjusteId", 0)
+	if err != nil || tipoAjusteId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del tipo de ajuste debe ser un número válido mayor a 0"))
+	}
+
+	err = t.tipoAjusteService.ModificarTipoAjusteById(c.UserContext(), &tipoAjusteId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(util.NewMessage("Tipo de ajuste modificado correctamente"))
+}
+
+func (t TipoAjusteHandler) ListarTiposAjuste(c *fiber.Ctx) error {
+	tiposAjuste, err := t.tipoAjusteService.ListarTiposAjuste(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(&tiposAjuste)
+}
+
+func (t TipoAjusteHandler) ObtenerTipoAjusteById(c *fiber.Ctx) error {
+	tipoAjusteId, err := c.ParamsInt("tipoAjusteId", 0)
+	if err != nil || tipoAjusteId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' del tipo de ajuste debe ser un número válido mayor a 0"))
+	}
+	tipoAjuste, err := t.tipoAjusteService.ObtenerTipoAjusteById(c.UserContext(), &tipoAjusteId)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(&tipoAjuste)
+}
+
+func NewTipoAjusteHandler(tipoAjusteService port.TipoAjusteService) *TipoAjusteHandler {
+	return &TipoAjusteHandler{tipoAjusteService: tipoAjusteService}
+}
+
+var _ port.TipoAjusteHandler = (*TipoAjusteHandler)(nil)
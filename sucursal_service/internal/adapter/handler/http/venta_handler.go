@@ -29,6 +29,32 @@ func (v VentaHandler) ListarProductosVentas(c *fiber.Ctx) error {
 	return c.JSON(list)
 }
 
+func (v VentaHandler) ListarConsumoComponentes(c *fiber.Ctx) error {
+	list, err := v.ventaService.ListarConsumoComponentes(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
+func (v VentaHandler) ListarMargenesProductos(c *fiber.Ctx) error {
+	list, err := v.ventaService.ListarMargenesProductos(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
 func (v VentaHandler) RegistrarVenta(c *fiber.Ctx) error {
 	var request domain.VentaRequest
 	if err := c.BodyParser(&request); err != nil {
@@ -51,7 +77,14 @@ func (v VentaHandler) AnularVentaById(c *fiber.Ctx) error {
 	if err != nil || ventaId <= 0 {
 		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la venta debe ser un número válido mayor a 0"))
 	}
-	err = v.ventaService.AnularVentaById(c.UserContext(), &ventaId)
+	// El cuerpo solo se envía cuando la anulación necesita autorización de un supervisor
+	var request domain.AnularVentaRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+		}
+	}
+	err = v.ventaService.AnularVentaById(c.UserContext(), &ventaId, &request)
 	if err != nil {
 		log.Print(err.Error())
 		var errorResponse *datatype.ErrorResponse
@@ -114,6 +147,74 @@ func (v VentaHandler) ListarVentas(c *fiber.Ctx) error {
 	return c.JSON(list)
 }
 
+func (v VentaHandler) DividirVenta(c *fiber.Ctx) error {
+	ventaId, err := c.ParamsInt("ventaId", 0)
+	if err != nil || ventaId <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("El 'id' de la venta debe ser un número válido mayor a 0"))
+	}
+	var request domain.DividirVentaRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	ventaIds, err := v.ventaService.DividirVenta(c.UserContext(), &ventaId, &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	ventas := make([]domain.VentaId, 0, len(*ventaIds))
+	for _, id := range *ventaIds {
+		ventas = append(ventas, domain.VentaId{Id: id})
+	}
+	return c.JSON(util.NewMessageData(ventas, "Venta dividida correctamente"))
+}
+
+func (v VentaHandler) SincronizarVentas(c *fiber.Ctx) error {
+	var request domain.SincronizacionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(util.NewMessage("Petición inválida: datos incompletos o incorrectos"))
+	}
+	resultados, err := v.ventaService.SincronizarVentas(c.UserContext(), &request)
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(resultados)
+}
+
+func (v VentaHandler) ObtenerEstadisticasVentas(c *fiber.Ctx) error {
+	estadisticas, err := v.ventaService.ObtenerEstadisticasVentas(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(estadisticas)
+}
+
+func (v VentaHandler) ListarOperacionesOffline(c *fiber.Ctx) error {
+	list, err := v.ventaService.ListarOperacionesOffline(c.UserContext(), c.Queries())
+	if err != nil {
+		log.Print(err.Error())
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+		}
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return c.JSON(list)
+}
+
 func NewVentaHandler(ventaService port.VentaService) *VentaHandler {
 	return &VentaHandler{ventaService: ventaService}
 }
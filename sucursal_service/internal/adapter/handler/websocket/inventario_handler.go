@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+
+	"github.com/gofiber/contrib/websocket"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type InventarioHandlerWS struct {
+	rabbitMQService port.RabbitMQService
+}
+
+// ---------- CONSUMIDOR DE ALERTAS POR SUCURSAL ----------
+// Todas las conexiones de la sucursal comparten la clave de la cola, así cada alerta llega a cada administrador conectado
+func (i *InventarioHandlerWS) startAlertasConsumer(queueName string) {
+	getOnceForQueue(queueName).Do(func() {
+		log.Printf("📡 Iniciando consumidor único para cola '%s'", queueName)
+
+		err := i.rabbitMQService.StartConsumer(queueName, func(msg amqp.Delivery) {
+			conns, ok := wsAlertasStockManagers.getConnections(queueName)
+			if ok {
+				conns.Range(func(k, _ any) bool {
+					conn, ok := k.(*websocket.Conn)
+					if !ok {
+						return true
+					}
+					go func(c *websocket.Conn, data []byte) {
+						if err := c.WriteMessage(websocket.TextMessage, data); err != nil {
+							log.Printf("❌ Error enviando WS a %s: %v", queueName, err)
+							wsAlertasStockManagers.removeConnection(queueName, c)
+							_ = c.Close()
+						}
+					}(conn, msg.Body)
+					return true
+				})
+			}
+			_ = msg.Ack(false)
+		}, port.ArgsColaAlertasStock)
+
+		if err != nil {
+			log.Printf("❌ Error iniciando consumidor para %s: %v", queueName, err)
+		}
+	})
+}
+
+// ---------- ALERTAS DE STOCK POR SUCURSAL ----------
+func (i InventarioHandlerWS) AlertasStock(c *websocket.Conn) {
+	userId := fmt.Sprintf("%v", c.Locals("userId"))
+	sucursalId, err := strconv.Atoi(c.Params("sucursalId"))
+	if err != nil || sucursalId <= 0 {
+		log.Printf("⚠️ sucursalId inválido en alertas de stock: %q", c.Params("sucursalId"))
+		_ = c.Close()
+		return
+	}
+	queueName := port.ColaAlertasStock(sucursalId)
+
+	log.Println("🛰️ Usuario conectado:", userId, "a alertas de stock de sucursal:", sucursalId)
+
+	wsAlertasStockManagers.addConnection(queueName, c)
+
+	cm := newConnectionManager(func() {
+		wsAlertasStockManagers.removeConnection(queueName, c)
+
+		if err := c.Close(); err != nil {
+			log.Printf("⚠️ Error al cerrar conexión WS: %v", err)
+		}
+
+		log.Println("❌ Cliente desconectado de alertas de stock - Usuario:", userId, "Sucursal:", sucursalId)
+	})
+	defer cm.close()
+
+	i.startAlertasConsumer(queueName)
+	readLoop(c, cm, fmt.Sprintf("usuario %s (alertas de stock sucursal %d)", userId, sucursalId))
+}
+
+// ---------- CONSTRUCTOR ----------
+func NewInventarioHandlerWS(rabbitMQService port.RabbitMQService) *InventarioHandlerWS {
+	return &InventarioHandlerWS{rabbitMQService: rabbitMQService}
+}
+
+var _ port.InventarioHandlerWS = (*InventarioHandlerWS)(nil)
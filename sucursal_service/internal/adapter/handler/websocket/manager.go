@@ -14,6 +14,7 @@ var (
 	wsUsuariosManagers         = &SyncMap{}
 	wsUsuariosSucursalManagers = &SyncMap{}
 	wsUsuariosBySalaManagers   = &SyncMap{}
+	wsAlertasStockManagers     = &SyncMap{}
 )
 
 // Añadir conexión
@@ -159,7 +159,7 @@ func (s *SalaHandlerWS) startQueueConsumer(queueName string, managerType connect
 }
 
 // ---------- LOOP DE LECTURA GENÉRICO ----------
-func (s *SalaHandlerWS) readLoop(c *websocket.Conn, cm *connectionManager, contextInfo string) {
+func readLoop(c *websocket.Conn, cm *connectionManager, contextInfo string) {
 	c.SetReadLimit(512)
 
 	for {
@@ -208,7 +208,7 @@ func (s SalaHandlerWS) UsoSalasBySucursalId(c *websocket.Conn) {
 	defer cm.close()
 
 	s.startQueueConsumer(queueName, managerTypeSucursal, connectionKey, cm)
-	s.readLoop(c, cm, fmt.Sprintf("usuario %s (sucursal %s)", userId, sucursalIdStr))
+	readLoop(c, cm, fmt.Sprintf("usuario %s (sucursal %s)", userId, sucursalIdStr))
 }
 
 // ---------- USO GENERAL ----------
@@ -235,7 +235,7 @@ func (s SalaHandlerWS) UsoSalas(c *websocket.Conn) {
 	defer cm.close()
 
 	s.startQueueConsumer(queueName, managerTypeUsuarios, connectionKey, cm)
-	s.readLoop(c, cm, fmt.Sprintf("usuario %s (general)", userId))
+	readLoop(c, cm, fmt.Sprintf("usuario %s (general)", userId))
 }
 
 // ---------- USO POR SALA ----------
@@ -263,7 +263,7 @@ func (s SalaHandlerWS) UsoSala(c *websocket.Conn) {
 	defer cm.close()
 
 	s.startQueueConsumer(queueName, managerTypeSala, connectionKey, cm)
-	s.readLoop(c, cm, fmt.Sprintf("usuario %s (sala %s)", userId, salaId))
+	readLoop(c, cm, fmt.Sprintf("usuario %s (sala %s)", userId, salaId))
 }
 
 // ---------- CONSTRUCTOR ----------
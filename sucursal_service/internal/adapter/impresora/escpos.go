@@ -0,0 +1,127 @@
+package impresora
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Alineaciones ESC/POS (ESC a n)
+const (
+	alineacionIzquierda byte = 0
+	alineacionCentro    byte = 1
+	alineacionDerecha   byte = 2
+)
+
+// escPos acumula los comandos de un ticket. El texto se convierte a la página de códigos CP850,
+// que cubre los caracteres del español en la mayoría de impresoras térmicas.
+type escPos struct {
+	buf bytes.Buffer
+}
+
+func nuevoEscPos() *escPos {
+	e := &escPos{}
+	e.buf.Write([]byte{0x1B, 0x40})       // ESC @: reinicia la impresora
+	e.buf.Write([]byte{0x1B, 0x74, 0x02}) // ESC t 2: página de códigos CP850
+	return e
+}
+
+func (e *escPos) alinear(alineacion byte) *escPos {
+	e.buf.Write([]byte{0x1B, 0x61, alineacion})
+	return e
+}
+
+func (e *escPos) negrita(activa bool) *escPos {
+	var n byte
+	if activa {
+		n = 1
+	}
+	e.buf.Write([]byte{0x1B, 0x45, n})
+	return e
+}
+
+// dobleTamano duplica alto y ancho del texto (GS ! n)
+func (e *escPos) dobleTamano(activo bool) *escPos {
+	var n byte
+	if activo {
+		n = 0x11
+	}
+	e.buf.Write([]byte{0x1D, 0x21, n})
+	return e
+}
+
+func (e *escPos) texto(s string) *escPos {
+	e.buf.Write(aCP850(s))
+	return e
+}
+
+func (e *escPos) linea(s string) *escPos {
+	e.texto(s)
+	e.buf.WriteByte('\n')
+	return e
+}
+
+func (e *escPos) avanzar(lineas byte) *escPos {
+	e.buf.Write([]byte{0x1B, 0x64, lineas})
+	return e
+}
+
+// qr imprime un código QR (modelo 2, tamaño de módulo 6, corrección M) con la función GS ( k
+func (e *escPos) qr(datos string) *escPos {
+	contenido := []byte(datos)
+	largo := len(contenido) + 3
+	e.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00})
+	e.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, 0x06})
+	e.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31})
+	e.buf.Write([]byte{0x1D, 0x28, 0x6B, byte(largo % 256), byte(largo / 256), 0x31, 0x50, 0x30})
+	e.buf.Write(contenido)
+	e.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30})
+	e.buf.WriteByte('\n')
+	return e
+}
+
+// cortar avanza el papel y hace un corte parcial (GS V 66 n)
+func (e *escPos) cortar() *escPos {
+	e.buf.Write([]byte{0x1D, 0x56, 0x42, 0x03})
+	return e
+}
+
+func (e *escPos) bytes() []byte {
+	return e.buf.Bytes()
+}
+
+var cp850 = map[rune]byte{
+	'á': 0xA0, 'é': 0x82, 'í': 0xA1, 'ó': 0xA2, 'ú': 0xA3, 'ñ': 0xA4, 'Ñ': 0xA5, 'ü': 0x81, 'Ü': 0x9A,
+	'Á': 0xB5, 'É': 0x90, 'Í': 0xD6, 'Ó': 0xE0, 'Ú': 0xE9, '¡': 0xAD, '¿': 0xA8, '°': 0xF8, 'º': 0xA7, 'ª': 0xA6,
+}
+
+func aCP850(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			out = append(out, byte(r))
+		default:
+			if b, ok := cp850[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// columnas arma una fila con el texto izquierdo y el derecho separados para ocupar el ancho completo;
+// el izquierdo se recorta si no alcanza el espacio.
+func columnas(izquierda, derecha string, ancho int) string {
+	espacio := ancho - utf8.RuneCountInString(derecha) - 1
+	if espacio < 1 {
+		return derecha
+	}
+	runas := []rune(izquierda)
+	if len(runas) > espacio {
+		runas = runas[:espacio]
+	}
+	return string(runas) + strings.Repeat(" ", ancho-len(runas)-utf8.RuneCountInString(derecha)) + derecha
+}
@@ -0,0 +1,171 @@
+package impresora
+
+import (
+	"context"
+	"fmt"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/port"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EscPosImpresora genera tickets ESC/POS y los envía a la impresora por TCP en crudo (RAW, puerto 9100)
+type EscPosImpresora struct {
+	timeout time.Duration
+}
+
+// caracteresPorLinea con la fuente A: 32 en papel de 58mm y 48 en 80mm
+func caracteresPorLinea(anchoPapel string) int {
+	if anchoPapel == domain.AnchoPapel58mm {
+		return 32
+	}
+	return 48
+}
+
+func (e EscPosImpresora) RenderizarTicket(venta *domain.Venta, plantilla *domain.PlantillaComprobante, anchoPapel string) []byte {
+	ancho := caracteresPorLinea(anchoPapel)
+	fMoney := func(val float64) string {
+		return fmt.Sprintf("%.2f", val)
+	}
+	separador := strings.Repeat("-", ancho)
+	t := nuevoEscPos()
+
+	// Cabecera
+	t.alinear(alineacionCentro).negrita(true).dobleTamano(true).linea(plantilla.NombreComercial).dobleTamano(false).negrita(false)
+	if venta.Sucursal != nil {
+		t.linea(venta.Sucursal.Nombre)
+	}
+	if plantilla.Direccion != nil && *plantilla.Direccion != "" {
+		t.linea(*plantilla.Direccion)
+	}
+	if plantilla.Telefono != nil && *plantilla.Telefono != "" {
+		t.linea(fmt.Sprintf("Tel. %s", *plantilla.Telefono))
+	}
+	if plantilla.Nit != nil && *plantilla.Nit != "" {
+		t.linea(fmt.Sprintf("NIT: %s", *plantilla.Nit))
+	}
+	t.linea(separador)
+
+	// Datos de la orden
+	t.negrita(true).linea(fmt.Sprintf("ORDEN #: %06d", venta.CodigoVenta)).negrita(false)
+	t.alinear(alineacionIzquierda)
+	t.linea(fmt.Sprintf("Fecha: %s", venta.CreadoEn.Format("02/01/2006 15:04")))
+	t.linea(fmt.Sprintf("Atendió: %s", venta.Usuario.Username))
+	if venta.Sala != nil {
+		t.linea(fmt.Sprintf("Sala: %s", venta.Sala.Nombre))
+	}
+	if venta.Nit != nil && *venta.Nit != "" {
+		t.linea(fmt.Sprintf("NIT/CI: %s", *venta.Nit))
+	}
+	if venta.RazonSocial != nil && *venta.RazonSocial != "" {
+		t.linea(fmt.Sprintf("Señor(es): %s", *venta.RazonSocial))
+	}
+	t.linea(separador)
+
+	// Detalle
+	var subTotal float64
+	for _, d := range venta.Detalles {
+		totalLinea := float64(d.Cantidad)*d.PrecioVenta - d.Descuento
+		subTotal += totalLinea
+		t.linea(columnas(fmt.Sprintf("%d x %s", d.Cantidad, d.Producto.Nombre), fMoney(totalLinea), ancho))
+		for _, o := range d.Opciones {
+			t.linea(columnas(fmt.Sprintf("  + %s", o.Nombre), "", ancho))
+		}
+		if d.Descuento > 0 {
+			t.linea(columnas("  Descuento", "-"+fMoney(d.Descuento), ancho))
+		}
+	}
+	if venta.CostoTiempoVenta > 0 {
+		totalTiempo := venta.CostoTiempoVenta - venta.DescuentoTiempo
+		subTotal += totalTiempo
+		t.linea(columnas("USO SALA", fMoney(totalTiempo), ancho))
+	}
+	t.linea(separador)
+
+	// Totales
+	t.linea(columnas("Subtotal:", fMoney(subTotal), ancho))
+	if venta.DescuentoGeneral > 0 {
+		t.linea(columnas("Descuento adicional:", "-"+fMoney(venta.DescuentoGeneral), ancho))
+	}
+	t.negrita(true).linea(columnas("TOTAL:", "Bs."+fMoney(venta.Total), ancho)).negrita(false)
+	t.linea(columnas("Base imponible:", fMoney(venta.BaseImponible), ancho))
+	t.linea(columnas("Impuesto:", fMoney(venta.Impuesto), ancho))
+
+	if venta.Pagos != nil && len(*venta.Pagos) > 0 {
+		t.linea(separador)
+		var totalPagado float64
+		for _, p := range *venta.Pagos {
+			totalPagado += p.Monto
+			t.linea(columnas(p.MetodoPago.Nombre, fMoney(p.Monto), ancho))
+		}
+		if totalPagado > venta.Total {
+			t.linea(columnas("Cambio:", fMoney(totalPagado-venta.Total), ancho))
+		}
+	}
+
+	for _, tarjeta := range venta.TarjetasRegalo {
+		t.linea(columnas(fmt.Sprintf("Tarjeta %s", tarjeta.Codigo), fMoney(tarjeta.MontoInicial), ancho))
+	}
+
+	// Factura y QR de verificación
+	t.alinear(alineacionCentro)
+	if doc := venta.DocumentoFiscal; doc != nil && doc.Estado == domain.DocumentoFiscalEmitido && doc.Numero != nil {
+		t.linea(separador)
+		t.linea(fmt.Sprintf("Factura N° %s", *doc.Numero))
+		if doc.CodigoAutorizacion != nil {
+			t.linea(fmt.Sprintf("Cod. Aut.: %s", *doc.CodigoAutorizacion))
+			t.qr(fmt.Sprintf("%s|%s|%s|%.2f", *doc.Numero, *doc.CodigoAutorizacion, venta.CreadoEn.Format("2006-01-02"), venta.Total))
+		}
+	} else {
+		t.qr(fmt.Sprintf("VENTA-%d", venta.CodigoVenta))
+	}
+
+	// Pie
+	t.linea(separador)
+	if plantilla.PiePagina != nil {
+		for _, linea := range strings.Split(*plantilla.PiePagina, "\n") {
+			t.linea(linea)
+		}
+	}
+	return t.avanzar(3).cortar().bytes()
+}
+
+func (e EscPosImpresora) RenderizarPrueba(impresora *domain.Impresora) []byte {
+	ancho := caracteresPorLinea(impresora.AnchoPapel)
+	t := nuevoEscPos()
+	t.alinear(alineacionCentro).negrita(true).dobleTamano(true).linea("PRUEBA").dobleTamano(false).negrita(false)
+	t.linea(impresora.Nombre)
+	t.linea(strings.Repeat("-", ancho))
+	t.alinear(alineacionIzquierda)
+	t.linea(columnas("Dirección:", fmt.Sprintf("%s:%d", impresora.Direccion, impresora.Puerto), ancho))
+	t.linea(columnas("Papel:", impresora.AnchoPapel, ancho))
+	t.linea(columnas("Fecha:", time.Now().Format("02/01/2006 15:04"), ancho))
+	t.linea("Acentos: áéíóú ñ ÁÉÍÓÚ Ñ ¡¿")
+	t.alinear(alineacionDerecha).linea("Derecha")
+	t.alinear(alineacionCentro).qr("MULTIROOM")
+	return t.avanzar(3).cortar().bytes()
+}
+
+func (e EscPosImpresora) Enviar(ctx context.Context, direccion string, puerto int, datos []byte) error {
+	dialer := net.Dialer{Timeout: e.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(direccion, strconv.Itoa(puerto)))
+	if err != nil {
+		return fmt.Errorf("no se pudo conectar con la impresora: %w", err)
+	}
+	defer conn.Close()
+	if err = conn.SetWriteDeadline(time.Now().Add(e.timeout)); err != nil {
+		return err
+	}
+	if _, err = conn.Write(datos); err != nil {
+		return fmt.Errorf("error al enviar datos a la impresora: %w", err)
+	}
+	return nil
+}
+
+func NewEscPosImpresora() *EscPosImpresora {
+	return &EscPosImpresora{timeout: 5 * time.Second}
+}
+
+var _ port.ImpresoraTermica = (*EscPosImpresora)(nil)
@@ -0,0 +1,246 @@
+package impresora
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/service"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// escucharImpresora abre un puerto TCP local que hace de impresora y entrega por el canal lo recibido en cada conexión
+func escucharImpresora(t *testing.T) (net.Listener, <-chan []byte) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("no se pudo abrir el puerto de prueba: %v", err)
+	}
+	recibido := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		datos, _ := io.ReadAll(conn)
+		recibido <- datos
+	}()
+	return ln, recibido
+}
+
+func direccionPuerto(ln net.Listener) (string, int) {
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func ventaDePrueba() *domain.Venta {
+	razonSocial := "Pérez"
+	venta := &domain.Venta{
+		VentaInfo: domain.VentaInfo{
+			CodigoVenta:   42,
+			Total:         50,
+			BaseImponible: 44.25,
+			Impuesto:      5.75,
+			RazonSocial:   &razonSocial,
+			CreadoEn:      time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC),
+			Usuario:       domain.UsuarioSimple{Username: "cajero"},
+		},
+		Detalles: []domain.DetalleVenta{{Cantidad: 2, PrecioVenta: 25}},
+	}
+	venta.Detalles[0].Producto.Nombre = "Hamburguesa doble con queso y tocino"
+	return venta
+}
+
+// lineasTexto separa el ticket en líneas y quita los comandos ESC/GS que las preceden
+func lineasTexto(datos []byte) []string {
+	var lineas []string
+	for _, l := range bytes.Split(datos, []byte{'\n'}) {
+		for len(l) > 0 && (l[0] == 0x1B || l[0] == 0x1D) {
+			switch {
+			case bytes.HasPrefix(l, []byte{0x1B, 0x40}):
+				l = l[2:]
+			case len(l) >= 3:
+				l = l[3:]
+			default:
+				l = nil
+			}
+		}
+		lineas = append(lineas, string(l))
+	}
+	return lineas
+}
+
+func TestEnviarTicket(t *testing.T) {
+	casos := []struct {
+		anchoPapel string
+		ancho      int
+		detalle    string
+	}{
+		{domain.AnchoPapel58mm, 32, "2 x Hamburguesa doble con  50.00"},
+		{domain.AnchoPapel80mm, 48, "2 x Hamburguesa doble con queso y tocino   50.00"},
+	}
+	for _, c := range casos {
+		t.Run(c.anchoPapel, func(t *testing.T) {
+			ln, recibido := escucharImpresora(t)
+			defer ln.Close()
+			direccion, puerto := direccionPuerto(ln)
+
+			impresora := NewEscPosImpresora()
+			ticket := impresora.RenderizarTicket(ventaDePrueba(), &domain.PlantillaComprobante{NombreComercial: "Multiroom"}, c.anchoPapel)
+			if err := impresora.Enviar(context.Background(), direccion, puerto, ticket); err != nil {
+				t.Fatalf("Enviar: %v", err)
+			}
+
+			var datos []byte
+			select {
+			case datos = <-recibido:
+			case <-time.After(2 * time.Second):
+				t.Fatal("la impresora no recibió el ticket")
+			}
+			if !bytes.Equal(datos, ticket) {
+				t.Fatalf("bytes recibidos distintos a los enviados")
+			}
+
+			// ESC @ reinicia y ESC t 2 elige CP850; al final avanza 3 líneas (ESC d 3) y corta (GS V 66 3)
+			if !bytes.HasPrefix(datos, []byte{0x1B, 0x40, 0x1B, 0x74, 0x02}) {
+				t.Errorf("el ticket no inicia con ESC @ ESC t 2: % X", datos[:5])
+			}
+			if !bytes.HasSuffix(datos, []byte{0x1B, 0x64, 0x03, 0x1D, 0x56, 0x42, 0x03}) {
+				t.Errorf("el ticket no termina con avance y corte: % X", datos[len(datos)-7:])
+			}
+			if !bytes.Contains(datos, []byte("Se\xA4or(es): P\x82rez")) {
+				t.Error("los acentos no se convirtieron a CP850")
+			}
+
+			lineas := lineasTexto(datos)
+			var separador, detalle bool
+			for _, l := range lineas {
+				if l == strings.Repeat("-", c.ancho) {
+					separador = true
+				}
+				if l == c.detalle {
+					detalle = true
+				}
+				if strings.HasPrefix(l, "2 x ") || strings.HasPrefix(l, "TOTAL:") || strings.HasPrefix(l, "Subtotal:") {
+					if len(l) != c.ancho {
+						t.Errorf("la fila %q ocupa %d columnas, se esperaban %d", l, len(l), c.ancho)
+					}
+				}
+			}
+			if !separador {
+				t.Errorf("no hay separador de %d columnas", c.ancho)
+			}
+			if !detalle {
+				t.Errorf("no se encontró la fila de detalle %q", c.detalle)
+			}
+		})
+	}
+}
+
+func TestEnviarSinImpresora(t *testing.T) {
+	ln, _ := escucharImpresora(t)
+	direccion, puerto := direccionPuerto(ln)
+	ln.Close()
+
+	impresora := &EscPosImpresora{timeout: time.Second}
+	if err := impresora.Enviar(context.Background(), direccion, puerto, []byte("x")); err == nil {
+		t.Fatal("se esperaba error al enviar a un puerto cerrado")
+	}
+}
+
+// trabajosEnMemoria reproduce la cola de trabajo_impresion: reservar un trabajo lo aparta un minuto y un fallo
+// suma el intento y lo reprograma, o lo deja en Error si no hay próximo intento.
+type trabajosEnMemoria struct {
+	port.ImpresoraRepository
+	trabajo  domain.TrabajoImpresionEnvio
+	estado   string
+	proximo  time.Time
+	ahora    time.Time
+	esperas  []time.Duration
+	impresos int
+}
+
+func (r *trabajosEnMemoria) ReservarTrabajosImpresion(_ context.Context) ([]domain.TrabajoImpresionEnvio, error) {
+	if r.estado != domain.TrabajoImpresionPendiente || r.proximo.After(r.ahora) {
+		return nil, nil
+	}
+	r.proximo = r.ahora.Add(time.Minute)
+	return []domain.TrabajoImpresionEnvio{r.trabajo}, nil
+}
+
+func (r *trabajosEnMemoria) MarcarTrabajoImpreso(_ context.Context, _ int) error {
+	r.trabajo.Intentos++
+	r.estado = domain.TrabajoImpresionImpreso
+	r.impresos++
+	return nil
+}
+
+func (r *trabajosEnMemoria) MarcarTrabajoFallido(_ context.Context, _ int, _ string, proximoIntento *time.Time) error {
+	r.trabajo.Intentos++
+	if proximoIntento == nil {
+		r.estado = domain.TrabajoImpresionError
+		return nil
+	}
+	r.esperas = append(r.esperas, proximoIntento.Sub(time.Now()))
+	r.proximo = *proximoIntento
+	return nil
+}
+
+func TestProcesarTrabajosImpresionReintentos(t *testing.T) {
+	ln, _ := escucharImpresora(t)
+	direccion, puerto := direccionPuerto(ln)
+	ln.Close()
+
+	ahora := time.Now()
+	repo := &trabajosEnMemoria{
+		trabajo: domain.TrabajoImpresionEnvio{Id: 1, ImpresoraId: 1, Direccion: direccion, Puerto: puerto, Contenido: []byte("x")},
+		estado:  domain.TrabajoImpresionPendiente,
+		proximo: ahora,
+		ahora:   ahora,
+	}
+	impresoraService := service.NewImpresoraService(repo, nil, nil, &EscPosImpresora{timeout: time.Second})
+
+	for intento := 1; intento <= domain.MaxIntentosImpresion; intento++ {
+		impresos, err := impresoraService.ProcesarTrabajosImpresion(context.Background())
+		if err != nil {
+			t.Fatalf("intento %d: %v", intento, err)
+		}
+		if impresos != 0 {
+			t.Fatalf("intento %d: se imprimieron %d trabajos sin impresora", intento, impresos)
+		}
+		if repo.trabajo.Intentos != intento {
+			t.Fatalf("intentos = %d, se esperaba %d", repo.trabajo.Intentos, intento)
+		}
+		if intento < domain.MaxIntentosImpresion && repo.estado != domain.TrabajoImpresionPendiente {
+			t.Fatalf("intento %d: el trabajo quedó en %s antes de agotar los intentos", intento, repo.estado)
+		}
+		// Adelanta el reloj de la cola hasta el próximo intento programado
+		repo.ahora = repo.proximo
+	}
+
+	if repo.estado != domain.TrabajoImpresionError {
+		t.Fatalf("tras %d intentos el trabajo quedó en %s, se esperaba %s", domain.MaxIntentosImpresion, repo.estado, domain.TrabajoImpresionError)
+	}
+	if len(repo.esperas) != domain.MaxIntentosImpresion-1 {
+		t.Fatalf("se programaron %d reintentos, se esperaban %d", len(repo.esperas), domain.MaxIntentosImpresion-1)
+	}
+	for i, espera := range repo.esperas {
+		esperada := time.Duration(i+1) * 15 * time.Second
+		if espera > esperada || espera < esperada-5*time.Second {
+			t.Errorf("reintento %d programado a %v, se esperaba ~%v", i+1, espera, esperada)
+		}
+	}
+
+	// Sin más intentos la cola queda vacía
+	if impresos, err := impresoraService.ProcesarTrabajosImpresion(context.Background()); err != nil || impresos != 0 {
+		t.Fatalf("un trabajo en Error no debe procesarse: impresos=%d err=%v", impresos, err)
+	}
+	if repo.trabajo.Intentos != domain.MaxIntentosImpresion {
+		t.Fatalf("intentos = %d tras quedar en Error", repo.trabajo.Intentos)
+	}
+}
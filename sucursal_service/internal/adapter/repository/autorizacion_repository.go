@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AutorizacionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// autorizacionSupervisor es el resultado de validar un PIN o token
+type autorizacionSupervisor struct {
+	SupervisorId int
+	Metodo       string
+	Motivo       *string
+}
+
+// querySupervisorSucursal comprueba que el usuario tenga el permiso de autorizar ventas y esté asignado a la sucursal
+const querySupervisorSucursal = `
+SELECT EXISTS(
+    SELECT 1
+    FROM usuario_admin ua
+    JOIN usuario_admin_rol uar ON uar.usuario_admin_id = ua.id
+    JOIN rol r ON uar.rol_id = r.id AND r.estado = 'Activo'
+    JOIN rol_permiso rp ON rp.rol_id = r.id
+    JOIN permiso p ON rp.permiso_id = p.id
+    JOIN usuario_admin_sucursal uas ON uas.usuario_admin_id = ua.id
+    WHERE ua.id = $1 AND ua.estado = 'Activo' AND p.nombre = $2 AND uas.sucursal_id = $3
+)`
+
+// Tras maxIntentosPin fallidos seguidos el PIN del supervisor queda bloqueado durante bloqueoPin
+const (
+	maxIntentosPin = 5
+	bloqueoPin     = 15 * time.Minute
+)
+
+// intentosPinSupervisor cuenta los PIN fallidos por supervisor. Vive en memoria porque la operación que valida el
+// PIN hace rollback al rechazarlo, y con él se perdería un contador guardado en la misma transacción.
+type intentosPinSupervisor struct {
+	mu       sync.Mutex
+	fallidos map[int]int
+	hasta    map[int]time.Time
+}
+
+var intentosPin = &intentosPinSupervisor{fallidos: make(map[int]int), hasta: make(map[int]time.Time)}
+
+// bloqueado devuelve hasta cuándo sigue bloqueado el PIN del supervisor, si lo está
+func (p *intentosPinSupervisor) bloqueado(supervisorId int) (time.Time, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	hasta, ok := p.hasta[supervisorId]
+	if !ok {
+		return time.Time{}, false
+	}
+	if time.Now().After(hasta) {
+		delete(p.hasta, supervisorId)
+		return time.Time{}, false
+	}
+	return hasta, true
+}
+
+// fallido suma un intento y bloquea el PIN al llegar a maxIntentosPin; devuelve los intentos que quedan
+func (p *intentosPinSupervisor) fallido(supervisorId int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fallidos[supervisorId]++
+	restantes := maxIntentosPin - p.fallidos[supervisorId]
+	if restantes <= 0 {
+		delete(p.fallidos, supervisorId)
+		p.hasta[supervisorId] = time.Now().Add(bloqueoPin)
+	}
+	return restantes
+}
+
+func (p *intentosPinSupervisor) correcto(supervisorId int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.fallidos, supervisorId)
+}
+
+func hashTokenAutorizacion(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// validarAutorizacion exige la autorización de un supervisor de la sucursal. El token se consume en la misma
+// transacción, así que solo sirve para una operación, y debe estar vigente al aplicarla: la hora que informa un POS
+// sin conexión no cuenta, para que no se pueda reutilizar un token vencido fechando la venta hacia atrás.
+func validarAutorizacion(ctx context.Context, tx pgx.Tx, sucursalId int, request *domain.AutorizacionRequest, mensaje string) (*autorizacionSupervisor, error) {
+	if request == nil || ((request.Token == nil || strings.TrimSpace(*request.Token) == "") && (request.Pin == nil || *request.Pin == "")) {
+		return nil, datatype.NewForbiddenError(mensaje)
+	}
+
+	if request.Token != nil && strings.TrimSpace(*request.Token) != "" {
+		var supervisorId int
+		query := `
+            UPDATE token_autorizacion SET usado_en = NOW()
+            WHERE id = (
+                SELECT id FROM token_autorizacion
+                WHERE codigo_hash = $1 AND sucursal_id = $2 AND usado_en IS NULL AND expira_en > NOW()
+                ORDER BY id LIMIT 1
+                FOR UPDATE
+            )
+            RETURNING supervisor_id`
+		err := tx.QueryRow(ctx, query, hashTokenAutorizacion(*request.Token), sucursalId).Scan(&supervisorId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, datatype.NewForbiddenError("El token de autorización no es válido, ya fue usado o expiró.")
+			}
+			log.Println("Error al validar token de autorización:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		return &autorizacionSupervisor{SupervisorId: supervisorId, Metodo: domain.AutorizacionToken, Motivo: request.Motivo}, nil
+	}
+
+	if request.SupervisorId == nil {
+		return nil, datatype.NewBadRequestError("Indique el supervisor que autoriza con su PIN.")
+	}
+
+	if hasta, ok := intentosPin.bloqueado(*request.SupervisorId); ok {
+		return nil, datatype.NewForbiddenError(fmt.Sprintf("El PIN del supervisor está bloqueado por intentos fallidos hasta las %s.", hasta.Format("15:04")))
+	}
+
+	var esSupervisor bool
+	err := tx.QueryRow(ctx, querySupervisorSucursal, *request.SupervisorId, domain.PermisoAutorizarVenta, sucursalId).Scan(&esSupervisor)
+	if err != nil {
+		log.Println("Error al verificar supervisor:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if !esSupervisor {
+		return nil, datatype.NewForbiddenError("El usuario indicado no puede autorizar ventas en esta sucursal.")
+	}
+
+	var pinHash string
+	err = tx.QueryRow(ctx, `SELECT pin_hash FROM pin_supervisor WHERE usuario_admin_id = $1`, *request.SupervisorId).Scan(&pinHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewForbiddenError("El supervisor no tiene un PIN registrado.")
+		}
+		log.Println("Error al obtener PIN de supervisor:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(pinHash), []byte(*request.Pin)); err != nil {
+		log.Printf("PIN de supervisor incorrecto (supervisor %d, sucursal %d)", *request.SupervisorId, sucursalId)
+		if intentosPin.fallido(*request.SupervisorId) <= 0 {
+			return nil, datatype.NewForbiddenError(fmt.Sprintf("PIN incorrecto; se bloqueó por %d minutos tras %d intentos fallidos.", int(bloqueoPin.Minutes()), maxIntentosPin))
+		}
+		return nil, datatype.NewForbiddenError("El PIN del supervisor es incorrecto.")
+	}
+	intentosPin.correcto(*request.SupervisorId)
+	return &autorizacionSupervisor{SupervisorId: *request.SupervisorId, Metodo: domain.AutorizacionPin, Motivo: request.Motivo}, nil
+}
+
+// registrarAutorizacionVenta guarda la excepción aprobada junto a la venta
+func registrarAutorizacionVenta(ctx context.Context, tx pgx.Tx, ventaId int, tipo string, autorizacion *autorizacionSupervisor, usuarioId *int, detalle string) error {
+	query := `
+        INSERT INTO autorizacion_venta (venta_id, tipo, metodo, supervisor_id, usuario_id, motivo, detalle, creado_en)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`
+	_, err := tx.Exec(ctx, query, ventaId, tipo, autorizacion.Metodo, autorizacion.SupervisorId, usuarioId, autorizacion.Motivo, detalle)
+	if err != nil {
+		log.Println("Error al registrar autorización de venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func (a AutorizacionRepository) RegistrarPinSupervisor(ctx context.Context, usuarioId int, pin string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("Error al generar hash del PIN:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	query := `
+        INSERT INTO pin_supervisor (usuario_admin_id, pin_hash, actualizado_en)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (usuario_admin_id) DO UPDATE SET pin_hash = EXCLUDED.pin_hash, actualizado_en = NOW()`
+	_, err = a.pool.Exec(ctx, query, usuarioId, string(hash))
+	if err != nil {
+		log.Println("Error al registrar PIN de supervisor:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func (a AutorizacionRepository) RegistrarTokenAutorizacion(ctx context.Context, usuarioId int, sucursalId int, token string, expiraEn time.Time) error {
+	var esSupervisor bool
+	err := a.pool.QueryRow(ctx, querySupervisorSucursal, usuarioId, domain.PermisoAutorizarVenta, sucursalId).Scan(&esSupervisor)
+	if err != nil {
+		log.Println("Error al verificar supervisor:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if !esSupervisor {
+		return datatype.NewForbiddenError("No está asignado a esta sucursal como supervisor.")
+	}
+
+	query := `
+        INSERT INTO token_autorizacion (codigo_hash, supervisor_id, sucursal_id, expira_en, creado_en)
+        VALUES ($1, $2, $3, $4, NOW())`
+	_, err = a.pool.Exec(ctx, query, hashTokenAutorizacion(token), usuarioId, sucursalId, expiraEn)
+	if err != nil {
+		log.Println("Error al registrar token de autorización:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func (a AutorizacionRepository) ListarAutorizacionesVenta(ctx context.Context, filtros map[string]string) (*[]domain.AutorizacionVenta, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+
+	for _, filtro := range []struct{ clave, columna string }{
+		{"sucursalId", "v.sucursal_id"},
+		{"ventaId", "av.venta_id"},
+		{"supervisorId", "av.supervisor_id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+	if tipo := filtros["tipo"]; tipo != "" {
+		filters = append(filters, fmt.Sprintf("av.tipo = $%d", j))
+		args = append(args, tipo)
+		j++
+	}
+	if fechaInicio := filtros["fechaInicio"]; fechaInicio != "" {
+		filters = append(filters, fmt.Sprintf("av.creado_en >= $%d::date", j))
+		args = append(args, fechaInicio)
+		j++
+	}
+	if fechaFin := filtros["fechaFin"]; fechaFin != "" {
+		filters = append(filters, fmt.Sprintf("av.creado_en < $%d::date + INTERVAL '1 day'", j))
+		args = append(args, fechaFin)
+	}
+
+	query := `
+        SELECT av.id, av.venta_id, v.codigo_venta,
+               json_build_object('id', s.id, 'nombre', s.nombre, 'estado', s.estado, 'creadoEn', s.creado_en),
+               av.tipo, av.metodo, av.detalle, av.motivo,
+               json_build_object('id', sup.id, 'username', sup.username),
+               CASE WHEN u.id IS NOT NULL THEN json_build_object('id', u.id, 'username', u.username) END,
+               av.creado_en
+        FROM autorizacion_venta av
+        JOIN venta v ON av.venta_id = v.id
+        JOIN sucursal s ON v.sucursal_id = s.id
+        JOIN usuario_admin sup ON av.supervisor_id = sup.id
+        LEFT JOIN usuario_admin u ON av.usuario_id = u.id`
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY av.creado_en DESC, av.id DESC"
+
+	rows, err := a.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar autorizaciones de venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.AutorizacionVenta, 0)
+	for rows.Next() {
+		var item domain.AutorizacionVenta
+		err := rows.Scan(&item.Id, &item.VentaId, &item.CodigoVenta, &item.Sucursal, &item.Tipo, &item.Metodo, &item.Detalle, &item.Motivo,
+			&item.Supervisor, &item.Usuario, &item.CreadoEn)
+		if err != nil {
+			log.Println("Error al escanear autorización de venta:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func NewAutorizacionRepository(pool *pgxpool.Pool) *AutorizacionRepository {
+	return &AutorizacionRepository{pool: pool}
+}
+
+var _ port.AutorizacionRepository = (*AutorizacionRepository)(nil)
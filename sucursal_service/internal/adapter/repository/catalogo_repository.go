@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CatalogoRepository struct {
+	pool *pgxpool.Pool
+}
+
+// cambioDesde indica si la fila la escribió una transacción que seguía abierta (o aún no existía) al tomar el cursor
+// anterior ($1). xmin es de 32 bits: se lleva a 64 bits restándolo del xmax de la instantánea actual ($2).
+func cambioDesde(xmin string) string {
+	return fmt.Sprintf("$2::bigint - (($2::bigint - %s::text::bigint) %% 4294967296 + 4294967296) %% 4294967296 >= $1", xmin)
+}
+
+// filtroCambiosCatalogo limita la consulta a las filas escritas desde el cursor; sin cursor ($1 NULL) devuelve todo.
+func filtroCambiosCatalogo(xmins ...string) string {
+	condiciones := make([]string, len(xmins))
+	for i, xmin := range xmins {
+		condiciones[i] = cambioDesde(xmin)
+	}
+	return "($1::bigint IS NULL OR " + strings.Join(condiciones, " OR ") + ")"
+}
+
+func (c CatalogoRepository) ObtenerCambiosCatalogo(ctx context.Context, sucursalId *int, cursor *string) (*domain.CambiosCatalogo, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	var desde *int64
+	if cursor != nil && *cursor != "" {
+		xid, err := strconv.ParseInt(*cursor, 10, 64)
+		if err != nil || xid <= 0 {
+			return nil, datatype.NewBadRequestError("El cursor no es válido; vuelva a sincronizar sin cursor.")
+		}
+		desde = &xid
+	}
+
+	// Todas las consultas leen la misma foto de la base para que el cursor sea consistente
+	tx, err := c.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer func() {
+		if rollErr := tx.Rollback(ctx); rollErr != nil {
+			log.Println("Error durante rollback:", rollErr)
+		}
+	}()
+
+	var sucursalExiste bool
+	if err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM sucursal WHERE id = $1)`, *sucursalId).Scan(&sucursalExiste); err != nil {
+		log.Println("Error al validar sucursal:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if !sucursalExiste {
+		return nil, datatype.NewNotFoundError("Sucursal no encontrada")
+	}
+
+	// El cursor sigue el orden de confirmación: es el xmin de la instantánea, el menor xid que aún no había terminado.
+	// La próxima sincronización trae todo lo escrito desde ese xid, incluidas las transacciones largas que
+	// confirmaron después de esta lectura; lo que ya estaba confirmado por encima del xmin puede llegar dos veces.
+	var siguiente, corte int64
+	queryInstantanea := `
+        SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint, pg_snapshot_xmax(pg_current_snapshot())::text::bigint`
+	if err = tx.QueryRow(ctx, queryInstantanea).Scan(&siguiente, &corte); err != nil {
+		log.Println("Error al obtener cursor del catálogo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	cambios := domain.CambiosCatalogo{
+		Cursor:      strconv.FormatInt(siguiente, 10),
+		Completo:    desde == nil,
+		Productos:   make([]domain.ProductoCatalogo, 0),
+		Categorias:  make([]domain.ProductoCategoria, 0),
+		Salas:       make([]domain.Sala, 0),
+		MetodosPago: make([]domain.MetodoPagoCatalogo, 0),
+		Ubicaciones: make([]domain.UbicacionCatalogo, 0),
+		Eliminados:  make([]domain.EntidadEliminada, 0),
+	}
+	// eliminado agrega la marca; en la sincronización completa las entidades fuera del catálogo simplemente no viajan
+	eliminado := func(entidad string, id int, eliminadoEn time.Time) {
+		if desde != nil {
+			cambios.Eliminados = append(cambios.Eliminados, domain.EntidadEliminada{Entidad: entidad, Id: id, EliminadoEn: eliminadoEn})
+		}
+	}
+
+	// 1. Productos con precio y estado de la sucursal; los eliminados o desactivados (global o en la sucursal) salen
+	// del catálogo del POS como tombstone
+	queryProductos := `
+        SELECT x.id, x.producto_sucursal_id, x.nombre, x.url_foto, x.es_inventariable, x.es_tarjeta_regalo, x.categoria_id,
+               x.precio, x.estado, x.estado_sucursal, x.actualizado_en, x.eliminado_en
+        FROM (
+            SELECT p.id, ps.id AS producto_sucursal_id, p.nombre,
+                   COALESCE($4::text || p.id::text || '/' || p.foto, '') AS url_foto,
+                   p.es_inventariable, p.es_tarjeta_regalo, p.categoria_id, ps.precio, p.estado, ps.estado AS estado_sucursal,
+                   GREATEST(COALESCE(p.actualizado_en, p.creado_en), ps.actualizado_en, p.eliminado_en) AS actualizado_en,
+                   p.eliminado_en, p.xmin AS xmin_producto, ps.xmin AS xmin_sucursal
+            FROM producto_sucursal ps
+            JOIN producto p ON p.id = ps.producto_id
+            WHERE ps.sucursal_id = $3
+        ) x
+        WHERE ` + filtroCambiosCatalogo("x.xmin_producto", "x.xmin_sucursal") + `
+        ORDER BY x.id`
+	rows, err := tx.Query(ctx, queryProductos, desde, corte, *sucursalId, fullHostname)
+	if err != nil {
+		log.Println("Error al obtener cambios de productos:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	for rows.Next() {
+		var item domain.ProductoCatalogo
+		var eliminadoEn *time.Time
+		if err = rows.Scan(&item.Id, &item.ProductoSucursalId, &item.Nombre, &item.UrlFoto, &item.EsInventariable, &item.EsTarjetaRegalo,
+			&item.CategoriaId, &item.Precio, &item.Estado, &item.EstadoSucursal, &item.ActualizadoEn, &eliminadoEn); err != nil {
+			rows.Close()
+			log.Println("Error al escanear producto del catálogo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if eliminadoEn != nil || item.Estado != "Activo" || item.EstadoSucursal != "Activo" {
+			eliminado(domain.EntidadProducto, item.Id, item.ActualizadoEn)
+			continue
+		}
+		cambios.Productos = append(cambios.Productos, item)
+	}
+	rows.Close()
+
+	// 2. Categorías (globales); las desactivadas viajan como tombstone
+	queryCategorias := `
+        SELECT x.id, x.nombre, COALESCE(x.descripcion, ''), x.estado, x.creado_en, COALESCE(x.actualizado_en, x.creado_en)
+        FROM categoria_producto x
+        WHERE ` + filtroCambiosCatalogo("x.xmin") + `
+        ORDER BY x.id`
+	rows, err = tx.Query(ctx, queryCategorias, desde, corte)
+	if err != nil {
+		log.Println("Error al obtener cambios de categorías:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	for rows.Next() {
+		var item domain.ProductoCategoria
+		if err = rows.Scan(&item.Id, &item.Nombre, &item.Descripcion, &item.Estado, &item.CreadoEn, &item.ActualizadoEn); err != nil {
+			rows.Close()
+			log.Println("Error al escanear categoría del catálogo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if item.Estado != "Activo" {
+			eliminado(domain.EntidadCategoria, item.Id, item.ActualizadoEn)
+			continue
+		}
+		cambios.Categorias = append(cambios.Categorias, item)
+	}
+	rows.Close()
+
+	// 3. Salas de la sucursal; las eliminadas o desactivadas viajan como tombstone
+	querySalas := `
+        SELECT x.id, x.nombre, x.estado, x.creado_en, GREATEST(COALESCE(x.actualizado_en, x.creado_en), x.eliminado_en), x.eliminado_en
+        FROM sala x
+        WHERE x.sucursal_id = $3 AND ` + filtroCambiosCatalogo("x.xmin") + `
+        ORDER BY x.id`
+	rows, err = tx.Query(ctx, querySalas, desde, corte, *sucursalId)
+	if err != nil {
+		log.Println("Error al obtener cambios de salas:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	for rows.Next() {
+		var item domain.Sala
+		if err = rows.Scan(&item.Id, &item.Nombre, &item.Estado, &item.CreadoEn, &item.ActualizadoEn, &item.EliminadoEn); err != nil {
+			rows.Close()
+			log.Println("Error al escanear sala del catálogo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if item.EliminadoEn != nil || item.Estado != "Activo" {
+			eliminado(domain.EntidadSala, item.Id, item.ActualizadoEn)
+			continue
+		}
+		cambios.Salas = append(cambios.Salas, item)
+	}
+	rows.Close()
+
+	// 4. Métodos de pago (globales); los desactivados viajan como tombstone
+	queryMetodosPago := `
+        SELECT x.id, x.nombre, x.codigo, x.estado, x.actualizado_en
+        FROM metodo_pago x
+        WHERE ` + filtroCambiosCatalogo("x.xmin") + `
+        ORDER BY x.id`
+	rows, err = tx.Query(ctx, queryMetodosPago, desde, corte)
+	if err != nil {
+		log.Println("Error al obtener cambios de métodos de pago:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	for rows.Next() {
+		var item domain.MetodoPagoCatalogo
+		if err = rows.Scan(&item.Id, &item.Nombre, &item.Codigo, &item.Estado, &item.ActualizadoEn); err != nil {
+			rows.Close()
+			log.Println("Error al escanear método de pago del catálogo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if item.Estado != "Activo" {
+			eliminado(domain.EntidadMetodoPago, item.Id, item.ActualizadoEn)
+			continue
+		}
+		cambios.MetodosPago = append(cambios.MetodosPago, item)
+	}
+	rows.Close()
+
+	// 5. Ubicaciones de la sucursal; las desactivadas viajan como tombstone
+	queryUbicaciones := `
+        SELECT x.id, x.nombre, x.estado, x.es_vendible, x.prioridad_venta, x.actualizado_en
+        FROM ubicacion x
+        WHERE x.sucursal_id = $3 AND ` + filtroCambiosCatalogo("x.xmin") + `
+        ORDER BY x.prioridad_venta, x.id`
+	rows, err = tx.Query(ctx, queryUbicaciones, desde, corte, *sucursalId)
+	if err != nil {
+		log.Println("Error al obtener cambios de ubicaciones:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	for rows.Next() {
+		var item domain.UbicacionCatalogo
+		if err = rows.Scan(&item.Id, &item.Nombre, &item.Estado, &item.EsVendible, &item.PrioridadVenta, &item.ActualizadoEn); err != nil {
+			rows.Close()
+			log.Println("Error al escanear ubicación del catálogo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if item.Estado != "Activo" {
+			eliminado(domain.EntidadUbicacion, item.Id, item.ActualizadoEn)
+			continue
+		}
+		cambios.Ubicaciones = append(cambios.Ubicaciones, item)
+	}
+	rows.Close()
+
+	// 6. Entidades que dejaron la sucursal (una sala trasladada a otra); no aplica si volvió a ella
+	if desde != nil {
+		queryBajas := `
+            SELECT x.entidad, x.entidad_id, x.eliminado_en
+            FROM catalogo_baja x
+            WHERE x.sucursal_id = $3 AND ` + filtroCambiosCatalogo("x.xmin") + `
+              AND NOT (x.entidad = 'sala' AND EXISTS(SELECT 1 FROM sala s WHERE s.id = x.entidad_id AND s.sucursal_id = x.sucursal_id))
+            ORDER BY x.id`
+		rows, err = tx.Query(ctx, queryBajas, desde, corte, *sucursalId)
+		if err != nil {
+			log.Println("Error al obtener bajas del catálogo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		for rows.Next() {
+			var item domain.EntidadEliminada
+			if err = rows.Scan(&item.Entidad, &item.Id, &item.EliminadoEn); err != nil {
+				rows.Close()
+				log.Println("Error al escanear baja del catálogo:", err)
+				return nil, datatype.NewInternalServerErrorGeneric()
+			}
+			cambios.Eliminados = append(cambios.Eliminados, item)
+		}
+		rows.Close()
+	}
+
+	return &cambios, nil
+}
+
+func NewCatalogoRepository(pool *pgxpool.Pool) *CatalogoRepository {
+	return &CatalogoRepository{pool: pool}
+}
+
+var _ port.CatalogoRepository = (*CatalogoRepository)(nil)
@@ -9,8 +9,10 @@ import (
 	"multiroom/sucursal-service/internal/core/domain"
 	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -89,6 +91,7 @@ SELECT
     	'email',p.email,
     	'telefono',p.telefono,
     	'celular',p.celular,
+    	'diasEntrega',p.dias_entrega,
     	'creadoEn',p.creado_en,
     	'actualizadoEn',p.actualizado_en,
     	'eliminadoEn',p.eliminado_en
@@ -149,18 +152,31 @@ SELECT
        'email',p.email,
        'telefono',p.telefono,
        'celular',p.celular,
+       'diasEntrega',p.dias_entrega,
        'creadoEn',p.creado_en,
        'actualizadoEn',p.actualizado_en,
        'eliminadoEn',p.eliminado_en
     ) AS proveedor,
+    c.saldo_cancelado_en,
+    CASE WHEN uc.id IS NULL THEN NULL ELSE json_build_object(
+       'id',uc.id,
+       'username',uc.username
+    ) END AS saldo_cancelado_por,
+    c.motivo_cancelacion,
     COALESCE(
        json_agg(
           CASE 
              WHEN dc.id IS NULL THEN NULL
              ELSE json_build_object(
+                'id',dc.id,
                 'cantidad',dc.cantidad,
+                'cantidadRecibida',dc.cantidad_recibida,
+                'cantidadCancelada',dc.cantidad_cancelada,
+                'cantidadPendiente',dc.cantidad - dc.cantidad_recibida - dc.cantidad_cancelada,
                 'precioCompra',dc.precio_compra,
                 'precioVenta',dc.precio_venta,
+                'lote',dc.lote,
+                'fechaVencimiento',dc.fecha_vencimiento::text,
                 'producto', json_build_object(
                 	'id',pr.id,
                    	'nombre',pr.nombre,
@@ -186,15 +202,17 @@ FROM compra c
 LEFT JOIN public.sucursal s on c.sucursal_id = s.id
 LEFT JOIN public.proveedor p on p.id = c.proveedor_id
 LEFT JOIN public.usuario_admin ua on ua.id = c.usuario_admin_id
+LEFT JOIN public.usuario_admin uc on uc.id = c.saldo_cancelado_por
 LEFT JOIN public.detalle_compra dc on c.id = dc.compra_id
 LEFT JOIN public.producto pr on dc.producto_id = pr.id
 LEFT JOIN public.ubicacion ub on dc.ubicacion_id = ub.id
 WHERE c.id=$2 
-GROUP BY c.id,p.id,ua.id,s.id`
+GROUP BY c.id,p.id,ua.id,uc.id,s.id`
 
 	var item domain.Compra
 	err := c.pool.QueryRow(ctx, query, fullHostname, *id).
-		Scan(&item.Id, &item.CodigoCompra, &item.Estado, &item.CreadoEn, &item.ActualizadoEn, &item.EliminadoEn, &item.Usuario, &item.Sucursal, &item.Proveedor, &item.Detalles)
+		Scan(&item.Id, &item.CodigoCompra, &item.Estado, &item.CreadoEn, &item.ActualizadoEn, &item.EliminadoEn, &item.Usuario, &item.Sucursal, &item.Proveedor,
+			&item.SaldoCanceladoEn, &item.SaldoCanceladoPor, &item.MotivoCancelacion, &item.Detalles)
 	if err != nil {
 		log.Println("Error al obtener compra:", err)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -311,6 +329,11 @@ func (c CompraRepository) RegistrarOrdenCompra(ctx context.Context, request *dom
 			}
 		}
 
+		fechaVencimiento, err := fechaVencimientoLote(detalle.FechaVencimiento)
+		if err != nil {
+			return nil, err
+		}
+
 		rows = append(rows, []interface{}{
 			compraId,
 			detalle.ProductoId,
@@ -318,10 +341,12 @@ func (c CompraRepository) RegistrarOrdenCompra(ctx context.Context, request *dom
 			detalle.Cantidad,
 			detalle.PrecioCompra,
 			precioVentaFinal, // Guardamos el precio correcto
+			detalle.Lote,
+			fechaVencimiento,
 		})
 	}
 
-	columnas := []string{"compra_id", "producto_id", "ubicacion_id", "cantidad", "precio_compra", "precio_venta"}
+	columnas := []string{"compra_id", "producto_id", "ubicacion_id", "cantidad", "precio_compra", "precio_venta", "lote", "fecha_vencimiento"}
 
 	copyCount, err := tx.CopyFrom(
 		ctx,
@@ -493,6 +518,11 @@ func (c CompraRepository) ModificarOrdenCompra(ctx context.Context, id *int, req
 			}
 		}
 
+		fechaVencimiento, err := fechaVencimientoLote(detalle.FechaVencimiento)
+		if err != nil {
+			return err
+		}
+
 		rows = append(rows, []interface{}{
 			*id, // ID de la compra existente
 			detalle.ProductoId,
@@ -500,10 +530,12 @@ func (c CompraRepository) ModificarOrdenCompra(ctx context.Context, id *int, req
 			detalle.Cantidad,
 			detalle.PrecioCompra,
 			precioVentaFinal, // Usamos el precio calculado
+			detalle.Lote,
+			fechaVencimiento,
 		})
 	}
 
-	columnas := []string{"compra_id", "producto_id", "ubicacion_id", "cantidad", "precio_compra", "precio_venta"}
+	columnas := []string{"compra_id", "producto_id", "ubicacion_id", "cantidad", "precio_compra", "precio_venta", "lote", "fecha_vencimiento"}
 
 	copyCount, err := tx.CopyFrom(
 		ctx,
@@ -530,6 +562,7 @@ func (c CompraRepository) ModificarOrdenCompra(ctx context.Context, id *int, req
 	return nil
 }
 
+// ConfirmarRecepcionCompra recibe todo lo pendiente de la compra en la ubicación de cada línea
 func (c CompraRepository) ConfirmarRecepcionCompra(ctx context.Context, id *int) error {
 	// Iniciar transacción
 	tx, err := c.pool.Begin(ctx)
@@ -546,94 +579,510 @@ func (c CompraRepository) ConfirmarRecepcionCompra(ctx context.Context, id *int)
 		}
 	}()
 
+	var usuarioId *int
+	if uid, ok := ctx.Value(util.ContextUserIdKey).(int); ok {
+		usuarioId = &uid
+	}
+	if _, err = recibirCompra(ctx, tx, *id, &domain.RecepcionCompraRequest{}, usuarioId); err != nil {
+		return err
+	}
+
+	// Confirmar transacción
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	committed = true
+	return nil
+}
+
+func (c CompraRepository) RegistrarRecepcionCompra(ctx context.Context, compraId int, request *domain.RecepcionCompraRequest, usuarioId int) (*int, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	recepcionId, err := recibirCompra(ctx, tx, compraId, request, &usuarioId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return &recepcionId, nil
+}
+
+// lineaCompra es una línea de detalle_compra con lo que aún falta recibir
+type lineaCompra struct {
+	Id               int
+	ProductoId       int
+	UbicacionId      int
+	Pendiente        int
+	PrecioCompra     float64
+	Lote             *string
+	FechaVencimiento *time.Time
+}
+
+// recibirCompra registra una recepción dentro de la transacción: suma stock, lotes, costo promedio y kardex de lo
+// recibido, actualiza el precio de venta de esos productos y deja la compra Parcialmente recibida o Completado.
+// Sin detalles en el request recibe todo lo pendiente en la ubicación de cada línea.
+func recibirCompra(ctx context.Context, tx pgx.Tx, compraId int, request *domain.RecepcionCompraRequest, usuarioId *int) (int, error) {
 	// Verificar estado de la compra (y bloquear la fila)
 	var estadoActual string
-	queryEstado := `SELECT estado FROM compra WHERE id = $1 FOR UPDATE`
-	err = tx.QueryRow(ctx, queryEstado, *id).Scan(&estadoActual)
-
+	var usuarioCompraId *int
+	var sucursalId int
+	queryEstado := `SELECT estado, usuario_admin_id, sucursal_id FROM compra WHERE id = $1 FOR UPDATE`
+	err := tx.QueryRow(ctx, queryEstado, compraId).Scan(&estadoActual, &usuarioCompraId, &sucursalId)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return datatype.NewNotFoundError("Compra no encontrada")
+			return 0, datatype.NewNotFoundError("Compra no encontrada")
 		}
 		log.Println("Error al obtener estado de la compra:", err)
-		return datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	if estadoActual != domain.CompraPendiente && estadoActual != domain.CompraParcialmenteRecibida {
+		log.Printf("Intento de recibir compra %d con estado %s\n", compraId, estadoActual)
+		return 0, datatype.NewBadRequestError(fmt.Sprintf("La compra está '%s' y no admite recepciones.", estadoActual))
+	}
+	if usuarioId == nil {
+		usuarioId = usuarioCompraId
 	}
 
-	if estadoActual != "Pendiente" {
-		log.Printf("Intento de confirmar compra %d con estado %s\n", *id, estadoActual)
-		return datatype.NewBadRequestError("Esta compra no está pendiente, no se puede confirmar.")
-	}
-
-	// Actualizar inventario
-	queryUpsertInventario := `
-       INSERT INTO inventario (producto_id, ubicacion_id, stock)
-       (
-          SELECT 
-             producto_id, 
-             ubicacion_id, 
-             cantidad 
-          FROM detalle_compra 
-          WHERE compra_id = $1
-       )
-       ON CONFLICT (producto_id, ubicacion_id) 
-       DO UPDATE SET
-          stock = inventario.stock + EXCLUDED.stock;
-    `
-	_, err = tx.Exec(ctx, queryUpsertInventario, *id)
+	rows, err := tx.Query(ctx, `
+        SELECT id, producto_id, ubicacion_id, cantidad - cantidad_recibida - cantidad_cancelada, precio_compra, lote, fecha_vencimiento
+        FROM detalle_compra WHERE compra_id = $1 ORDER BY id FOR UPDATE`, compraId)
 	if err != nil {
-		log.Println("Error al actualizar el inventario (UPSERT):", err)
-		return datatype.NewInternalServerErrorGeneric()
+		log.Println("Error al obtener detalles de la compra:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	lineas := make(map[int]lineaCompra)
+	var orden []int
+	for rows.Next() {
+		var l lineaCompra
+		if err := rows.Scan(&l.Id, &l.ProductoId, &l.UbicacionId, &l.Pendiente, &l.PrecioCompra, &l.Lote, &l.FechaVencimiento); err != nil {
+			rows.Close()
+			log.Println("Error al escanear detalle de compra:", err)
+			return 0, datatype.NewInternalServerErrorGeneric()
+		}
+		lineas[l.Id] = l
+		orden = append(orden, l.Id)
+	}
+	rows.Close()
+
+	detalles := request.Detalles
+	if len(detalles) == 0 {
+		for _, id := range orden {
+			if lineas[id].Pendiente > 0 {
+				detalles = append(detalles, domain.DetalleRecepcionCompraRequest{DetalleCompraId: id, Cantidad: lineas[id].Pendiente})
+			}
+		}
+		if len(detalles) == 0 {
+			return 0, datatype.NewBadRequestError("La compra no tiene cantidades pendientes de recibir.")
+		}
+	}
+
+	// Resolver ubicación y lote de cada línea recibida
+	var movimientos []movimientoInventario
+	var lotes []*string
+	var vencimientos []*time.Time
+	var detalleCompraIds, idsUbicaciones []int
+	mapUbicaciones := make(map[int]struct{})
+	for _, detalle := range detalles {
+		linea, ok := lineas[detalle.DetalleCompraId]
+		if !ok {
+			return 0, datatype.NewBadRequestError(fmt.Sprintf("La línea %d no pertenece a la compra.", detalle.DetalleCompraId))
+		}
+		if detalle.Cantidad > linea.Pendiente {
+			return 0, datatype.NewBadRequestError(fmt.Sprintf(
+				"La línea %d (producto %d) tiene %d unidades pendientes; no se pueden recibir %d.",
+				linea.Id, linea.ProductoId, linea.Pendiente, detalle.Cantidad,
+			))
+		}
+
+		ubicacionId := linea.UbicacionId
+		if detalle.UbicacionId != nil {
+			ubicacionId = *detalle.UbicacionId
+		} else if request.UbicacionId != nil {
+			ubicacionId = *request.UbicacionId
+		}
+		if _, ok := mapUbicaciones[ubicacionId]; !ok {
+			mapUbicaciones[ubicacionId] = struct{}{}
+			idsUbicaciones = append(idsUbicaciones, ubicacionId)
+		}
+
+		lote, fechaVencimiento := linea.Lote, linea.FechaVencimiento
+		if detalle.FechaVencimiento != nil {
+			if fechaVencimiento, err = fechaVencimientoLote(detalle.FechaVencimiento); err != nil {
+				return 0, err
+			}
+			lote = detalle.Lote
+		}
+
+		costo := linea.PrecioCompra
+		movimientos = append(movimientos, movimientoInventario{ProductoId: linea.ProductoId, UbicacionId: ubicacionId, Cantidad: detalle.Cantidad, CostoUnitario: &costo})
+		lotes = append(lotes, lote)
+		vencimientos = append(vencimientos, fechaVencimiento)
+		detalleCompraIds = append(detalleCompraIds, linea.Id)
+	}
+
+	var countUbicaciones int
+	queryValidarUbi := `SELECT COUNT(id) FROM ubicacion WHERE sucursal_id = $1 AND id = ANY($2)`
+	err = tx.QueryRow(ctx, queryValidarUbi, sucursalId, idsUbicaciones).Scan(&countUbicaciones)
+	if err != nil || countUbicaciones != len(idsUbicaciones) {
+		return 0, datatype.NewBadRequestError("Una ubicación no es válida o no pertenece a la sucursal de la compra.")
+	}
+
+	// Documento de la recepción
+	var recepcionId int
+	err = tx.QueryRow(ctx, `INSERT INTO recepcion_compra (compra_id, usuario_id, observacion, creado_en) VALUES ($1, $2, $3, NOW()) RETURNING id`,
+		compraId, usuarioId, request.Observacion).Scan(&recepcionId)
+	if err != nil {
+		log.Println("Error al registrar recepción de compra:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+
+	// Actualizar inventario línea por línea para dejar cada entrada en el kardex con su costo
+	var rowsDetalle [][]interface{}
+	for i := range movimientos {
+		// El costo promedio se recalcula antes de sumar el stock recibido
+		if err = actualizarCostoPromedio(ctx, tx, movimientos[i].ProductoId, sucursalId, movimientos[i].Cantidad, *movimientos[i].CostoUnitario); err != nil {
+			return 0, err
+		}
+		movimientos[i].Saldo, err = sumarStock(ctx, tx, movimientos[i].ProductoId, movimientos[i].UbicacionId, movimientos[i].Cantidad)
+		if err != nil {
+			log.Println("Error al actualizar el inventario (UPSERT):", err)
+			return 0, datatype.NewInternalServerErrorGeneric()
+		}
+		if vencimientos[i] != nil {
+			var lote string
+			if lotes[i] != nil {
+				lote = *lotes[i]
+			}
+			if err = sumarLote(ctx, tx, movimientos[i].ProductoId, movimientos[i].UbicacionId, lote, *vencimientos[i], movimientos[i].Cantidad); err != nil {
+				return 0, err
+			}
+		}
+		_, err = tx.Exec(ctx, `UPDATE detalle_compra SET cantidad_recibida = cantidad_recibida + $1 WHERE id = $2`, movimientos[i].Cantidad, detalleCompraIds[i])
+		if err != nil {
+			log.Println("Error al actualizar cantidad recibida:", err)
+			return 0, datatype.NewInternalServerErrorGeneric()
+		}
+		rowsDetalle = append(rowsDetalle, []interface{}{
+			recepcionId,
+			detalleCompraIds[i],
+			movimientos[i].ProductoId,
+			movimientos[i].UbicacionId,
+			movimientos[i].Cantidad,
+			*movimientos[i].CostoUnitario,
+			lotes[i],
+			vencimientos[i],
+		})
 	}
 
-	// Actualizar precios en la tabla 'producto_sucursal' ---
-	// Actualizar precios en la tabla 'producto_sucursal'
+	columnas := []string{"recepcion_compra_id", "detalle_compra_id", "producto_id", "ubicacion_id", "cantidad", "costo_unitario", "lote", "fecha_vencimiento"}
+	copyCount, err := tx.CopyFrom(ctx, pgx.Identifier{"detalle_recepcion_compra"}, columnas, pgx.CopyFromRows(rowsDetalle))
+	if err != nil {
+		log.Println("Error copyFrom:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	if int(copyCount) != len(rowsDetalle) {
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+
+	if err = registrarMovimientosInventario(ctx, tx, domain.DocumentoCompra, compraId, usuarioId, movimientos); err != nil {
+		return 0, err
+	}
+
+	// Actualizar precios en la tabla 'producto_sucursal' de los productos recibidos
 	queryUpdatePrecios := `
         UPDATE producto_sucursal AS ps
         SET 
-            precio = dc.precio_venta
+            precio = dc.precio_venta,
+            actualizado_en = NOW()
         FROM (
             SELECT DISTINCT ON (producto_id)
                 producto_id,
                 precio_venta
             FROM detalle_compra
-            WHERE compra_id = $1
+            WHERE compra_id = $1 AND id = ANY($2)
         ) AS dc
         WHERE ps.producto_id = dc.producto_id
-          AND ps.sucursal_id = (SELECT sucursal_id FROM compra WHERE id = $1);
+          AND ps.sucursal_id = $3
+          AND ps.precio IS DISTINCT FROM dc.precio_venta;
     `
 
-	cmdTag, err := tx.Exec(ctx, queryUpdatePrecios, *id)
+	cmdTag, err := tx.Exec(ctx, queryUpdatePrecios, compraId, detalleCompraIds, sucursalId)
 	if err != nil {
 		log.Println("Error al actualizar precios de productos:", err)
-		return datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
 	}
 
 	// Opcional: Loguear cuántos precios se actualizaron
 	log.Printf("Se actualizaron precios de %d productos en la sucursal", cmdTag.RowsAffected())
 
-	// Actualizar estado de compra
-	queryUpdateCompra := `UPDATE compra SET estado = 'Completado', actualizado_en = CURRENT_TIMESTAMP WHERE id = $1`
-
-	ct, err := tx.Exec(ctx, queryUpdateCompra, *id)
+	// La compra se completa cuando no queda nada pendiente en ninguna línea
+	queryUpdateCompra := `
+        UPDATE compra SET
+            estado = CASE WHEN EXISTS (
+                SELECT 1 FROM detalle_compra WHERE compra_id = $1 AND cantidad_recibida + cantidad_cancelada < cantidad
+            ) THEN $2 ELSE $3 END,
+            actualizado_en = CURRENT_TIMESTAMP
+        WHERE id = $1`
+	_, err = tx.Exec(ctx, queryUpdateCompra, compraId, domain.CompraParcialmenteRecibida, domain.CompraCompletada)
 	if err != nil {
 		log.Println("Error al actualizar estado de la compra:", err)
-		return datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
 	}
 
-	if ct.RowsAffected() == 0 {
-		log.Println("Error, la compra no se actualizó (RowsAffected 0)")
-		return datatype.NewInternalServerErrorGeneric()
+	return recepcionId, nil
+}
+
+// CancelarSaldoCompra da por cancelado lo que falta recibir y cierra la compra: Completado si se recibió algo,
+// Cancelado si no
+func (c CompraRepository) CancelarSaldoCompra(ctx context.Context, compraId int, motivo string, usuarioId int) (string, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return "", datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	var estadoActual string
+	err = tx.QueryRow(ctx, `SELECT estado FROM compra WHERE id = $1 FOR UPDATE`, compraId).Scan(&estadoActual)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", datatype.NewNotFoundError("Compra no encontrada")
+		}
+		log.Println("Error al obtener estado de la compra:", err)
+		return "", datatype.NewInternalServerErrorGeneric()
+	}
+	if estadoActual != domain.CompraPendiente && estadoActual != domain.CompraParcialmenteRecibida {
+		return "", datatype.NewBadRequestError(fmt.Sprintf("La compra está '%s' y no tiene saldo pendiente.", estadoActual))
+	}
+
+	var recibido bool
+	err = tx.QueryRow(ctx, `
+        WITH cancelado AS (
+            UPDATE detalle_compra SET cantidad_cancelada = cantidad - cantidad_recibida
+            WHERE compra_id = $1
+            RETURNING cantidad_recibida
+        )
+        SELECT COALESCE(bool_or(cantidad_recibida > 0), false) FROM cancelado`, compraId).Scan(&recibido)
+	if err != nil {
+		log.Println("Error al cancelar saldo de la compra:", err)
+		return "", datatype.NewInternalServerErrorGeneric()
+	}
+
+	estado := domain.CompraCancelada
+	if recibido {
+		estado = domain.CompraCompletada
+	}
+	_, err = tx.Exec(ctx, `
+        UPDATE compra SET estado = $1, saldo_cancelado_en = NOW(), saldo_cancelado_por = $2, motivo_cancelacion = $3,
+            actualizado_en = CURRENT_TIMESTAMP
+        WHERE id = $4`, estado, usuarioId, motivo, compraId)
+	if err != nil {
+		log.Println("Error al actualizar estado de la compra:", err)
+		return "", datatype.NewInternalServerErrorGeneric()
 	}
 
-	// Confirmar transacción
 	err = tx.Commit(ctx)
 	if err != nil {
 		log.Println("Error al confirmar transacción:", err)
-		return datatype.NewInternalServerErrorGeneric()
+		return "", datatype.NewInternalServerErrorGeneric()
 	}
-
 	committed = true
-	return nil
+	return estado, nil
+}
+
+func (c CompraRepository) ListarRecepcionesCompra(ctx context.Context, compraId int) (*[]domain.RecepcionCompra, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	var existe bool
+	err := c.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM compra WHERE id = $1)`, compraId).Scan(&existe)
+	if err != nil {
+		log.Println("Error al verificar compra:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if !existe {
+		return nil, datatype.NewNotFoundError("Compra no encontrada")
+	}
+
+	query := `
+SELECT 
+    rc.id,
+    rc.compra_id,
+    CASE WHEN ua.id IS NULL THEN NULL ELSE json_build_object(
+       'id',ua.id,
+       'username',ua.username
+    ) END AS usuario,
+    rc.observacion,
+    rc.creado_en,
+    COALESCE(
+       json_agg(
+          json_build_object(
+             'id',drc.id,
+             'detalleCompraId',drc.detalle_compra_id,
+             'cantidad',drc.cantidad,
+             'costoUnitario',drc.costo_unitario,
+             'lote',drc.lote,
+             'fechaVencimiento',drc.fecha_vencimiento::text,
+             'producto', json_build_object(
+                'id',pr.id,
+                'nombre',pr.nombre,
+                'estado',pr.estado,
+                'urlFoto',($1::text || pr.id::text || '/' || pr.foto),
+                'creadoEn',pr.creado_en,
+                'actualizadoEn',pr.actualizado_en,
+                'eliminadoEn',pr.eliminado_en
+             ),
+             'ubicacion', json_build_object(
+                'id',ub.id,
+                'nombre',ub.nombre,
+                'estado',ub.estado,
+                'esVendible',ub.es_vendible,
+                'prioridadVenta',ub.prioridad_venta
+             )
+          ) ORDER BY drc.id
+       ) FILTER (WHERE drc.id IS NOT NULL),
+       '[]'
+    ) AS detalles
+FROM recepcion_compra rc
+LEFT JOIN public.usuario_admin ua on ua.id = rc.usuario_id
+LEFT JOIN public.detalle_recepcion_compra drc on drc.recepcion_compra_id = rc.id
+LEFT JOIN public.producto pr on drc.producto_id = pr.id
+LEFT JOIN public.ubicacion ub on drc.ubicacion_id = ub.id
+WHERE rc.compra_id = $2
+GROUP BY rc.id, ua.id
+ORDER BY rc.id`
+	rows, err := c.pool.Query(ctx, query, fullHostname, compraId)
+	if err != nil {
+		log.Println("Error al listar recepciones de compra:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.RecepcionCompra, 0)
+	for rows.Next() {
+		var item domain.RecepcionCompra
+		err := rows.Scan(&item.Id, &item.CompraId, &item.Usuario, &item.Observacion, &item.CreadoEn, &item.Detalles)
+		if err != nil {
+			log.Println("Error al escanear recepción de compra:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("Error durante la iteración de filas:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &list, nil
+}
+
+// ListarDatosReposicion junta por producto inventariable de la sucursal el stock, lo pedido sin recibir y lo
+// consumido por ventas no anuladas en los últimos dias, incluidos los componentes de kits y opciones
+func (c CompraRepository) ListarDatosReposicion(ctx context.Context, sucursalId int, dias int, categoriaId *int) (*[]domain.ProductoReposicion, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	query := `
+        WITH ventas AS (
+            SELECT dv.id, dv.producto_id, dv.cantidad, dv.ubicacion_id
+            FROM detalle_venta dv
+            JOIN venta v ON dv.venta_id = v.id
+            WHERE v.sucursal_id = $2 AND v.estado NOT IN ('Anulada', 'Dividida') AND v.creado_en >= NOW() - make_interval(days => $3)
+        ), vendido AS (
+            SELECT producto_id, SUM(cantidad)::int AS unidades
+            FROM (
+                SELECT producto_id, cantidad FROM ventas WHERE ubicacion_id IS NOT NULL
+                UNION ALL
+                SELECT dvc.producto_id, dvc.cantidad FROM detalle_venta_componente dvc JOIN ventas ON dvc.detalle_venta_id = ventas.id
+            ) consumo
+            GROUP BY producto_id
+        ), pendiente AS (
+            SELECT dc.producto_id, SUM(dc.cantidad - dc.cantidad_recibida - dc.cantidad_cancelada)::int AS cantidad
+            FROM detalle_compra dc
+            JOIN compra c ON dc.compra_id = c.id
+            WHERE c.sucursal_id = $2 AND c.estado IN ('Pendiente', 'Parcialmente recibida')
+            GROUP BY dc.producto_id
+        )
+        SELECT json_build_object(
+                  'id', p.id,
+                  'nombre', p.nombre,
+                  'estado', p.estado,
+                  'urlFoto', ($1::text || p.id::text || '/' || p.foto),
+                  'esInventariable', p.es_inventariable,
+                  'creadoEn', p.creado_en,
+                  'actualizadoEn', p.actualizado_en,
+                  'eliminadoEn', p.eliminado_en
+               ),
+               st.stock, COALESCE(pe.cantidad, 0), COALESCE(ve.unidades, 0),
+               ps.stock_minimo, ps.punto_reorden, ps.stock_maximo,
+               uc.proveedor_id, pr.nombre, COALESCE(pr.dias_entrega, 0),
+               COALESCE(uc.precio_compra, cp.costo_promedio, 0)::float8
+        FROM producto_sucursal ps
+        JOIN producto p ON ps.producto_id = p.id
+        CROSS JOIN LATERAL (
+            SELECT COALESCE(SUM(i.stock), 0)::int AS stock
+            FROM inventario i
+            JOIN ubicacion u ON i.ubicacion_id = u.id
+            WHERE i.producto_id = ps.producto_id AND u.sucursal_id = ps.sucursal_id
+        ) st
+        LEFT JOIN vendido ve ON ve.producto_id = p.id
+        LEFT JOIN pendiente pe ON pe.producto_id = p.id
+        LEFT JOIN LATERAL (
+            SELECT c.proveedor_id, dc.precio_compra
+            FROM detalle_compra dc
+            JOIN compra c ON dc.compra_id = c.id
+            WHERE c.sucursal_id = ps.sucursal_id AND dc.producto_id = p.id
+            ORDER BY c.creado_en DESC, c.id DESC
+            LIMIT 1
+        ) uc ON true
+        LEFT JOIN proveedor pr ON pr.id = uc.proveedor_id
+        LEFT JOIN costo_producto cp ON cp.producto_id = p.id AND cp.sucursal_id = ps.sucursal_id
+        WHERE ps.sucursal_id = $2 AND ps.estado = 'Activo' AND p.estado = 'Activo' AND p.es_inventariable
+          AND ($4::int IS NULL OR p.categoria_id = $4)
+        ORDER BY pr.nombre NULLS LAST, p.nombre`
+	rows, err := c.pool.Query(ctx, query, fullHostname, sucursalId, dias, categoriaId)
+	if err != nil {
+		log.Println("Error al obtener datos de reposición:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.ProductoReposicion, 0)
+	for rows.Next() {
+		var item domain.ProductoReposicion
+		err := rows.Scan(&item.Producto, &item.Stock, &item.PedidoPendiente, &item.UnidadesVendidas, &item.StockMinimo, &item.PuntoReorden,
+			&item.StockMaximo, &item.ProveedorId, &item.Proveedor, &item.DiasEntrega, &item.PrecioCompra)
+		if err != nil {
+			log.Println("Error al escanear datos de reposición:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
 }
 
 func NewCompraRepository(pool *pgxpool.Pool) *CompraRepository {
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ConfiguracionSucursalRepository struct {
+	pool *pgxpool.Pool
+}
+
+const queryConfiguracionSucursal = `
+SELECT s.id, COALESCE(cs.politica_stock_offline, $2), cs.descuento_maximo, cs.ventana_anulacion_minutos, cs.actualizado_en
+FROM sucursal s
+LEFT JOIN configuracion_sucursal cs ON cs.sucursal_id = s.id
+WHERE s.id = $1`
+
+func (c ConfiguracionSucursalRepository) ObtenerConfiguracionSucursal(ctx context.Context, sucursalId *int) (*domain.ConfiguracionSucursal, error) {
+	var item domain.ConfiguracionSucursal
+	err := c.pool.QueryRow(ctx, queryConfiguracionSucursal, *sucursalId, domain.PoliticaStockMarcar).Scan(&item.SucursalId, &item.PoliticaStockOffline, &item.DescuentoMaximo,
+		&item.VentanaAnulacionMinutos, &item.ActualizadoEn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Sucursal no encontrada")
+		}
+		log.Println("Error al obtener configuración de sucursal:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+func (c ConfiguracionSucursalRepository) ModificarConfiguracionSucursal(ctx context.Context, sucursalId *int, request *domain.ConfiguracionSucursalRequest) error {
+	query := `
+        INSERT INTO configuracion_sucursal (sucursal_id, politica_stock_offline, descuento_maximo, ventana_anulacion_minutos, actualizado_en)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (sucursal_id) DO UPDATE
+        SET politica_stock_offline = EXCLUDED.politica_stock_offline,
+            descuento_maximo = EXCLUDED.descuento_maximo,
+            ventana_anulacion_minutos = EXCLUDED.ventana_anulacion_minutos,
+            actualizado_en = NOW()`
+	_, err := c.pool.Exec(ctx, query, *sucursalId, request.PoliticaStockOffline, request.DescuentoMaximo, request.VentanaAnulacionMinutos)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return datatype.NewNotFoundError("Sucursal no encontrada")
+		}
+		log.Println("Error al guardar configuración de sucursal:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// obtenerConfiguracionSucursal lee la configuración dentro de una transacción abierta.
+func obtenerConfiguracionSucursal(ctx context.Context, tx pgx.Tx, sucursalId int) (*domain.ConfiguracionSucursal, error) {
+	var item domain.ConfiguracionSucursal
+	err := tx.QueryRow(ctx, queryConfiguracionSucursal, sucursalId, domain.PoliticaStockMarcar).Scan(&item.SucursalId, &item.PoliticaStockOffline, &item.DescuentoMaximo,
+		&item.VentanaAnulacionMinutos, &item.ActualizadoEn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Sucursal no encontrada")
+		}
+		log.Println("Error al obtener configuración de sucursal:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+func NewConfiguracionSucursalRepository(pool *pgxpool.Pool) *ConfiguracionSucursalRepository {
+	return &ConfiguracionSucursalRepository{pool: pool}
+}
+
+var _ port.ConfiguracionSucursalRepository = (*ConfiguracionSucursalRepository)(nil)
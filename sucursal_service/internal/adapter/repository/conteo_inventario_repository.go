@@ -0,0 +1,494 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ConteoInventarioRepository struct {
+	pool *pgxpool.Pool
+}
+
+// queryLineasConteo calcula por línea lo contado y el stock esperado: la foto al abrir más los movimientos de la
+// ubicación hasta la última captura (o hasta ahora si no se contó). Al contabilizar ambos valores quedan fijos.
+const queryLineasConteo = `
+    lineas AS (
+        SELECT d.id, d.producto_id, d.ubicacion_id, d.stock_sistema,
+               COALESCE(d.cantidad_contada, cap.contada) AS contada,
+               COALESCE(d.stock_esperado, d.stock_sistema + mov.cantidad) AS stock_esperado
+        FROM detalle_conteo_inventario d
+        JOIN conteo_inventario ci ON d.conteo_inventario_id = ci.id
+        LEFT JOIN LATERAL (
+            SELECT SUM(cc.cantidad)::int AS contada, MAX(cc.movimiento_id) AS movimiento_id
+            FROM captura_conteo_inventario cc
+            WHERE cc.detalle_conteo_inventario_id = d.id
+        ) cap ON true
+        CROSS JOIN LATERAL (
+            SELECT COALESCE(SUM(mi.cantidad), 0)::int AS cantidad
+            FROM movimiento_inventario mi
+            WHERE mi.producto_id = d.producto_id AND mi.ubicacion_id = d.ubicacion_id
+              AND mi.id > ci.movimiento_inicial_id
+              AND (cap.movimiento_id IS NULL OR mi.id <= cap.movimiento_id)
+        ) mov
+        WHERE d.conteo_inventario_id = $1
+    )`
+
+const queryConteoInfo = `
+    SELECT ci.id,
+           json_build_object('id', s.id, 'nombre', s.nombre, 'estado', s.estado, 'creadoEn', s.creado_en),
+           CASE WHEN u.id IS NOT NULL THEN json_build_object(
+               'id', u.id, 'nombre', u.nombre, 'estado', u.estado, 'esVendible', u.es_vendible, 'prioridadVenta', u.prioridad_venta
+           ) END,
+           ci.categoria_id, ci.estado, ci.ciego, ci.motivo,
+           json_build_object('id', ua.id, 'username', ua.username),
+           ci.ajuste_inventario_id,
+           (SELECT COUNT(*) FROM detalle_conteo_inventario d WHERE d.conteo_inventario_id = ci.id),
+           (SELECT COUNT(*) FROM detalle_conteo_inventario d
+            WHERE d.conteo_inventario_id = ci.id
+              AND (d.cantidad_contada IS NOT NULL
+                   OR EXISTS(SELECT 1 FROM captura_conteo_inventario cc WHERE cc.detalle_conteo_inventario_id = d.id))),
+           ci.creado_en, ci.contabilizado_en
+    FROM conteo_inventario ci
+    JOIN sucursal s ON ci.sucursal_id = s.id
+    LEFT JOIN ubicacion u ON ci.ubicacion_id = u.id
+    JOIN usuario_admin ua ON ci.usuario_id = ua.id`
+
+func scanConteoInfo(row pgx.Row, item *domain.ConteoInventarioInfo) error {
+	return row.Scan(&item.Id, &item.Sucursal, &item.Ubicacion, &item.CategoriaId, &item.Estado, &item.Ciego, &item.Motivo, &item.Usuario,
+		&item.AjusteId, &item.LineasTotales, &item.LineasContadas, &item.CreadoEn, &item.ContabilizadoEn)
+}
+
+func (c ConteoInventarioRepository) AbrirConteo(ctx context.Context, request *domain.ConteoInventarioRequest, usuarioId int) (*int, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	if request.UbicacionId != nil {
+		var pertenece bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM ubicacion WHERE id = $1 AND sucursal_id = $2)`, *request.UbicacionId, request.SucursalId).Scan(&pertenece)
+		if err != nil {
+			log.Println("Error al validar ubicación del conteo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if !pertenece {
+			return nil, datatype.NewBadRequestError("La ubicación no pertenece a la sucursal del conteo.")
+		}
+	}
+
+	// Un conteo de la sucursal completa se superpone con cualquier conteo abierto de sus ubicaciones
+	var superpuesto *int
+	querySuperpuesto := `
+        SELECT id FROM conteo_inventario
+        WHERE sucursal_id = $1 AND estado IN ($2, $3)
+          AND ($4::int IS NULL OR ubicacion_id IS NULL OR ubicacion_id = $4)
+        LIMIT 1
+        FOR UPDATE`
+	err = tx.QueryRow(ctx, querySuperpuesto, request.SucursalId, domain.ConteoAbierto, domain.ConteoEnRevision, request.UbicacionId).Scan(&superpuesto)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Println("Error al verificar conteos abiertos:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if superpuesto != nil {
+		return nil, datatype.NewConflictError(fmt.Sprintf("El conteo #%d sigue abierto para esta sucursal o ubicación.", *superpuesto))
+	}
+
+	var conteoId int
+	queryConteo := `
+        INSERT INTO conteo_inventario (sucursal_id, ubicacion_id, categoria_id, estado, ciego, motivo, usuario_id, movimiento_inicial_id, creado_en)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, 0, NOW())
+        RETURNING id`
+	err = tx.QueryRow(ctx, queryConteo, request.SucursalId, request.UbicacionId, request.CategoriaId, domain.ConteoAbierto, request.Ciego,
+		request.Motivo, usuarioId).Scan(&conteoId)
+	if err != nil {
+		log.Println("Error al abrir conteo de inventario:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	// Foto del stock. Bloquear las filas espera a las ventas en curso, así el último movimiento leído después ya
+	// incluye todo lo que cambió esas filas.
+	querySnapshot := `
+        INSERT INTO detalle_conteo_inventario (conteo_inventario_id, producto_id, ubicacion_id, stock_sistema)
+        SELECT $1, i.producto_id, i.ubicacion_id, i.stock
+        FROM inventario i
+        JOIN ubicacion u ON i.ubicacion_id = u.id
+        JOIN producto p ON i.producto_id = p.id
+        WHERE u.sucursal_id = $2 AND u.estado = 'Activo'
+          AND ($3::int IS NULL OR i.ubicacion_id = $3)
+          AND ($4::int IS NULL OR p.categoria_id = $4)
+        FOR SHARE OF i`
+	if _, err = tx.Exec(ctx, querySnapshot, conteoId, request.SucursalId, request.UbicacionId, request.CategoriaId); err != nil {
+		log.Println("Error al registrar la foto del conteo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	queryMovimiento := `UPDATE conteo_inventario SET movimiento_inicial_id = (SELECT COALESCE(MAX(id), 0) FROM movimiento_inventario) WHERE id = $1`
+	if _, err = tx.Exec(ctx, queryMovimiento, conteoId); err != nil {
+		log.Println("Error al fijar el movimiento inicial del conteo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return &conteoId, nil
+}
+
+func (c ConteoInventarioRepository) RegistrarCapturasConteo(ctx context.Context, conteoId int, request *domain.CapturaConteoRequest, usuarioId int) error {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	// FOR SHARE deja capturar a varios usuarios a la vez y bloquea el cierre mientras tanto
+	var estado string
+	var sucursalId, movimientoInicialId int
+	var ubicacionId *int
+	err = tx.QueryRow(ctx, `SELECT estado, sucursal_id, ubicacion_id, movimiento_inicial_id FROM conteo_inventario WHERE id = $1 FOR SHARE`, conteoId).
+		Scan(&estado, &sucursalId, &ubicacionId, &movimientoInicialId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datatype.NewNotFoundError("El conteo no existe.")
+		}
+		log.Println("Error al obtener conteo:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if estado != domain.ConteoAbierto {
+		return datatype.NewConflictError(fmt.Sprintf("El conteo está '%s' y ya no admite capturas.", estado))
+	}
+
+	var ultimoMovimientoId int
+	if err = tx.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM movimiento_inventario`).Scan(&ultimoMovimientoId); err != nil {
+		log.Println("Error al obtener último movimiento:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	// Un producto que no estaba en la foto entra con el stock que tenía al abrir el conteo
+	queryLinea := `
+        WITH nueva AS (
+            INSERT INTO detalle_conteo_inventario (conteo_inventario_id, producto_id, ubicacion_id, stock_sistema)
+            SELECT $1, $2, $3,
+                   COALESCE((SELECT stock FROM inventario WHERE producto_id = $2 AND ubicacion_id = $3), 0)
+                   - COALESCE((SELECT SUM(cantidad) FROM movimiento_inventario
+                               WHERE producto_id = $2 AND ubicacion_id = $3 AND id > $4), 0)
+            WHERE EXISTS(SELECT 1 FROM producto WHERE id = $2)
+            ON CONFLICT (conteo_inventario_id, producto_id, ubicacion_id) DO NOTHING
+            RETURNING id
+        )
+        SELECT id FROM nueva
+        UNION ALL
+        SELECT id FROM detalle_conteo_inventario WHERE conteo_inventario_id = $1 AND producto_id = $2 AND ubicacion_id = $3`
+	queryUbicacion := `SELECT EXISTS(SELECT 1 FROM ubicacion WHERE id = $1 AND sucursal_id = $2 AND ($3::int IS NULL OR id = $3))`
+	for _, captura := range request.Capturas {
+		var valida bool
+		if err = tx.QueryRow(ctx, queryUbicacion, captura.UbicacionId, sucursalId, ubicacionId).Scan(&valida); err != nil {
+			log.Println("Error al validar ubicación de la captura:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if !valida {
+			return datatype.NewBadRequestError(fmt.Sprintf("La ubicación %d no forma parte del conteo.", captura.UbicacionId))
+		}
+
+		var detalleId int
+		err = tx.QueryRow(ctx, queryLinea, conteoId, captura.ProductoId, captura.UbicacionId, movimientoInicialId).Scan(&detalleId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return datatype.NewBadRequestError(fmt.Sprintf("El producto %d no existe.", captura.ProductoId))
+			}
+			log.Println("Error al obtener línea del conteo:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if request.Reemplazar {
+			if _, err = tx.Exec(ctx, `DELETE FROM captura_conteo_inventario WHERE detalle_conteo_inventario_id = $1`, detalleId); err != nil {
+				log.Println("Error al reemplazar capturas del conteo:", err)
+				return datatype.NewInternalServerErrorGeneric()
+			}
+		}
+		queryCaptura := `
+            INSERT INTO captura_conteo_inventario (detalle_conteo_inventario_id, cantidad, usuario_id, movimiento_id, creado_en)
+            VALUES ($1, $2, $3, $4, NOW())`
+		if _, err = tx.Exec(ctx, queryCaptura, detalleId, captura.Cantidad, usuarioId, ultimoMovimientoId); err != nil {
+			log.Println("Error al registrar captura del conteo:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return nil
+}
+
+func (c ConteoInventarioRepository) CambiarEstadoConteo(ctx context.Context, conteoId int, estadosPermitidos []string, estadoNuevo string) error {
+	var estadoActual string
+	err := c.pool.QueryRow(ctx, `SELECT estado FROM conteo_inventario WHERE id = $1`, conteoId).Scan(&estadoActual)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datatype.NewNotFoundError("El conteo no existe.")
+		}
+		log.Println("Error al obtener conteo:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	cmdTag, err := c.pool.Exec(ctx, `UPDATE conteo_inventario SET estado = $1 WHERE id = $2 AND estado = ANY($3)`, estadoNuevo, conteoId, estadosPermitidos)
+	if err != nil {
+		log.Println("Error al cambiar estado del conteo:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return datatype.NewConflictError(fmt.Sprintf("El conteo está '%s' y no puede pasar a '%s'.", estadoActual, estadoNuevo))
+	}
+	return nil
+}
+
+func (c ConteoInventarioRepository) ContabilizarConteo(ctx context.Context, conteoId int, request *domain.ContabilizarConteoRequest, usuarioId int) (*int, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	var estado, motivo string
+	var sucursalId int
+	err = tx.QueryRow(ctx, `SELECT estado, sucursal_id, motivo FROM conteo_inventario WHERE id = $1 FOR UPDATE`, conteoId).Scan(&estado, &sucursalId, &motivo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("El conteo no existe.")
+		}
+		log.Println("Error al obtener conteo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if estado != domain.ConteoEnRevision {
+		return nil, datatype.NewConflictError(fmt.Sprintf("Solo se contabiliza un conteo en revisión; este está '%s'.", estado))
+	}
+
+	// Se fijan lo contado y el esperado de cada línea; las no contadas cuentan como cero solo si se pidió
+	queryFijar := `
+        WITH ` + queryLineasConteo + `
+        UPDATE detalle_conteo_inventario d
+        SET stock_esperado = l.stock_esperado,
+            cantidad_contada = COALESCE(l.contada, CASE WHEN $2 THEN 0 END)
+        FROM lineas l
+        WHERE d.id = l.id
+        RETURNING d.producto_id, d.ubicacion_id, d.cantidad_contada - d.stock_esperado`
+	rows, err := tx.Query(ctx, queryFijar, conteoId, request.IncluirNoContados)
+	if err != nil {
+		log.Println("Error al fijar diferencias del conteo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var detalles []domain.DetalleAjusteInventarioRequest
+	for rows.Next() {
+		var productoId, ubicacionId int
+		var diferencia *int
+		if err := rows.Scan(&productoId, &ubicacionId, &diferencia); err != nil {
+			rows.Close()
+			log.Println("Error al escanear diferencia del conteo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if diferencia != nil && *diferencia != 0 {
+			detalles = append(detalles, domain.DetalleAjusteInventarioRequest{ProductoId: productoId, UbicacionId: ubicacionId, Cantidad: int64(*diferencia)})
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Println("Error al fijar diferencias del conteo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	// Sin diferencias el conteo se cierra sin ajuste
+	var ajusteId *int
+	if len(detalles) > 0 {
+		motivoAjuste := fmt.Sprintf("Conteo físico #%d", conteoId)
+		if motivo != "" {
+			motivoAjuste += ": " + motivo
+		}
+		id, err := registrarAjuste(ctx, tx, &domain.AjusteInventarioRequest{
+			TipoAjuste:   domain.TipoAjusteConteo,
+			Motivo:       motivoAjuste,
+			SucursalId:   sucursalId,
+			UsuarioId:    usuarioId,
+			Detalles:     detalles,
+			Autorizacion: request.Autorizacion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ajusteId = &id
+	}
+
+	queryCerrar := `
+        UPDATE conteo_inventario
+        SET estado = $1, ajuste_inventario_id = $2, contabilizado_por = $3, contabilizado_en = NOW()
+        WHERE id = $4`
+	if _, err = tx.Exec(ctx, queryCerrar, domain.ConteoContabilizado, ajusteId, usuarioId, conteoId); err != nil {
+		log.Println("Error al contabilizar conteo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return ajusteId, nil
+}
+
+func (c ConteoInventarioRepository) ListarConteos(ctx context.Context, filtros map[string]string) (*[]domain.ConteoInventarioInfo, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+	for _, filtro := range []struct{ clave, columna string }{
+		{"sucursalId", "ci.sucursal_id"},
+		{"ubicacionId", "ci.ubicacion_id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+	if estado := filtros["estado"]; estado != "" {
+		filters = append(filters, fmt.Sprintf("ci.estado = $%d", j))
+		args = append(args, estado)
+	}
+
+	query := queryConteoInfo
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY ci.id DESC"
+
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar conteos de inventario:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.ConteoInventarioInfo, 0)
+	for rows.Next() {
+		var item domain.ConteoInventarioInfo
+		if err := scanConteoInfo(rows, &item); err != nil {
+			log.Println("Error al escanear conteo de inventario:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func (c ConteoInventarioRepository) ObtenerConteo(ctx context.Context, conteoId int) (*domain.ConteoInventario, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	var conteo domain.ConteoInventario
+	err := scanConteoInfo(c.pool.QueryRow(ctx, queryConteoInfo+" WHERE ci.id = $1", conteoId), &conteo.ConteoInventarioInfo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("El conteo no existe.")
+		}
+		log.Println("Error al obtener conteo de inventario:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	query := `
+        WITH ` + queryLineasConteo + `
+        SELECT l.id,
+               json_build_object(
+                  'id', p.id,
+                  'nombre', p.nombre,
+                  'estado', p.estado,
+                  'urlFoto', ($2::text || p.id::text || '/' || p.foto),
+                  'esInventariable', p.es_inventariable,
+                  'creadoEn', p.creado_en,
+                  'actualizadoEn', p.actualizado_en,
+                  'eliminadoEn', p.eliminado_en
+               ),
+               json_build_object(
+                  'id', u.id, 'nombre', u.nombre, 'estado', u.estado, 'esVendible', u.es_vendible, 'prioridadVenta', u.prioridad_venta
+               ),
+               l.stock_sistema, l.stock_esperado - l.stock_sistema, l.stock_esperado, l.contada,
+               COALESCE((
+                   SELECT json_agg(json_build_object(
+                       'cantidad', cc.cantidad,
+                       'usuario', json_build_object('id', ua.id, 'username', ua.username),
+                       'creadoEn', cc.creado_en
+                   ) ORDER BY cc.id)
+                   FROM captura_conteo_inventario cc
+                   JOIN usuario_admin ua ON cc.usuario_id = ua.id
+                   WHERE cc.detalle_conteo_inventario_id = l.id
+               ), '[]')
+        FROM lineas l
+        JOIN producto p ON l.producto_id = p.id
+        JOIN ubicacion u ON l.ubicacion_id = u.id
+        ORDER BY u.nombre, p.nombre`
+	rows, err := c.pool.Query(ctx, query, conteoId, fullHostname)
+	if err != nil {
+		log.Println("Error al obtener detalles del conteo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	conteo.Detalles = make([]domain.DetalleConteoInventario, 0)
+	for rows.Next() {
+		var item domain.DetalleConteoInventario
+		err := rows.Scan(&item.Id, &item.Producto, &item.Ubicacion, &item.StockSistema, &item.MovimientosConteo, &item.StockEsperado,
+			&item.CantidadContada, &item.Capturas)
+		if err != nil {
+			log.Println("Error al escanear detalle del conteo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if item.CantidadContada != nil && item.StockEsperado != nil {
+			diferencia := *item.CantidadContada - *item.StockEsperado
+			item.Diferencia = &diferencia
+		}
+		conteo.Detalles = append(conteo.Detalles, item)
+	}
+	return &conteo, nil
+}
+
+func NewConteoInventarioRepository(pool *pgxpool.Pool) *ConteoInventarioRepository {
+	return &ConteoInventarioRepository{pool: pool}
+}
+
+var _ port.ConteoInventarioRepository = (*ConteoInventarioRepository)(nil)
@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CuentaClienteRepository struct {
+	pool *pgxpool.Pool
+}
+
+const queryCuentaCliente = `
+SELECT
+    cc.id,
+    json_build_object(
+       'id', c.id,
+       'nombres', c.nombres,
+       'apellidos', c.apellidos,
+       'codigoPais', c.codigo_pais,
+       'celular', c.celular,
+       'fechaNacimiento', c.fecha_nacimiento,
+       'estado', c.estado,
+       'creadoEn', c.creado_en
+    ) AS cliente,
+    json_build_object(
+       'id', s.id,
+       'nombre', s.nombre,
+       'estado', s.estado,
+       'creadoEn', s.creado_en
+    ) AS sucursal,
+    cc.saldo,
+    cc.limite_credito,
+    cc.saldo + cc.limite_credito AS disponible,
+    cc.estado,
+    cc.creado_en,
+    cc.actualizado_en
+FROM cuenta_cliente cc
+JOIN cliente c ON cc.cliente_id = c.id
+JOIN sucursal s ON cc.sucursal_id = s.id`
+
+func escanearCuentaCliente(row pgx.Row, item *domain.CuentaCliente) error {
+	return row.Scan(&item.Id, &item.Cliente, &item.Sucursal, &item.Saldo, &item.LimiteCredito, &item.Disponible, &item.Estado, &item.CreadoEn, &item.ActualizadoEn)
+}
+
+func (c CuentaClienteRepository) RegistrarCuentaCliente(ctx context.Context, request *domain.CuentaClienteRequest) (*int, error) {
+	var id int
+	query := `
+        INSERT INTO cuenta_cliente (cliente_id, sucursal_id, saldo, limite_credito, estado)
+        VALUES ($1, $2, 0, $3, $4)
+        RETURNING id`
+	err := c.pool.QueryRow(ctx, query, request.ClienteId, request.SucursalId, request.LimiteCredito, request.Estado).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return nil, datatype.NewConflictError("El cliente ya tiene una cuenta corriente en esta sucursal.")
+			case "23503":
+				return nil, datatype.NewBadRequestError("El cliente o la sucursal no existen.")
+			}
+		}
+		log.Println("Error al registrar cuenta de cliente:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &id, nil
+}
+
+func (c CuentaClienteRepository) ModificarCuentaCliente(ctx context.Context, id *int, request *domain.CuentaClienteRequest) error {
+	query := `UPDATE cuenta_cliente SET limite_credito = $1, estado = $2, actualizado_en = NOW() WHERE id = $3`
+	ct, err := c.pool.Exec(ctx, query, request.LimiteCredito, request.Estado, *id)
+	if err != nil {
+		log.Println("Error al modificar cuenta de cliente:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewNotFoundError("Cuenta de cliente no encontrada")
+	}
+	return nil
+}
+
+func (c CuentaClienteRepository) ListarCuentasCliente(ctx context.Context, filtros map[string]string) (*[]domain.CuentaCliente, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+
+	if val := filtros["sucursalId"]; val != "" {
+		sucursalId, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("cc.sucursal_id = $%d", j))
+		args = append(args, sucursalId)
+		j++
+	}
+	if val := filtros["clienteId"]; val != "" {
+		clienteId, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de clienteId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("cc.cliente_id = $%d", j))
+		args = append(args, clienteId)
+		j++
+	}
+	if val := filtros["estado"]; val != "" {
+		filters = append(filters, fmt.Sprintf("cc.estado = $%d", j))
+		args = append(args, val)
+		j++
+	}
+	if filtros["conDeuda"] == "true" {
+		filters = append(filters, "cc.saldo < 0")
+	}
+
+	query := queryCuentaCliente
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY s.nombre, cc.saldo, c.nombres"
+
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar cuentas de cliente:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.CuentaCliente, 0)
+	for rows.Next() {
+		var item domain.CuentaCliente
+		if err := escanearCuentaCliente(rows, &item); err != nil {
+			log.Println("Error al escanear cuenta de cliente:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func (c CuentaClienteRepository) ObtenerCuentaClienteById(ctx context.Context, id *int) (*domain.CuentaCliente, error) {
+	var item domain.CuentaCliente
+	err := escanearCuentaCliente(c.pool.QueryRow(ctx, queryCuentaCliente+" WHERE cc.id = $1", *id), &item)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Cuenta de cliente no encontrada")
+		}
+		log.Println("Error al obtener cuenta de cliente:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+func (c CuentaClienteRepository) RecargarCuentaCliente(ctx context.Context, id *int, request *domain.RecargaCuentaRequest) (*int, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	// La recarga entra por un medio de pago real; no se puede recargar con la propia cuenta corriente
+	var codigo *string
+	err = tx.QueryRow(ctx, `SELECT codigo FROM metodo_pago WHERE id = $1 AND estado = 'Activo'`, request.MetodoPagoId).Scan(&codigo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewBadRequestError("El método de pago seleccionado no existe.")
+		}
+		log.Println("Error al obtener método de pago:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if codigo != nil && *codigo == domain.MetodoPagoCuentaCorriente {
+		return nil, datatype.NewBadRequestError("No se puede recargar la cuenta con el método cuenta corriente.")
+	}
+
+	var estado string
+	err = tx.QueryRow(ctx, `SELECT estado FROM cuenta_cliente WHERE id = $1 FOR UPDATE`, *id).Scan(&estado)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Cuenta de cliente no encontrada")
+		}
+		log.Println("Error al bloquear cuenta de cliente:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if estado != "Activo" {
+		return nil, datatype.NewBadRequestError("La cuenta del cliente está inactiva.")
+	}
+
+	movimientoId, err := registrarMovimientoCuenta(ctx, tx, *id, domain.MovimientoCuentaRecarga, request.Monto, nil, &request.MetodoPagoId, request.Referencia, request.UsuarioId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar recarga:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return &movimientoId, nil
+}
+
+func (c CuentaClienteRepository) ObtenerEstadoCuentaCliente(ctx context.Context, id *int, filtros map[string]string) (*domain.EstadoCuentaCliente, error) {
+	cuenta, err := c.ObtenerCuentaClienteById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	estadoCuenta := domain.EstadoCuentaCliente{Cuenta: *cuenta, Movimientos: make([]domain.MovimientoCuentaCliente, 0)}
+
+	var filters = []string{"m.cuenta_cliente_id = $1"}
+	var args = []interface{}{*id}
+	var j = 2
+
+	// Saldo inicial: el resultante del último movimiento anterior al rango
+	if fechaInicio := filtros["fechaInicio"]; fechaInicio != "" {
+		querySaldoInicial := `
+            SELECT COALESCE((SELECT saldo_resultante FROM movimiento_cuenta_cliente
+                             WHERE cuenta_cliente_id = $1 AND creado_en < $2
+                             ORDER BY creado_en DESC, id DESC LIMIT 1), 0)`
+		if err := c.pool.QueryRow(ctx, querySaldoInicial, *id, fechaInicio).Scan(&estadoCuenta.SaldoInicial); err != nil {
+			log.Println("Error al obtener saldo inicial:", err)
+			return nil, datatype.NewBadRequestError("El valor de fechaInicio no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("m.creado_en >= $%d", j))
+		args = append(args, fechaInicio)
+		j++
+	}
+	if fechaFin := filtros["fechaFin"]; fechaFin != "" {
+		filters = append(filters, fmt.Sprintf("m.creado_en <= $%d", j))
+		args = append(args, fechaFin)
+	}
+
+	query := `
+        SELECT m.id, m.tipo, m.monto, m.saldo_resultante, m.venta_id,
+               (CASE WHEN mp.id IS NOT NULL THEN json_build_object('id', mp.id, 'nombre', mp.nombre, 'codigo', mp.codigo, 'estado', mp.estado) END),
+               m.referencia,
+               json_build_object('id', ua.id, 'username', ua.username),
+               m.creado_en
+        FROM movimiento_cuenta_cliente m
+        LEFT JOIN metodo_pago mp ON m.metodo_pago_id = mp.id
+        LEFT JOIN usuario_admin ua ON m.usuario_id = ua.id
+        WHERE ` + strings.Join(filters, " AND ") + `
+        ORDER BY m.creado_en, m.id`
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar movimientos de cuenta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+
+	estadoCuenta.SaldoFinal = estadoCuenta.SaldoInicial
+	for rows.Next() {
+		var m domain.MovimientoCuentaCliente
+		if err := rows.Scan(&m.Id, &m.Tipo, &m.Monto, &m.SaldoResultante, &m.VentaId, &m.MetodoPago, &m.Referencia, &m.Usuario, &m.CreadoEn); err != nil {
+			log.Println("Error al escanear movimiento de cuenta:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if m.Monto < 0 {
+			estadoCuenta.TotalCargos += -m.Monto
+		} else {
+			estadoCuenta.TotalAbonos += m.Monto
+		}
+		estadoCuenta.SaldoFinal = m.SaldoResultante
+		estadoCuenta.Movimientos = append(estadoCuenta.Movimientos, m)
+	}
+	return &estadoCuenta, nil
+}
+
+// registrarMovimientoCuenta aplica el monto (con signo) sobre la cuenta y guarda el saldo resultante.
+func registrarMovimientoCuenta(ctx context.Context, tx pgx.Tx, cuentaId int, tipo string, monto float64, ventaId, metodoPagoId *int, referencia *string, usuarioId int) (int, error) {
+	var saldo float64
+	err := tx.QueryRow(ctx, `UPDATE cuenta_cliente SET saldo = saldo + $1, actualizado_en = NOW() WHERE id = $2 RETURNING saldo`, monto, cuentaId).Scan(&saldo)
+	if err != nil {
+		log.Println("Error al actualizar saldo de cuenta:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	var movimientoId int
+	query := `
+        INSERT INTO movimiento_cuenta_cliente (cuenta_cliente_id, tipo, monto, saldo_resultante, venta_id, metodo_pago_id, referencia, usuario_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id`
+	err = tx.QueryRow(ctx, query, cuentaId, tipo, monto, saldo, ventaId, metodoPagoId, referencia, usuarioId).Scan(&movimientoId)
+	if err != nil {
+		log.Println("Error al registrar movimiento de cuenta:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	return movimientoId, nil
+}
+
+// cargarCuentaCorriente cobra un pago de la venta a la cuenta del cliente en la sucursal sin superar su límite de crédito.
+func cargarCuentaCorriente(ctx context.Context, tx pgx.Tx, clienteId *int64, sucursalId int, monto float64, ventaId, metodoPagoId int, referencia *string, usuarioId int) error {
+	if clienteId == nil {
+		return datatype.NewBadRequestError("La venta no tiene cliente; no se puede cobrar a cuenta corriente.")
+	}
+	var cuentaId int
+	var saldo, limiteCredito float64
+	var estado string
+	queryCuenta := `
+        SELECT id, saldo, limite_credito, estado
+        FROM cuenta_cliente
+        WHERE cliente_id = $1 AND sucursal_id = $2
+        FOR UPDATE`
+	err := tx.QueryRow(ctx, queryCuenta, *clienteId, sucursalId).Scan(&cuentaId, &saldo, &limiteCredito, &estado)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datatype.NewBadRequestError("El cliente no tiene cuenta corriente en esta sucursal.")
+		}
+		log.Println("Error al bloquear cuenta de cliente:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if estado != "Activo" {
+		return datatype.NewBadRequestError("La cuenta corriente del cliente está inactiva.")
+	}
+	const epsilon = 0.001
+	if saldo-monto < -limiteCredito-epsilon {
+		return datatype.NewBadRequestError(fmt.Sprintf("Crédito insuficiente en la cuenta del cliente. Disponible: %.2f", math.Max(saldo+limiteCredito, 0)))
+	}
+	_, err = registrarMovimientoCuenta(ctx, tx, cuentaId, domain.MovimientoCuentaCargo, -monto, &ventaId, &metodoPagoId, referencia, usuarioId)
+	return err
+}
+
+// revertirCargosCuenta devuelve a cada cuenta el neto cobrado en la venta.
+func revertirCargosCuenta(ctx context.Context, tx pgx.Tx, ventaId int, anuladoPor int) error {
+	type cargoCuenta struct {
+		CuentaId int
+		Neto     float64
+	}
+	query := `
+        SELECT cuenta_cliente_id, SUM(monto)
+        FROM movimiento_cuenta_cliente
+        WHERE venta_id = $1
+        GROUP BY cuenta_cliente_id
+        HAVING SUM(monto) < 0`
+	rows, err := tx.Query(ctx, query, ventaId)
+	if err != nil {
+		log.Println("Error al obtener cargos de cuenta de la venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var cargos []cargoCuenta
+	for rows.Next() {
+		var cc cargoCuenta
+		if err := rows.Scan(&cc.CuentaId, &cc.Neto); err != nil {
+			rows.Close()
+			log.Println("Error al escanear cargo de cuenta:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		cargos = append(cargos, cc)
+	}
+	rows.Close()
+
+	for _, cc := range cargos {
+		if _, err := registrarMovimientoCuenta(ctx, tx, cc.CuentaId, domain.MovimientoCuentaReverso, -cc.Neto, &ventaId, nil, nil, anuladoPor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewCuentaClienteRepository(pool *pgxpool.Pool) *CuentaClienteRepository {
+	return &CuentaClienteRepository{pool: pool}
+}
+
+var _ port.CuentaClienteRepository = (*CuentaClienteRepository)(nil)
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotenciaRepository struct {
+	pool *pgxpool.Pool
+}
+
+// ReservarIdempotencia intenta registrar la clave. Si la clave ya existía (y no expiró)
+// devuelve el registro existente; si la reserva es nueva devuelve nil.
+func (i IdempotenciaRepository) ReservarIdempotencia(ctx context.Context, request *domain.IdempotenciaRequest) (*domain.Idempotencia, error) {
+	// Una clave expirada se puede reutilizar: se elimina antes de intentar la reserva
+	queryExpirada := `DELETE FROM idempotencia WHERE clave = $1 AND usuario_id = $2 AND expira_en <= NOW()`
+	if _, err := i.pool.Exec(ctx, queryExpirada, request.Clave, request.UsuarioId); err != nil {
+		log.Println("Error al limpiar clave de idempotencia expirada:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	queryInsert := `
+		INSERT INTO idempotencia (clave, usuario_id, metodo, ruta, hash_peticion, expira_en)
+		VALUES ($1, $2, $3, $4, $5, NOW() + make_interval(secs => $6))
+		ON CONFLICT (clave, usuario_id) DO NOTHING`
+	tag, err := i.pool.Exec(ctx, queryInsert, request.Clave, request.UsuarioId, request.Metodo, request.Ruta,
+		request.HashPeticion, request.Reserva.Seconds())
+	if err != nil {
+		log.Println("Error al reservar clave de idempotencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	var item domain.Idempotencia
+	query := `
+		SELECT clave, usuario_id, metodo, ruta, hash_peticion, codigo_estado, content_type, respuesta, creado_en, expira_en
+		FROM idempotencia
+		WHERE clave = $1 AND usuario_id = $2`
+	err = i.pool.QueryRow(ctx, query, request.Clave, request.UsuarioId).Scan(&item.Clave, &item.UsuarioId, &item.Metodo,
+		&item.Ruta, &item.HashPeticion, &item.CodigoEstado, &item.ContentType, &item.Respuesta, &item.CreadoEn, &item.ExpiraEn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// La petición original se liberó entre ambas consultas
+			return nil, datatype.NewConflictError("La petición con esta clave de idempotencia se está procesando, intente nuevamente")
+		}
+		log.Println("Error al obtener clave de idempotencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+func (i IdempotenciaRepository) GuardarRespuestaIdempotencia(ctx context.Context, request *domain.IdempotenciaRespuestaRequest) error {
+	query := `
+		UPDATE idempotencia
+		SET codigo_estado = $3, content_type = $4, respuesta = $5, expira_en = NOW() + make_interval(secs => $6)
+		WHERE clave = $1 AND usuario_id = $2`
+	_, err := i.pool.Exec(ctx, query, request.Clave, request.UsuarioId, request.CodigoEstado, request.ContentType, request.Respuesta,
+		request.Retencion.Seconds())
+	if err != nil {
+		log.Println("Error al guardar respuesta de idempotencia:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func (i IdempotenciaRepository) LiberarIdempotencia(ctx context.Context, clave string, usuarioId int) error {
+	query := `DELETE FROM idempotencia WHERE clave = $1 AND usuario_id = $2 AND codigo_estado IS NULL`
+	_, err := i.pool.Exec(ctx, query, clave, usuarioId)
+	if err != nil {
+		log.Println("Error al liberar clave de idempotencia:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func (i IdempotenciaRepository) EliminarIdempotenciasExpiradas(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotencia WHERE expira_en <= NOW()`
+	tag, err := i.pool.Exec(ctx, query)
+	if err != nil {
+		log.Println("Error al eliminar claves de idempotencia expiradas:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	return tag.RowsAffected(), nil
+}
+
+func NewIdempotenciaRepository(pool *pgxpool.Pool) *IdempotenciaRepository {
+	return &IdempotenciaRepository{pool: pool}
+}
+
+var _ port.IdempotenciaRepository = (*IdempotenciaRepository)(nil)
@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ImpresoraRepository struct {
+	pool *pgxpool.Pool
+}
+
+const queryImpresora = `
+SELECT
+    i.id,
+    i.nombre,
+    i.direccion,
+    i.puerto,
+    i.ancho_papel,
+    i.estado,
+    json_build_object('id', s.id, 'nombre', s.nombre, 'estado', s.estado, 'creadoEn', s.creado_en) AS sucursal,
+    i.creado_en,
+    i.actualizado_en
+FROM impresora i
+JOIN sucursal s ON i.sucursal_id = s.id`
+
+func escanearImpresora(row pgx.Row, item *domain.Impresora) error {
+	return row.Scan(&item.Id, &item.Nombre, &item.Direccion, &item.Puerto, &item.AnchoPapel, &item.Estado, &item.Sucursal, &item.CreadoEn, &item.ActualizadoEn)
+}
+
+func errorGuardarImpresora(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return datatype.NewConflictError("Ya existe una impresora con ese nombre en la sucursal.")
+		case "23503":
+			return datatype.NewBadRequestError("La sucursal indicada no existe.")
+		}
+	}
+	log.Println("Error al guardar impresora:", err)
+	return datatype.NewInternalServerErrorGeneric()
+}
+
+func (i ImpresoraRepository) RegistrarImpresora(ctx context.Context, request *domain.ImpresoraRequest) (*int, error) {
+	var id int
+	query := `
+        INSERT INTO impresora (sucursal_id, nombre, direccion, puerto, ancho_papel, estado)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id`
+	err := i.pool.QueryRow(ctx, query, request.SucursalId, request.Nombre, request.Direccion, request.Puerto, request.AnchoPapel, request.Estado).Scan(&id)
+	if err != nil {
+		return nil, errorGuardarImpresora(err)
+	}
+	return &id, nil
+}
+
+func (i ImpresoraRepository) ModificarImpresora(ctx context.Context, id *int, request *domain.ImpresoraRequest) error {
+	query := `
+        UPDATE impresora
+        SET sucursal_id = $1, nombre = $2, direccion = $3, puerto = $4, ancho_papel = $5, estado = $6, actualizado_en = NOW()
+        WHERE id = $7`
+	ct, err := i.pool.Exec(ctx, query, request.SucursalId, request.Nombre, request.Direccion, request.Puerto, request.AnchoPapel, request.Estado, *id)
+	if err != nil {
+		return errorGuardarImpresora(err)
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewNotFoundError("Impresora no encontrada")
+	}
+	return nil
+}
+
+func (i ImpresoraRepository) ListarImpresoras(ctx context.Context, filtros map[string]string) (*[]domain.Impresora, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+
+	if sucursalIdStr := filtros["sucursalId"]; sucursalIdStr != "" {
+		sucursalId, err := strconv.Atoi(sucursalIdStr)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("i.sucursal_id = $%d", j))
+		args = append(args, sucursalId)
+		j++
+	}
+	if estado := filtros["estado"]; estado != "" {
+		filters = append(filters, fmt.Sprintf("i.estado = $%d", j))
+		args = append(args, estado)
+	}
+
+	query := queryImpresora
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY s.nombre, i.nombre"
+
+	rows, err := i.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar impresoras:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.Impresora, 0)
+	for rows.Next() {
+		var item domain.Impresora
+		if err := escanearImpresora(rows, &item); err != nil {
+			log.Println("Error al escanear impresora:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func (i ImpresoraRepository) ObtenerImpresoraById(ctx context.Context, id *int) (*domain.Impresora, error) {
+	var item domain.Impresora
+	err := escanearImpresora(i.pool.QueryRow(ctx, queryImpresora+" WHERE i.id = $1", *id), &item)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Impresora no encontrada")
+		}
+		log.Println("Error al obtener impresora:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+func (i ImpresoraRepository) RegistrarTrabajoImpresion(ctx context.Context, impresoraId int, ventaId *int, contenido []byte) (*int, error) {
+	var id int
+	query := `
+        INSERT INTO trabajo_impresion (impresora_id, venta_id, contenido, estado, intentos, proximo_intento_en)
+        VALUES ($1, $2, $3, $4, 0, NOW())
+        RETURNING id`
+	err := i.pool.QueryRow(ctx, query, impresoraId, ventaId, contenido, domain.TrabajoImpresionPendiente).Scan(&id)
+	if err != nil {
+		log.Println("Error al registrar trabajo de impresión:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &id, nil
+}
+
+func (i ImpresoraRepository) ListarTrabajosImpresion(ctx context.Context, filtros map[string]string) (*[]domain.TrabajoImpresion, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+
+	for _, filtro := range []struct{ clave, columna string }{
+		{"impresoraId", "t.impresora_id"},
+		{"sucursalId", "i.sucursal_id"},
+		{"ventaId", "t.venta_id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+	if estado := filtros["estado"]; estado != "" {
+		filters = append(filters, fmt.Sprintf("t.estado = $%d", j))
+		args = append(args, estado)
+	}
+
+	query := `
+        SELECT t.id, t.impresora_id, i.nombre, t.venta_id, t.estado, t.intentos, t.ultimo_error,
+               CASE WHEN t.estado = 'Pendiente' THEN t.proximo_intento_en END, t.creado_en, t.impreso_en
+        FROM trabajo_impresion t
+        JOIN impresora i ON t.impresora_id = i.id`
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY t.id DESC LIMIT 200"
+
+	rows, err := i.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar trabajos de impresión:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.TrabajoImpresion, 0)
+	for rows.Next() {
+		var item domain.TrabajoImpresion
+		err := rows.Scan(&item.Id, &item.ImpresoraId, &item.NombreImpresora, &item.VentaId, &item.Estado, &item.Intentos, &item.UltimoError,
+			&item.ProximoIntentoEn, &item.CreadoEn, &item.ImpresoEn)
+		if err != nil {
+			log.Println("Error al escanear trabajo de impresión:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func (i ImpresoraRepository) ReintentarTrabajoImpresion(ctx context.Context, id *int) error {
+	query := `
+        UPDATE trabajo_impresion
+        SET estado = 'Pendiente', intentos = 0, ultimo_error = NULL, proximo_intento_en = NOW()
+        WHERE id = $1 AND estado IN ('Error', 'Cancelado')`
+	ct, err := i.pool.Exec(ctx, query, *id)
+	if err != nil {
+		log.Println("Error al reintentar trabajo de impresión:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewBadRequestError("Solo se pueden reintentar trabajos en estado Error o Cancelado.")
+	}
+	return nil
+}
+
+func (i ImpresoraRepository) CancelarTrabajoImpresion(ctx context.Context, id *int) error {
+	ct, err := i.pool.Exec(ctx, `UPDATE trabajo_impresion SET estado = 'Cancelado' WHERE id = $1 AND estado = 'Pendiente'`, *id)
+	if err != nil {
+		log.Println("Error al cancelar trabajo de impresión:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewBadRequestError("Solo se pueden cancelar trabajos pendientes.")
+	}
+	return nil
+}
+
+// ReservarTrabajosImpresion toma el trabajo pendiente más antiguo de cada impresora activa, para respetar
+// el orden de la cola, si ya le toca intentarlo. La reserva posterga su próximo intento un minuto para que
+// otra instancia del servicio no lo envíe a la vez.
+func (i ImpresoraRepository) ReservarTrabajosImpresion(ctx context.Context) ([]domain.TrabajoImpresionEnvio, error) {
+	query := `
+        WITH siguientes AS (
+            SELECT DISTINCT ON (t.impresora_id) t.id
+            FROM trabajo_impresion t
+            WHERE t.estado = 'Pendiente'
+            ORDER BY t.impresora_id, t.id
+        )
+        UPDATE trabajo_impresion t
+        SET proximo_intento_en = NOW() + INTERVAL '1 minute'
+        FROM siguientes s, impresora i
+        WHERE t.id = s.id
+          AND t.estado = 'Pendiente'
+          AND t.proximo_intento_en <= NOW()
+          AND i.id = t.impresora_id
+          AND i.estado = 'Activo'
+        RETURNING t.id, t.impresora_id, i.direccion, i.puerto, t.contenido, t.intentos`
+	rows, err := i.pool.Query(ctx, query)
+	if err != nil {
+		log.Println("Error al reservar trabajos de impresión:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	var trabajos []domain.TrabajoImpresionEnvio
+	for rows.Next() {
+		var t domain.TrabajoImpresionEnvio
+		if err := rows.Scan(&t.Id, &t.ImpresoraId, &t.Direccion, &t.Puerto, &t.Contenido, &t.Intentos); err != nil {
+			log.Println("Error al escanear trabajo de impresión:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		trabajos = append(trabajos, t)
+	}
+	return trabajos, nil
+}
+
+func (i ImpresoraRepository) MarcarTrabajoImpreso(ctx context.Context, id int) error {
+	query := `
+        UPDATE trabajo_impresion
+        SET estado = 'Impreso', intentos = intentos + 1, ultimo_error = NULL, impreso_en = NOW()
+        WHERE id = $1`
+	if _, err := i.pool.Exec(ctx, query, id); err != nil {
+		log.Println("Error al marcar trabajo impreso:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// MarcarTrabajoFallido suma el intento; sin próximo intento (o agotados los intentos) el trabajo queda en Error.
+func (i ImpresoraRepository) MarcarTrabajoFallido(ctx context.Context, id int, mensaje string, proximoIntento *time.Time) error {
+	query := `
+        UPDATE trabajo_impresion
+        SET intentos = intentos + 1,
+            ultimo_error = $2,
+            estado = CASE WHEN $3::timestamptz IS NULL OR intentos + 1 >= $4 THEN 'Error' ELSE estado END,
+            proximo_intento_en = COALESCE($3, proximo_intento_en)
+        WHERE id = $1`
+	if _, err := i.pool.Exec(ctx, query, id, mensaje, proximoIntento, domain.MaxIntentosImpresion); err != nil {
+		log.Println("Error al marcar trabajo fallido:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func NewImpresoraRepository(pool *pgxpool.Pool) *ImpresoraRepository {
+	return &ImpresoraRepository{pool: pool}
+}
+
+var _ port.ImpresoraRepository = (*ImpresoraRepository)(nil)
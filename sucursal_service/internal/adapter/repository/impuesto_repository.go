@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ImpuestoRepository struct {
+	pool *pgxpool.Pool
+}
+
+// tasasImpuesto son las tasas vigentes de una sucursal ya resueltas por prioridad:
+// sucursal + categoría, país + categoría, sucursal general y país general.
+type tasasImpuesto struct {
+	porCategoria map[int]float64
+	general      float64
+}
+
+func (t tasasImpuesto) tasa(categoriaId *int) float64 {
+	if categoriaId != nil {
+		if tasa, ok := t.porCategoria[*categoriaId]; ok {
+			return tasa
+		}
+	}
+	return t.general
+}
+
+const queryImpuesto = `
+SELECT
+    i.id,
+    i.nombre,
+    i.porcentaje,
+    i.exento,
+    (CASE WHEN p.id IS NOT NULL THEN json_build_object('id', p.id, 'nombre', p.nombre, 'codigoLocal', p.codigo_local, 'estado', p.estado, 'creadoEn', p.creado_en) END) AS pais,
+    (CASE WHEN s.id IS NOT NULL THEN json_build_object('id', s.id, 'nombre', s.nombre, 'estado', s.estado, 'creadoEn', s.creado_en) END) AS sucursal,
+    (CASE WHEN cp.id IS NOT NULL THEN json_build_object('id', cp.id, 'nombre', cp.nombre, 'descripcion', cp.descripcion, 'estado', cp.estado) END) AS categoria,
+    i.estado,
+    i.creado_en,
+    i.actualizado_en
+FROM impuesto i
+LEFT JOIN pais p ON i.pais_id = p.id
+LEFT JOIN sucursal s ON i.sucursal_id = s.id
+LEFT JOIN categoria_producto cp ON i.categoria_id = cp.id`
+
+func escanearImpuesto(row pgx.Row, item *domain.Impuesto) error {
+	return row.Scan(&item.Id, &item.Nombre, &item.Porcentaje, &item.Exento, &item.Pais, &item.Sucursal, &item.Categoria, &item.Estado, &item.CreadoEn, &item.ActualizadoEn)
+}
+
+func errorGuardarImpuesto(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return datatype.NewConflictError("Ya existe un impuesto activo para ese ámbito y categoría.")
+		case "23503":
+			return datatype.NewBadRequestError("El país, la sucursal o la categoría indicados no existen.")
+		}
+	}
+	log.Println("Error al guardar impuesto:", err)
+	return datatype.NewInternalServerErrorGeneric()
+}
+
+func (i ImpuestoRepository) RegistrarImpuesto(ctx context.Context, request *domain.ImpuestoRequest) (*int, error) {
+	var id int
+	query := `
+        INSERT INTO impuesto (nombre, porcentaje, exento, pais_id, sucursal_id, categoria_id, estado)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id`
+	err := i.pool.QueryRow(ctx, query, request.Nombre, request.Porcentaje, request.Exento, request.PaisId, request.SucursalId, request.CategoriaId, request.Estado).Scan(&id)
+	if err != nil {
+		return nil, errorGuardarImpuesto(err)
+	}
+	return &id, nil
+}
+
+func (i ImpuestoRepository) ModificarImpuesto(ctx context.Context, id *int, request *domain.ImpuestoRequest) error {
+	query := `
+        UPDATE impuesto
+        SET nombre = $1, porcentaje = $2, exento = $3, pais_id = $4, sucursal_id = $5, categoria_id = $6, estado = $7, actualizado_en = NOW()
+        WHERE id = $8`
+	ct, err := i.pool.Exec(ctx, query, request.Nombre, request.Porcentaje, request.Exento, request.PaisId, request.SucursalId, request.CategoriaId, request.Estado, *id)
+	if err != nil {
+		return errorGuardarImpuesto(err)
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewNotFoundError("Impuesto no encontrado")
+	}
+	return nil
+}
+
+func (i ImpuestoRepository) ListarImpuestos(ctx context.Context, filtros map[string]string) (*[]domain.Impuesto, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+
+	for _, filtro := range []struct{ clave, columna string }{
+		{"paisId", "i.pais_id"},
+		{"sucursalId", "i.sucursal_id"},
+		{"categoriaId", "i.categoria_id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+	if val := filtros["estado"]; val != "" {
+		filters = append(filters, fmt.Sprintf("i.estado = $%d", j))
+		args = append(args, val)
+	}
+
+	query := queryImpuesto
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY p.nombre NULLS LAST, s.nombre NULLS FIRST, cp.nombre NULLS FIRST"
+
+	rows, err := i.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar impuestos:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.Impuesto, 0)
+	for rows.Next() {
+		var item domain.Impuesto
+		if err := escanearImpuesto(rows, &item); err != nil {
+			log.Println("Error al escanear impuesto:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func (i ImpuestoRepository) ObtenerImpuestoById(ctx context.Context, id *int) (*domain.Impuesto, error) {
+	var item domain.Impuesto
+	err := escanearImpuesto(i.pool.QueryRow(ctx, queryImpuesto+" WHERE i.id = $1", *id), &item)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Impuesto no encontrado")
+		}
+		log.Println("Error al obtener impuesto:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+// obtenerTasasImpuesto carga las tasas activas que aplican a la sucursal (propias y de su país).
+func obtenerTasasImpuesto(ctx context.Context, tx pgx.Tx, sucursalId int) (*tasasImpuesto, error) {
+	query := `
+        SELECT i.categoria_id, CASE WHEN i.exento THEN 0 ELSE i.porcentaje END
+        FROM impuesto i
+        JOIN sucursal s ON s.id = $1
+        WHERE i.estado = 'Activo'
+          AND (i.sucursal_id = s.id OR (i.sucursal_id IS NULL AND i.pais_id = s.pais_id))
+        -- Las de país primero para que las de sucursal las sobrescriban
+        ORDER BY (i.sucursal_id IS NOT NULL)`
+	rows, err := tx.Query(ctx, query, sucursalId)
+	if err != nil {
+		log.Println("Error al obtener tasas de impuesto:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+
+	tasas := tasasImpuesto{porCategoria: make(map[int]float64)}
+	for rows.Next() {
+		var categoriaId *int
+		var porcentaje float64
+		if err := rows.Scan(&categoriaId, &porcentaje); err != nil {
+			log.Println("Error al escanear tasa de impuesto:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if categoriaId != nil {
+			tasas.porCategoria[*categoriaId] = porcentaje
+		} else {
+			tasas.general = porcentaje
+		}
+	}
+	return &tasas, nil
+}
+
+// calcularImpuestosVenta desglosa el impuesto incluido en cada línea según su tasa_impuesto y totaliza
+// la venta sumando el tiempo de sala (tasa general) y prorrateando el descuento general.
+func calcularImpuestosVenta(ctx context.Context, tx pgx.Tx, ventaId int, tasaTiempo float64) error {
+	queryLineas := `
+        UPDATE detalle_venta
+        SET impuesto = ROUND(((precio_venta * cantidad - descuento) * tasa_impuesto / (100 + tasa_impuesto))::numeric, 2),
+            base_imponible = ROUND((precio_venta * cantidad - descuento)::numeric, 2)
+                             - ROUND(((precio_venta * cantidad - descuento) * tasa_impuesto / (100 + tasa_impuesto))::numeric, 2)
+        WHERE venta_id = $1`
+	if _, err := tx.Exec(ctx, queryLineas, ventaId); err != nil {
+		log.Println("Error al calcular impuesto de las líneas:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	queryVenta := `
+        WITH lineas AS (
+            SELECT COALESCE(SUM(impuesto), 0) AS impuesto
+            FROM detalle_venta
+            WHERE venta_id = $1
+        ), bruto AS (
+            SELECT v.id,
+                   lineas.impuesto + (v.costo_tiempo_venta - v.descuento_tiempo) * $2 / (100 + $2) AS impuesto,
+                   v.total + v.descuento_general AS total_bruto,
+                   v.total
+            FROM venta v, lineas
+            WHERE v.id = $1
+        )
+        UPDATE venta v
+        SET tasa_impuesto_tiempo = $2,
+            impuesto = ROUND((CASE WHEN b.total_bruto > 0 THEN b.impuesto * b.total / b.total_bruto ELSE 0 END)::numeric, 2),
+            base_imponible = ROUND(b.total::numeric, 2)
+                             - ROUND((CASE WHEN b.total_bruto > 0 THEN b.impuesto * b.total / b.total_bruto ELSE 0 END)::numeric, 2)
+        FROM bruto b
+        WHERE v.id = b.id`
+	if _, err := tx.Exec(ctx, queryVenta, ventaId, tasaTiempo); err != nil {
+		log.Println("Error al calcular impuesto de la venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func NewImpuestoRepository(pool *pgxpool.Pool) *ImpuestoRepository {
+	return &ImpuestoRepository{pool: pool}
+}
+
+var _ port.ImpuestoRepository = (*ImpuestoRepository)(nil)
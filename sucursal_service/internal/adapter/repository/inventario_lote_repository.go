@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Los lotes desglosan el stock de inventario por fecha de vencimiento dentro de cada ubicación. inventario sigue
+// siendo el total; lo que no está en un lote es stock sin lote (anterior al registro de lotes, ingresado sin
+// fecha o devuelto por una anulación).
+
+// consumoLote es lo descontado de un lote en una salida
+type consumoLote struct {
+	LoteId           int
+	Lote             string
+	FechaVencimiento time.Time
+	Cantidad         int
+}
+
+// fechaVencimientoLote convierte la fecha recibida (ya validada en el servicio); nil si no se indicó
+func fechaVencimientoLote(fecha *string) (*time.Time, error) {
+	if fecha == nil {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", *fecha)
+	if err != nil {
+		return nil, datatype.NewBadRequestError("fechaVencimiento debe tener formato YYYY-MM-DD.")
+	}
+	return &t, nil
+}
+
+// sumarLote agrega al lote la cantidad que entró a la ubicación
+func sumarLote(ctx context.Context, tx pgx.Tx, productoId, ubicacionId int, lote string, fechaVencimiento time.Time, cantidad int) error {
+	query := `
+        INSERT INTO inventario_lote (producto_id, ubicacion_id, lote, fecha_vencimiento, stock, creado_en)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        ON CONFLICT (producto_id, ubicacion_id, lote, fecha_vencimiento)
+        DO UPDATE SET stock = inventario_lote.stock + EXCLUDED.stock`
+	if _, err := tx.Exec(ctx, query, productoId, ubicacionId, lote, fechaVencimiento, cantidad); err != nil {
+		log.Println("Error al sumar stock al lote:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// sumarLotes vuelve a ingresar en otra ubicación los lotes que salieron de una transferencia
+func sumarLotes(ctx context.Context, tx pgx.Tx, productoId, ubicacionId int, lotes []consumoLote) error {
+	for _, l := range lotes {
+		if err := sumarLote(ctx, tx, productoId, ubicacionId, l.Lote, l.FechaVencimiento, l.Cantidad); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// descontarLotes reparte entre los lotes de la ubicación una salida ya aplicada en inventario; saldo es el stock
+// que quedó. Salen primero los lotes vigentes por fecha de vencimiento (FEFO), luego el stock sin lote y al
+// final los lotes vencidos, que solo se tocan si no queda otra cosa. Con vencidosPrimero (bajas por vencimiento
+// o merma) el orden se invierte: primero los vencidos, luego el stock sin lote y al final los vigentes.
+func descontarLotes(ctx context.Context, tx pgx.Tx, productoId, ubicacionId, cantidad, saldo int, vencidosPrimero bool) ([]consumoLote, error) {
+	type loteDisponible struct {
+		consumoLote
+		Stock   int
+		Vencido bool
+	}
+	query := `
+        SELECT id, lote, fecha_vencimiento, stock, fecha_vencimiento < CURRENT_DATE
+        FROM inventario_lote
+        WHERE producto_id = $1 AND ubicacion_id = $2 AND stock > 0
+        ORDER BY fecha_vencimiento, id
+        FOR UPDATE`
+	rows, err := tx.Query(ctx, query, productoId, ubicacionId)
+	if err != nil {
+		log.Println("Error al obtener lotes:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var lotes []loteDisponible
+	var stockLotes int
+	for rows.Next() {
+		var l loteDisponible
+		if err := rows.Scan(&l.LoteId, &l.Lote, &l.FechaVencimiento, &l.Stock, &l.Vencido); err != nil {
+			rows.Close()
+			log.Println("Error al escanear lote:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		lotes = append(lotes, l)
+		stockLotes += l.Stock
+	}
+	rows.Close()
+	if len(lotes) == 0 {
+		return nil, nil
+	}
+
+	var consumos []consumoLote
+	resto := cantidad
+	tomar := func(vencidos bool) {
+		for _, l := range lotes {
+			if resto == 0 {
+				return
+			}
+			if l.Vencido != vencidos {
+				continue
+			}
+			c := l.consumoLote
+			c.Cantidad = min(resto, l.Stock)
+			resto -= c.Cantidad
+			consumos = append(consumos, c)
+		}
+	}
+	tomar(vencidosPrimero)
+	resto -= min(resto, max(saldo+cantidad-stockLotes, 0))
+	tomar(!vencidosPrimero)
+
+	for _, c := range consumos {
+		if _, err := tx.Exec(ctx, `UPDATE inventario_lote SET stock = stock - $1 WHERE id = $2`, c.Cantidad, c.LoteId); err != nil {
+			log.Println("Error al descontar stock del lote:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+	}
+	return consumos, nil
+}
+
+// descontarLote saca la cantidad de un lote puntual, como en la baja de un lote vencido
+func descontarLote(ctx context.Context, tx pgx.Tx, loteId, productoId, ubicacionId, cantidad int) error {
+	query := `
+        UPDATE inventario_lote SET stock = stock - $1
+        WHERE id = $2 AND producto_id = $3 AND ubicacion_id = $4 AND stock >= $1`
+	cmdTag, err := tx.Exec(ctx, query, cantidad, loteId, productoId, ubicacionId)
+	if err != nil {
+		log.Println("Error al descontar stock del lote:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return datatype.NewBadRequestError(fmt.Sprintf("El lote %d no tiene stock suficiente del producto %d en la ubicación %d.", loteId, productoId, ubicacionId))
+	}
+	return nil
+}
+
+func (i InventarioRepository) ListarLotes(ctx context.Context, filtros map[string]string) (*[]domain.LoteInventario, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	filters := []string{"il.stock > 0"}
+	args := []interface{}{fullHostname}
+	var j = 2
+	for _, filtro := range []struct{ clave, columna string }{
+		{"sucursalId", "u.sucursal_id"},
+		{"ubicacionId", "il.ubicacion_id"},
+		{"productoId", "il.producto_id"},
+		{"categoriaId", "p.categoria_id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+	// diasVencimiento deja los lotes que vencen dentro de ese plazo, incluidos los ya vencidos
+	if val := filtros["diasVencimiento"]; val != "" {
+		dias, err := strconv.Atoi(val)
+		if err != nil || dias < 0 {
+			return nil, datatype.NewBadRequestError("El valor de diasVencimiento no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("il.fecha_vencimiento <= CURRENT_DATE + $%d::int", j))
+		args = append(args, dias)
+		j++
+	}
+	if filtros["soloVencidos"] == "true" {
+		filters = append(filters, "il.fecha_vencimiento < CURRENT_DATE")
+	}
+
+	query := `
+        SELECT il.id,
+               json_build_object(
+                  'id', p.id,
+                  'nombre', p.nombre,
+                  'estado', p.estado,
+                  'urlFoto', ($1::text || p.id::text || '/' || p.foto),
+                  'esInventariable', p.es_inventariable,
+                  'creadoEn', p.creado_en,
+                  'actualizadoEn', p.actualizado_en,
+                  'eliminadoEn', p.eliminado_en
+               ),
+               json_build_object(
+                  'id', u.id, 'nombre', u.nombre, 'estado', u.estado, 'esVendible', u.es_vendible, 'prioridadVenta', u.prioridad_venta
+               ),
+               il.lote, il.fecha_vencimiento, (il.fecha_vencimiento - CURRENT_DATE), il.fecha_vencimiento < CURRENT_DATE,
+               il.stock, cp.costo_promedio, ROUND(il.stock * cp.costo_promedio, 2)::float8
+        FROM inventario_lote il
+        JOIN ubicacion u ON il.ubicacion_id = u.id
+        JOIN producto p ON il.producto_id = p.id
+        LEFT JOIN costo_producto cp ON cp.producto_id = il.producto_id AND cp.sucursal_id = u.sucursal_id
+        WHERE ` + strings.Join(filters, " AND ") + `
+        ORDER BY il.fecha_vencimiento, p.nombre, u.nombre`
+	rows, err := i.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar lotes:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.LoteInventario, 0)
+	for rows.Next() {
+		var item domain.LoteInventario
+		err := rows.Scan(&item.Id, &item.Producto, &item.Ubicacion, &item.Lote, &item.FechaVencimiento, &item.DiasParaVencer, &item.Vencido,
+			&item.Stock, &item.CostoUnitario, &item.ValorStock)
+		if err != nil {
+			log.Println("Error al escanear lote:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+// DarDeBajaLotes saca todo el stock de los lotes en un ajuste VENCIMIENTO con un detalle por lote
+func (i InventarioRepository) DarDeBajaLotes(ctx context.Context, request *domain.BajaLotesRequest, usuarioId int) (*int, error) {
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	loteIds := request.LoteIds
+	if loteIds == nil {
+		loteIds = []int{}
+	}
+	query := `
+        SELECT il.id, il.producto_id, il.ubicacion_id, il.stock
+        FROM inventario_lote il
+        JOIN ubicacion u ON il.ubicacion_id = u.id
+        WHERE u.sucursal_id = $1 AND il.stock > 0
+          AND ($2::int IS NULL OR il.ubicacion_id = $2)
+          AND (il.id = ANY($3) OR (cardinality($3::int[]) = 0 AND il.fecha_vencimiento < CURRENT_DATE))
+        ORDER BY il.id
+        FOR UPDATE OF il`
+	rows, err := tx.Query(ctx, query, request.SucursalId, request.UbicacionId, loteIds)
+	if err != nil {
+		log.Println("Error al obtener lotes a dar de baja:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var detalles []domain.DetalleAjusteInventarioRequest
+	for rows.Next() {
+		var loteId, productoId, ubicacionId int
+		var stock int64
+		if err := rows.Scan(&loteId, &productoId, &ubicacionId, &stock); err != nil {
+			rows.Close()
+			log.Println("Error al escanear lote:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		detalles = append(detalles, domain.DetalleAjusteInventarioRequest{ProductoId: productoId, UbicacionId: ubicacionId, Cantidad: -stock, LoteId: &loteId})
+	}
+	rows.Close()
+	if len(request.LoteIds) > 0 && len(detalles) != len(request.LoteIds) {
+		return nil, datatype.NewBadRequestError("Uno o más lotes no existen, no tienen stock o no pertenecen a la sucursal.")
+	}
+	if len(detalles) == 0 {
+		return nil, datatype.NewBadRequestError("No hay lotes vencidos con stock para dar de baja.")
+	}
+
+	motivo := request.Motivo
+	if motivo == "" {
+		motivo = "Baja de lotes vencidos"
+	}
+	ajusteId, err := registrarAjuste(ctx, tx, &domain.AjusteInventarioRequest{
+		TipoAjuste:   domain.TipoAjusteVencimiento,
+		Motivo:       motivo,
+		SucursalId:   request.SucursalId,
+		UsuarioId:    usuarioId,
+		Detalles:     detalles,
+		Autorizacion: request.Autorizacion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return &ajusteId, nil
+}
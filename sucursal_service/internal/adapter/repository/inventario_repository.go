@@ -58,11 +58,38 @@ func (i InventarioRepository) ListarTransferencias(ctx context.Context, filtros
 		j++
 	}
 
+	for _, filtro := range []struct{ clave, columna string }{
+		{"sucursalOrigenId", "uo.sucursal_id"},
+		{"sucursalDestinoId", "ud.sucursal_id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+
+	if estado := filtros["estado"]; estado != "" {
+		filters = append(filters, fmt.Sprintf("t.estado = $%d", j))
+		args = append(args, estado)
+		j++
+	}
+
 	query := `
 SELECT 
     t.id,
     t.motivo,
+    t.estado,
+    t.motivo_rechazo,
+    t.ajuste_inventario_id,
     t.fecha,
+    t.revisado_en,
+    t.despachado_en,
+    t.recibido_en,
     json_build_object(
     	'id',u.id,
 		'username',u.username
@@ -99,7 +126,8 @@ LEFT JOIN public.ubicacion ud ON t.ubicacion_destino_id = ud.id
 	list := make([]domain.TransferenciaInventarioInfo, 0)
 	for rows.Next() {
 		var item domain.TransferenciaInventarioInfo
-		err := rows.Scan(&item.Id, &item.Motivo, &item.Fecha, &item.Usuario, &item.UbicacionOrigen, &item.UbicacionDestino)
+		err := rows.Scan(&item.Id, &item.Motivo, &item.Estado, &item.MotivoRechazo, &item.AjusteId, &item.Fecha, &item.RevisadoEn, &item.DespachadoEn,
+			&item.RecibidoEn, &item.Usuario, &item.UbicacionOrigen, &item.UbicacionDestino)
 		if err != nil {
 			log.Println("Error al escanear ajuste_inventario", err)
 			return nil, datatype.NewInternalServerErrorGeneric()
@@ -116,7 +144,13 @@ func (i InventarioRepository) ObtenerTransferenciaById(ctx context.Context, id *
 SELECT 
     t.id,
     t.motivo,
+    t.estado,
+    t.motivo_rechazo,
+    t.ajuste_inventario_id,
     t.fecha,
+    t.revisado_en,
+    t.despachado_en,
+    t.recibido_en,
     json_build_object(
     	'id',u.id,
 		'username',u.username
@@ -141,7 +175,9 @@ SELECT
              WHEN dt.id IS NULL THEN NULL
              ELSE json_build_object(
              	'id',dt.id,
+                'cantidadSolicitada',dt.cantidad_solicitada,
                 'cantidad',dt.cantidad,
+                'cantidadRecibida',dt.cantidad_recibida,
                 'producto', json_build_object(
                     'id',p.id,
                     'nombre',p.nombre,
@@ -167,7 +203,8 @@ GROUP BY t.id,u.id,uo.id,ud.id
 LIMIT 1
 `
 	var item domain.TransferenciaInventario
-	err := i.pool.QueryRow(ctx, query, fullHostname, *id).Scan(&item.Id, &item.Motivo, &item.Fecha, &item.Usuario, &item.UbicacionOrigen, &item.UbicacionDestino, &item.Detalles)
+	err := i.pool.QueryRow(ctx, query, fullHostname, *id).Scan(&item.Id, &item.Motivo, &item.Estado, &item.MotivoRechazo, &item.AjusteId, &item.Fecha, &item.RevisadoEn,
+		&item.DespachadoEn, &item.RecibidoEn, &item.Usuario, &item.UbicacionOrigen, &item.UbicacionDestino, &item.Detalles)
 	if err != nil {
 		log.Println("Error al consultar:", err)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -185,12 +222,18 @@ func (i InventarioRepository) ObtenerAjusteById(ctx context.Context, id *int) (*
 SELECT 
     ai.id,
     ai.tipo_ajuste,
+    COALESCE(ta.nombre, ai.tipo_ajuste),
+    ta.categoria_contable,
     ai.motivo,
     ai.fecha,
     json_build_object(
         'id',ua.id,
         'username',ua.username
     ) AS usuario,
+    CASE WHEN sup.id IS NULL THEN NULL ELSE json_build_object(
+        'id',sup.id,
+        'username',sup.username
+    ) END AS autorizado_por,
     json_build_object(
         'id',s.id,
         'nombre',s.nombre,
@@ -226,16 +269,19 @@ SELECT
        '[]'
     ) AS detalles
 FROM ajuste_inventario ai
+LEFT JOIN public.tipo_ajuste ta on ta.codigo = ai.tipo_ajuste
 LEFT JOIN public.usuario_admin ua on ai.usuario_id = ua.id
+LEFT JOIN public.usuario_admin sup on ai.autorizado_por = sup.id
 LEFT JOIN public.sucursal s on ai.sucursal_id = s.id
 LEFT JOIN public.detalle_ajuste_inventario dai on ai.id = dai.ajuste_inventario_id
 LEFT JOIN public.producto p on dai.producto_id = p.id
 LEFT JOIN public.ubicacion u on dai.ubicacion_id = u.id
 WHERE ai.id = $2
-GROUP BY ai.id, ua.id, s.id
+GROUP BY ai.id, ta.id, ua.id, sup.id, s.id
 `
 	var item domain.AjusteInventario
-	err := i.pool.QueryRow(ctx, query, fullHostname, *id).Scan(&item.Id, &item.TipoAjuste, &item.Motivo, &item.Fecha, &item.Usuario, &item.Sucursal, &item.Detalles)
+	err := i.pool.QueryRow(ctx, query, fullHostname, *id).Scan(&item.Id, &item.TipoAjuste, &item.TipoAjusteNombre, &item.CategoriaContable, &item.Motivo, &item.Fecha, &item.Usuario,
+		&item.AutorizadoPor, &item.Sucursal, &item.Detalles)
 	if err != nil {
 		log.Println("Error al consultar:", err)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -246,6 +292,79 @@ GROUP BY ai.id, ua.id, s.id
 	return &item, nil
 }
 
+// ResumenAjustesPorTipo totaliza unidades y valor de los ajustes por tipo desde el kardex; el valor usa el costo
+// unitario registrado en cada movimiento.
+func (i InventarioRepository) ResumenAjustesPorTipo(ctx context.Context, filtros map[string]string) (*[]domain.ResumenTipoAjuste, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+	if sucursalIdStr := filtros["sucursalId"]; sucursalIdStr != "" {
+		sucursalId, err := strconv.Atoi(sucursalIdStr)
+		if err != nil {
+			log.Println("Error al convertir sucursalId a int:", err)
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("ai.sucursal_id = $%d", j))
+		args = append(args, sucursalId)
+		j++
+	}
+	for _, f := range []struct{ clave, columna string }{
+		{"tipoAjuste", "ai.tipo_ajuste = $%d"},
+		{"categoriaContable", "ta.categoria_contable = $%d"},
+		{"fechaInicio", "ai.fecha >= $%d::date"},
+		{"fechaFin", "ai.fecha < $%d::date + 1"},
+	} {
+		if val := filtros[f.clave]; val != "" {
+			filters = append(filters, fmt.Sprintf(f.columna, j))
+			args = append(args, val)
+			j++
+		}
+	}
+
+	query := `
+SELECT
+    ai.tipo_ajuste,
+    COALESCE(ta.nombre, ai.tipo_ajuste),
+    COALESCE(ta.direccion, 'MIXTO'),
+    ta.categoria_contable,
+    COUNT(DISTINCT ai.id),
+    COALESCE(SUM(m.cantidad) FILTER (WHERE m.cantidad > 0), 0),
+    COALESCE(-SUM(m.cantidad) FILTER (WHERE m.cantidad < 0), 0),
+    COALESCE(SUM(m.cantidad * COALESCE(m.costo_unitario, 0)) FILTER (WHERE m.cantidad > 0), 0)::float8,
+    COALESCE(-SUM(m.cantidad * COALESCE(m.costo_unitario, 0)) FILTER (WHERE m.cantidad < 0), 0)::float8
+FROM ajuste_inventario ai
+LEFT JOIN tipo_ajuste ta ON ta.codigo = ai.tipo_ajuste
+LEFT JOIN movimiento_inventario m ON m.tipo_documento = 'AJUSTE' AND m.documento_id = ai.id
+`
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " GROUP BY ai.tipo_ajuste, ta.id ORDER BY ta.categoria_contable NULLS LAST, 2"
+
+	rows, err := i.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al ejecutar consulta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.ResumenTipoAjuste, 0)
+	for rows.Next() {
+		var item domain.ResumenTipoAjuste
+		err := rows.Scan(&item.Codigo, &item.Nombre, &item.Direccion, &item.CategoriaContable, &item.CantidadAjustes,
+			&item.UnidadesEntrada, &item.UnidadesSalida, &item.ValorEntrada, &item.ValorSalida)
+		if err != nil {
+			log.Println("Error al escanear resumen de ajustes", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("Error durante la iteración de filas:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &list, nil
+}
+
 func (i InventarioRepository) ListarAjustes(ctx context.Context, filtros map[string]string) (*[]domain.AjusteInventarioInfo, error) {
 	var filters []string
 	var args []interface{}
@@ -260,17 +379,35 @@ func (i InventarioRepository) ListarAjustes(ctx context.Context, filtros map[str
 		args = append(args, sucursalId)
 		j++
 	}
+	for _, f := range []struct{ clave, columna string }{
+		{"tipoAjuste", "ai.tipo_ajuste = $%d"},
+		{"categoriaContable", "ta.categoria_contable = $%d"},
+		{"fechaInicio", "ai.fecha >= $%d::date"},
+		{"fechaFin", "ai.fecha < $%d::date + 1"},
+	} {
+		if val := filtros[f.clave]; val != "" {
+			filters = append(filters, fmt.Sprintf(f.columna, j))
+			args = append(args, val)
+			j++
+		}
+	}
 
 	query := `
 SELECT 
     ai.id,
     ai.tipo_ajuste,
+    COALESCE(ta.nombre, ai.tipo_ajuste),
+    ta.categoria_contable,
     ai.motivo,
     ai.fecha,
     json_build_object(
     	'id',ua.id,
     	'username',ua.username
     ) AS usuario,
+    CASE WHEN sup.id IS NULL THEN NULL ELSE json_build_object(
+    	'id',sup.id,
+    	'username',sup.username
+    ) END AS autorizado_por,
     json_build_object(
     	'id',s.id,
     	'nombre',s.nombre,
@@ -278,7 +415,9 @@ SELECT
     	'creadoEn',s.creado_en
     ) AS sucursal
 FROM ajuste_inventario ai
+LEFT JOIN public.tipo_ajuste ta on ta.codigo = ai.tipo_ajuste
 LEFT JOIN public.usuario_admin ua on ai.usuario_id = ua.id
+LEFT JOIN public.usuario_admin sup on ai.autorizado_por = sup.id
 LEFT JOIN public.sucursal s on ai.sucursal_id = s.id
 `
 	if len(filters) > 0 {
@@ -294,7 +433,8 @@ LEFT JOIN public.sucursal s on ai.sucursal_id = s.id
 	list := make([]domain.AjusteInventarioInfo, 0)
 	for rows.Next() {
 		var item domain.AjusteInventarioInfo
-		err := rows.Scan(&item.Id, &item.TipoAjuste, &item.Motivo, &item.Fecha, &item.Usuario, &item.Sucursal)
+		err := rows.Scan(&item.Id, &item.TipoAjuste, &item.TipoAjusteNombre, &item.CategoriaContable, &item.Motivo, &item.Fecha,
+			&item.Usuario, &item.AutorizadoPor, &item.Sucursal)
 		if err != nil {
 			log.Println("Error al escanear ajuste_inventario", err)
 			return nil, datatype.NewInternalServerErrorGeneric()
@@ -329,14 +469,25 @@ func (i InventarioRepository) RegistrarTransferencia(ctx context.Context, reques
 		}
 	}()
 
+	// Entre sucursales el stock sale del origen y queda en tránsito hasta que el destino confirma la recepción
+	sucursalOrigenId, sucursalDestinoId, err := obtenerSucursalesTransferencia(ctx, tx, request.UbicacionOrigenId, request.UbicacionDestinoId)
+	if err != nil {
+		return nil, err
+	}
+	enTransito := sucursalOrigenId != sucursalDestinoId
+	estado := domain.TransferenciaCompletada
+	if enTransito {
+		estado = domain.TransferenciaEnTransito
+	}
+
 	// 2. INSERTAR ENCABEZADO (transferencia)
 	var transferenciaId int
 	queryEncabezado := `
-        INSERT INTO transferencia (ubicacion_origen_id, ubicacion_destino_id, usuario_id, motivo, fecha) 
-        VALUES($1, $2, $3, $4, NOW()) 
+        INSERT INTO transferencia (ubicacion_origen_id, ubicacion_destino_id, usuario_id, motivo, estado, fecha, despachado_por, despachado_en, recibido_por, recibido_en) 
+        VALUES($1, $2, $3, $4, $5, NOW(), $3, NOW(), CASE WHEN $6 THEN $3::int END, CASE WHEN $6 THEN NOW() END) 
         RETURNING id`
 
-	err = tx.QueryRow(ctx, queryEncabezado, request.UbicacionOrigenId, request.UbicacionDestinoId, request.UsuarioId, request.Motivo).Scan(&transferenciaId)
+	err = tx.QueryRow(ctx, queryEncabezado, request.UbicacionOrigenId, request.UbicacionDestinoId, request.UsuarioId, request.Motivo, estado, !enTransito).Scan(&transferenciaId)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -362,17 +513,7 @@ func (i InventarioRepository) RegistrarTransferencia(ctx context.Context, reques
 
 	// 3. BUCLE: Aplicar Lógica de Stock y Recolectar para CopyFrom
 	var rowsDetalle [][]interface{}
-
-	queryCheckStock := `SELECT stock FROM inventario WHERE producto_id = $1 AND ubicacion_id = $2 FOR UPDATE`
-
-	queryUpdateOrigen := `UPDATE inventario SET stock = stock - $1 WHERE producto_id = $2 AND ubicacion_id = $3`
-
-	querySumaDestino := `
-       INSERT INTO inventario (producto_id, ubicacion_id, stock)
-       VALUES ($1, $2, $3)
-       ON CONFLICT (producto_id, ubicacion_id) 
-       DO UPDATE SET stock = inventario.stock + EXCLUDED.stock;
-    `
+	var movimientos []movimientoInventario
 
 	for _, detalle := range request.Detalles {
 
@@ -381,40 +522,30 @@ func (i InventarioRepository) RegistrarTransferencia(ctx context.Context, reques
 		}
 
 		// RESTAR de la Ubicación de Origen (con chequeo de stock)
-		var stockOrigen int64
-		err = tx.QueryRow(ctx, queryCheckStock, detalle.ProductoId, request.UbicacionOrigenId).Scan(&stockOrigen)
-
+		saldoOrigen, lotes, err := descontarStockTransferencia(ctx, tx, detalle.ProductoId, request.UbicacionOrigenId, detalle.Cantidad)
 		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				return nil, datatype.NewBadRequestError(fmt.Sprintf("No hay stock del producto %d en la ubicación de origen.", detalle.ProductoId))
-			}
-			log.Println("Error al consultar stock de origen:", err)
-			return nil, datatype.NewInternalServerErrorGeneric()
+			return nil, err
 		}
-
-		if stockOrigen < detalle.Cantidad {
-			return nil, datatype.NewBadRequestError(fmt.Sprintf("Stock insuficiente en Origen para producto %d. Actual: %d, Se intenta transferir: %d", detalle.ProductoId, stockOrigen, detalle.Cantidad))
+		costoOrigen, err := obtenerCostoPromedio(ctx, tx, detalle.ProductoId, sucursalOrigenId)
+		if err != nil {
+			return nil, err
 		}
+		movimientos = append(movimientos, movimientoInventario{ProductoId: detalle.ProductoId, UbicacionId: request.UbicacionOrigenId, Cantidad: -int(detalle.Cantidad), Saldo: saldoOrigen, CostoUnitario: costoOrigen})
 
-		_, err = tx.Exec(ctx, queryUpdateOrigen, detalle.Cantidad, detalle.ProductoId, request.UbicacionOrigenId)
-		if err != nil {
-			var pgErr *pgconn.PgError
-			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
-				return nil, datatype.NewBadRequestError(fmt.Sprintf("Error de referencia en el encabezado: %s", pgErr.ConstraintName))
+		// SUMAR a la Ubicación de Destino solo si no viaja
+		var cantidadRecibida *int64
+		if !enTransito {
+			movimiento, err := ingresarStockTransferencia(ctx, tx, detalle.ProductoId, request.UbicacionDestinoId, sucursalOrigenId, sucursalDestinoId, int(detalle.Cantidad), costoOrigen)
+			if err != nil {
+				return nil, err
 			}
-			// Fallback por si la validación de Go falla
-			if errors.As(err, &pgErr) && pgErr.Code == "23514" && pgErr.ConstraintName == "check_diferente_ubicacion" {
-				return nil, datatype.NewBadRequestError("La ubicación de origen y destino no pueden ser la misma.")
+			movimientos = append(movimientos, movimiento)
+			cantidadRecibida = &detalle.Cantidad
+			if err = sumarLotes(ctx, tx, detalle.ProductoId, request.UbicacionDestinoId, lotes); err != nil {
+				return nil, err
 			}
-			log.Println("Error al restar stock de origen:", err)
-			return nil, datatype.NewInternalServerErrorGeneric()
-		}
-
-		// SUMAR a la Ubicación de Destino (UPSERT)
-		_, err = tx.Exec(ctx, querySumaDestino, detalle.ProductoId, request.UbicacionDestinoId, detalle.Cantidad)
-		if err != nil {
-			log.Println("Error al sumar stock a destino (UPSERT):", err)
-			return nil, datatype.NewInternalServerErrorGeneric()
+		} else if err = registrarLotesTransferencia(ctx, tx, transferenciaId, detalle.ProductoId, lotes); err != nil {
+			return nil, err
 		}
 
 		// 3c. Recolectar para el INSERT masivo de detalles
@@ -422,11 +553,13 @@ func (i InventarioRepository) RegistrarTransferencia(ctx context.Context, reques
 			transferenciaId,
 			detalle.ProductoId,
 			detalle.Cantidad,
+			cantidadRecibida,
+			costoOrigen,
 		})
 	}
 
 	// INSERTAR DETALLES (Usando CopyFrom)
-	columnasDetalle := []string{"transferencia_id", "producto_id", "cantidad"}
+	columnasDetalle := []string{"transferencia_id", "producto_id", "cantidad", "cantidad_recibida", "costo_unitario"}
 
 	_, err = tx.CopyFrom(
 		ctx,
@@ -443,6 +576,10 @@ func (i InventarioRepository) RegistrarTransferencia(ctx context.Context, reques
 		return nil, datatype.NewInternalServerErrorGeneric()
 	}
 
+	if err = registrarMovimientosInventario(ctx, tx, domain.DocumentoTransferencia, transferenciaId, &request.UsuarioId, movimientos); err != nil {
+		return nil, err
+	}
+
 	// 5. Commit de la transacción
 	err = tx.Commit(ctx)
 	if err != nil {
@@ -454,6 +591,494 @@ func (i InventarioRepository) RegistrarTransferencia(ctx context.Context, reques
 	return &transferenciaId, nil
 }
 
+// RegistrarSolicitudTransferencia guarda el pedido de la sucursal de destino sin mover stock; queda pendiente de
+// aprobación por el origen.
+func (i InventarioRepository) RegistrarSolicitudTransferencia(ctx context.Context, request *domain.TransferenciaRequest) (*int, error) {
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	if _, _, err = obtenerSucursalesTransferencia(ctx, tx, request.UbicacionOrigenId, request.UbicacionDestinoId); err != nil {
+		return nil, err
+	}
+
+	var transferenciaId int
+	queryEncabezado := `
+        INSERT INTO transferencia (ubicacion_origen_id, ubicacion_destino_id, usuario_id, motivo, estado, fecha)
+        VALUES($1, $2, $3, $4, $5, NOW())
+        RETURNING id`
+	err = tx.QueryRow(ctx, queryEncabezado, request.UbicacionOrigenId, request.UbicacionDestinoId, request.UsuarioId, request.Motivo,
+		domain.TransferenciaSolicitada).Scan(&transferenciaId)
+	if err != nil {
+		log.Println("Error al insertar solicitud de transferencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	var rowsDetalle [][]interface{}
+	for _, detalle := range request.Detalles {
+		rowsDetalle = append(rowsDetalle, []interface{}{transferenciaId, detalle.ProductoId, detalle.Cantidad, detalle.Cantidad})
+	}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"detalle_transferencia"}, []string{"transferencia_id", "producto_id", "cantidad_solicitada", "cantidad"}, pgx.CopyFromRows(rowsDetalle))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, datatype.NewBadRequestError("Uno de los productos solicitados no existe.")
+		}
+		log.Println("Error al insertar detalles de la solicitud de transferencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción de transferencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return &transferenciaId, nil
+}
+
+func (i InventarioRepository) AprobarTransferencia(ctx context.Context, transferenciaId int, usuarioId int) error {
+	query := `UPDATE transferencia SET estado = $1, revisado_por = $2, revisado_en = NOW() WHERE id = $3 AND estado = $4`
+	cmdTag, err := i.pool.Exec(ctx, query, domain.TransferenciaAprobada, usuarioId, transferenciaId, domain.TransferenciaSolicitada)
+	if err != nil {
+		log.Println("Error al aprobar transferencia:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return estadoTransferenciaInvalido(ctx, i.pool, transferenciaId, "aprobar")
+	}
+	return nil
+}
+
+// RechazarTransferencia descarta una solicitud todavía no despachada
+func (i InventarioRepository) RechazarTransferencia(ctx context.Context, transferenciaId int, motivo string, usuarioId int) error {
+	query := `
+        UPDATE transferencia SET estado = $1, motivo_rechazo = $2, revisado_por = $3, revisado_en = NOW()
+        WHERE id = $4 AND estado IN ($5, $6)`
+	cmdTag, err := i.pool.Exec(ctx, query, domain.TransferenciaRechazada, motivo, usuarioId, transferenciaId, domain.TransferenciaSolicitada, domain.TransferenciaAprobada)
+	if err != nil {
+		log.Println("Error al rechazar transferencia:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return estadoTransferenciaInvalido(ctx, i.pool, transferenciaId, "rechazar")
+	}
+	return nil
+}
+
+// DespacharTransferencia descuenta del origen lo que se envía de una solicitud aprobada. Entre sucursales queda
+// en tránsito; dentro de la misma sucursal se completa en el acto.
+func (i InventarioRepository) DespacharTransferencia(ctx context.Context, transferenciaId int, request *domain.DespachoTransferenciaRequest, usuarioId int) (string, error) {
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return "", datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	transferencia, err := bloquearTransferencia(ctx, tx, transferenciaId)
+	if err != nil {
+		return "", err
+	}
+	if transferencia.estado != domain.TransferenciaAprobada {
+		return "", datatype.NewConflictError(fmt.Sprintf("Solo se despacha una transferencia aprobada; esta está '%s'.", transferencia.estado))
+	}
+	sucursalOrigenId, sucursalDestinoId, err := obtenerSucursalesTransferencia(ctx, tx, transferencia.ubicacionOrigenId, transferencia.ubicacionDestinoId)
+	if err != nil {
+		return "", err
+	}
+	enTransito := sucursalOrigenId != sucursalDestinoId
+
+	detalles, err := obtenerDetallesTransferencia(ctx, tx, transferenciaId)
+	if err != nil {
+		return "", err
+	}
+	enviar := make(map[int]int64, len(request.Detalles))
+	for _, d := range request.Detalles {
+		enviar[d.ProductoId] = d.Cantidad
+	}
+	for productoId := range enviar {
+		if !contieneProductoTransferencia(detalles, productoId) {
+			return "", datatype.NewBadRequestError(fmt.Sprintf("El producto %d no forma parte de la transferencia.", productoId))
+		}
+	}
+
+	var movimientos []movimientoInventario
+	var totalEnviado int64
+	queryDetalle := `UPDATE detalle_transferencia SET cantidad = $1, costo_unitario = $2, cantidad_recibida = $3 WHERE id = $4`
+	for _, detalle := range detalles {
+		cantidad := detalle.cantidad
+		if v, ok := enviar[detalle.productoId]; ok {
+			cantidad = v
+		}
+		if cantidad < 0 {
+			return "", datatype.NewBadRequestError(fmt.Sprintf("La cantidad a enviar del producto %d no puede ser negativa.", detalle.productoId))
+		}
+		var costoOrigen *float64
+		var cantidadRecibida *int64
+		if cantidad > 0 {
+			saldoOrigen, lotes, err := descontarStockTransferencia(ctx, tx, detalle.productoId, transferencia.ubicacionOrigenId, cantidad)
+			if err != nil {
+				return "", err
+			}
+			costoOrigen, err = obtenerCostoPromedio(ctx, tx, detalle.productoId, sucursalOrigenId)
+			if err != nil {
+				return "", err
+			}
+			movimientos = append(movimientos, movimientoInventario{ProductoId: detalle.productoId, UbicacionId: transferencia.ubicacionOrigenId, Cantidad: -int(cantidad), Saldo: saldoOrigen, CostoUnitario: costoOrigen})
+			if !enTransito {
+				movimiento, err := ingresarStockTransferencia(ctx, tx, detalle.productoId, transferencia.ubicacionDestinoId, sucursalOrigenId, sucursalDestinoId, int(cantidad), costoOrigen)
+				if err != nil {
+					return "", err
+				}
+				movimientos = append(movimientos, movimiento)
+				cantidadRecibida = &cantidad
+				if err = sumarLotes(ctx, tx, detalle.productoId, transferencia.ubicacionDestinoId, lotes); err != nil {
+					return "", err
+				}
+			} else if err = registrarLotesTransferencia(ctx, tx, transferenciaId, detalle.productoId, lotes); err != nil {
+				return "", err
+			}
+			totalEnviado += cantidad
+		}
+		if _, err = tx.Exec(ctx, queryDetalle, cantidad, costoOrigen, cantidadRecibida, detalle.id); err != nil {
+			log.Println("Error al actualizar detalle de transferencia:", err)
+			return "", datatype.NewInternalServerErrorGeneric()
+		}
+	}
+	if totalEnviado == 0 {
+		return "", datatype.NewBadRequestError("El despacho debe enviar al menos un producto.")
+	}
+
+	if err = registrarMovimientosInventario(ctx, tx, domain.DocumentoTransferencia, transferenciaId, &usuarioId, movimientos); err != nil {
+		return "", err
+	}
+
+	estado := domain.TransferenciaCompletada
+	if enTransito {
+		estado = domain.TransferenciaEnTransito
+	}
+	queryEncabezado := `
+        UPDATE transferencia
+        SET estado = $1, despachado_por = $2, despachado_en = NOW(),
+            recibido_por = CASE WHEN $3 THEN $2::int END, recibido_en = CASE WHEN $3 THEN NOW() END
+        WHERE id = $4`
+	if _, err = tx.Exec(ctx, queryEncabezado, estado, usuarioId, !enTransito, transferenciaId); err != nil {
+		log.Println("Error al despachar transferencia:", err)
+		return "", datatype.NewInternalServerErrorGeneric()
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción de transferencia:", err)
+		return "", datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return estado, nil
+}
+
+// RecibirTransferencia ingresa en destino lo despachado y registra como merma lo que no llegó. El envío completo
+// entra al costo de origen y el faltante sale en un ajuste MERMA, así el kardex de destino muestra ambas partes.
+func (i InventarioRepository) RecibirTransferencia(ctx context.Context, transferenciaId int, request *domain.RecepcionTransferenciaRequest, usuarioId int) (*int, error) {
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	transferencia, err := bloquearTransferencia(ctx, tx, transferenciaId)
+	if err != nil {
+		return nil, err
+	}
+	if transferencia.estado != domain.TransferenciaEnTransito {
+		return nil, datatype.NewConflictError(fmt.Sprintf("Solo se recibe una transferencia en tránsito; esta está '%s'.", transferencia.estado))
+	}
+	sucursalOrigenId, sucursalDestinoId, err := obtenerSucursalesTransferencia(ctx, tx, transferencia.ubicacionOrigenId, transferencia.ubicacionDestinoId)
+	if err != nil {
+		return nil, err
+	}
+
+	detalles, err := obtenerDetallesTransferencia(ctx, tx, transferenciaId)
+	if err != nil {
+		return nil, err
+	}
+	recibido := make(map[int]int64, len(request.Detalles))
+	for _, d := range request.Detalles {
+		recibido[d.ProductoId] = d.CantidadRecibida
+	}
+	for productoId := range recibido {
+		if !contieneProductoTransferencia(detalles, productoId) {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("El producto %d no forma parte de la transferencia.", productoId))
+		}
+	}
+
+	lotesEnTransito, err := obtenerLotesTransferencia(ctx, tx, transferenciaId)
+	if err != nil {
+		return nil, err
+	}
+
+	var movimientos []movimientoInventario
+	var mermas []domain.DetalleAjusteInventarioRequest
+	queryDetalle := `UPDATE detalle_transferencia SET cantidad_recibida = $1 WHERE id = $2`
+	for _, detalle := range detalles {
+		cantidadRecibida := detalle.cantidad
+		if v, ok := recibido[detalle.productoId]; ok {
+			cantidadRecibida = v
+		}
+		if cantidadRecibida < 0 || cantidadRecibida > detalle.cantidad {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("La cantidad recibida del producto %d debe estar entre 0 y %d (lo enviado).", detalle.productoId, detalle.cantidad))
+		}
+		if detalle.cantidad > 0 {
+			movimiento, err := ingresarStockTransferencia(ctx, tx, detalle.productoId, transferencia.ubicacionDestinoId, sucursalOrigenId, sucursalDestinoId, int(detalle.cantidad), detalle.costoUnitario)
+			if err != nil {
+				return nil, err
+			}
+			movimientos = append(movimientos, movimiento)
+			if err = sumarLotes(ctx, tx, detalle.productoId, transferencia.ubicacionDestinoId, lotesEnTransito[detalle.productoId]); err != nil {
+				return nil, err
+			}
+		}
+		if faltante := detalle.cantidad - cantidadRecibida; faltante > 0 {
+			mermas = append(mermas, domain.DetalleAjusteInventarioRequest{ProductoId: detalle.productoId, UbicacionId: transferencia.ubicacionDestinoId, Cantidad: -faltante})
+		}
+		if _, err = tx.Exec(ctx, queryDetalle, cantidadRecibida, detalle.id); err != nil {
+			log.Println("Error al actualizar detalle de transferencia:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+	}
+
+	if err = registrarMovimientosInventario(ctx, tx, domain.DocumentoTransferencia, transferenciaId, &usuarioId, movimientos); err != nil {
+		return nil, err
+	}
+
+	var ajusteId *int
+	if len(mermas) > 0 {
+		id, err := registrarAjuste(ctx, tx, &domain.AjusteInventarioRequest{
+			TipoAjuste:   domain.TipoAjusteMerma,
+			Motivo:       fmt.Sprintf("Faltante en la recepción de la transferencia #%d", transferenciaId),
+			SucursalId:   sucursalDestinoId,
+			UsuarioId:    usuarioId,
+			Detalles:     mermas,
+			Autorizacion: request.Autorizacion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ajusteId = &id
+	}
+
+	queryEncabezado := `
+        UPDATE transferencia SET estado = $1, recibido_por = $2, recibido_en = NOW(), ajuste_inventario_id = $3
+        WHERE id = $4`
+	if _, err = tx.Exec(ctx, queryEncabezado, domain.TransferenciaRecibida, usuarioId, ajusteId, transferenciaId); err != nil {
+		log.Println("Error al recibir transferencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción de transferencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return ajusteId, nil
+}
+
+type transferenciaBloqueada struct {
+	estado             string
+	ubicacionOrigenId  int
+	ubicacionDestinoId int
+}
+
+type detalleTransferenciaPendiente struct {
+	id            int
+	productoId    int
+	cantidad      int64
+	costoUnitario *float64
+}
+
+func bloquearTransferencia(ctx context.Context, tx pgx.Tx, transferenciaId int) (*transferenciaBloqueada, error) {
+	var t transferenciaBloqueada
+	query := `SELECT estado, ubicacion_origen_id, ubicacion_destino_id FROM transferencia WHERE id = $1 FOR UPDATE`
+	err := tx.QueryRow(ctx, query, transferenciaId).Scan(&t.estado, &t.ubicacionOrigenId, &t.ubicacionDestinoId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("La transferencia no existe.")
+		}
+		log.Println("Error al obtener transferencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &t, nil
+}
+
+func obtenerDetallesTransferencia(ctx context.Context, tx pgx.Tx, transferenciaId int) ([]detalleTransferenciaPendiente, error) {
+	rows, err := tx.Query(ctx, `SELECT id, producto_id, cantidad, costo_unitario FROM detalle_transferencia WHERE transferencia_id = $1 ORDER BY id`, transferenciaId)
+	if err != nil {
+		log.Println("Error al obtener detalles de transferencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	var detalles []detalleTransferenciaPendiente
+	for rows.Next() {
+		var d detalleTransferenciaPendiente
+		if err := rows.Scan(&d.id, &d.productoId, &d.cantidad, &d.costoUnitario); err != nil {
+			log.Println("Error al escanear detalle de transferencia:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		detalles = append(detalles, d)
+	}
+	if err = rows.Err(); err != nil {
+		log.Println("Error al obtener detalles de transferencia:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return detalles, nil
+}
+
+// registrarLotesTransferencia guarda los lotes que viajan para ingresarlos en destino al recibir
+func registrarLotesTransferencia(ctx context.Context, tx pgx.Tx, transferenciaId, productoId int, lotes []consumoLote) error {
+	query := `
+        INSERT INTO detalle_transferencia_lote (transferencia_id, producto_id, lote, fecha_vencimiento, cantidad)
+        VALUES ($1, $2, $3, $4, $5)`
+	for _, l := range lotes {
+		if _, err := tx.Exec(ctx, query, transferenciaId, productoId, l.Lote, l.FechaVencimiento, l.Cantidad); err != nil {
+			log.Println("Error al registrar lote en tránsito:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+	return nil
+}
+
+func obtenerLotesTransferencia(ctx context.Context, tx pgx.Tx, transferenciaId int) (map[int][]consumoLote, error) {
+	query := `SELECT producto_id, lote, fecha_vencimiento, cantidad FROM detalle_transferencia_lote WHERE transferencia_id = $1 ORDER BY id`
+	rows, err := tx.Query(ctx, query, transferenciaId)
+	if err != nil {
+		log.Println("Error al obtener lotes en tránsito:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	lotes := make(map[int][]consumoLote)
+	for rows.Next() {
+		var productoId int
+		var l consumoLote
+		if err := rows.Scan(&productoId, &l.Lote, &l.FechaVencimiento, &l.Cantidad); err != nil {
+			log.Println("Error al escanear lote en tránsito:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		lotes[productoId] = append(lotes[productoId], l)
+	}
+	if err = rows.Err(); err != nil {
+		log.Println("Error al obtener lotes en tránsito:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return lotes, nil
+}
+
+func contieneProductoTransferencia(detalles []detalleTransferenciaPendiente, productoId int) bool {
+	for _, d := range detalles {
+		if d.productoId == productoId {
+			return true
+		}
+	}
+	return false
+}
+
+// obtenerSucursalesTransferencia valida las ubicaciones y devuelve la sucursal de cada una
+func obtenerSucursalesTransferencia(ctx context.Context, tx pgx.Tx, ubicacionOrigenId, ubicacionDestinoId int) (int, int, error) {
+	var sucursalOrigenId, sucursalDestinoId *int
+	query := `SELECT (SELECT sucursal_id FROM ubicacion WHERE id = $1), (SELECT sucursal_id FROM ubicacion WHERE id = $2)`
+	if err := tx.QueryRow(ctx, query, ubicacionOrigenId, ubicacionDestinoId).Scan(&sucursalOrigenId, &sucursalDestinoId); err != nil {
+		log.Println("Error al obtener sucursales de la transferencia:", err)
+		return 0, 0, datatype.NewInternalServerErrorGeneric()
+	}
+	if sucursalOrigenId == nil {
+		return 0, 0, datatype.NewBadRequestError("La ubicación de origen seleccionada no existe.")
+	}
+	if sucursalDestinoId == nil {
+		return 0, 0, datatype.NewBadRequestError("La ubicación de destino seleccionada no existe.")
+	}
+	return *sucursalOrigenId, *sucursalDestinoId, nil
+}
+
+// descontarStockTransferencia resta del origen verificando el stock disponible y devuelve el saldo junto con los
+// lotes que salieron, para ingresarlos igual en destino.
+func descontarStockTransferencia(ctx context.Context, tx pgx.Tx, productoId, ubicacionId int, cantidad int64) (int, []consumoLote, error) {
+	var stockOrigen int64
+	err := tx.QueryRow(ctx, `SELECT stock FROM inventario WHERE producto_id = $1 AND ubicacion_id = $2 FOR UPDATE`, productoId, ubicacionId).Scan(&stockOrigen)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, datatype.NewBadRequestError(fmt.Sprintf("No hay stock del producto %d en la ubicación de origen.", productoId))
+		}
+		log.Println("Error al consultar stock de origen:", err)
+		return 0, nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if stockOrigen < cantidad {
+		return 0, nil, datatype.NewBadRequestError(fmt.Sprintf("Stock insuficiente en Origen para producto %d. Actual: %d, Se intenta transferir: %d", productoId, stockOrigen, cantidad))
+	}
+
+	var saldo int
+	err = tx.QueryRow(ctx, `UPDATE inventario SET stock = stock - $1 WHERE producto_id = $2 AND ubicacion_id = $3 RETURNING stock`, cantidad, productoId, ubicacionId).Scan(&saldo)
+	if err != nil {
+		log.Println("Error al restar stock de origen:", err)
+		return 0, nil, datatype.NewInternalServerErrorGeneric()
+	}
+	lotes, err := descontarLotes(ctx, tx, productoId, ubicacionId, int(cantidad), saldo, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	return saldo, lotes, nil
+}
+
+// ingresarStockTransferencia suma en destino; entre sucursales el stock llega con el costo promedio de origen y
+// recalcula el de destino.
+func ingresarStockTransferencia(ctx context.Context, tx pgx.Tx, productoId, ubicacionId, sucursalOrigenId, sucursalDestinoId, cantidad int, costo *float64) (movimientoInventario, error) {
+	if sucursalOrigenId != sucursalDestinoId && costo != nil {
+		if err := actualizarCostoPromedio(ctx, tx, productoId, sucursalDestinoId, cantidad, *costo); err != nil {
+			return movimientoInventario{}, err
+		}
+	}
+	saldo, err := sumarStock(ctx, tx, productoId, ubicacionId, cantidad)
+	if err != nil {
+		log.Println("Error al sumar stock a destino (UPSERT):", err)
+		return movimientoInventario{}, datatype.NewInternalServerErrorGeneric()
+	}
+	return movimientoInventario{ProductoId: productoId, UbicacionId: ubicacionId, Cantidad: cantidad, Saldo: saldo, CostoUnitario: costo}, nil
+}
+
+func estadoTransferenciaInvalido(ctx context.Context, pool *pgxpool.Pool, transferenciaId int, accion string) error {
+	var estado string
+	err := pool.QueryRow(ctx, `SELECT estado FROM transferencia WHERE id = $1`, transferenciaId).Scan(&estado)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datatype.NewNotFoundError("La transferencia no existe.")
+		}
+		log.Println("Error al obtener transferencia:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return datatype.NewConflictError(fmt.Sprintf("La transferencia está '%s' y no se puede %s.", estado, accion))
+}
+
 func (i InventarioRepository) RegistrarAjusteConDetalle(ctx context.Context, request *domain.AjusteInventarioRequest) (*int, error) {
 
 	// Iniciar transacción
@@ -471,6 +1096,60 @@ func (i InventarioRepository) RegistrarAjusteConDetalle(ctx context.Context, req
 		}
 	}()
 
+	ajusteId, err := registrarAjuste(ctx, tx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 6. Commit de la transacción
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+
+	return &ajusteId, nil
+}
+
+// registrarAjuste aplica el ajuste y sus movimientos dentro de la transacción recibida; lo usan el ajuste manual,
+// la contabilización de un conteo, la merma de una transferencia y la baja de lotes vencidos. Las reglas del
+// tipo_ajuste (estado, motivo, dirección y aprobación) se validan aquí para que ningún camino las saltee.
+func registrarAjuste(ctx context.Context, tx pgx.Tx, request *domain.AjusteInventarioRequest) (int, error) {
+	var estado, direccion string
+	var requiereMotivo, requiereAprobacion bool
+	queryTipo := `SELECT estado, direccion, requiere_motivo, requiere_aprobacion FROM tipo_ajuste WHERE codigo = $1`
+	err := tx.QueryRow(ctx, queryTipo, request.TipoAjuste).Scan(&estado, &direccion, &requiereMotivo, &requiereAprobacion)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, datatype.NewBadRequestError(fmt.Sprintf("El tipo de ajuste '%s' no es válido.", request.TipoAjuste))
+		}
+		log.Println("Error al obtener tipo de ajuste:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	if estado != "Activo" {
+		return 0, datatype.NewBadRequestError(fmt.Sprintf("El tipo de ajuste '%s' está deshabilitado.", request.TipoAjuste))
+	}
+	if requiereMotivo && strings.TrimSpace(request.Motivo) == "" {
+		return 0, datatype.NewBadRequestError(fmt.Sprintf("El tipo de ajuste '%s' requiere indicar el motivo.", request.TipoAjuste))
+	}
+	for _, detalle := range request.Detalles {
+		if (direccion == domain.DireccionAjusteSalida && detalle.Cantidad > 0) || (direccion == domain.DireccionAjusteEntrada && detalle.Cantidad < 0) {
+			return 0, datatype.NewBadRequestError(fmt.Sprintf("El tipo de ajuste '%s' no admite la cantidad %d (producto %d).",
+				request.TipoAjuste, detalle.Cantidad, detalle.ProductoId))
+		}
+	}
+
+	// Los tipos que requieren aprobación consumen la autorización del supervisor en la misma transacción
+	var autorizacion *autorizacionSupervisor
+	if requiereAprobacion {
+		autorizacion, err = validarAutorizacion(ctx, tx, request.SucursalId, request.Autorizacion,
+			fmt.Sprintf("El tipo de ajuste '%s' requiere la autorización de un supervisor.", request.TipoAjuste))
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// VALIDACIÓN N+1: Verificar que todas las ubicaciones pertenezcan a la sucursal
 	var ubicacionIds []int
 	for _, detalle := range request.Detalles {
@@ -478,7 +1157,7 @@ func (i InventarioRepository) RegistrarAjusteConDetalle(ctx context.Context, req
 
 		if detalle.Cantidad == 0 {
 			// La BD ya lo valida (CHECK <> 0), pero fallar aquí es más rápido.
-			return nil, datatype.NewBadRequestError("La cantidad a ajustar no puede ser cero.")
+			return 0, datatype.NewBadRequestError("La cantidad a ajustar no puede ser cero.")
 		}
 	}
 
@@ -488,46 +1167,46 @@ func (i InventarioRepository) RegistrarAjusteConDetalle(ctx context.Context, req
 	err = tx.QueryRow(ctx, queryValidarUbi, request.SucursalId, ubicacionIds).Scan(&countUbicaciones)
 	if err != nil {
 		log.Println("Error al validar ubicaciones en lote:", err)
-		return nil, datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
 	}
 
 	if countUbicaciones != len(ubicacionIds) {
-		return nil, datatype.NewBadRequestError("Una o más ubicaciones no son válidas o no pertenecen a la sucursal del ajuste.")
+		return 0, datatype.NewBadRequestError("Una o más ubicaciones no son válidas o no pertenecen a la sucursal del ajuste.")
 	}
 
 	// INSERTAR ENCABEZADO (ajuste_inventario)
 	var ajusteId int
 	queryEncabezado := `
-        INSERT INTO ajuste_inventario (usuario_id, sucursal_id, motivo, tipo_ajuste, fecha) 
-        VALUES($1, $2, $3, $4, NOW()) 
+        INSERT INTO ajuste_inventario (usuario_id, sucursal_id, motivo, tipo_ajuste, fecha, autorizado_por, metodo_autorizacion) 
+        VALUES($1, $2, $3, $4, NOW(), $5, $6) 
         RETURNING id`
 
-	err = tx.QueryRow(ctx, queryEncabezado, request.UsuarioId, request.SucursalId, request.Motivo, request.TipoAjuste).Scan(&ajusteId)
+	var autorizadoPor *int
+	var metodoAutorizacion *string
+	if autorizacion != nil {
+		autorizadoPor, metodoAutorizacion = &autorizacion.SupervisorId, &autorizacion.Metodo
+	}
+	err = tx.QueryRow(ctx, queryEncabezado, request.UsuarioId, request.SucursalId, request.Motivo, request.TipoAjuste,
+		autorizadoPor, metodoAutorizacion).Scan(&ajusteId)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
-			return nil, datatype.NewBadRequestError(fmt.Sprintf("Error de referencia en el encabezado: %s", pgErr.ConstraintName))
+			return 0, datatype.NewBadRequestError(fmt.Sprintf("Error de referencia en el encabezado: %s", pgErr.ConstraintName))
 		}
 		log.Println("Error al insertar encabezado de ajuste:", err)
-		return nil, datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
 	}
 
 	// BUCLE: Aplicar Ajustes de Stock y Recolectar para CopyFrom
 	var rowsDetalle [][]interface{}
-
-	querySumaInventario := `
-       INSERT INTO inventario (producto_id, ubicacion_id, stock)
-       VALUES ($1, $2, $3)
-       ON CONFLICT (producto_id, ubicacion_id) 
-       DO UPDATE SET
-          stock = inventario.stock + EXCLUDED.stock;
-    `
+	var movimientos []movimientoInventario
 
 	queryRestaInventario := `
         UPDATE inventario 
         SET stock = stock - $1 
         WHERE producto_id = $2 AND ubicacion_id = $3
+        RETURNING stock
     `
 	// Query para la validación de CARGA_INICIAL
 	queryCheckExist := `SELECT 1 FROM inventario WHERE producto_id = $1 AND ubicacion_id = $2`
@@ -541,7 +1220,7 @@ func (i InventarioRepository) RegistrarAjusteConDetalle(ctx context.Context, req
 
 			if errStock == nil {
 				// Lo encontró (incluso con stock 0), rechazamos.
-				return nil, datatype.NewBadRequestError(fmt.Sprintf(
+				return 0, datatype.NewBadRequestError(fmt.Sprintf(
 					"CARGA_INICIAL fallida: El producto %d en la ubicación %d ya existe en el inventario.",
 					detalle.ProductoId, detalle.UbicacionId,
 				))
@@ -549,40 +1228,77 @@ func (i InventarioRepository) RegistrarAjusteConDetalle(ctx context.Context, req
 			if !errors.Is(errStock, pgx.ErrNoRows) {
 				// Error de BD real
 				log.Println("Error al verificar stock para CARGA_INICIAL:", errStock)
-				return nil, datatype.NewInternalServerErrorGeneric()
+				return 0, datatype.NewInternalServerErrorGeneric()
 			}
 			// Si es pgx.ErrNoRows, continúa (es el caso OK)
 		}
 
 		// APLICAR AJUSTE (Lógica Separada)
+		var saldo int
 		if detalle.Cantidad > 0 {
 			// --- LÓGICA DE SUMA (Entrada) ---
-			_, err = tx.Exec(ctx, querySumaInventario, detalle.ProductoId, detalle.UbicacionId, detalle.Cantidad)
+			// Una entrada con costo recalcula el promedio; sin costo entra al promedio vigente
+			if detalle.CostoUnitario != nil {
+				if *detalle.CostoUnitario < 0 {
+					return 0, datatype.NewBadRequestError("El costo unitario no puede ser negativo.")
+				}
+				if err = actualizarCostoPromedio(ctx, tx, detalle.ProductoId, request.SucursalId, int(detalle.Cantidad), *detalle.CostoUnitario); err != nil {
+					return 0, err
+				}
+			}
+			saldo, err = sumarStock(ctx, tx, detalle.ProductoId, detalle.UbicacionId, int(detalle.Cantidad))
 			if err != nil {
 				log.Println("Error al sumar stock (UPSERT):", err)
-				return nil, datatype.NewInternalServerErrorGeneric()
+				return 0, datatype.NewInternalServerErrorGeneric()
+			}
+			fechaVencimiento, err := fechaVencimientoLote(detalle.FechaVencimiento)
+			if err != nil {
+				return 0, err
+			}
+			if fechaVencimiento != nil {
+				var lote string
+				if detalle.Lote != nil {
+					lote = *detalle.Lote
+				}
+				if err = sumarLote(ctx, tx, detalle.ProductoId, detalle.UbicacionId, lote, *fechaVencimiento, int(detalle.Cantidad)); err != nil {
+					return 0, err
+				}
 			}
 
 		} else if detalle.Cantidad < 0 {
 			cantidadARestar := -detalle.Cantidad
 
-			ct, err := tx.Exec(ctx, queryRestaInventario, cantidadARestar, detalle.ProductoId, detalle.UbicacionId)
+			err := tx.QueryRow(ctx, queryRestaInventario, cantidadARestar, detalle.ProductoId, detalle.UbicacionId).Scan(&saldo)
 
 			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					// No se puede restar de un producto/ubicación que no existe en el inventario
+					return 0, datatype.NewBadRequestError(fmt.Sprintf("No se puede restar stock: El producto %d no existe en la ubicación %d.", detalle.ProductoId, detalle.UbicacionId))
+				}
 				var pgErr *pgconn.PgError
 				if errors.As(err, &pgErr) && pgErr.Code == "23514" {
 					// Atrapa el CHECK (stock >= 0)
-					return nil, datatype.NewBadRequestError(fmt.Sprintf("Stock insuficiente para el producto %d en la ubicación %d.", detalle.ProductoId, detalle.UbicacionId))
+					return 0, datatype.NewBadRequestError(fmt.Sprintf("Stock insuficiente para el producto %d en la ubicación %d.", detalle.ProductoId, detalle.UbicacionId))
 				}
 				log.Println("Error al restar stock (UPDATE):", err)
-				return nil, datatype.NewInternalServerErrorGeneric()
+				return 0, datatype.NewInternalServerErrorGeneric()
 			}
-
-			if ct.RowsAffected() == 0 {
-				// No se puede restar de un producto/ubicación que no existe en el inventario
-				return nil, datatype.NewBadRequestError(fmt.Sprintf("No se puede restar stock: El producto %d no existe en la ubicación %d.", detalle.ProductoId, detalle.UbicacionId))
+			if detalle.LoteId != nil {
+				err = descontarLote(ctx, tx, *detalle.LoteId, detalle.ProductoId, detalle.UbicacionId, int(cantidadARestar))
+			} else {
+				// Las bajas por vencimiento o merma retiran primero lo vencido
+				vencidosPrimero := request.TipoAjuste == domain.TipoAjusteVencimiento || request.TipoAjuste == domain.TipoAjusteMerma
+				_, err = descontarLotes(ctx, tx, detalle.ProductoId, detalle.UbicacionId, int(cantidadARestar), saldo, vencidosPrimero)
+			}
+			if err != nil {
+				return 0, err
 			}
 		}
+		movimiento := movimientoInventario{ProductoId: detalle.ProductoId, UbicacionId: detalle.UbicacionId, Cantidad: int(detalle.Cantidad), Saldo: saldo}
+		if detalle.Cantidad > 0 {
+			movimiento.CostoUnitario = detalle.CostoUnitario
+		}
+		movimientos = append(movimientos, movimiento)
 
 		// Recolectar para el INSERT masivo de detalles
 		rowsDetalle = append(rowsDetalle, []interface{}{
@@ -590,11 +1306,12 @@ func (i InventarioRepository) RegistrarAjusteConDetalle(ctx context.Context, req
 			detalle.ProductoId,
 			detalle.UbicacionId,
 			detalle.Cantidad,
+			detalle.LoteId,
 		})
 	}
 
 	// INSERTAR DETALLES (Usando CopyFrom)
-	columnasDetalle := []string{"ajuste_inventario_id", "producto_id", "ubicacion_id", "cantidad"}
+	columnasDetalle := []string{"ajuste_inventario_id", "producto_id", "ubicacion_id", "cantidad", "inventario_lote_id"}
 
 	_, err = tx.CopyFrom(
 		ctx,
@@ -604,18 +1321,13 @@ func (i InventarioRepository) RegistrarAjusteConDetalle(ctx context.Context, req
 	)
 	if err != nil {
 		log.Println("Error durante la inserción masiva de detalles de ajuste:", err)
-		return nil, datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
 	}
 
-	// 6. Commit de la transacción
-	err = tx.Commit(ctx)
-	if err != nil {
-		log.Println("Error al confirmar transacción:", err)
-		return nil, datatype.NewInternalServerErrorGeneric()
+	if err = registrarMovimientosInventario(ctx, tx, domain.DocumentoAjuste, ajusteId, &request.UsuarioId, movimientos); err != nil {
+		return 0, err
 	}
-	committed = true
-
-	return &ajusteId, nil
+	return ajusteId, nil
 }
 
 func (i InventarioRepository) ListarInventario(ctx context.Context, filtros map[string]string) (*[]domain.Inventario, error) {
@@ -734,3 +1446,384 @@ func NewInventarioRepository(pool *pgxpool.Pool) *InventarioRepository {
 }
 
 var _ port.InventarioRepository = (*InventarioRepository)(nil)
+
+func (i InventarioRepository) ObtenerKardex(ctx context.Context, filtros map[string]string) (*domain.Kardex, error) {
+	var kardex domain.Kardex
+	var err error
+
+	kardex.ProductoId, err = strconv.Atoi(filtros["productoId"])
+	if err != nil || kardex.ProductoId <= 0 {
+		return nil, datatype.NewBadRequestError("El productoId es requerido")
+	}
+	for _, filtro := range []struct {
+		clave   string
+		destino **int
+	}{
+		{"sucursalId", &kardex.SucursalId},
+		{"ubicacionId", &kardex.UbicacionId},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			*filtro.destino = &id
+		}
+	}
+	if kardex.SucursalId == nil && kardex.UbicacionId == nil {
+		return nil, datatype.NewBadRequestError("Indique sucursalId o ubicacionId")
+	}
+	if val := filtros["fechaInicio"]; val != "" {
+		kardex.FechaInicio = &val
+	}
+	if val := filtros["fechaFin"]; val != "" {
+		kardex.FechaFin = &val
+	}
+
+	err = i.pool.QueryRow(ctx, `SELECT nombre FROM producto WHERE id = $1`, kardex.ProductoId).Scan(&kardex.Producto)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Producto no encontrado")
+		}
+		log.Println("Error al obtener producto del kardex:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	// El saldo inicial se reconstruye desde el stock actual restando lo movido desde fechaInicio, así el stock
+	// anterior a la existencia del kardex también queda explicado.
+	querySaldo := `
+        SELECT
+            (SELECT COALESCE(SUM(i.stock), 0)
+             FROM inventario i
+             JOIN ubicacion u ON i.ubicacion_id = u.id
+             WHERE i.producto_id = $1 AND ($2::int IS NULL OR u.sucursal_id = $2) AND ($3::int IS NULL OR i.ubicacion_id = $3)),
+            (SELECT COALESCE(SUM(m.cantidad), 0)
+             FROM movimiento_inventario m
+             JOIN ubicacion u ON m.ubicacion_id = u.id
+             WHERE m.producto_id = $1 AND ($2::int IS NULL OR u.sucursal_id = $2) AND ($3::int IS NULL OR m.ubicacion_id = $3)
+               AND ($4::date IS NULL OR m.creado_en >= $4::date))`
+	var stockActual, movidoDesdeInicio int
+	err = i.pool.QueryRow(ctx, querySaldo, kardex.ProductoId, kardex.SucursalId, kardex.UbicacionId, kardex.FechaInicio).Scan(&stockActual, &movidoDesdeInicio)
+	if err != nil {
+		log.Println("Error al calcular saldo inicial del kardex:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	kardex.SaldoInicial = stockActual - movidoDesdeInicio
+
+	query := `
+        SELECT m.id, m.creado_en, m.tipo_documento, m.documento_id, m.ubicacion_id, u.nombre, m.cantidad, m.saldo, m.costo_unitario,
+               CASE WHEN ua.id IS NOT NULL THEN json_build_object('id', ua.id, 'username', ua.username) END
+        FROM movimiento_inventario m
+        JOIN ubicacion u ON m.ubicacion_id = u.id
+        LEFT JOIN usuario_admin ua ON m.usuario_id = ua.id
+        WHERE m.producto_id = $1 AND ($2::int IS NULL OR u.sucursal_id = $2) AND ($3::int IS NULL OR m.ubicacion_id = $3)
+          AND ($4::date IS NULL OR m.creado_en >= $4::date)
+          AND ($5::date IS NULL OR m.creado_en < $5::date + 1)
+        ORDER BY m.id`
+	rows, err := i.pool.Query(ctx, query, kardex.ProductoId, kardex.SucursalId, kardex.UbicacionId, kardex.FechaInicio, kardex.FechaFin)
+	if err != nil {
+		log.Println("Error al listar movimientos de inventario:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+
+	saldo := kardex.SaldoInicial
+	kardex.Movimientos = make([]domain.MovimientoInventario, 0)
+	for rows.Next() {
+		var item domain.MovimientoInventario
+		err := rows.Scan(&item.Id, &item.Fecha, &item.TipoDocumento, &item.DocumentoId, &item.UbicacionId, &item.Ubicacion, &item.Cantidad,
+			&item.SaldoUbicacion, &item.CostoUnitario, &item.Usuario)
+		if err != nil {
+			log.Println("Error al escanear movimiento de inventario:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		saldo += item.Cantidad
+		item.Saldo = saldo
+		if item.Cantidad > 0 {
+			kardex.Entradas += item.Cantidad
+		} else {
+			kardex.Salidas -= item.Cantidad
+		}
+		kardex.Movimientos = append(kardex.Movimientos, item)
+	}
+	kardex.SaldoFinal = saldo
+	return &kardex, nil
+}
+
+// movimientoInventario es una entrada del kardex pendiente de guardar; Saldo es el stock de la ubicación tras aplicarla.
+type movimientoInventario struct {
+	ProductoId    int
+	UbicacionId   int
+	Cantidad      int
+	Saldo         int
+	CostoUnitario *float64
+}
+
+// sumarStock aplica una cantidad con signo al stock de la ubicación y devuelve el saldo resultante.
+func sumarStock(ctx context.Context, tx pgx.Tx, productoId, ubicacionId, cantidad int) (int, error) {
+	query := `
+       INSERT INTO inventario (producto_id, ubicacion_id, stock)
+       VALUES ($1, $2, $3)
+       ON CONFLICT (producto_id, ubicacion_id)
+       DO UPDATE SET stock = inventario.stock + EXCLUDED.stock
+       RETURNING stock`
+	var saldo int
+	if err := tx.QueryRow(ctx, query, productoId, ubicacionId, cantidad).Scan(&saldo); err != nil {
+		return 0, err
+	}
+	return saldo, nil
+}
+
+// actualizarCostoPromedio recalcula el costo promedio ponderado del producto en la sucursal por el ingreso de
+// cantidad unidades a costoUnitario. Se llama antes de sumar el stock; sin stock previo el costo es el del ingreso.
+func actualizarCostoPromedio(ctx context.Context, tx pgx.Tx, productoId, sucursalId, cantidad int, costoUnitario float64) error {
+	if cantidad <= 0 {
+		return nil
+	}
+	var costoActual *float64
+	err := tx.QueryRow(ctx, `SELECT costo_promedio FROM costo_producto WHERE producto_id = $1 AND sucursal_id = $2 FOR UPDATE`,
+		productoId, sucursalId).Scan(&costoActual)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Println("Error al obtener costo promedio:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	var stockActual int
+	queryStock := `
+        SELECT COALESCE(SUM(i.stock), 0)
+        FROM inventario i
+        JOIN ubicacion u ON i.ubicacion_id = u.id
+        WHERE i.producto_id = $1 AND u.sucursal_id = $2`
+	if err = tx.QueryRow(ctx, queryStock, productoId, sucursalId).Scan(&stockActual); err != nil {
+		log.Println("Error al obtener stock para costo promedio:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	costoNuevo := costoUnitario
+	if costoActual != nil && stockActual > 0 {
+		costoNuevo = (float64(stockActual)**costoActual + float64(cantidad)*costoUnitario) / float64(stockActual+cantidad)
+	}
+	queryUpsert := `
+        INSERT INTO costo_producto (producto_id, sucursal_id, costo_promedio, actualizado_en)
+        VALUES ($1, $2, ROUND($3::numeric, 4), NOW())
+        ON CONFLICT (producto_id, sucursal_id) DO UPDATE SET costo_promedio = EXCLUDED.costo_promedio, actualizado_en = NOW()`
+	if _, err = tx.Exec(ctx, queryUpsert, productoId, sucursalId, costoNuevo); err != nil {
+		log.Println("Error al guardar costo promedio:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// obtenerCostoPromedio devuelve el costo promedio del producto en la sucursal o nil si aún no tiene.
+func obtenerCostoPromedio(ctx context.Context, tx pgx.Tx, productoId, sucursalId int) (*float64, error) {
+	var costo float64
+	err := tx.QueryRow(ctx, `SELECT costo_promedio FROM costo_producto WHERE producto_id = $1 AND sucursal_id = $2`, productoId, sucursalId).Scan(&costo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		log.Println("Error al obtener costo promedio:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &costo, nil
+}
+
+// registrarMovimientosInventario agrega al kardex los movimientos de un documento en la misma transacción que
+// cambió el stock. Sin costo explícito se usa el costo promedio del producto en la sucursal.
+func registrarMovimientosInventario(ctx context.Context, tx pgx.Tx, tipoDocumento string, documentoId int, usuarioId *int, movimientos []movimientoInventario) error {
+	query := `
+        INSERT INTO movimiento_inventario (producto_id, ubicacion_id, tipo_documento, documento_id, cantidad, saldo, costo_unitario, usuario_id, creado_en)
+        VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, (
+            SELECT cp.costo_promedio
+            FROM costo_producto cp
+            WHERE cp.producto_id = $1 AND cp.sucursal_id = (SELECT sucursal_id FROM ubicacion WHERE id = $2)
+        )), $8, NOW())`
+	for _, m := range movimientos {
+		if m.Cantidad == 0 {
+			continue
+		}
+		_, err := tx.Exec(ctx, query, m.ProductoId, m.UbicacionId, tipoDocumento, documentoId, m.Cantidad, m.Saldo, m.CostoUnitario, usuarioId)
+		if err != nil {
+			log.Println("Error al registrar movimiento de inventario:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+	return registrarAlertasStock(ctx, tx, tipoDocumento, documentoId, movimientos)
+}
+
+// registrarAlertasStock deja pendiente de publicar una alerta por cada producto cuyo stock en la sucursal cruzó
+// hacia abajo el punto de reorden o el mínimo con este documento. Un movimiento entre ubicaciones de la misma
+// sucursal no cambia el stock de la sucursal y no genera alertas.
+func registrarAlertasStock(ctx context.Context, tx pgx.Tx, tipoDocumento string, documentoId int, movimientos []movimientoInventario) error {
+	var productoIds, ubicacionIds, cantidades []int
+	for _, m := range movimientos {
+		if m.Cantidad < 0 {
+			productoIds = append(productoIds, m.ProductoId)
+			ubicacionIds = append(ubicacionIds, m.UbicacionId)
+			cantidades = append(cantidades, m.Cantidad)
+		}
+	}
+	if len(productoIds) == 0 {
+		return nil
+	}
+	for _, m := range movimientos {
+		if m.Cantidad > 0 {
+			productoIds = append(productoIds, m.ProductoId)
+			ubicacionIds = append(ubicacionIds, m.UbicacionId)
+			cantidades = append(cantidades, m.Cantidad)
+		}
+	}
+
+	query := `
+        WITH cambio AS (
+            SELECT m.producto_id, u.sucursal_id, SUM(m.cantidad) AS neto
+            FROM unnest($1::int[], $2::int[], $3::int[]) AS m(producto_id, ubicacion_id, cantidad)
+            JOIN ubicacion u ON u.id = m.ubicacion_id
+            GROUP BY m.producto_id, u.sucursal_id
+            HAVING SUM(m.cantidad) < 0
+        ), nivel AS (
+            SELECT c.producto_id, c.sucursal_id, ps.stock_minimo, ps.punto_reorden, ps.stock_maximo,
+                   st.stock, st.stock - c.neto AS stock_anterior
+            FROM cambio c
+            JOIN producto_sucursal ps ON ps.producto_id = c.producto_id AND ps.sucursal_id = c.sucursal_id
+            CROSS JOIN LATERAL (
+                SELECT COALESCE(SUM(i.stock), 0)::int AS stock
+                FROM inventario i
+                JOIN ubicacion u ON i.ubicacion_id = u.id
+                WHERE i.producto_id = c.producto_id AND u.sucursal_id = c.sucursal_id
+            ) st
+            WHERE ps.stock_minimo IS NOT NULL OR ps.punto_reorden IS NOT NULL
+        )
+        INSERT INTO alerta_stock (producto_id, sucursal_id, tipo, stock, stock_minimo, punto_reorden, stock_maximo, tipo_documento, documento_id, creado_en)
+        SELECT producto_id, sucursal_id,
+               CASE WHEN stock < stock_minimo AND stock_anterior >= stock_minimo THEN $4 ELSE $5 END,
+               stock, stock_minimo, punto_reorden, stock_maximo, $6, $7, NOW()
+        FROM nivel
+        WHERE (stock < stock_minimo AND stock_anterior >= stock_minimo)
+           OR (stock <= punto_reorden AND stock_anterior > punto_reorden)`
+	_, err := tx.Exec(ctx, query, productoIds, ubicacionIds, cantidades, domain.AlertaStockBajoMinimo, domain.AlertaStockPuntoReorden,
+		tipoDocumento, documentoId)
+	if err != nil {
+		log.Println("Error al registrar alertas de stock:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func (i InventarioRepository) ListarAlertasStockPendientes(ctx context.Context, limite int) ([]domain.AlertaStock, error) {
+	query := `
+        SELECT a.id, a.tipo, a.producto_id, p.nombre, a.sucursal_id, a.stock, a.stock_minimo, a.punto_reorden, a.stock_maximo,
+               a.tipo_documento, a.documento_id, a.creado_en
+        FROM alerta_stock a
+        JOIN producto p ON a.producto_id = p.id
+        WHERE a.publicado_en IS NULL
+        ORDER BY a.id
+        LIMIT $1`
+	rows, err := i.pool.Query(ctx, query, limite)
+	if err != nil {
+		log.Println("Error al listar alertas de stock pendientes:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	var list []domain.AlertaStock
+	for rows.Next() {
+		var item domain.AlertaStock
+		err := rows.Scan(&item.Id, &item.Tipo, &item.ProductoId, &item.Producto, &item.SucursalId, &item.Stock, &item.StockMinimo,
+			&item.PuntoReorden, &item.StockMaximo, &item.TipoDocumento, &item.DocumentoId, &item.CreadoEn)
+		if err != nil {
+			log.Println("Error al escanear alerta de stock:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+func (i InventarioRepository) MarcarAlertaStockPublicada(ctx context.Context, id int) error {
+	_, err := i.pool.Exec(ctx, `UPDATE alerta_stock SET publicado_en = NOW() WHERE id = $1`, id)
+	if err != nil {
+		log.Println("Error al marcar alerta de stock publicada:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func (i InventarioRepository) ListarProductosStockBajo(ctx context.Context, filtros map[string]string) (*[]domain.ProductoStockBajo, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	var filters []string
+	var args = []interface{}{fullHostname}
+	var j = 2
+	for _, filtro := range []struct{ clave, columna string }{
+		{"sucursalId", "ps.sucursal_id"},
+		{"categoriaId", "p.categoria_id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+	// Por defecto se listan los productos bajo su mínimo; nivel=reorden agrega los que llegaron al punto de reorden
+	switch filtros["nivel"] {
+	case "", "minimo":
+		filters = append(filters, "st.stock < ps.stock_minimo")
+	case "reorden":
+		filters = append(filters, "(st.stock < ps.stock_minimo OR st.stock <= ps.punto_reorden)")
+	default:
+		return nil, datatype.NewBadRequestError("El nivel debe ser 'minimo' o 'reorden'.")
+	}
+
+	query := `
+        SELECT ps.id,
+               json_build_object(
+                  'id', p.id,
+                  'nombre', p.nombre,
+                  'estado', p.estado,
+                  'urlFoto', ($1::text || p.id::text || '/' || p.foto),
+                  'esInventariable', p.es_inventariable,
+                  'creadoEn', p.creado_en,
+                  'actualizadoEn', p.actualizado_en,
+                  'eliminadoEn', p.eliminado_en
+               ),
+               s.id, s.nombre, st.stock, ps.stock_minimo, ps.punto_reorden, ps.stock_maximo,
+               GREATEST(COALESCE(ps.stock_maximo, ps.stock_minimo, ps.punto_reorden) - st.stock, 0)
+        FROM producto_sucursal ps
+        JOIN producto p ON ps.producto_id = p.id
+        JOIN sucursal s ON ps.sucursal_id = s.id
+        CROSS JOIN LATERAL (
+            SELECT COALESCE(SUM(i.stock), 0)::int AS stock
+            FROM inventario i
+            JOIN ubicacion u ON i.ubicacion_id = u.id
+            WHERE i.producto_id = ps.producto_id AND u.sucursal_id = ps.sucursal_id
+        ) st
+        WHERE ps.estado = 'Activo' AND p.estado = 'Activo' AND p.es_inventariable`
+	if len(filters) > 0 {
+		query += " AND " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY s.nombre, st.stock - COALESCE(ps.stock_minimo, ps.punto_reorden), p.nombre"
+
+	rows, err := i.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar productos con stock bajo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.ProductoStockBajo, 0)
+	for rows.Next() {
+		var item domain.ProductoStockBajo
+		err := rows.Scan(&item.ProductoSucursalId, &item.Producto, &item.SucursalId, &item.Sucursal, &item.Stock, &item.StockMinimo,
+			&item.PuntoReorden, &item.StockMaximo, &item.CantidadSugerida)
+		if err != nil {
+			log.Println("Error al escanear producto con stock bajo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
@@ -26,7 +26,7 @@ func (m MetodoPagoRepository) ListarMetodosPago(ctx context.Context, filtros map
 		j++
 	}
 
-	query := `SELECT m.id,m.nombre,m.estado FROM metodo_pago m`
+	query := `SELECT m.id,m.nombre,m.codigo,m.estado FROM metodo_pago m`
 	if len(filters) > 0 {
 		query += " WHERE " + strings.Join(filters, " AND ")
 	}
@@ -39,7 +39,7 @@ func (m MetodoPagoRepository) ListarMetodosPago(ctx context.Context, filtros map
 	list := make([]domain.MetodoPago, 0)
 	for rows.Next() {
 		var item domain.MetodoPago
-		err = rows.Scan(&item.Id, &item.Nombre, &item.Estado)
+		err = rows.Scan(&item.Id, &item.Nombre, &item.Codigo, &item.Estado)
 		if err != nil {
 			return nil, datatype.NewInternalServerErrorGeneric()
 		}
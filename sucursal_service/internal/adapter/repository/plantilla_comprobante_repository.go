@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PlantillaComprobanteRepository struct {
+	pool *pgxpool.Pool
+}
+
+// Valores del comprobante para sucursales sin plantilla registrada
+const (
+	nombreComercialPorDefecto = "ESCONDITE MULTIROOM"
+	telefonoPorDefecto        = "76328248"
+	piePaginaPorDefecto       = "¡Gracias por su compra!\nVuelva pronto"
+)
+
+func (p PlantillaComprobanteRepository) ObtenerPlantillaComprobante(ctx context.Context, sucursalId *int) (*domain.PlantillaComprobante, error) {
+	fullHostname, _ := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/sucursales/")
+
+	query := `
+        SELECT s.id, pc.sucursal_id IS NOT NULL, COALESCE(pc.nombre_comercial, ''), pc.logo, pc.direccion, pc.telefono, pc.nit,
+               pc.pie_pagina, COALESCE(pc.ancho_papel, $2), COALESCE(pc.imprimir_al_cobrar, false), pc.impresora_id, pc.actualizado_en
+        FROM sucursal s
+        LEFT JOIN plantilla_comprobante pc ON pc.sucursal_id = s.id
+        WHERE s.id = $1`
+	var item domain.PlantillaComprobante
+	var registrada bool
+	var logo *string
+	err := p.pool.QueryRow(ctx, query, *sucursalId, domain.AnchoPapel80mm).Scan(&item.SucursalId, &registrada, &item.NombreComercial, &logo,
+		&item.Direccion, &item.Telefono, &item.Nit, &item.PiePagina, &item.AnchoPapel, &item.ImprimirAlCobrar, &item.ImpresoraId, &item.ActualizadoEn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Sucursal no encontrada")
+		}
+		log.Println("Error al obtener plantilla de comprobante:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if !registrada {
+		telefono, pie := telefonoPorDefecto, piePaginaPorDefecto
+		item.NombreComercial = nombreComercialPorDefecto
+		item.Telefono = &telefono
+		item.PiePagina = &pie
+	}
+	if logo != nil {
+		url := fmt.Sprintf("%s%d/%s", fullHostname, item.SucursalId, *logo)
+		ruta := fmt.Sprintf("./public/uploads/sucursales/%d/%s", item.SucursalId, *logo)
+		item.UrlLogo = &url
+		item.RutaLogo = &ruta
+	}
+	return &item, nil
+}
+
+func (p PlantillaComprobanteRepository) ModificarPlantillaComprobante(ctx context.Context, sucursalId *int, request *domain.PlantillaComprobanteRequest, fileHeader *multipart.FileHeader) error {
+	// Iniciar transacción
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	if request.ImpresoraId != nil {
+		var impresoraValida bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM impresora WHERE id = $1 AND sucursal_id = $2)`, *request.ImpresoraId, *sucursalId).Scan(&impresoraValida)
+		if err != nil {
+			log.Println("Error al validar impresora:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if !impresoraValida {
+			return datatype.NewBadRequestError("La impresora no pertenece a la sucursal.")
+		}
+	}
+
+	// El logo solo cambia si se envía uno nuevo o se pide eliminarlo
+	var nombreArchivo *string
+	if fileHeader != nil {
+		nombre := strings.ToLower(fileHeader.Filename)
+		nombreArchivo = &nombre
+	}
+	reemplazarLogo := fileHeader != nil || request.EliminarLogo
+
+	query := `
+        INSERT INTO plantilla_comprobante (sucursal_id, nombre_comercial, logo, direccion, telefono, nit, pie_pagina, ancho_papel,
+                                           imprimir_al_cobrar, impresora_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $10, $11)
+        ON CONFLICT (sucursal_id) DO UPDATE
+        SET nombre_comercial = EXCLUDED.nombre_comercial,
+            logo = CASE WHEN $9 THEN EXCLUDED.logo ELSE plantilla_comprobante.logo END,
+            direccion = EXCLUDED.direccion,
+            telefono = EXCLUDED.telefono,
+            nit = EXCLUDED.nit,
+            pie_pagina = EXCLUDED.pie_pagina,
+            ancho_papel = EXCLUDED.ancho_papel,
+            imprimir_al_cobrar = EXCLUDED.imprimir_al_cobrar,
+            impresora_id = EXCLUDED.impresora_id,
+            actualizado_en = NOW()`
+	_, err = tx.Exec(ctx, query, *sucursalId, request.NombreComercial, nombreArchivo, request.Direccion, request.Telefono, request.Nit,
+		request.PiePagina, request.AnchoPapel, reemplazarLogo, request.ImprimirAlCobrar, request.ImpresoraId)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return datatype.NewNotFoundError("Sucursal no encontrada")
+		}
+		log.Println("Error al guardar plantilla de comprobante:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	if reemplazarLogo {
+		// Respaldar el logo anterior por si falla el guardado
+		routeDir := fmt.Sprintf("./public/uploads/sucursales/%d", *sucursalId)
+		backupFiles, err := util.File.BackupFiles(routeDir)
+		if err != nil {
+			log.Println(err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		defer func() {
+			if !committed {
+				_ = util.File.RestoreFiles(backupFiles, routeDir)
+			}
+		}()
+		if err = util.File.MakeDir(routeDir); err != nil {
+			log.Println("Error al crear directorio:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if err = util.File.DeleteAllFiles(routeDir); err != nil {
+			log.Println("Error al eliminar archivos:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if fileHeader != nil {
+			file, err := fileHeader.Open()
+			if err != nil {
+				log.Println("Error al abrir archivo")
+				return datatype.NewInternalServerErrorGeneric()
+			}
+			if err = util.File.SaveFile(routeDir, *nombreArchivo, file); err != nil {
+				log.Println("Error al guardar imagen:", err)
+				return datatype.NewInternalServerError("Error al guardar imagen")
+			}
+		}
+	}
+
+	// Confirmar transacción
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return nil
+}
+
+func NewPlantillaComprobanteRepository(pool *pgxpool.Pool) *PlantillaComprobanteRepository {
+	return &PlantillaComprobanteRepository{pool: pool}
+}
+
+var _ port.PlantillaComprobanteRepository = (*PlantillaComprobanteRepository)(nil)
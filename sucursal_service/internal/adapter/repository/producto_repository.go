@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"mime/multipart"
 	"multiroom/sucursal-service/internal/core/domain"
 	"multiroom/sucursal-service/internal/core/domain/datatype"
@@ -32,6 +33,9 @@ func (p ProductoRepository) ObtenerProductoSucursalById(ctx context.Context, id
        ps.id,
        ps.precio,
        ps.estado,
+       ps.stock_minimo,
+       ps.punto_reorden,
+       ps.stock_maximo,
        -- Sumamos el stock total de todas las ubicaciones de ESTA sucursal
        COALESCE(SUM(i.stock), 0) as stock,
        json_build_object(
@@ -59,6 +63,9 @@ func (p ProductoRepository) ObtenerProductoSucursalById(ctx context.Context, id
 		&item.Id,
 		&item.Precio,
 		&item.Estado,
+		&item.StockMinimo,
+		&item.PuntoReorden,
+		&item.StockMaximo,
 		&item.Stock,
 		&item.Producto,
 	)
@@ -87,7 +94,8 @@ func (p ProductoRepository) ActualizarProductoSucursal(ctx context.Context, id *
         UPDATE producto_sucursal
         SET 
             precio = $1,
-            estado = $2
+            estado = $2,
+            actualizado_en = NOW()
         WHERE id = $3
     `
 
@@ -104,6 +112,22 @@ func (p ProductoRepository) ActualizarProductoSucursal(ctx context.Context, id *
 	return nil
 }
 
+func (p ProductoRepository) ActualizarNivelesStock(ctx context.Context, id *int, req *domain.NivelesStockRequest) error {
+	query := `
+        UPDATE producto_sucursal
+        SET stock_minimo = $1, punto_reorden = $2, stock_maximo = $3, actualizado_en = NOW()
+        WHERE id = $4`
+	cmdTag, err := p.pool.Exec(ctx, query, req.StockMinimo, req.PuntoReorden, req.StockMaximo, *id)
+	if err != nil {
+		log.Println("Error al actualizar niveles de stock:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return datatype.NewNotFoundError("No se encontró el registro para actualizar")
+	}
+	return nil
+}
+
 func (p ProductoRepository) ListarProductosPorSucursal(ctx context.Context, filtros map[string]string) (*[]domain.ProductoSucursalInfo, error) {
 	fullHostname := ctx.Value("fullHostname").(string)
 	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
@@ -157,6 +181,9 @@ func (p ProductoRepository) ListarProductosPorSucursal(ctx context.Context, filt
        ps.id,
        ps.estado,
        ps.precio,
+       ps.stock_minimo,
+       ps.punto_reorden,
+       ps.stock_maximo,
 	   COALESCE(stocks.total_stock, 0)  AS stock,
        json_build_object(
             'id', p.id,
@@ -209,7 +236,7 @@ func (p ProductoRepository) ListarProductosPorSucursal(ctx context.Context, filt
 	list := make([]domain.ProductoSucursalInfo, 0)
 	for rows.Next() {
 		var item domain.ProductoSucursalInfo
-		err = rows.Scan(&item.Id, &item.Estado, &item.Precio, &item.Stock, &item.Producto)
+		err = rows.Scan(&item.Id, &item.Estado, &item.Precio, &item.StockMinimo, &item.PuntoReorden, &item.StockMaximo, &item.Stock, &item.Producto)
 		if err != nil {
 			log.Println("Error scan:", err)
 			return nil, datatype.NewInternalServerErrorGeneric()
@@ -233,7 +260,7 @@ func (p ProductoRepository) HabilitarProductoById(ctx context.Context, productoI
 			_ = tx.Rollback(ctx)
 		}
 	}()
-	query := `UPDATE producto SET estado='Activo' WHERE id=$1`
+	query := `UPDATE producto SET estado='Activo',actualizado_en=now() WHERE id=$1`
 	ct, err := tx.Exec(ctx, query, *productoId)
 	if err != nil {
 		log.Println("Error al actualizar producto:", err)
@@ -266,7 +293,7 @@ func (p ProductoRepository) DeshabilitarProductoById(ctx context.Context, produc
 			_ = tx.Rollback(ctx)
 		}
 	}()
-	query := `UPDATE producto SET estado='Inactivo' WHERE id=$1`
+	query := `UPDATE producto SET estado='Inactivo',actualizado_en=now() WHERE id=$1`
 	ct, err := tx.Exec(ctx, query, *productoId)
 	if err != nil {
 		log.Println("Error al actualizar producto:", err)
@@ -341,6 +368,8 @@ func (p ProductoRepository) ListarProductosMasVendidos(ctx context.Context, filt
     SELECT 
         COALESCE(v_stats.total_dinero, 0) as total_ventas,
         COALESCE(v_stats.total_cantidad, 0) as cantidad_ventas,
+        COALESCE(v_stats.ventas_netas, 0) as ventas_netas,
+        COALESCE(v_stats.costo_ventas, 0) as costo_ventas,
         COALESCE(c_stats.total_dinero, 0) as total_compras,
         COALESCE(c_stats.total_cantidad, 0) as cantidad_compras,
 
@@ -378,11 +407,11 @@ func (p ProductoRepository) ListarProductosMasVendidos(ctx context.Context, filt
             SELECT json_agg(cd) FROM (
                 SELECT 
                     TO_CHAR(c.creado_en, 'YYYY-MM-DD') as fecha, 
-                    SUM(dc.cantidad * dc.precio_compra) as total
+                    SUM(dc.cantidad_recibida * dc.precio_compra) as total
                 FROM detalle_compra dc
                 JOIN compra c ON dc.compra_id = c.id
                 WHERE dc.producto_id = p.id 
-                  AND c.estado = 'Completado'
+                  AND c.estado IN ('Completado', 'Parcialmente recibida')
                   AND ($3::int IS NULL OR c.sucursal_id = $3)
                   AND ($4::date IS NULL OR c.creado_en::date >= $4::date)
                   AND ($5::date IS NULL OR c.creado_en::date <= $5::date)
@@ -397,7 +426,10 @@ func (p ProductoRepository) ListarProductosMasVendidos(ctx context.Context, filt
     LEFT JOIN LATERAL (
         SELECT 
             SUM(dv.cantidad * dv.precio_venta) as total_dinero,
-            SUM(dv.cantidad) as total_cantidad
+            SUM(dv.cantidad) as total_cantidad,
+            -- Margen bruto: venta neta de descuentos de línea y del general prorrateado contra el costo guardado al vender
+            SUM((dv.cantidad * dv.precio_venta - dv.descuento) * v.total / NULLIF(v.total + v.descuento_general, 0)) as ventas_netas,
+            SUM(dv.cantidad * COALESCE(dv.costo_unitario, 0)) as costo_ventas
         FROM detalle_venta dv
         JOIN venta v ON dv.venta_id = v.id
         WHERE dv.producto_id = p.id 
@@ -410,12 +442,12 @@ func (p ProductoRepository) ListarProductosMasVendidos(ctx context.Context, filt
     -- D. Totales Compra
     LEFT JOIN LATERAL (
         SELECT 
-            SUM(dc.cantidad * dc.precio_compra) as total_dinero,
-            SUM(dc.cantidad) as total_cantidad
+            SUM(dc.cantidad_recibida * dc.precio_compra) as total_dinero,
+            SUM(dc.cantidad_recibida) as total_cantidad
         FROM detalle_compra dc
         JOIN compra c ON dc.compra_id = c.id
         WHERE dc.producto_id = p.id 
-          AND c.estado = 'Completado'
+          AND c.estado IN ('Completado', 'Parcialmente recibida')
           AND ($3::int IS NULL OR c.sucursal_id = $3)
           AND ($4::date IS NULL OR c.creado_en::date >= $4::date)
           AND ($5::date IS NULL OR c.creado_en::date <= $5::date)
@@ -443,6 +475,8 @@ func (p ProductoRepository) ListarProductosMasVendidos(ctx context.Context, filt
 		err = rows.Scan(
 			&item.TotalVentas,
 			&item.CantidadVentas,
+			&item.VentasNetas,
+			&item.CostoVentas,
 			&item.TotalCompras,
 			&item.CantidadCompras,
 			&item.Producto,
@@ -453,6 +487,10 @@ func (p ProductoRepository) ListarProductosMasVendidos(ctx context.Context, filt
 			log.Println("Error al  escanear:", err)
 			return nil, datatype.NewInternalServerErrorGeneric()
 		}
+		item.MargenBruto = item.VentasNetas - item.CostoVentas
+		if item.VentasNetas != 0 {
+			item.MargenPorcentaje = math.Round(item.MargenBruto/item.VentasNetas*10000) / 100
+		}
 		list = append(list, item)
 	}
 
@@ -475,8 +513,8 @@ func (p ProductoRepository) RegistrarProducto(ctx context.Context, request *doma
 		}
 	}()
 	var productoId int
-	query := `INSERT INTO producto(nombre, estado, foto,es_inventariable,categoria_id) VALUES ($1,$2,$3,$4,$5) RETURNING id`
-	err = tx.QueryRow(ctx, query, request.Nombre, request.Estado, nombreArchivo, request.EsInventariable, request.CategoriaId).Scan(&productoId)
+	query := `INSERT INTO producto(nombre, estado, foto,es_inventariable,categoria_id,es_tarjeta_regalo,vigencia_dias_tarjeta,sku) VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8,'')) RETURNING id`
+	err = tx.QueryRow(ctx, query, request.Nombre, request.Estado, nombreArchivo, request.EsInventariable, request.CategoriaId, request.EsTarjetaRegalo, request.VigenciaDiasTarjeta, request.Sku).Scan(&productoId)
 	if err != nil {
 		log.Println("Error al actualizar producto:", err)
 		var pgErr *pgconn.PgError
@@ -486,11 +524,19 @@ func (p ProductoRepository) RegistrarProducto(ctx context.Context, request *doma
 				if pgErr.ConstraintName == "unique_producto" {
 					return nil, datatype.NewConflictError("Ya existe un producto con ese nombre")
 				}
+				if pgErr.ConstraintName == "unique_producto_sku" {
+					return nil, datatype.NewConflictError("Ya existe un producto con ese SKU")
+				}
 				return nil, datatype.NewInternalServerErrorGeneric()
 			}
 		}
 		return nil, datatype.NewInternalServerErrorGeneric()
 	}
+	if request.CodigosBarras != nil {
+		if err = guardarCodigosBarras(ctx, tx, productoId, *request.CodigosBarras); err != nil {
+			return nil, err
+		}
+	}
 	query = `INSERT INTO producto_sucursal (producto_id, sucursal_id, precio, estado)SELECT $1, id, $2, 'Activo' FROM sucursal`
 	_, err = tx.Exec(ctx, query, productoId, request.Precio)
 	if err != nil {
@@ -546,8 +592,9 @@ func (p ProductoRepository) ModificarProductoById(ctx context.Context, productoI
 			_ = tx.Rollback(ctx)
 		}
 	}()
-	query := `UPDATE producto SET nombre=$1,foto=$2,estado=$3,actualizado_en=now(), categoria_id=$4 WHERE id=$5`
-	ct, err := tx.Exec(ctx, query, request.Nombre, nombreArchivo, request.Estado, request.CategoriaId, *productoId)
+	query := `UPDATE producto SET nombre=$1,foto=$2,estado=$3,actualizado_en=now(), categoria_id=$4, es_tarjeta_regalo=$5, vigencia_dias_tarjeta=$6,
+        sku = CASE WHEN $8::text IS NULL THEN sku ELSE NULLIF($8, '') END WHERE id=$7`
+	ct, err := tx.Exec(ctx, query, request.Nombre, nombreArchivo, request.Estado, request.CategoriaId, request.EsTarjetaRegalo, request.VigenciaDiasTarjeta, *productoId, request.Sku)
 	if err != nil {
 		log.Println("Error al actualizar producto:", err)
 		var pgErr *pgconn.PgError
@@ -557,6 +604,9 @@ func (p ProductoRepository) ModificarProductoById(ctx context.Context, productoI
 				if pgErr.ConstraintName == "unique_producto" {
 					return datatype.NewConflictError("Ya existe un producto con ese nombre")
 				}
+				if pgErr.ConstraintName == "unique_producto_sku" {
+					return datatype.NewConflictError("Ya existe un producto con ese SKU")
+				}
 				// Otra violación única
 				return datatype.NewInternalServerErrorGeneric()
 			}
@@ -566,6 +616,15 @@ func (p ProductoRepository) ModificarProductoById(ctx context.Context, productoI
 	if ct.RowsAffected() == 0 {
 		return datatype.NewNotFoundError("Producto no encontrado")
 	}
+	if request.CodigosBarras != nil {
+		if _, err = tx.Exec(ctx, `DELETE FROM codigo_barras_producto WHERE producto_id = $1`, *productoId); err != nil {
+			log.Println("Error al eliminar códigos de barras:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if err = guardarCodigosBarras(ctx, tx, *productoId, *request.CodigosBarras); err != nil {
+			return err
+		}
+	}
 
 	// Respaldar archivos existentes
 	route := fmt.Sprintf("./public/uploads/productos/%d", *productoId)
@@ -696,14 +755,22 @@ SELECT
 			'estado', c.estado
 		)
 		ELSE NULL
-	END AS categoria
+	END AS categoria,
+	p.es_tarjeta_regalo,
+	p.vigencia_dias_tarjeta,
+	p.sku,
+	COALESCE((
+		SELECT json_agg(json_build_object('id', cb.id, 'codigo', cb.codigo, 'tipo', cb.tipo) ORDER BY cb.id)
+		FROM codigo_barras_producto cb WHERE cb.producto_id = p.id
+	), '[]'::json) AS codigos_barras
 FROM producto p 
 LEFT JOIN categoria_producto c ON p.categoria_id = c.id
 WHERE p.id = $2 
 LIMIT 1`
 	var item domain.Producto
 	err := p.pool.QueryRow(ctx, query, fullHostname, *productoId).
-		Scan(&item.Id, &item.Nombre, &item.Estado, &item.UrlFoto, &item.EsInventariable, &item.CreadoEn, &item.ActualizadoEn, &item.EliminadoEn, &item.Categoria)
+		Scan(&item.Id, &item.Nombre, &item.Estado, &item.UrlFoto, &item.EsInventariable, &item.CreadoEn, &item.ActualizadoEn, &item.EliminadoEn, &item.Categoria,
+			&item.EsTarjetaRegalo, &item.VigenciaDiasTarjeta, &item.Sku, &item.CodigosBarras)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, datatype.NewNotFoundError("Producto no encontrado")
@@ -712,6 +779,423 @@ LIMIT 1`
 	}
 	return &item, nil
 }
+
+// ObtenerProductoPorCodigo busca primero entre los códigos de barras y luego por SKU
+func (p ProductoRepository) ObtenerProductoPorCodigo(ctx context.Context, codigo string, sucursalId int) (*domain.ProductoEscaneado, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	query := `
+    WITH coincidencia AS (
+        SELECT producto_id, codigo, tipo FROM (
+            SELECT cb.producto_id, cb.codigo, cb.tipo, 1 AS prioridad FROM codigo_barras_producto cb WHERE cb.codigo = $2
+            UNION ALL
+            SELECT p.id, p.sku, $4::text, 2 FROM producto p WHERE p.sku = $2
+        ) c
+        ORDER BY prioridad
+        LIMIT 1
+    )
+    SELECT 
+       ps.id,
+       ps.precio,
+       ps.estado,
+       ps.stock_minimo,
+       ps.punto_reorden,
+       ps.stock_maximo,
+       COALESCE(SUM(i.stock), 0) AS stock,
+       -- Igual que al vender, los lotes vencidos no cuentan como stock vendible
+       COALESCE(SUM(GREATEST(i.stock - COALESCE(lv.vencido, 0), 0)) FILTER (WHERE u.es_vendible), 0) AS stock_vendible,
+       json_build_object(
+          'id', p.id,
+          'nombre', p.nombre,
+          'estado', p.estado,
+          'urlFoto', ($1::text || p.id::text || '/' || p.foto),
+          'esInventariable', p.es_inventariable,
+          'creadoEn', p.creado_en,
+          'actualizadoEn', p.actualizado_en,
+          'eliminadoEn', p.eliminado_en
+       ) AS producto_info,
+       p.sku,
+       c.codigo,
+       c.tipo
+    FROM coincidencia c
+    JOIN producto p ON p.id = c.producto_id
+    JOIN producto_sucursal ps ON ps.producto_id = p.id AND ps.sucursal_id = $3
+    LEFT JOIN (
+        inventario i
+        JOIN ubicacion u ON i.ubicacion_id = u.id AND u.estado = 'Activo'
+    ) ON i.producto_id = p.id AND u.sucursal_id = $3
+    LEFT JOIN LATERAL (
+        SELECT SUM(il.stock) AS vencido
+        FROM inventario_lote il
+        WHERE il.producto_id = i.producto_id AND il.ubicacion_id = i.ubicacion_id AND il.fecha_vencimiento < CURRENT_DATE
+    ) lv ON true
+    GROUP BY ps.id, p.id, c.codigo, c.tipo`
+	var item domain.ProductoEscaneado
+	err := p.pool.QueryRow(ctx, query, fullHostname, codigo, sucursalId, domain.CodigoSku).Scan(
+		&item.Id,
+		&item.Precio,
+		&item.Estado,
+		&item.StockMinimo,
+		&item.PuntoReorden,
+		&item.StockMaximo,
+		&item.Stock,
+		&item.StockVendible,
+		&item.Producto,
+		&item.Sku,
+		&item.Codigo,
+		&item.TipoCodigo,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("No hay un producto con ese código en la sucursal")
+		}
+		log.Println("Error al buscar producto por código:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+// ListarEtiquetasProductos devuelve las etiquetas en el orden pedido con el precio de la sucursal y el primer
+// código de barras registrado de cada producto; un producto repetido sale una sola vez
+func (p ProductoRepository) ListarEtiquetasProductos(ctx context.Context, sucursalId int, productoIds []int) (*[]domain.EtiquetaProducto, error) {
+	vistos := make(map[int]bool, len(productoIds))
+	unicos := make([]int, 0, len(productoIds))
+	for _, id := range productoIds {
+		if !vistos[id] {
+			vistos[id] = true
+			unicos = append(unicos, id)
+		}
+	}
+	productoIds = unicos
+
+	query := `
+    SELECT p.id, p.nombre, ps.precio, COALESCE(cb.codigo, p.sku), COALESCE(cb.tipo, $3::text)
+    FROM producto p
+    JOIN producto_sucursal ps ON ps.producto_id = p.id AND ps.sucursal_id = $1
+    LEFT JOIN LATERAL (
+        SELECT codigo, tipo FROM codigo_barras_producto WHERE producto_id = p.id ORDER BY id LIMIT 1
+    ) cb ON true
+    WHERE p.id = ANY($2::int[])
+    ORDER BY array_position($2::int[], p.id)`
+	rows, err := p.pool.Query(ctx, query, sucursalId, productoIds, domain.CodigoSku)
+	if err != nil {
+		log.Println("Error al listar etiquetas de productos:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.EtiquetaProducto, 0)
+	for rows.Next() {
+		var item domain.EtiquetaProducto
+		var codigo *string
+		if err := rows.Scan(&item.ProductoId, &item.Nombre, &item.Precio, &codigo, &item.Tipo); err != nil {
+			log.Println("Error al escanear etiqueta de producto:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if codigo == nil {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("El producto '%s' no tiene código de barras ni SKU", item.Nombre))
+		}
+		item.Codigo = *codigo
+		list = append(list, item)
+	}
+	if len(list) != len(productoIds) {
+		return nil, datatype.NewBadRequestError("Uno o más productos no existen o no están asignados a la sucursal")
+	}
+	return &list, nil
+}
+
+// guardarCodigosBarras registra los códigos del producto; un código ya asignado a otro producto es un conflicto
+func guardarCodigosBarras(ctx context.Context, tx pgx.Tx, productoId int, codigos []domain.CodigoBarrasRequest) error {
+	for _, c := range codigos {
+		_, err := tx.Exec(ctx, `INSERT INTO codigo_barras_producto (producto_id, codigo, tipo) VALUES ($1, $2, $3)`, productoId, c.Codigo, c.Tipo)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "unique_codigo_barras" {
+				return datatype.NewConflictError(fmt.Sprintf("El código de barras %s ya está asignado a otro producto", c.Codigo))
+			}
+			log.Println("Error al registrar código de barras:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+	return nil
+}
+
+func (p ProductoRepository) ListarComponentesProducto(ctx context.Context, productoId *int, filtros map[string]string) (*[]domain.ProductoComponente, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	sucursalId, err := strconv.Atoi(filtros["sucursalId"])
+	if err != nil || sucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+	}
+
+	query := `
+SELECT 
+    p.id,
+    p.nombre,
+    p.estado,
+    ($1::text || p.id::text || '/' || p.foto) AS url_foto,
+    p.es_inventariable,
+    p.creado_en,
+    p.actualizado_en,
+    p.eliminado_en,
+    pc.cantidad
+FROM producto_componente pc
+JOIN producto p ON pc.componente_id = p.id
+WHERE pc.producto_id = $2 AND pc.sucursal_id = $3
+ORDER BY p.nombre`
+	rows, err := p.pool.Query(ctx, query, fullHostname, *productoId, sucursalId)
+	if err != nil {
+		log.Println("Error al listar componentes del producto:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.ProductoComponente, 0)
+	for rows.Next() {
+		var item domain.ProductoComponente
+		c := &item.Componente
+		err = rows.Scan(&c.Id, &c.Nombre, &c.Estado, &c.UrlFoto, &c.EsInventariable, &c.CreadoEn, &c.ActualizadoEn, &c.EliminadoEn, &item.Cantidad)
+		if err != nil {
+			log.Println("Error al escanear componente:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+// ModificarComponentesProducto reemplaza la receta del kit en la sucursal; una lista vacía lo vuelve un producto simple.
+func (p ProductoRepository) ModificarComponentesProducto(ctx context.Context, productoId *int, request *domain.ProductoComponentesRequest) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	var existe bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM producto_sucursal WHERE producto_id = $1 AND sucursal_id = $2)`, *productoId, request.SucursalId).Scan(&existe)
+	if err != nil {
+		log.Println("Error al validar producto en sucursal:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if !existe {
+		return datatype.NewNotFoundError("El producto no está registrado en la sucursal")
+	}
+
+	// No se permiten kits anidados: el kit no puede ser componente de otro kit ni tener kits como componentes
+	if len(request.Componentes) > 0 {
+		var esComponente bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM producto_componente WHERE componente_id = $1 AND sucursal_id = $2)`, *productoId, request.SucursalId).Scan(&esComponente)
+		if err != nil {
+			log.Println("Error al validar kits anidados:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if esComponente {
+			return datatype.NewBadRequestError("El producto es componente de otro kit y no puede tener componentes")
+		}
+	}
+	// Cada componente debe estar registrado en la sucursal del kit para poder descontar su stock al vender
+	queryEsKit := `
+        SELECT EXISTS(SELECT 1 FROM producto_sucursal WHERE producto_id = $1 AND sucursal_id = $2),
+               EXISTS(SELECT 1 FROM producto_componente WHERE producto_id = $1 AND sucursal_id = $2)`
+	for _, c := range request.Componentes {
+		var enSucursal, esKit bool
+		if err = tx.QueryRow(ctx, queryEsKit, c.ProductoId, request.SucursalId).Scan(&enSucursal, &esKit); err != nil {
+			log.Println("Error al validar componentes:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if !enSucursal {
+			return datatype.NewBadRequestError(fmt.Sprintf("El producto %d no está registrado en la sucursal y no puede usarse como componente", c.ProductoId))
+		}
+		if esKit {
+			return datatype.NewBadRequestError(fmt.Sprintf("El producto %d es un kit y no puede usarse como componente", c.ProductoId))
+		}
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM producto_componente WHERE producto_id = $1 AND sucursal_id = $2`, *productoId, request.SucursalId)
+	if err != nil {
+		log.Println("Error al eliminar componentes:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	if len(request.Componentes) > 0 {
+		var filas [][]interface{}
+		for _, c := range request.Componentes {
+			filas = append(filas, []interface{}{request.SucursalId, *productoId, c.ProductoId, c.Cantidad})
+		}
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"producto_componente"},
+			[]string{"sucursal_id", "producto_id", "componente_id", "cantidad"},
+			pgx.CopyFromRows(filas))
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return datatype.NewBadRequestError("Uno de los componentes no existe")
+			}
+			log.Println("Error al registrar componentes:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar componentes:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return nil
+}
+
+func (p ProductoRepository) ListarOpcionesProducto(ctx context.Context, productoId *int) (*[]domain.GrupoOpcion, error) {
+	query := `
+SELECT 
+    g.id,
+    g.nombre,
+    g.tipo,
+    g.seleccion_minima,
+    g.seleccion_maxima,
+    g.estado,
+    COALESCE(json_agg(json_build_object(
+        'id', o.id,
+        'nombre', o.nombre,
+        'precioDelta', o.precio_delta,
+        'productoStockId', o.producto_stock_id,
+        'cantidadStock', o.cantidad_stock,
+        'estado', o.estado
+    ) ORDER BY o.id) FILTER (WHERE o.id IS NOT NULL), '[]') AS opciones
+FROM grupo_opcion g
+LEFT JOIN opcion_producto o ON o.grupo_opcion_id = g.id AND o.estado = 'Activo'
+WHERE g.producto_id = $1 AND g.estado = 'Activo'
+GROUP BY g.id
+ORDER BY g.id`
+	rows, err := p.pool.Query(ctx, query, *productoId)
+	if err != nil {
+		log.Println("Error al listar opciones del producto:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.GrupoOpcion, 0)
+	for rows.Next() {
+		var item domain.GrupoOpcion
+		err = rows.Scan(&item.Id, &item.Nombre, &item.Tipo, &item.SeleccionMinima, &item.SeleccionMaxima, &item.Estado, &item.Opciones)
+		if err != nil {
+			log.Println("Error al escanear grupo de opciones:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+// ModificarOpcionesProducto sincroniza los grupos y opciones del producto: los que traen id se actualizan, los nuevos se
+// insertan y los omitidos pasan a 'Inactivo' para no romper el historial de ventas.
+func (p ProductoRepository) ModificarOpcionesProducto(ctx context.Context, productoId *int, request *domain.ProductoOpcionesRequest) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	var existe bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM producto WHERE id = $1)`, *productoId).Scan(&existe)
+	if err != nil {
+		log.Println("Error al validar producto:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if !existe {
+		return datatype.NewNotFoundError("Producto no encontrado")
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE opcion_producto SET estado = 'Inactivo'
+        WHERE grupo_opcion_id IN (SELECT id FROM grupo_opcion WHERE producto_id = $1)`, *productoId)
+	if err != nil {
+		log.Println("Error al desactivar opciones:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	_, err = tx.Exec(ctx, `UPDATE grupo_opcion SET estado = 'Inactivo' WHERE producto_id = $1`, *productoId)
+	if err != nil {
+		log.Println("Error al desactivar grupos de opciones:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	queryUpdateGrupo := `
+        UPDATE grupo_opcion SET nombre = $1, tipo = $2, seleccion_minima = $3, seleccion_maxima = $4, estado = 'Activo'
+        WHERE id = $5 AND producto_id = $6`
+	queryInsertGrupo := `
+        INSERT INTO grupo_opcion (producto_id, nombre, tipo, seleccion_minima, seleccion_maxima, estado)
+        VALUES ($1, $2, $3, $4, $5, 'Activo')
+        RETURNING id`
+	queryUpdateOpcion := `
+        UPDATE opcion_producto SET nombre = $1, precio_delta = $2, producto_stock_id = $3, cantidad_stock = $4, estado = 'Activo'
+        WHERE id = $5 AND grupo_opcion_id = $6`
+	queryInsertOpcion := `
+        INSERT INTO opcion_producto (grupo_opcion_id, nombre, precio_delta, producto_stock_id, cantidad_stock, estado)
+        VALUES ($1, $2, $3, $4, $5, 'Activo')`
+
+	for _, g := range request.Grupos {
+		var grupoId int
+		if g.Id != nil {
+			ct, err := tx.Exec(ctx, queryUpdateGrupo, g.Nombre, g.Tipo, g.SeleccionMinima, g.SeleccionMaxima, *g.Id, *productoId)
+			if err != nil {
+				log.Println("Error al actualizar grupo de opciones:", err)
+				return datatype.NewInternalServerErrorGeneric()
+			}
+			if ct.RowsAffected() == 0 {
+				return datatype.NewBadRequestError(fmt.Sprintf("El grupo %d no pertenece al producto", *g.Id))
+			}
+			grupoId = *g.Id
+		} else {
+			err = tx.QueryRow(ctx, queryInsertGrupo, *productoId, g.Nombre, g.Tipo, g.SeleccionMinima, g.SeleccionMaxima).Scan(&grupoId)
+			if err != nil {
+				log.Println("Error al registrar grupo de opciones:", err)
+				return datatype.NewInternalServerErrorGeneric()
+			}
+		}
+
+		for _, o := range g.Opciones {
+			var ct pgconn.CommandTag
+			if o.Id != nil {
+				ct, err = tx.Exec(ctx, queryUpdateOpcion, o.Nombre, o.PrecioDelta, o.ProductoStockId, o.CantidadStock, *o.Id, grupoId)
+				if err == nil && ct.RowsAffected() == 0 {
+					return datatype.NewBadRequestError(fmt.Sprintf("La opción %d no pertenece al grupo '%s'", *o.Id, g.Nombre))
+				}
+			} else {
+				_, err = tx.Exec(ctx, queryInsertOpcion, grupoId, o.Nombre, o.PrecioDelta, o.ProductoStockId, o.CantidadStock)
+			}
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+					return datatype.NewBadRequestError(fmt.Sprintf("El producto de stock de la opción '%s' no existe", o.Nombre))
+				}
+				log.Println("Error al guardar opción del producto:", err)
+				return datatype.NewInternalServerErrorGeneric()
+			}
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar opciones del producto:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return nil
+}
+
 func NewProductoRepository(pool *pgxpool.Pool) *ProductoRepository {
 	return &ProductoRepository{pool: pool}
 }
@@ -0,0 +1,444 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PromocionRepository struct {
+	pool *pgxpool.Pool
+}
+
+const queryPromocionInfo = `
+SELECT
+    pr.id,
+    pr.nombre,
+    pr.tipo,
+    pr.alcance,
+    pr.valor,
+    pr.cantidad_compra,
+    pr.cantidad_gratis,
+    pr.fecha_inicio,
+    pr.fecha_fin,
+    to_char(pr.hora_inicio, 'HH24:MI'),
+    to_char(pr.hora_fin, 'HH24:MI'),
+    pr.dias_semana,
+    pr.codigo_cupon,
+    pr.uso_maximo,
+    pr.usos,
+    pr.estado,
+    pr.creado_en`
+
+func (p PromocionRepository) RegistrarPromocion(ctx context.Context, request *domain.PromocionRequest) (*int, error) {
+	var promocionId int
+	query := `
+		INSERT INTO promocion (nombre, tipo, alcance, valor, cantidad_compra, cantidad_gratis, sucursal_id, producto_id, categoria_id,
+		                       fecha_inicio, fecha_fin, hora_inicio, hora_fin, dias_semana, codigo_cupon, uso_maximo, estado)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12::time, $13::time, $14, $15, $16, $17)
+		RETURNING id`
+	err := p.pool.QueryRow(ctx, query, request.Nombre, request.Tipo, request.Alcance, request.Valor, request.CantidadCompra,
+		request.CantidadGratis, request.SucursalId, request.ProductoId, request.CategoriaId, request.FechaInicio, request.FechaFin,
+		request.HoraInicio, request.HoraFin, request.DiasSemana, request.CodigoCupon, request.UsoMaximo, request.Estado).Scan(&promocionId)
+	if err != nil {
+		return nil, errorPromocion(err)
+	}
+	return &promocionId, nil
+}
+
+func (p PromocionRepository) ModificarPromocionById(ctx context.Context, id *int, request *domain.PromocionRequest) error {
+	query := `
+		UPDATE promocion
+		SET nombre = $1, tipo = $2, alcance = $3, valor = $4, cantidad_compra = $5, cantidad_gratis = $6, sucursal_id = $7,
+		    producto_id = $8, categoria_id = $9, fecha_inicio = $10, fecha_fin = $11, hora_inicio = $12::time, hora_fin = $13::time,
+		    dias_semana = $14, codigo_cupon = $15, uso_maximo = $16, estado = $17, actualizado_en = NOW()
+		WHERE id = $18`
+	ct, err := p.pool.Exec(ctx, query, request.Nombre, request.Tipo, request.Alcance, request.Valor, request.CantidadCompra,
+		request.CantidadGratis, request.SucursalId, request.ProductoId, request.CategoriaId, request.FechaInicio, request.FechaFin,
+		request.HoraInicio, request.HoraFin, request.DiasSemana, request.CodigoCupon, request.UsoMaximo, request.Estado, *id)
+	if err != nil {
+		return errorPromocion(err)
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewNotFoundError("Promoción no encontrada")
+	}
+	return nil
+}
+
+func (p PromocionRepository) HabilitarPromocionById(ctx context.Context, id *int) error {
+	query := `UPDATE promocion SET estado = 'Activo', actualizado_en = NOW() WHERE id = $1`
+	ct, err := p.pool.Exec(ctx, query, *id)
+	if err != nil {
+		log.Println("Error al habilitar promoción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewNotFoundError("Promoción no encontrada")
+	}
+	return nil
+}
+
+func (p PromocionRepository) DeshabilitarPromocionById(ctx context.Context, id *int) error {
+	query := `UPDATE promocion SET estado = 'Inactivo', actualizado_en = NOW() WHERE id = $1`
+	ct, err := p.pool.Exec(ctx, query, *id)
+	if err != nil {
+		log.Println("Error al deshabilitar promoción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewNotFoundError("Promoción no encontrada")
+	}
+	return nil
+}
+
+func (p PromocionRepository) ListarPromociones(ctx context.Context, filtros map[string]string) (*[]domain.PromocionInfo, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+
+	if sucursalIdStr := filtros["sucursalId"]; sucursalIdStr != "" {
+		sucursalId, err := strconv.Atoi(sucursalIdStr)
+		if err != nil {
+			log.Println("Error al convertir sucursalId a int:", err)
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("pr.sucursal_id = $%d", j))
+		args = append(args, sucursalId)
+		j++
+	}
+	if estado := filtros["estado"]; estado != "" {
+		filters = append(filters, fmt.Sprintf("pr.estado = $%d", j))
+		args = append(args, estado)
+		j++
+	}
+	if tipo := filtros["tipo"]; tipo != "" {
+		filters = append(filters, fmt.Sprintf("pr.tipo = $%d", j))
+		args = append(args, tipo)
+		j++
+	}
+	if filtros["vigente"] == "true" {
+		filters = append(filters, "(pr.fecha_inicio IS NULL OR pr.fecha_inicio <= NOW()) AND (pr.fecha_fin IS NULL OR pr.fecha_fin >= NOW())")
+	}
+
+	query := queryPromocionInfo + ` FROM promocion pr`
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY pr.id DESC"
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar promociones:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+
+	list := make([]domain.PromocionInfo, 0)
+	for rows.Next() {
+		var item domain.PromocionInfo
+		err := rows.Scan(&item.Id, &item.Nombre, &item.Tipo, &item.Alcance, &item.Valor, &item.CantidadCompra, &item.CantidadGratis,
+			&item.FechaInicio, &item.FechaFin, &item.HoraInicio, &item.HoraFin, &item.DiasSemana, &item.CodigoCupon, &item.UsoMaximo,
+			&item.Usos, &item.Estado, &item.CreadoEn)
+		if err != nil {
+			log.Println("Error al escanear promoción:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func (p PromocionRepository) ObtenerPromocionById(ctx context.Context, id *int) (*domain.Promocion, error) {
+	query := queryPromocionInfo + `,
+    json_build_object(
+        'id', s.id,
+        'nombre', s.nombre,
+        'estado', s.estado,
+        'creadoEn', s.creado_en
+    ) AS sucursal,
+    (CASE WHEN p.id IS NOT NULL THEN json_build_object(
+        'id', p.id,
+        'nombre', p.nombre,
+        'estado', p.estado,
+        'esInventariable', p.es_inventariable,
+        'creadoEn', p.creado_en,
+        'actualizadoEn', p.actualizado_en
+    ) END) AS producto,
+    (CASE WHEN c.id IS NOT NULL THEN json_build_object(
+        'id', c.id,
+        'nombre', c.nombre,
+        'descripcion', c.descripcion,
+        'estado', c.estado
+    ) END) AS categoria
+FROM promocion pr
+JOIN sucursal s ON pr.sucursal_id = s.id
+LEFT JOIN producto p ON pr.producto_id = p.id
+LEFT JOIN categoria_producto c ON pr.categoria_id = c.id
+WHERE pr.id = $1`
+	var item domain.Promocion
+	err := p.pool.QueryRow(ctx, query, *id).Scan(&item.Id, &item.Nombre, &item.Tipo, &item.Alcance, &item.Valor, &item.CantidadCompra,
+		&item.CantidadGratis, &item.FechaInicio, &item.FechaFin, &item.HoraInicio, &item.HoraFin, &item.DiasSemana, &item.CodigoCupon,
+		&item.UsoMaximo, &item.Usos, &item.Estado, &item.CreadoEn, &item.Sucursal, &item.Producto, &item.Categoria)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Promoción no encontrada")
+		}
+		log.Println("Error al obtener promoción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+// ListarDescuentosPorPromocion acepta los mismos filtros que el listado de ventas; sin estado cuenta las ventas cobradas.
+func (p PromocionRepository) ListarDescuentosPorPromocion(ctx context.Context, filtros map[string]string) (*[]domain.PromocionDescuentoStat, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+
+	for _, filtro := range []struct{ clave, columna string }{
+		{"sucursalId", "v.sucursal_id"},
+		{"usuarioId", "v.usuario_id"},
+		{"salaId", "v.sala_id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+	if estado := filtros["estado"]; estado != "" {
+		filters = append(filters, fmt.Sprintf("v.estado = $%d", j))
+		args = append(args, estado)
+		j++
+	} else {
+		filters = append(filters, "v.estado = 'Completado'")
+	}
+	if fechaInicio := filtros["fechaInicio"]; fechaInicio != "" {
+		filters = append(filters, fmt.Sprintf("v.creado_en >= $%d", j))
+		args = append(args, fechaInicio)
+		j++
+	}
+	if fechaFin := filtros["fechaFin"]; fechaFin != "" {
+		filters = append(filters, fmt.Sprintf("v.creado_en <= $%d", j))
+		args = append(args, fechaFin)
+		j++
+	}
+
+	// Los descuentos de promoción se registran por línea (detalle_venta) y sobre el tiempo de sala (venta)
+	query := `
+		SELECT pr.id, pr.nombre, pr.tipo, COUNT(DISTINCT d.venta_id), COALESCE(SUM(d.monto), 0)
+		FROM (
+			SELECT dv.venta_id, dv.promocion_id, dv.descuento_promocion AS monto
+			FROM detalle_venta dv
+			WHERE dv.promocion_id IS NOT NULL
+			UNION ALL
+			SELECT v1.id, v1.promocion_tiempo_id, v1.descuento_tiempo
+			FROM venta v1
+			WHERE v1.promocion_tiempo_id IS NOT NULL
+		) d
+		JOIN venta v ON d.venta_id = v.id
+		JOIN promocion pr ON d.promocion_id = pr.id
+		WHERE ` + strings.Join(filters, " AND ") + `
+		GROUP BY pr.id
+		ORDER BY 5 DESC`
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar descuentos por promoción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+
+	list := make([]domain.PromocionDescuentoStat, 0)
+	for rows.Next() {
+		var item domain.PromocionDescuentoStat
+		err := rows.Scan(&item.Promocion.Id, &item.Promocion.Nombre, &item.Tipo, &item.CantidadVentas, &item.TotalDescuentos)
+		if err != nil {
+			log.Println("Error al escanear descuento por promoción:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func errorPromocion(err error) error {
+	log.Println("Error al guardar promoción:", err)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return datatype.NewConflictError("Ya existe una promoción con ese código de cupón en la sucursal")
+		case "23503":
+			return datatype.NewBadRequestError("La sucursal, producto o categoría indicada no existe")
+		}
+	}
+	return datatype.NewInternalServerErrorGeneric()
+}
+
+// promocionVigente es la forma interna en la que RegistrarVenta evalúa las promociones
+type promocionVigente struct {
+	Id             int
+	Nombre         string
+	Tipo           string
+	Alcance        string
+	Valor          float64
+	CantidadCompra *int64
+	CantidadGratis *int64
+	ProductoId     *int
+	CategoriaId    *int
+	CodigoCupon    *string
+	UsoMaximo      *int
+	Usos           int
+}
+
+// obtenerPromocionesVigentes devuelve las promociones automáticas vigentes de la sucursal en el momento indicado
+// más, si se envió, la promoción del cupón. Las ventanas de horario se evalúan con la zona horaria de la conexión.
+func obtenerPromocionesVigentes(ctx context.Context, tx pgx.Tx, sucursalId int, codigoCupon *string, momento time.Time) ([]promocionVigente, error) {
+	var cupon *string
+	if codigoCupon != nil && strings.TrimSpace(*codigoCupon) != "" {
+		c := strings.ToUpper(strings.TrimSpace(*codigoCupon))
+		cupon = &c
+	}
+
+	query := `
+		SELECT id, nombre, tipo, alcance, valor, cantidad_compra, cantidad_gratis, producto_id, categoria_id, codigo_cupon, uso_maximo, usos
+		FROM promocion
+		WHERE sucursal_id = $1
+		  AND estado = 'Activo'
+		  AND (fecha_inicio IS NULL OR fecha_inicio <= $3::timestamptz)
+		  AND (fecha_fin IS NULL OR fecha_fin >= $3::timestamptz)
+		  AND (hora_inicio IS NULL OR hora_fin IS NULL OR
+		       (CASE WHEN hora_inicio <= hora_fin THEN $3::timestamptz::time BETWEEN hora_inicio AND hora_fin
+		             ELSE $3::timestamptz::time >= hora_inicio OR $3::timestamptz::time <= hora_fin END))
+		  AND (dias_semana IS NULL OR cardinality(dias_semana) = 0 OR EXTRACT(ISODOW FROM $3::timestamptz)::int = ANY(dias_semana))
+		  AND (codigo_cupon IS NULL OR codigo_cupon = $2)`
+	rows, err := tx.Query(ctx, query, sucursalId, cupon, momento)
+	if err != nil {
+		log.Println("Error al obtener promociones vigentes:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+
+	var list []promocionVigente
+	cuponEncontrado := false
+	for rows.Next() {
+		var item promocionVigente
+		err := rows.Scan(&item.Id, &item.Nombre, &item.Tipo, &item.Alcance, &item.Valor, &item.CantidadCompra, &item.CantidadGratis,
+			&item.ProductoId, &item.CategoriaId, &item.CodigoCupon, &item.UsoMaximo, &item.Usos)
+		if err != nil {
+			log.Println("Error al escanear promoción vigente:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if item.CodigoCupon != nil {
+			cuponEncontrado = true
+			if item.UsoMaximo != nil && item.Usos >= *item.UsoMaximo {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El cupón %s alcanzó su límite de usos.", *item.CodigoCupon))
+			}
+		}
+		list = append(list, item)
+	}
+	if rows.Err() != nil {
+		log.Println("Error en iteración de promociones vigentes:", rows.Err())
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	if cupon != nil && !cuponEncontrado {
+		return nil, datatype.NewBadRequestError(fmt.Sprintf("El cupón %s no es válido o no está vigente.", *cupon))
+	}
+	return list, nil
+}
+
+// aplicaA indica si la promoción alcanza a un producto de la venta
+func (p promocionVigente) aplicaA(productoId int, categoriaId *int) bool {
+	switch p.Alcance {
+	case domain.AlcancePromocionTodos:
+		return true
+	case domain.AlcancePromocionProducto:
+		return p.ProductoId != nil && *p.ProductoId == productoId
+	case domain.AlcancePromocionCategoria:
+		return p.CategoriaId != nil && categoriaId != nil && *p.CategoriaId == *categoriaId
+	}
+	return false
+}
+
+// calcularDescuento obtiene el descuento que la promoción otorga sobre un importe (precio unitario x cantidad)
+func (p promocionVigente) calcularDescuento(precioUnitario float64, cantidad int64) float64 {
+	subtotal := precioUnitario * float64(cantidad)
+	var descuento float64
+	switch p.Tipo {
+	case domain.PromocionPorcentaje:
+		descuento = subtotal * p.Valor / 100
+	case domain.PromocionMontoFijo:
+		// El monto fijo se aplica por unidad vendida
+		descuento = p.Valor * float64(cantidad)
+	case domain.PromocionCompraXLlevaY:
+		if p.CantidadCompra == nil || p.CantidadGratis == nil {
+			return 0
+		}
+		grupo := *p.CantidadCompra + *p.CantidadGratis
+		gratis := (cantidad / grupo) * *p.CantidadGratis
+		descuento = precioUnitario * float64(gratis)
+	}
+	descuento = math.Round(math.Min(descuento, subtotal)*100) / 100
+	return descuento
+}
+
+// mejorPromocion elige la promoción con mayor descuento para una línea; las promociones no se acumulan entre sí
+func mejorPromocion(promociones []promocionVigente, productoId int, categoriaId *int, precioUnitario float64, cantidad int64) (*promocionVigente, float64) {
+	var mejor *promocionVigente
+	var mejorDescuento float64
+	for i := range promociones {
+		if !promociones[i].aplicaA(productoId, categoriaId) {
+			continue
+		}
+		descuento := promociones[i].calcularDescuento(precioUnitario, cantidad)
+		if descuento > mejorDescuento {
+			mejor = &promociones[i]
+			mejorDescuento = descuento
+		}
+	}
+	return mejor, mejorDescuento
+}
+
+// mejorPromocionTiempo elige la promoción de tiempo de sala (happy hour) con mayor descuento
+func mejorPromocionTiempo(promociones []promocionVigente, costoTiempo float64) (*promocionVigente, float64) {
+	var mejor *promocionVigente
+	var mejorDescuento float64
+	if costoTiempo <= 0 {
+		return nil, 0
+	}
+	for i := range promociones {
+		if promociones[i].Alcance != domain.AlcancePromocionTiempoSala {
+			continue
+		}
+		descuento := promociones[i].calcularDescuento(costoTiempo, 1)
+		if descuento > mejorDescuento {
+			mejor = &promociones[i]
+			mejorDescuento = descuento
+		}
+	}
+	return mejor, mejorDescuento
+}
+
+func NewPromocionRepository(pool *pgxpool.Pool) *PromocionRepository {
+	return &PromocionRepository{pool: pool}
+}
+
+var _ port.PromocionRepository = (*PromocionRepository)(nil)
@@ -17,7 +17,7 @@ type ProveedorRepository struct {
 }
 
 func (p ProveedorRepository) ListarProveedores(ctx context.Context, filtros map[string]string) (*[]domain.Proveedor, error) {
-	query := `SELECT p.id,p.nombre,p.estado,p.email,p.celular,p.telefono,p.creado_en,p.actualizado_en,p.eliminado_en FROM proveedor p`
+	query := `SELECT p.id,p.nombre,p.estado,p.email,p.celular,p.telefono,p.dias_entrega,p.creado_en,p.actualizado_en,p.eliminado_en FROM proveedor p`
 	rows, err := p.pool.Query(ctx, query)
 	if err != nil {
 		return nil, datatype.NewInternalServerErrorGeneric()
@@ -26,7 +26,7 @@ func (p ProveedorRepository) ListarProveedores(ctx context.Context, filtros map[
 	list := make([]domain.Proveedor, 0)
 	for rows.Next() {
 		var item domain.Proveedor
-		err = rows.Scan(&item.Id, &item.Nombre, &item.Estado, &item.Email, &item.Celular, &item.Telefono, &item.CreadoEn, &item.ActualizadoEn, &item.EliminadoEn)
+		err = rows.Scan(&item.Id, &item.Nombre, &item.Estado, &item.Email, &item.Celular, &item.Telefono, &item.DiasEntrega, &item.CreadoEn, &item.ActualizadoEn, &item.EliminadoEn)
 		if err != nil {
 			log.Println("Error scanning rows:", err)
 			return nil, datatype.NewInternalServerErrorGeneric()
@@ -37,10 +37,10 @@ func (p ProveedorRepository) ListarProveedores(ctx context.Context, filtros map[
 }
 
 func (p ProveedorRepository) ObtenerProveedorById(ctx context.Context, id *int) (*domain.Proveedor, error) {
-	query := `SELECT p.id,p.nombre,p.estado,p.email,p.celular,p.telefono,p.creado_en,p.actualizado_en,p.eliminado_en FROM proveedor p WHERE p.id=$1 LIMIT 1`
+	query := `SELECT p.id,p.nombre,p.estado,p.email,p.celular,p.telefono,p.dias_entrega,p.creado_en,p.actualizado_en,p.eliminado_en FROM proveedor p WHERE p.id=$1 LIMIT 1`
 	var proveedor domain.Proveedor
 	err := p.pool.QueryRow(ctx, query, *id).
-		Scan(&proveedor.Id, &proveedor.Nombre, &proveedor.Estado, &proveedor.Email, &proveedor.Celular, &proveedor.Telefono, &proveedor.CreadoEn, &proveedor.ActualizadoEn, &proveedor.EliminadoEn)
+		Scan(&proveedor.Id, &proveedor.Nombre, &proveedor.Estado, &proveedor.Email, &proveedor.Celular, &proveedor.Telefono, &proveedor.DiasEntrega, &proveedor.CreadoEn, &proveedor.ActualizadoEn, &proveedor.EliminadoEn)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, datatype.NewNotFoundError("Proveedor no encontrado")
@@ -65,8 +65,8 @@ func (p ProveedorRepository) RegistrarProveedor(ctx context.Context, request *do
 		}
 	}()
 	var proveedorId int
-	query := `INSERT INTO proveedor(nombre, estado,email,celular,telefono) VALUES ($1,$2,$3,$4,$5) RETURNING id`
-	err = tx.QueryRow(ctx, query, request.Nombre, request.Estado, request.Email, request.Celular, request.Telefono).Scan(&proveedorId)
+	query := `INSERT INTO proveedor(nombre, estado,email,celular,telefono,dias_entrega) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id`
+	err = tx.QueryRow(ctx, query, request.Nombre, request.Estado, request.Email, request.Celular, request.Telefono, request.DiasEntrega).Scan(&proveedorId)
 	if err != nil {
 		log.Println("Error al insertar proveedor:", err)
 		return nil, datatype.NewInternalServerErrorGeneric()
@@ -96,8 +96,8 @@ func (p ProveedorRepository) ModificarProveedor(ctx context.Context, id *int, re
 		}
 	}()
 
-	query := `UPDATE proveedor SET nombre=$1,estado=$2,email=$3,celular=$4,telefono=$5,actualizado_en=now() WHERE id=$6`
-	ct, err := tx.Exec(ctx, query, request.Nombre, request.Estado, request.Email, request.Celular, request.Telefono, *id)
+	query := `UPDATE proveedor SET nombre=$1,estado=$2,email=$3,celular=$4,telefono=$5,dias_entrega=$6,actualizado_en=now() WHERE id=$7`
+	ct, err := tx.Exec(ctx, query, request.Nombre, request.Estado, request.Email, request.Celular, request.Telefono, request.DiasEntrega, *id)
 	if err != nil {
 		log.Println("Error al modificar proveedor:", err)
 		return datatype.NewInternalServerErrorGeneric()
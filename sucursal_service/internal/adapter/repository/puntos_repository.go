@@ -0,0 +1,621 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PuntosRepository struct {
+	pool *pgxpool.Pool
+}
+
+// programaPuntosActivo son las tasas de la sucursal usadas al acumular y canjear
+type programaPuntosActivo struct {
+	PuntosPorBs         float64
+	PuntosPorHora       float64
+	ValorPunto          float64
+	PuntosPorHoraGratis int
+	DiasVigencia        int
+}
+
+func (p PuntosRepository) ObtenerProgramaPuntos(ctx context.Context, sucursalId *int) (*domain.ProgramaPuntos, error) {
+	query := `
+SELECT
+    json_build_object(
+       'id', s.id,
+       'nombre', s.nombre,
+       'estado', s.estado,
+       'creadoEn', s.creado_en
+    ) AS sucursal,
+    pp.puntos_por_bs,
+    pp.puntos_por_hora,
+    pp.valor_punto,
+    pp.puntos_por_hora_gratis,
+    pp.dias_vigencia,
+    pp.estado,
+    COALESCE((
+        SELECT json_agg(json_build_object(
+            'categoria', json_build_object('id', cp.id, 'nombre', cp.nombre, 'descripcion', cp.descripcion, 'estado', cp.estado),
+            'puntosPorBs', ppc.puntos_por_bs
+        ) ORDER BY cp.nombre)
+        FROM programa_puntos_categoria ppc
+        JOIN categoria_producto cp ON ppc.categoria_id = cp.id
+        WHERE ppc.sucursal_id = pp.sucursal_id
+    ), '[]'::json) AS categorias,
+    pp.actualizado_en
+FROM programa_puntos pp
+JOIN sucursal s ON pp.sucursal_id = s.id
+WHERE pp.sucursal_id = $1`
+	var programa domain.ProgramaPuntos
+	err := p.pool.QueryRow(ctx, query, *sucursalId).Scan(&programa.Sucursal, &programa.PuntosPorBs, &programa.PuntosPorHora, &programa.ValorPunto,
+		&programa.PuntosPorHoraGratis, &programa.DiasVigencia, &programa.Estado, &programa.Categorias, &programa.ActualizadoEn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("La sucursal no tiene programa de puntos configurado")
+		}
+		log.Println("Error al obtener programa de puntos:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &programa, nil
+}
+
+func (p PuntosRepository) ModificarProgramaPuntos(ctx context.Context, sucursalId *int, request *domain.ProgramaPuntosRequest) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	query := `
+        INSERT INTO programa_puntos (sucursal_id, puntos_por_bs, puntos_por_hora, valor_punto, puntos_por_hora_gratis, dias_vigencia, estado)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (sucursal_id) DO UPDATE SET
+            puntos_por_bs = EXCLUDED.puntos_por_bs,
+            puntos_por_hora = EXCLUDED.puntos_por_hora,
+            valor_punto = EXCLUDED.valor_punto,
+            puntos_por_hora_gratis = EXCLUDED.puntos_por_hora_gratis,
+            dias_vigencia = EXCLUDED.dias_vigencia,
+            estado = EXCLUDED.estado,
+            actualizado_en = NOW()`
+	_, err = tx.Exec(ctx, query, *sucursalId, request.PuntosPorBs, request.PuntosPorHora, request.ValorPunto, request.PuntosPorHoraGratis, request.DiasVigencia, request.Estado)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return datatype.NewBadRequestError("La sucursal especificada no existe")
+		}
+		log.Println("Error al guardar programa de puntos:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	// Las tasas por categoría se reemplazan completas
+	_, err = tx.Exec(ctx, `DELETE FROM programa_puntos_categoria WHERE sucursal_id = $1`, *sucursalId)
+	if err != nil {
+		log.Println("Error al eliminar tasas por categoría:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if len(request.Categorias) > 0 {
+		var filas [][]interface{}
+		for _, c := range request.Categorias {
+			filas = append(filas, []interface{}{*sucursalId, c.CategoriaId, c.PuntosPorBs})
+		}
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"programa_puntos_categoria"},
+			[]string{"sucursal_id", "categoria_id", "puntos_por_bs"},
+			pgx.CopyFromRows(filas))
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return datatype.NewBadRequestError("Una de las categorías indicadas no existe")
+			}
+			log.Println("Error al registrar tasas por categoría:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return nil
+}
+
+func (p PuntosRepository) ObtenerPuntosCliente(ctx context.Context, clienteId *int64, filtros map[string]string) (*domain.PuntosCliente, error) {
+	puntos := domain.PuntosCliente{ClienteId: *clienteId, Movimientos: make([]domain.MovimientoPuntos, 0)}
+
+	// El saldo es la suma del libro; lo próximo a vencer no puede superar el saldo
+	querySaldo := `
+        SELECT COALESCE(SUM(puntos), 0),
+               COALESCE(SUM(puntos_disponibles) FILTER (WHERE vence_en > NOW() AND vence_en <= NOW() + INTERVAL '30 days'), 0)
+        FROM movimiento_puntos
+        WHERE cliente_id = $1`
+	if err := p.pool.QueryRow(ctx, querySaldo, *clienteId).Scan(&puntos.Saldo, &puntos.PorVencer); err != nil {
+		log.Println("Error al obtener saldo de puntos:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	puntos.PorVencer = min(puntos.PorVencer, max(puntos.Saldo, 0))
+
+	var filters = []string{"m.cliente_id = $1"}
+	var args = []interface{}{*clienteId}
+	var j = 2
+	if val := filtros["sucursalId"]; val != "" {
+		sucursalId, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("m.sucursal_id = $%d", j))
+		args = append(args, sucursalId)
+		j++
+	}
+	if fechaInicio := filtros["fechaInicio"]; fechaInicio != "" {
+		filters = append(filters, fmt.Sprintf("m.creado_en >= $%d", j))
+		args = append(args, fechaInicio)
+		j++
+	}
+	if fechaFin := filtros["fechaFin"]; fechaFin != "" {
+		filters = append(filters, fmt.Sprintf("m.creado_en <= $%d", j))
+		args = append(args, fechaFin)
+	}
+
+	query := `
+        SELECT m.id, m.tipo, m.puntos,
+               json_build_object('id', s.id, 'nombre', s.nombre, 'estado', s.estado, 'creadoEn', s.creado_en),
+               m.venta_id, m.uso_sala_id, m.vence_en, m.creado_en
+        FROM movimiento_puntos m
+        JOIN sucursal s ON m.sucursal_id = s.id
+        WHERE ` + strings.Join(filters, " AND ") + `
+        ORDER BY m.creado_en DESC, m.id DESC`
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar movimientos de puntos:", err)
+		return nil, datatype.NewBadRequestError("Los filtros de fecha no son válidos")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m domain.MovimientoPuntos
+		if err := rows.Scan(&m.Id, &m.Tipo, &m.Puntos, &m.Sucursal, &m.VentaId, &m.UsoSalaId, &m.VenceEn, &m.CreadoEn); err != nil {
+			log.Println("Error al escanear movimiento de puntos:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		puntos.Movimientos = append(puntos.Movimientos, m)
+	}
+	return &puntos, nil
+}
+
+func (p PuntosRepository) VencerPuntos(ctx context.Context) (int64, error) {
+	type acumulacionVencida struct {
+		Id          int
+		ClienteId   int64
+		SucursalId  int
+		Disponibles int
+	}
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	query := `
+        SELECT id, cliente_id, sucursal_id, puntos_disponibles
+        FROM movimiento_puntos
+        WHERE puntos_disponibles > 0 AND vence_en <= NOW()
+        ORDER BY cliente_id, vence_en, id
+        FOR UPDATE`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		log.Println("Error al obtener puntos vencidos:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	var vencidas []acumulacionVencida
+	for rows.Next() {
+		var a acumulacionVencida
+		if err := rows.Scan(&a.Id, &a.ClienteId, &a.SucursalId, &a.Disponibles); err != nil {
+			rows.Close()
+			log.Println("Error al escanear puntos vencidos:", err)
+			return 0, datatype.NewInternalServerErrorGeneric()
+		}
+		vencidas = append(vencidas, a)
+	}
+	rows.Close()
+
+	var totalVencidos int64
+	for _, a := range vencidas {
+		_, err = tx.Exec(ctx, `UPDATE movimiento_puntos SET puntos_disponibles = 0 WHERE id = $1`, a.Id)
+		if err != nil {
+			log.Println("Error al marcar puntos vencidos:", err)
+			return 0, datatype.NewInternalServerErrorGeneric()
+		}
+		// Nunca se vence más de lo que el cliente tiene en saldo (p. ej. tras reversos de puntos ya gastados)
+		saldo, err := saldoPuntosCliente(ctx, tx, a.ClienteId)
+		if err != nil {
+			return 0, err
+		}
+		vencer := min(a.Disponibles, max(saldo, 0))
+		if vencer == 0 {
+			continue
+		}
+		if err = registrarMovimientoPuntos(ctx, tx, a.ClienteId, a.SucursalId, domain.MovimientoPuntosVencimiento, -vencer, 0, nil, nil, nil); err != nil {
+			return 0, err
+		}
+		totalVencidos += int64(vencer)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar vencimiento de puntos:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return totalVencidos, nil
+}
+
+// obtenerProgramaPuntosActivo devuelve nil si la sucursal no tiene un programa activo.
+func obtenerProgramaPuntosActivo(ctx context.Context, tx pgx.Tx, sucursalId int) (*programaPuntosActivo, error) {
+	var programa programaPuntosActivo
+	query := `
+        SELECT puntos_por_bs, puntos_por_hora, valor_punto, puntos_por_hora_gratis, dias_vigencia
+        FROM programa_puntos
+        WHERE sucursal_id = $1 AND estado = 'Activo'`
+	err := tx.QueryRow(ctx, query, sucursalId).Scan(&programa.PuntosPorBs, &programa.PuntosPorHora, &programa.ValorPunto, &programa.PuntosPorHoraGratis, &programa.DiasVigencia)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		log.Println("Error al obtener programa de puntos:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &programa, nil
+}
+
+// bloquearPuntosCliente serializa los canjes concurrentes del mismo cliente y devuelve su saldo.
+func bloquearPuntosCliente(ctx context.Context, tx pgx.Tx, clienteId int64) (int, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `SELECT id FROM cliente WHERE id = $1 FOR UPDATE`, clienteId).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, datatype.NewBadRequestError("El cliente no existe.")
+		}
+		log.Println("Error al bloquear cliente:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	return saldoPuntosCliente(ctx, tx, clienteId)
+}
+
+func saldoPuntosCliente(ctx context.Context, tx pgx.Tx, clienteId int64) (int, error) {
+	var saldo int
+	err := tx.QueryRow(ctx, `SELECT COALESCE(SUM(puntos), 0) FROM movimiento_puntos WHERE cliente_id = $1`, clienteId).Scan(&saldo)
+	if err != nil {
+		log.Println("Error al obtener saldo de puntos:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	return saldo, nil
+}
+
+// registrarMovimientoPuntos agrega un asiento al libro. Los asientos positivos quedan disponibles para
+// canje y vencen a los diasVigencia días (0 = sin vencimiento).
+func registrarMovimientoPuntos(ctx context.Context, tx pgx.Tx, clienteId int64, sucursalId int, tipo string, puntos int, diasVigencia int, ventaId *int, usoSalaId *int64, usuarioId *int) error {
+	query := `
+        INSERT INTO movimiento_puntos (cliente_id, sucursal_id, tipo, puntos, puntos_disponibles, venta_id, uso_sala_id, usuario_id, vence_en)
+        VALUES ($1, $2, $3, $4, GREATEST($4, 0), $5, $6, $7,
+                CASE WHEN $4 > 0 AND $8::int > 0 THEN NOW() + $8::int * INTERVAL '1 day' END)`
+	_, err := tx.Exec(ctx, query, clienteId, sucursalId, tipo, puntos, ventaId, usoSalaId, usuarioId, diasVigencia)
+	if err != nil {
+		log.Println("Error al registrar movimiento de puntos:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// consumirPuntosDisponibles descuenta de las acumulaciones vigentes, primero las que vencen antes.
+func consumirPuntosDisponibles(ctx context.Context, tx pgx.Tx, clienteId int64, puntos int) error {
+	query := `
+        SELECT id, puntos_disponibles
+        FROM movimiento_puntos
+        WHERE cliente_id = $1 AND puntos_disponibles > 0 AND (vence_en IS NULL OR vence_en > NOW())
+        ORDER BY vence_en NULLS LAST, id
+        FOR UPDATE`
+	rows, err := tx.Query(ctx, query, clienteId)
+	if err != nil {
+		log.Println("Error al obtener puntos disponibles:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	type disponible struct{ Id, Puntos int }
+	var disponibles []disponible
+	for rows.Next() {
+		var d disponible
+		if err := rows.Scan(&d.Id, &d.Puntos); err != nil {
+			rows.Close()
+			log.Println("Error al escanear puntos disponibles:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		disponibles = append(disponibles, d)
+	}
+	rows.Close()
+
+	for _, d := range disponibles {
+		if puntos == 0 {
+			break
+		}
+		tomar := min(puntos, d.Puntos)
+		_, err = tx.Exec(ctx, `UPDATE movimiento_puntos SET puntos_disponibles = puntos_disponibles - $1 WHERE id = $2`, tomar, d.Id)
+		if err != nil {
+			log.Println("Error al consumir puntos:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		puntos -= tomar
+	}
+	return nil
+}
+
+// canjearPuntos valida el saldo del cliente y registra el canje. El saldo canjeable son las acumulaciones
+// vigentes: las vencidas que el barrido aún no retiró no cuentan, porque consumirPuntosDisponibles no las toma.
+func canjearPuntos(ctx context.Context, tx pgx.Tx, clienteId int64, sucursalId int, puntos int, ventaId *int, usoSalaId *int64, usuarioId *int) error {
+	saldo, err := bloquearPuntosCliente(ctx, tx, clienteId)
+	if err != nil {
+		return err
+	}
+	var vigentes int
+	queryVigentes := `
+        SELECT COALESCE(SUM(puntos_disponibles), 0)
+        FROM movimiento_puntos
+        WHERE cliente_id = $1 AND puntos_disponibles > 0 AND (vence_en IS NULL OR vence_en > NOW())`
+	if err = tx.QueryRow(ctx, queryVigentes, clienteId).Scan(&vigentes); err != nil {
+		log.Println("Error al obtener puntos vigentes:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	saldo = min(saldo, vigentes)
+	if saldo < puntos {
+		return datatype.NewBadRequestError(fmt.Sprintf("Puntos insuficientes. Requeridos: %d, disponibles: %d", puntos, max(saldo, 0)))
+	}
+	if err = consumirPuntosDisponibles(ctx, tx, clienteId, puntos); err != nil {
+		return err
+	}
+	return registrarMovimientoPuntos(ctx, tx, clienteId, sucursalId, domain.MovimientoPuntosCanje, -puntos, 0, ventaId, usoSalaId, usuarioId)
+}
+
+// pagarConPuntos cobra un pago de la venta con puntos del cliente al valor configurado en la sucursal.
+func pagarConPuntos(ctx context.Context, tx pgx.Tx, clienteId *int64, sucursalId int, monto float64, ventaId, usuarioId int) error {
+	if clienteId == nil {
+		return datatype.NewBadRequestError("La venta no tiene cliente; no se puede pagar con puntos.")
+	}
+	programa, err := obtenerProgramaPuntosActivo(ctx, tx, sucursalId)
+	if err != nil {
+		return err
+	}
+	if programa == nil || programa.ValorPunto <= 0 {
+		return datatype.NewBadRequestError("La sucursal no acepta pagos con puntos.")
+	}
+	puntos := int(math.Ceil(monto/programa.ValorPunto - 0.000001))
+	return canjearPuntos(ctx, tx, *clienteId, sucursalId, puntos, &ventaId, nil, &usuarioId)
+}
+
+// puntosATiempo convierte puntos en segundos de sala gratis según la sucursal de la sala.
+func puntosATiempo(ctx context.Context, tx pgx.Tx, sucursalId int, puntos int) (int64, error) {
+	programa, err := obtenerProgramaPuntosActivo(ctx, tx, sucursalId)
+	if err != nil {
+		return 0, err
+	}
+	if programa == nil || programa.PuntosPorHoraGratis <= 0 {
+		return 0, datatype.NewBadRequestError("La sucursal no permite canjear puntos por tiempo de sala.")
+	}
+	return int64(puntos) * 3600 / int64(programa.PuntosPorHoraGratis), nil
+}
+
+// acumularPuntosVenta otorga los puntos de una venta pagada: productos según la tasa de su categoría
+// (o la general) y horas de sala proporcionales al costo de tiempo de la venta. Lo pagado con puntos no acumula.
+func acumularPuntosVenta(ctx context.Context, tx pgx.Tx, ventaId int, clienteId *int64, sucursalId, usuarioId int) error {
+	if clienteId == nil {
+		return nil
+	}
+	programa, err := obtenerProgramaPuntosActivo(ctx, tx, sucursalId)
+	if err != nil || programa == nil {
+		return err
+	}
+
+	var puntosProductos, pagadoConPuntos, total, horas float64
+	query := `
+        SELECT
+            COALESCE((
+                SELECT SUM((dv.precio_venta * dv.cantidad - dv.descuento) * COALESCE(ppc.puntos_por_bs, $2))
+                FROM detalle_venta dv
+                JOIN producto p ON dv.producto_id = p.id
+                LEFT JOIN programa_puntos_categoria ppc ON ppc.sucursal_id = v.sucursal_id AND ppc.categoria_id = p.categoria_id
+                WHERE dv.venta_id = v.id
+            ), 0),
+            COALESCE((
+                SELECT SUM(vp.monto)
+                FROM venta_pago vp
+                JOIN metodo_pago mp ON vp.metodo_pago_id = mp.id
+                WHERE vp.venta_id = v.id AND mp.codigo = $3
+            ), 0),
+            v.total,
+            COALESCE((
+                SELECT GREATEST(EXTRACT(EPOCH FROM (COALESCE(us.fin, NOW()) - us.inicio - COALESCE(us.duracion_pausa, INTERVAL '0'))) - us.segundos_cortesia, 0) / 3600
+                       * v.costo_tiempo_venta / NULLIF(us.costo_tiempo, 0)
+                FROM uso_sala us
+                WHERE us.id = v.uso_sala_id
+            ), 0)
+        FROM venta v
+        WHERE v.id = $1`
+	err = tx.QueryRow(ctx, query, ventaId, programa.PuntosPorBs, domain.MetodoPagoPuntos).Scan(&puntosProductos, &pagadoConPuntos, &total, &horas)
+	if err != nil {
+		log.Println("Error al calcular puntos de la venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	factor := 1.0
+	if total > 0 {
+		factor = math.Max(total-pagadoConPuntos, 0) / total
+	}
+	puntos := int(math.Floor((puntosProductos + horas*programa.PuntosPorHora) * factor))
+	if puntos <= 0 {
+		return nil
+	}
+	return registrarMovimientoPuntos(ctx, tx, *clienteId, sucursalId, domain.MovimientoPuntosAcumulacion, puntos, programa.DiasVigencia, &ventaId, nil, &usuarioId)
+}
+
+// revertirPuntosVenta quita los puntos ganados con la venta y devuelve los canjeados como pago.
+func revertirPuntosVenta(ctx context.Context, tx pgx.Tx, ventaId int, anuladoPor int) error {
+	type puntosVenta struct {
+		ClienteId  int64
+		SucursalId int
+		Tipo       string
+		Puntos     int
+	}
+	query := `
+        SELECT cliente_id, sucursal_id, tipo, SUM(puntos)
+        FROM movimiento_puntos
+        WHERE venta_id = $1 AND tipo IN ($2, $3)
+        GROUP BY cliente_id, sucursal_id, tipo`
+	rows, err := tx.Query(ctx, query, ventaId, domain.MovimientoPuntosAcumulacion, domain.MovimientoPuntosCanje)
+	if err != nil {
+		log.Println("Error al obtener puntos de la venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var movimientos []puntosVenta
+	for rows.Next() {
+		var m puntosVenta
+		if err := rows.Scan(&m.ClienteId, &m.SucursalId, &m.Tipo, &m.Puntos); err != nil {
+			rows.Close()
+			log.Println("Error al escanear puntos de la venta:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		movimientos = append(movimientos, m)
+	}
+	rows.Close()
+
+	for _, m := range movimientos {
+		if _, err := bloquearPuntosCliente(ctx, tx, m.ClienteId); err != nil {
+			return err
+		}
+		switch m.Tipo {
+		case domain.MovimientoPuntosAcumulacion:
+			// Se retira primero lo que queda de la propia acumulación y el resto de otras vigentes;
+			// si el cliente ya gastó los puntos el saldo puede quedar negativo hasta nuevas compras
+			queryRetirar := `
+                WITH previo AS (
+                    SELECT id, puntos_disponibles FROM movimiento_puntos
+                    WHERE venta_id = $1 AND tipo = $2 FOR UPDATE
+                )
+                UPDATE movimiento_puntos m SET puntos_disponibles = 0
+                FROM previo
+                WHERE m.id = previo.id
+                RETURNING previo.puntos_disponibles`
+			retirados, err := tx.Query(ctx, queryRetirar, ventaId, domain.MovimientoPuntosAcumulacion)
+			if err != nil {
+				log.Println("Error al retirar puntos de la venta:", err)
+				return datatype.NewInternalServerErrorGeneric()
+			}
+			restantes := m.Puntos
+			for retirados.Next() {
+				var d int
+				if err := retirados.Scan(&d); err != nil {
+					retirados.Close()
+					log.Println("Error al escanear puntos retirados:", err)
+					return datatype.NewInternalServerErrorGeneric()
+				}
+				restantes -= d
+			}
+			retirados.Close()
+			if restantes > 0 {
+				if err := consumirPuntosDisponibles(ctx, tx, m.ClienteId, restantes); err != nil {
+					return err
+				}
+			}
+			if err := registrarMovimientoPuntos(ctx, tx, m.ClienteId, m.SucursalId, domain.MovimientoPuntosReverso, -m.Puntos, 0, &ventaId, nil, &anuladoPor); err != nil {
+				return err
+			}
+		case domain.MovimientoPuntosCanje:
+			if err := devolverPuntosCanjeados(ctx, tx, m.ClienteId, m.SucursalId, -m.Puntos, &ventaId, nil, &anuladoPor); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// revertirPuntosUsoSala devuelve los puntos canjeados por tiempo gratis de un uso de sala cancelado.
+func revertirPuntosUsoSala(ctx context.Context, tx pgx.Tx, usoSalaId int64, usuarioId *int) error {
+	type canjeUso struct {
+		ClienteId  int64
+		SucursalId int
+		Puntos     int
+	}
+	query := `
+        SELECT cliente_id, sucursal_id, SUM(puntos)
+        FROM movimiento_puntos
+        WHERE uso_sala_id = $1 AND tipo = $2
+        GROUP BY cliente_id, sucursal_id`
+	rows, err := tx.Query(ctx, query, usoSalaId, domain.MovimientoPuntosCanje)
+	if err != nil {
+		log.Println("Error al obtener puntos canjeados del uso de sala:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var canjes []canjeUso
+	for rows.Next() {
+		var c canjeUso
+		if err := rows.Scan(&c.ClienteId, &c.SucursalId, &c.Puntos); err != nil {
+			rows.Close()
+			log.Println("Error al escanear puntos canjeados:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		canjes = append(canjes, c)
+	}
+	rows.Close()
+
+	for _, c := range canjes {
+		if _, err := bloquearPuntosCliente(ctx, tx, c.ClienteId); err != nil {
+			return err
+		}
+		if err := devolverPuntosCanjeados(ctx, tx, c.ClienteId, c.SucursalId, -c.Puntos, nil, &usoSalaId, usuarioId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// devolverPuntosCanjeados reacredita un canje como reverso con la vigencia actual del programa de la sucursal.
+func devolverPuntosCanjeados(ctx context.Context, tx pgx.Tx, clienteId int64, sucursalId int, puntos int, ventaId *int, usoSalaId *int64, usuarioId *int) error {
+	diasVigencia := 0
+	programa, err := obtenerProgramaPuntosActivo(ctx, tx, sucursalId)
+	if err != nil {
+		return err
+	}
+	if programa != nil {
+		diasVigencia = programa.DiasVigencia
+	}
+	return registrarMovimientoPuntos(ctx, tx, clienteId, sucursalId, domain.MovimientoPuntosReverso, puntos, diasVigencia, ventaId, usoSalaId, usuarioId)
+}
+
+func NewPuntosRepository(pool *pgxpool.Pool) *PuntosRepository {
+	return &PuntosRepository{pool: pool}
+}
+
+var _ port.PuntosRepository = (*PuntosRepository)(nil)
@@ -9,6 +9,7 @@ import (
 	"multiroom/sucursal-service/internal/core/domain"
 	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
 	"strconv"
 	"strings"
 	"time"
@@ -560,18 +561,41 @@ func (s SalaRepository) CancelarSala(ctx context.Context, salaId *int) error {
             actualizado_en = NOW()
         WHERE sala_id = $1
           AND estado IN ('En uso','Pausado')
+        RETURNING id
     `
 
-	cmdTag, err := tx.Exec(ctx, query, *salaId)
+	rows, err := tx.Query(ctx, query, *salaId)
 	if err != nil {
 		log.Println("Error al cancelar uso de sala:", err)
 		return datatype.NewInternalServerErrorGeneric()
 	}
+	var usoIds []int64
+	for rows.Next() {
+		var usoId int64
+		if err := rows.Scan(&usoId); err != nil {
+			rows.Close()
+			log.Println("Error al escanear uso de sala cancelado:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		usoIds = append(usoIds, usoId)
+	}
+	rows.Close()
 
-	if cmdTag.RowsAffected() == 0 {
+	if len(usoIds) == 0 {
 		return datatype.NewBadRequestError("No se pudo cancelar la sala: ya está finalizada o no existe")
 	}
 
+	// Los puntos canjeados por tiempo gratis vuelven al cliente, a nombre de quien cancela
+	var canceladoPor *int
+	if uid, ok := ctx.Value(util.ContextUserIdKey).(int); ok {
+		canceladoPor = &uid
+	}
+	for _, usoId := range usoIds {
+		if err = revertirPuntosUsoSala(ctx, tx, usoId, canceladoPor); err != nil {
+			return err
+		}
+	}
+
 	var dispositivoId int
 	query = `SELECT s.dispositivo_id FROM sala s WHERE s.id =$1 LIMIT 1`
 	err = tx.QueryRow(ctx, query, *salaId).Scan(&dispositivoId)
@@ -624,18 +648,44 @@ func (s SalaRepository) AsignarTiempoUsoSala(ctx context.Context, request *domai
 		return nil, datatype.NewBadRequestError("La sala ya se encuentra en uso")
 	}
 
+	// Tiempo gratis por canje de puntos, según la tasa de la sucursal de la sala
+	var segundosCortesia int64
+	var sucursalId int
+	if request.PuntosCanje > 0 {
+		if request.ClienteId <= 0 {
+			return nil, datatype.NewBadRequestError("Se requiere un cliente para canjear puntos por tiempo.")
+		}
+		err = tx.QueryRow(ctx, `SELECT sucursal_id FROM sala WHERE id = $1`, request.SalaId).Scan(&sucursalId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, datatype.NewNotFoundError("Sala no encontrada")
+			}
+			log.Println("Error al obtener sucursal de la sala:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		segundosCortesia, err = puntosATiempo(ctx, tx, sucursalId, request.PuntosCanje)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Insertar el nuevo uso
 	insertQuery := `
-INSERT INTO uso_sala(sala_id,cliente_id,inicio,fin,estado,pausado_en,duracion_pausa,tipo)
-VALUES ($1, $2, NOW(), NOW() + ($3 * INTERVAL '1 second'), 'En uso', NULL, INTERVAL '0 second',$4)
+INSERT INTO uso_sala(sala_id,cliente_id,inicio,fin,estado,pausado_en,duracion_pausa,tipo,segundos_cortesia)
+VALUES ($1, $2, NOW(), NOW() + ($3 * INTERVAL '1 second'), 'En uso', NULL, INTERVAL '0 second',$4,$5)
 RETURNING id`
 
 	var usoId int64
-	err = tx.QueryRow(ctx, insertQuery, request.SalaId, request.ClienteId, request.TiempoUso, request.Tipo).Scan(&usoId)
+	err = tx.QueryRow(ctx, insertQuery, request.SalaId, request.ClienteId, request.TiempoUso+segundosCortesia, request.Tipo, segundosCortesia).Scan(&usoId)
 	if err != nil {
 		log.Println("Error al insertar uso_sala:", err)
 		return nil, datatype.NewInternalServerErrorGeneric()
 	}
+	if request.PuntosCanje > 0 {
+		if err = canjearPuntos(ctx, tx, int64(request.ClienteId), sucursalId, request.PuntosCanje, nil, &usoId, nil); err != nil {
+			return nil, err
+		}
+	}
 	var dispositivoId int
 	query := `SELECT s.dispositivo_id FROM sala s WHERE s.id =$1 LIMIT 1`
 	err = tx.QueryRow(ctx, query, request.SalaId).Scan(&dispositivoId)
@@ -730,7 +780,16 @@ func (s SalaRepository) ModificarSala(ctx context.Context, id *int, request *dom
 		}
 	}()
 
-	query := `UPDATE sala SET nombre=$1,sucursal_id=$2,dispositivo_id=$3 WHERE id=$4`
+	// Si la sala cambia de sucursal, la anterior debe quitarla de su catálogo sincronizado
+	queryBaja := `
+        INSERT INTO catalogo_baja (sucursal_id, entidad, entidad_id, eliminado_en)
+        SELECT sucursal_id, $1, id, NOW() FROM sala WHERE id = $2 AND sucursal_id <> $3`
+	if _, err = tx.Exec(ctx, queryBaja, domain.EntidadSala, *id, request.SucursalId); err != nil {
+		log.Println("Error al registrar baja de sala en el catálogo:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+
+	query := `UPDATE sala SET nombre=$1,sucursal_id=$2,dispositivo_id=$3,actualizado_en=now() WHERE id=$4`
 	ct, err := tx.Exec(ctx, query, request.Nombre, request.SucursalId, request.DispositivoId, *id)
 	if err != nil {
 		log.Println("Error al modificar sala:", err)
@@ -0,0 +1,391 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TarjetaRegaloRepository struct {
+	pool *pgxpool.Pool
+}
+
+// tarjetaPorEmitir es una línea de venta de un producto tarjeta de regalo; se emite una tarjeta por unidad
+type tarjetaPorEmitir struct {
+	ProductoId   int
+	Monto        float64
+	Cantidad     int
+	VigenciaDias int
+}
+
+// Sin caracteres ambiguos (0/O, 1/I) para facilitar el ingreso manual del código
+const alfabetoCodigoTarjeta = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const queryTarjetaRegalo = `
+SELECT
+    t.id,
+    t.codigo,
+    json_build_object(
+       'id', p.id,
+       'nombre', p.nombre,
+       'estado', p.estado,
+       'esInventariable', p.es_inventariable,
+       'creadoEn', p.creado_en,
+       'actualizadoEn', p.actualizado_en,
+       'eliminadoEn', p.eliminado_en
+    ) AS producto,
+    json_build_object(
+       'id', s.id,
+       'nombre', s.nombre,
+       'estado', s.estado,
+       'creadoEn', s.creado_en
+    ) AS sucursal,
+    t.venta_id,
+    t.monto_inicial,
+    t.saldo,
+    (CASE WHEN t.estado = 'Activa' AND t.vence_en <= NOW() THEN 'Vencida' ELSE t.estado END) AS estado,
+    t.vence_en,
+    t.creado_en
+FROM tarjeta_regalo t
+JOIN producto p ON t.producto_id = p.id
+JOIN sucursal s ON t.sucursal_id = s.id`
+
+func escanearTarjetaRegalo(row pgx.Row, item *domain.TarjetaRegalo) error {
+	return row.Scan(&item.Id, &item.Codigo, &item.Producto, &item.Sucursal, &item.VentaId, &item.MontoInicial, &item.Saldo, &item.Estado, &item.VenceEn, &item.CreadoEn)
+}
+
+func (t TarjetaRegaloRepository) ListarTarjetasRegalo(ctx context.Context, filtros map[string]string) (*[]domain.TarjetaRegalo, error) {
+	var filters []string
+	var args []interface{}
+	var j = 1
+
+	if val := filtros["sucursalId"]; val != "" {
+		sucursalId, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("t.sucursal_id = $%d", j))
+		args = append(args, sucursalId)
+		j++
+	}
+	if val := filtros["ventaId"]; val != "" {
+		ventaId, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de ventaId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("t.venta_id = $%d", j))
+		args = append(args, ventaId)
+		j++
+	}
+	if val := filtros["estado"]; val != "" {
+		if val == "Vencida" {
+			filters = append(filters, "t.estado = 'Activa' AND t.vence_en <= NOW()")
+		} else {
+			filters = append(filters, fmt.Sprintf("t.estado = $%d", j))
+			args = append(args, val)
+			j++
+		}
+	}
+	if val := filtros["codigo"]; val != "" {
+		filters = append(filters, fmt.Sprintf("t.codigo ILIKE $%d", j))
+		args = append(args, "%"+val+"%")
+	}
+
+	query := queryTarjetaRegalo
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY t.creado_en DESC"
+
+	rows, err := t.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar tarjetas de regalo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.TarjetaRegalo, 0)
+	for rows.Next() {
+		var item domain.TarjetaRegalo
+		if err := escanearTarjetaRegalo(rows, &item); err != nil {
+			log.Println("Error al escanear tarjeta de regalo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func (t TarjetaRegaloRepository) ObtenerTarjetaRegaloByCodigo(ctx context.Context, codigo *string) (*domain.TarjetaRegalo, error) {
+	var tarjeta domain.TarjetaRegalo
+	err := escanearTarjetaRegalo(t.pool.QueryRow(ctx, queryTarjetaRegalo+" WHERE t.codigo = $1", *codigo), &tarjeta)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Tarjeta de regalo no encontrada")
+		}
+		log.Println("Error al obtener tarjeta de regalo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	query := `
+        SELECT m.id, m.tipo, m.monto, m.saldo_resultante, m.venta_id,
+               json_build_object('id', ua.id, 'username', ua.username),
+               m.creado_en
+        FROM movimiento_tarjeta_regalo m
+        LEFT JOIN usuario_admin ua ON m.usuario_id = ua.id
+        WHERE m.tarjeta_regalo_id = $1
+        ORDER BY m.creado_en, m.id`
+	rows, err := t.pool.Query(ctx, query, tarjeta.Id)
+	if err != nil {
+		log.Println("Error al listar movimientos de tarjeta de regalo:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	tarjeta.Movimientos = make([]domain.MovimientoTarjetaRegalo, 0)
+	for rows.Next() {
+		var m domain.MovimientoTarjetaRegalo
+		if err := rows.Scan(&m.Id, &m.Tipo, &m.Monto, &m.SaldoResultante, &m.VentaId, &m.Usuario, &m.CreadoEn); err != nil {
+			log.Println("Error al escanear movimiento de tarjeta de regalo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		tarjeta.Movimientos = append(tarjeta.Movimientos, m)
+	}
+	return &tarjeta, nil
+}
+
+// generarCodigoTarjeta produce un código con formato GC-XXXX-XXXX-XXXX
+func generarCodigoTarjeta() (string, error) {
+	var sb strings.Builder
+	sb.WriteString("GC")
+	limite := big.NewInt(int64(len(alfabetoCodigoTarjeta)))
+	for i := 0; i < 12; i++ {
+		if i%4 == 0 {
+			sb.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, limite)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(alfabetoCodigoTarjeta[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+func registrarMovimientoTarjeta(ctx context.Context, tx pgx.Tx, tarjetaId int, tipo string, monto, saldoResultante float64, ventaId *int, usuarioId int) error {
+	query := `
+        INSERT INTO movimiento_tarjeta_regalo (tarjeta_regalo_id, tipo, monto, saldo_resultante, venta_id, usuario_id)
+        VALUES ($1, $2, $3, $4, $5, $6)`
+	if _, err := tx.Exec(ctx, query, tarjetaId, tipo, monto, saldoResultante, ventaId, usuarioId); err != nil {
+		log.Println("Error al registrar movimiento de tarjeta de regalo:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// emitirTarjetasRegalo crea las tarjetas vendidas en estado Pendiente; se activan al pagar la venta.
+func emitirTarjetasRegalo(ctx context.Context, tx pgx.Tx, ventaId, sucursalId, usuarioId int, tarjetas []tarjetaPorEmitir) error {
+	query := `
+        INSERT INTO tarjeta_regalo (codigo, producto_id, sucursal_id, venta_id, monto_inicial, saldo, vigencia_dias, estado)
+        VALUES ($1, $2, $3, $4, $5, $5, $6, $7)
+        ON CONFLICT (codigo) DO NOTHING
+        RETURNING id`
+	for _, t := range tarjetas {
+		for range t.Cantidad {
+			var tarjetaId int
+			// Reintenta ante la improbable colisión de códigos
+			for intento := 0; ; intento++ {
+				codigo, err := generarCodigoTarjeta()
+				if err != nil {
+					log.Println("Error al generar código de tarjeta de regalo:", err)
+					return datatype.NewInternalServerErrorGeneric()
+				}
+				err = tx.QueryRow(ctx, query, codigo, t.ProductoId, sucursalId, ventaId, t.Monto, t.VigenciaDias, domain.TarjetaRegaloPendiente).Scan(&tarjetaId)
+				if err == nil {
+					break
+				}
+				if !errors.Is(err, pgx.ErrNoRows) || intento >= 5 {
+					log.Println("Error al emitir tarjeta de regalo:", err)
+					return datatype.NewInternalServerErrorGeneric()
+				}
+			}
+			if err := registrarMovimientoTarjeta(ctx, tx, tarjetaId, domain.MovimientoTarjetaEmision, t.Monto, t.Monto, &ventaId, usuarioId); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// activarTarjetasRegalo habilita las tarjetas emitidas por la venta e inicia su vigencia.
+func activarTarjetasRegalo(ctx context.Context, tx pgx.Tx, ventaId int) error {
+	query := `
+        UPDATE tarjeta_regalo
+        SET estado = $2,
+            vence_en = CASE WHEN vigencia_dias > 0 THEN NOW() + vigencia_dias * INTERVAL '1 day' END,
+            actualizado_en = NOW()
+        WHERE venta_id = $1 AND estado = $3`
+	if _, err := tx.Exec(ctx, query, ventaId, domain.TarjetaRegaloActiva, domain.TarjetaRegaloPendiente); err != nil {
+		log.Println("Error al activar tarjetas de regalo:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// canjearTarjetaRegalo descuenta el pago del saldo de la tarjeta; admite canjes parciales.
+func canjearTarjetaRegalo(ctx context.Context, tx pgx.Tx, codigo *string, monto float64, ventaId, usuarioId int) error {
+	if codigo == nil || strings.TrimSpace(*codigo) == "" {
+		return datatype.NewBadRequestError("Indique el código de la tarjeta de regalo en la referencia del pago.")
+	}
+	normalizado := strings.ToUpper(strings.TrimSpace(*codigo))
+
+	var tarjetaId int
+	var saldo float64
+	var estado string
+	var vencida bool
+	query := `
+        SELECT id, saldo, estado, COALESCE(vence_en <= NOW(), false)
+        FROM tarjeta_regalo
+        WHERE codigo = $1
+        FOR UPDATE`
+	err := tx.QueryRow(ctx, query, normalizado).Scan(&tarjetaId, &saldo, &estado, &vencida)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datatype.NewBadRequestError(fmt.Sprintf("La tarjeta de regalo %s no existe.", normalizado))
+		}
+		log.Println("Error al bloquear tarjeta de regalo:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if estado != domain.TarjetaRegaloActiva {
+		return datatype.NewBadRequestError(fmt.Sprintf("La tarjeta de regalo %s no está activa (%s).", normalizado, estado))
+	}
+	if vencida {
+		return datatype.NewBadRequestError(fmt.Sprintf("La tarjeta de regalo %s está vencida.", normalizado))
+	}
+	const epsilon = 0.001
+	if monto > saldo+epsilon {
+		return datatype.NewBadRequestError(fmt.Sprintf("Saldo insuficiente en la tarjeta de regalo %s. Disponible: %.2f", normalizado, saldo))
+	}
+
+	nuevoSaldo := saldo - monto
+	nuevoEstado := domain.TarjetaRegaloActiva
+	if nuevoSaldo < epsilon {
+		nuevoSaldo = 0
+		nuevoEstado = domain.TarjetaRegaloAgotada
+	}
+	_, err = tx.Exec(ctx, `UPDATE tarjeta_regalo SET saldo = $1, estado = $2, actualizado_en = NOW() WHERE id = $3`, nuevoSaldo, nuevoEstado, tarjetaId)
+	if err != nil {
+		log.Println("Error al descontar saldo de tarjeta de regalo:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return registrarMovimientoTarjeta(ctx, tx, tarjetaId, domain.MovimientoTarjetaCanje, -monto, nuevoSaldo, &ventaId, usuarioId)
+}
+
+// revertirTarjetasRegaloVenta anula las tarjetas emitidas por la venta (solo si no tienen consumos)
+// y devuelve el saldo a las tarjetas con las que se pagó, reactivando las agotadas.
+func revertirTarjetasRegaloVenta(ctx context.Context, tx pgx.Tx, ventaId, anuladoPor int) error {
+	type tarjetaVenta struct {
+		Id           int
+		Codigo       string
+		MontoInicial float64
+		Saldo        float64
+	}
+	const epsilon = 0.001
+
+	queryEmitidas := `
+        SELECT id, codigo, monto_inicial, saldo
+        FROM tarjeta_regalo
+        WHERE venta_id = $1 AND estado <> $2
+        FOR UPDATE`
+	rows, err := tx.Query(ctx, queryEmitidas, ventaId, domain.TarjetaRegaloAnulada)
+	if err != nil {
+		log.Println("Error al obtener tarjetas emitidas por la venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var emitidas []tarjetaVenta
+	for rows.Next() {
+		var t tarjetaVenta
+		if err := rows.Scan(&t.Id, &t.Codigo, &t.MontoInicial, &t.Saldo); err != nil {
+			rows.Close()
+			log.Println("Error al escanear tarjeta emitida:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		emitidas = append(emitidas, t)
+	}
+	rows.Close()
+
+	for _, t := range emitidas {
+		if t.Saldo < t.MontoInicial-epsilon {
+			return datatype.NewBadRequestError(fmt.Sprintf("La tarjeta de regalo %s ya tiene consumos; no se puede anular la venta.", t.Codigo))
+		}
+		_, err = tx.Exec(ctx, `UPDATE tarjeta_regalo SET saldo = 0, estado = $1, actualizado_en = NOW() WHERE id = $2`, domain.TarjetaRegaloAnulada, t.Id)
+		if err != nil {
+			log.Println("Error al anular tarjeta de regalo:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if err = registrarMovimientoTarjeta(ctx, tx, t.Id, domain.MovimientoTarjetaAnulacion, -t.Saldo, 0, &ventaId, anuladoPor); err != nil {
+			return err
+		}
+	}
+
+	queryCanjes := `
+        SELECT m.tarjeta_regalo_id, -SUM(m.monto)
+        FROM movimiento_tarjeta_regalo m
+        WHERE m.venta_id = $1 AND m.tipo = $2
+        GROUP BY m.tarjeta_regalo_id`
+	rows, err = tx.Query(ctx, queryCanjes, ventaId, domain.MovimientoTarjetaCanje)
+	if err != nil {
+		log.Println("Error al obtener canjes de tarjeta de la venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	type canjeTarjeta struct {
+		TarjetaId int
+		Monto     float64
+	}
+	var canjes []canjeTarjeta
+	for rows.Next() {
+		var c canjeTarjeta
+		if err := rows.Scan(&c.TarjetaId, &c.Monto); err != nil {
+			rows.Close()
+			log.Println("Error al escanear canje de tarjeta:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		canjes = append(canjes, c)
+	}
+	rows.Close()
+
+	queryDevolver := `
+        UPDATE tarjeta_regalo
+        SET saldo = saldo + $1,
+            estado = CASE WHEN estado = $3 THEN $4 ELSE estado END,
+            actualizado_en = NOW()
+        WHERE id = $2
+        RETURNING saldo`
+	for _, c := range canjes {
+		var saldo float64
+		err = tx.QueryRow(ctx, queryDevolver, c.Monto, c.TarjetaId, domain.TarjetaRegaloAgotada, domain.TarjetaRegaloActiva).Scan(&saldo)
+		if err != nil {
+			log.Println("Error al devolver saldo a tarjeta de regalo:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+		if err = registrarMovimientoTarjeta(ctx, tx, c.TarjetaId, domain.MovimientoTarjetaReverso, c.Monto, saldo, &ventaId, anuladoPor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewTarjetaRegaloRepository(pool *pgxpool.Pool) *TarjetaRegaloRepository {
+	return &TarjetaRegaloRepository{pool: pool}
+}
+
+var _ port.TarjetaRegaloRepository = (*TarjetaRegaloRepository)(nil)
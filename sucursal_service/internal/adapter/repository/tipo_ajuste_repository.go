@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TipoAjusteRepository struct {
+	pool *pgxpool.Pool
+}
+
+const selectTipoAjuste = `
+SELECT
+    ta.id,
+    ta.codigo,
+    ta.nombre,
+    ta.direccion,
+    ta.requiere_motivo,
+    ta.requiere_aprobacion,
+    ta.categoria_contable,
+    ta.estado,
+    ta.es_sistema,
+    ta.creado_en,
+    ta.actualizado_en
+FROM tipo_ajuste ta
+`
+
+func scanTipoAjuste(row pgx.Row, item *domain.TipoAjuste) error {
+	return row.Scan(&item.Id, &item.Codigo, &item.Nombre, &item.Direccion, &item.RequiereMotivo, &item.RequiereAprobacion,
+		&item.CategoriaContable, &item.Estado, &item.EsSistema, &item.CreadoEn, &item.ActualizadoEn)
+}
+
+func (t TipoAjusteRepository) RegistrarTipoAjuste(ctx context.Context, request *domain.TipoAjusteRequest) (*int, error) {
+	var tipoAjusteId int
+	query := `INSERT INTO tipo_ajuste(codigo, nombre, direccion, requiere_motivo, requiere_aprobacion, categoria_contable)
+VALUES($1,$2,$3,$4,$5,$6) RETURNING id`
+	err := t.pool.QueryRow(ctx, query, request.Codigo, request.Nombre, request.Direccion, request.RequiereMotivo,
+		request.RequiereAprobacion, request.CategoriaContable).Scan(&tipoAjusteId)
+	if err != nil {
+		log.Println("Error al registrar tipo de ajuste:", err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "unique_tipo_ajuste_codigo" {
+			return nil, datatype.NewConflictError(fmt.Sprintf("Ya existe un tipo de ajuste con el código '%s'", request.Codigo))
+		}
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &tipoAjusteId, nil
+}
+
+// ModificarTipoAjusteById no cambia el código: los ajustes registrados lo referencian
+func (t TipoAjusteRepository) ModificarTipoAjusteById(ctx context.Context, id *int, request *domain.TipoAjusteRequest) error {
+	tx, err := t.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción")
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var esSistema bool
+	var direccion string
+	err = tx.QueryRow(ctx, `SELECT es_sistema, direccion FROM tipo_ajuste WHERE id = $1 FOR UPDATE`, *id).Scan(&esSistema, &direccion)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datatype.NewNotFoundError("Tipo de ajuste no encontrado")
+		}
+		log.Println("Error al obtener tipo de ajuste:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if esSistema && direccion != request.Direccion {
+		return datatype.NewConflictError("No se puede cambiar la dirección de un tipo de ajuste del sistema")
+	}
+
+	query := `UPDATE tipo_ajuste SET nombre=$1, direccion=$2, requiere_motivo=$3, requiere_aprobacion=$4, categoria_contable=$5,
+actualizado_en=now() WHERE id=$6`
+	_, err = tx.Exec(ctx, query, request.Nombre, request.Direccion, request.RequiereMotivo, request.RequiereAprobacion,
+		request.CategoriaContable, *id)
+	if err != nil {
+		log.Println("Ha ocurrido un error en la transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Println("Error al confirmar transacción:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+	return nil
+}
+
+func (t TipoAjusteRepository) ListarTiposAjuste(ctx context.Context, filtros map[string]string) (*[]domain.TipoAjuste, error) {
+	var filters []string
+	var args []interface{}
+	i := 1
+
+	for _, f := range []struct{ clave, columna string }{
+		{"estado", "ta.estado"},
+		{"direccion", "ta.direccion"},
+		{"categoriaContable", "ta.categoria_contable"},
+	} {
+		if val := filtros[f.clave]; val != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", f.columna, i))
+			args = append(args, val)
+			i++
+		}
+	}
+
+	query := selectTipoAjuste
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY ta.nombre"
+
+	rows, err := t.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al ejecutar query:", err, query, args)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+
+	list := make([]domain.TipoAjuste, 0)
+	for rows.Next() {
+		var item domain.TipoAjuste
+		if err := scanTipoAjuste(rows, &item); err != nil {
+			log.Println("Error al escanear fila:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("Error durante la iteración de filas:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &list, nil
+}
+
+func (t TipoAjusteRepository) ObtenerTipoAjusteById(ctx context.Context, id *int) (*domain.TipoAjuste, error) {
+	var item domain.TipoAjuste
+	err := scanTipoAjuste(t.pool.QueryRow(ctx, selectTipoAjuste+" WHERE ta.id = $1", *id), &item)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("Tipo de ajuste no encontrado")
+		}
+		log.Println("Error al obtener tipo de ajuste por Id:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+func (t TipoAjusteRepository) ObtenerTipoAjustePorCodigo(ctx context.Context, codigo string) (*domain.TipoAjuste, error) {
+	var item domain.TipoAjuste
+	err := scanTipoAjuste(t.pool.QueryRow(ctx, selectTipoAjuste+" WHERE ta.codigo = $1", codigo), &item)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError(fmt.Sprintf("El tipo de ajuste '%s' no existe.", codigo))
+		}
+		log.Println("Error al obtener tipo de ajuste por código:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
+func (t TipoAjusteRepository) HabilitarTipoAjuste(ctx context.Context, id *int) error {
+	ct, err := t.pool.Exec(ctx, `UPDATE tipo_ajuste SET estado='Activo', actualizado_en=now() WHERE id=$1`, *id)
+	if err != nil {
+		log.Println("Error al habilitar tipo de ajuste:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if ct.RowsAffected() == 0 {
+		return datatype.NewNotFoundError("Tipo de ajuste no encontrado")
+	}
+	return nil
+}
+
+// DeshabilitarTipoAjuste impide usar el tipo en nuevos ajustes; los tipos del sistema siempre quedan activos
+func (t TipoAjusteRepository) DeshabilitarTipoAjuste(ctx context.Context, id *int) error {
+	var esSistema bool
+	err := t.pool.QueryRow(ctx, `
+UPDATE tipo_ajuste SET
+    estado = CASE WHEN es_sistema THEN estado ELSE 'Inactivo' END,
+    actualizado_en = CASE WHEN es_sistema THEN actualizado_en ELSE now() END
+WHERE id = $1
+RETURNING es_sistema`, *id).Scan(&esSistema)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datatype.NewNotFoundError("Tipo de ajuste no encontrado")
+		}
+		log.Println("Error al deshabilitar tipo de ajuste:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if esSistema {
+		return datatype.NewConflictError("Los tipos de ajuste del sistema no se pueden deshabilitar")
+	}
+	return nil
+}
+
+func NewTipoAjusteRepository(pool *pgxpool.Pool) *TipoAjusteRepository {
+	return &TipoAjusteRepository{pool: pool}
+}
+
+var _ port.TipoAjusteRepository = (*TipoAjusteRepository)(nil)
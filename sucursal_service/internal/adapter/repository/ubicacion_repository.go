@@ -32,7 +32,7 @@ func (u UbicacionRepository) HabilitarUbicacion(ctx context.Context, id *int) er
 			_ = tx.Rollback(ctx)
 		}
 	}()
-	query := `UPDATE ubicacion SET estado='Activo' WHERE id=$1`
+	query := `UPDATE ubicacion SET estado='Activo',actualizado_en=now() WHERE id=$1`
 	ct, err := tx.Exec(ctx, query, *id)
 	if err != nil {
 		log.Println("Ha ocurrido un error en la transacción:", err)
@@ -63,7 +63,7 @@ func (u UbicacionRepository) DeshabilitarUbicacion(ctx context.Context, id *int)
 		}
 	}()
 
-	query := `UPDATE ubicacion SET estado='Inactivo' WHERE id=$1`
+	query := `UPDATE ubicacion SET estado='Inactivo',actualizado_en=now() WHERE id=$1`
 	ct, err := tx.Exec(ctx, query, *id)
 	if err != nil {
 		log.Println("Ha ocurrido un error en la transacción:", err)
@@ -132,7 +132,7 @@ func (u UbicacionRepository) ModificarUbicacionById(ctx context.Context, id *int
 			_ = tx.Rollback(ctx)
 		}
 	}()
-	query := `UPDATE ubicacion SET nombre=$1,estado=$2,es_vendible=$3,prioridad_venta=$4 WHERE id=$5`
+	query := `UPDATE ubicacion SET nombre=$1,estado=$2,es_vendible=$3,prioridad_venta=$4,actualizado_en=now() WHERE id=$5`
 	ct, err := tx.Exec(ctx, query, request.Nombre, request.Estado, request.EsVendible, request.PrioridadVenta, *id)
 	if err != nil {
 		log.Println("Ha ocurrido un error en la transacción:", err)
@@ -6,11 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"multiroom/sucursal-service/internal/core/domain"
 	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -119,6 +122,169 @@ func (v VentaRepository) ListarProductosVentas(ctx context.Context, filtros map[
 	return &list, nil
 }
 
+func (v VentaRepository) ListarMargenesProductos(ctx context.Context, filtros map[string]string) (*[]domain.MargenProducto, error) {
+	var filters = []string{"v.estado = 'Completado'"}
+	var args []interface{}
+	var j = 1
+
+	for _, filtro := range []struct{ clave, columna string }{
+		{"sucursalId", "v.sucursal_id"},
+		{"categoriaId", "p.categoria_id"},
+		{"productoId", "p.id"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			id, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", filtro.clave))
+			}
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, j))
+			args = append(args, id)
+			j++
+		}
+	}
+	if fechaInicio := filtros["fechaInicio"]; fechaInicio != "" {
+		filters = append(filters, fmt.Sprintf("v.creado_en >= $%d", j))
+		args = append(args, fechaInicio)
+		j++
+	}
+	if fechaFin := filtros["fechaFin"]; fechaFin != "" {
+		filters = append(filters, fmt.Sprintf("v.creado_en < $%d::date + INTERVAL '1 day'", j))
+		args = append(args, fechaFin)
+	}
+
+	// El descuento general se prorratea sobre cada línea en proporción a su importe neto
+	query := `
+        SELECT s.id, s.nombre, cp.id, cp.nombre, p.id, p.nombre,
+               SUM(dv.cantidad),
+               COALESCE(SUM((dv.cantidad * dv.precio_venta - dv.descuento) * v.total / NULLIF(v.total + v.descuento_general, 0)), 0),
+               SUM(dv.cantidad * COALESCE(dv.costo_unitario, 0)),
+               COALESCE(SUM(dv.cantidad) FILTER (WHERE dv.costo_unitario IS NULL), 0)
+        FROM detalle_venta dv
+        JOIN venta v ON dv.venta_id = v.id
+        JOIN sucursal s ON v.sucursal_id = s.id
+        JOIN producto p ON dv.producto_id = p.id
+        LEFT JOIN categoria_producto cp ON p.categoria_id = cp.id
+        WHERE ` + strings.Join(filters, " AND ") + `
+        GROUP BY s.id, cp.id, p.id
+        ORDER BY s.nombre, cp.nombre NULLS LAST, p.nombre`
+
+	rows, err := v.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar márgenes de productos:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.MargenProducto, 0)
+	for rows.Next() {
+		var item domain.MargenProducto
+		err := rows.Scan(&item.SucursalId, &item.Sucursal, &item.CategoriaId, &item.Categoria, &item.ProductoId, &item.Producto,
+			&item.CantidadVentas, &item.VentasNetas, &item.CostoVentas, &item.UnidadesSinCosto)
+		if err != nil {
+			log.Println("Error al escanear margen de producto:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		item.MargenBruto = item.VentasNetas - item.CostoVentas
+		if item.VentasNetas != 0 {
+			item.MargenPorcentaje = math.Round(item.MargenBruto/item.VentasNetas*10000) / 100
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+func (v VentaRepository) ListarConsumoComponentes(ctx context.Context, filtros map[string]string) (*[]domain.ComponenteConsumoStat, error) {
+	fullHostname := ctx.Value("fullHostname").(string)
+	fullHostname = fmt.Sprintf("%s%s", fullHostname, "/uploads/productos/")
+
+	var filters = []string{"v.estado = 'Completado'"}
+	var args = []interface{}{fullHostname}
+	var j = 2
+
+	if sucursalIdStr := filtros["sucursalId"]; sucursalIdStr != "" {
+		sucursalId, err := strconv.Atoi(sucursalIdStr)
+		if err != nil {
+			log.Println("Error al convertir sucursalId a int:", err)
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("v.sucursal_id = $%d", j))
+		args = append(args, sucursalId)
+		j++
+	}
+	if fechaInicio := filtros["fechaInicio"]; fechaInicio != "" {
+		filters = append(filters, fmt.Sprintf("v.creado_en >= $%d", j))
+		args = append(args, fechaInicio)
+		j++
+	}
+	if fechaFin := filtros["fechaFin"]; fechaFin != "" {
+		filters = append(filters, fmt.Sprintf("v.creado_en <= $%d", j))
+		args = append(args, fechaFin)
+	}
+
+	// Primero se agrupa por línea de kit para no contar dos veces las unidades vendidas del kit. Solo cuentan los
+	// componentes del kit en la sucursal: el stock de variantes y modificadores también queda en
+	// detalle_venta_componente pero no es consumo de componentes.
+	query := `
+       WITH consumo AS (
+          SELECT dv.id, dv.producto_id AS kit_id, dv.cantidad AS kits, dvc.producto_id AS componente_id, SUM(dvc.cantidad) AS consumida
+          FROM detalle_venta dv
+          JOIN detalle_venta_componente dvc ON dvc.detalle_venta_id = dv.id
+          JOIN venta v ON dv.venta_id = v.id
+          JOIN producto_componente pc ON pc.producto_id = dv.producto_id AND pc.componente_id = dvc.producto_id AND pc.sucursal_id = v.sucursal_id
+          WHERE ` + strings.Join(filters, " AND ") + `
+          GROUP BY dv.id, dvc.producto_id
+       )
+       SELECT
+          json_build_object(
+             'id', k.id,
+             'nombre', k.nombre,
+             'estado', k.estado,
+             'urlFoto', ($1::text || k.id::text || '/' || k.foto),
+             'esInventariable', k.es_inventariable,
+             'creadoEn', k.creado_en,
+             'actualizadoEn', k.actualizado_en,
+             'eliminadoEn', k.eliminado_en
+          ) AS kit,
+          json_build_object(
+             'id', p.id,
+             'nombre', p.nombre,
+             'estado', p.estado,
+             'urlFoto', ($1::text || p.id::text || '/' || p.foto),
+             'esInventariable', p.es_inventariable,
+             'creadoEn', p.creado_en,
+             'actualizadoEn', p.actualizado_en,
+             'eliminadoEn', p.eliminado_en
+          ) AS componente,
+          SUM(c.kits) AS cantidad_kits,
+          SUM(c.consumida) AS cantidad_consumida
+       FROM consumo c
+       JOIN producto k ON c.kit_id = k.id
+       JOIN producto p ON c.componente_id = p.id
+       GROUP BY k.id, p.id
+       ORDER BY k.nombre, p.nombre`
+
+	rows, err := v.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar consumo de componentes:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+
+	var list = make([]domain.ComponenteConsumoStat, 0)
+	for rows.Next() {
+		var item domain.ComponenteConsumoStat
+		if err := rows.Scan(&item.Kit, &item.Componente, &item.CantidadKits, &item.CantidadConsumida); err != nil {
+			log.Println("Error al escanear:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	if rows.Err() != nil {
+		log.Println("Error en iteración de rows:", rows.Err())
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &list, nil
+}
+
 func (v VentaRepository) ListarVentas(ctx context.Context, filtros map[string]string) (*[]domain.VentaInfo, error) {
 	var filters []string
 	var args []interface{}
@@ -225,7 +391,12 @@ func (v VentaRepository) ListarVentas(ctx context.Context, filtros map[string]st
        v.actualizado_en,
        v.costo_tiempo_venta,
        v.descuento_general,
+       v.descuento_tiempo,
        v.observacion,
+       v.base_imponible,
+       v.impuesto,
+       v.nit,
+       v.razon_social,
        json_build_object(
           'id',ua.id,
           'username',ua.username
@@ -300,7 +471,8 @@ func (v VentaRepository) ListarVentas(ctx context.Context, filtros map[string]st
 	list := make([]domain.VentaInfo, 0)
 	for rows.Next() {
 		var item domain.VentaInfo
-		err := rows.Scan(&item.Id, &item.CodigoVenta, &item.Total, &item.Estado, &item.CreadoEn, &item.ActualizadoEn, &item.CostoTiempoVenta, &item.DescuentoGeneral, &item.Observacion, &item.Usuario, &item.Cliente, &item.Sucursal, &item.Sala)
+		err := rows.Scan(&item.Id, &item.CodigoVenta, &item.Total, &item.Estado, &item.CreadoEn, &item.ActualizadoEn, &item.CostoTiempoVenta, &item.DescuentoGeneral, &item.DescuentoTiempo, &item.Observacion,
+			&item.BaseImponible, &item.Impuesto, &item.Nit, &item.RazonSocial, &item.Usuario, &item.Cliente, &item.Sucursal, &item.Sala)
 		if err != nil {
 			log.Println("Error al obtener lista de venta:", err)
 			return nil, datatype.NewInternalServerErrorGeneric()
@@ -312,12 +484,6 @@ func (v VentaRepository) ListarVentas(ctx context.Context, filtros map[string]st
 }
 
 func (v VentaRepository) RegistrarVenta(ctx context.Context, request *domain.VentaRequest) (*int, error) {
-	type stockDisponible struct {
-		UbicacionId int
-		Stock       int
-	}
-
-	// 1. Iniciar transacción
 	tx, err := v.pool.Begin(ctx)
 	if err != nil {
 		log.Println("Error al iniciar transacción:", err)
@@ -332,180 +498,373 @@ func (v VentaRepository) RegistrarVenta(ctx context.Context, request *domain.Ven
 		}
 	}()
 
-	// 2. Validaciones iniciales
+	ventaId, err := registrarVenta(ctx, tx, request, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+
+	return &ventaId, nil
+}
+
+// registrarVenta inserta la venta dentro de una transacción abierta. Las ventas sincronizadas desde un POS sin
+// conexión conservan su hora en creadoEn y resuelven el stock insuficiente con faltantes; en línea ambos son nil.
+func registrarVenta(ctx context.Context, tx pgx.Tx, request *domain.VentaRequest, creadoEn *time.Time, faltantes *faltantesStock) (int, error) {
+	var err error
+
+	// 1. Validaciones iniciales
 	if request.SucursalId <= 0 {
-		return nil, datatype.NewBadRequestError("El ID de la sucursal es obligatorio.")
+		return 0, datatype.NewBadRequestError("El ID de la sucursal es obligatorio.")
 	}
 
 	var sucursalExiste bool
 	queryValidaSucursal := `SELECT EXISTS(SELECT 1 FROM sucursal WHERE id = $1 AND estado = 'Activo')`
 	err = tx.QueryRow(ctx, queryValidaSucursal, request.SucursalId).Scan(&sucursalExiste)
 	if err != nil {
-		return nil, datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
 	}
 
 	if !sucursalExiste {
-		return nil, datatype.NewBadRequestError(fmt.Sprintf("La sucursal con id %d no existe o está inactiva.", request.SucursalId))
+		return 0, datatype.NewBadRequestError(fmt.Sprintf("La sucursal con id %d no existe o está inactiva.", request.SucursalId))
+	}
+
+	// Las promociones se evalúan a la hora de la venta: la original si llega sincronizada (acotada por el servicio)
+	momento := time.Now()
+	if creadoEn != nil {
+		momento = *creadoEn
+	}
+
+	// Promociones vigentes (automáticas y, si se envió, la del cupón). Se evalúan en el servidor.
+	promociones, err := obtenerPromocionesVigentes(ctx, tx, request.SucursalId, request.CodigoCupon, momento)
+	if err != nil {
+		return 0, err
+	}
+	var cuponPromocionId *int
+
+	// Tasas de impuesto de la sucursal; los precios ya incluyen el impuesto
+	tasas, err := obtenerTasasImpuesto(ctx, tx, request.SucursalId)
+	if err != nil {
+		return 0, err
 	}
 
-	// 3. Actualizar uso de sala (si aplica)
+	// 2. Actualizar uso de sala (si aplica)
 	if request.UsoSalaId != nil {
 		queryUsoSala := `UPDATE uso_sala SET costo_tiempo = costo_tiempo + $1, actualizado_en = NOW() 
                          WHERE id = $2 AND estado IN ('En uso', 'Pausado') AND tipo = 'General'`
 		ct, err := tx.Exec(ctx, queryUsoSala, request.CostoTiempo, *request.UsoSalaId)
 		if err != nil || ct.RowsAffected() == 0 {
-			return nil, datatype.NewBadRequestError("La sesión de uso de sala no está activa.")
+			return 0, datatype.NewBadRequestError("La sesión de uso de sala no está activa.")
 		}
 	}
 
 	var totalVenta float64 = 0
 	var detallesParaGuardar [][]interface{}
+	var compuestosParaGuardar []detalleCompuesto
+	var tarjetasParaEmitir []tarjetaPorEmitir
+	var movimientos []movimientoInventario
+	var consumosVenta []consumoStock
+	// Importe bruto y descuento manual (sin promociones) para la política de descuento máximo
+	var subtotalBrutoVenta, descuentoManual float64
 
 	// Consultas preparadas
 	queryGetProductoInfo := `
-        SELECT ps.precio, p.es_inventariable, p.nombre 
+        SELECT ps.precio, p.es_inventariable, p.nombre, p.categoria_id, p.es_tarjeta_regalo, p.vigencia_dias_tarjeta
         FROM producto_sucursal ps
         JOIN producto p ON ps.producto_id = p.id
         WHERE ps.producto_id = $1 AND ps.sucursal_id = $2`
 
-	queryFindStock := `
-        SELECT i.stock, i.ubicacion_id
-        FROM inventario i
-        JOIN ubicacion u ON i.ubicacion_id = u.id
-        WHERE i.producto_id = $1 AND u.sucursal_id = $2 AND u.es_vendible = true AND u.estado = 'Activo'
-        ORDER BY u.prioridad_venta FOR UPDATE OF i`
-
-	// 4. Bucle de Detalles de Venta
+	// 3. Bucle de Detalles de Venta
 	for _, detalleReq := range request.Detalles {
 		if detalleReq.Cantidad <= 0 {
-			return nil, datatype.NewBadRequestError("Cantidad debe ser mayor a cero")
+			return 0, datatype.NewBadRequestError("Cantidad debe ser mayor a cero")
 		}
 
 		var precioVenta float64
 		var esInventariable bool
 		var nombreProducto string
+		var categoriaId *int
+		var esTarjetaRegalo bool
+		var vigenciaDiasTarjeta int
 
-		err = tx.QueryRow(ctx, queryGetProductoInfo, detalleReq.ProductoId, request.SucursalId).Scan(&precioVenta, &esInventariable, &nombreProducto)
+		err = tx.QueryRow(ctx, queryGetProductoInfo, detalleReq.ProductoId, request.SucursalId).Scan(&precioVenta, &esInventariable, &nombreProducto, &categoriaId,
+			&esTarjetaRegalo, &vigenciaDiasTarjeta)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
-				return nil, datatype.NewBadRequestError(fmt.Sprintf("Producto %d no disponible en esta sucursal.", detalleReq.ProductoId))
+				return 0, datatype.NewBadRequestError(fmt.Sprintf("Producto %d no disponible en esta sucursal.", detalleReq.ProductoId))
 			}
-			return nil, datatype.NewInternalServerErrorGeneric()
+			return 0, datatype.NewInternalServerErrorGeneric()
+		}
+
+		tasaImpuesto := tasas.tasa(categoriaId)
+
+		// Las opciones elegidas suman su diferencia de precio al precio unitario
+		opciones, err := resolverOpciones(ctx, tx, detalleReq.ProductoId, detalleReq.Opciones, nombreProducto)
+		if err != nil {
+			return 0, err
+		}
+		for _, o := range opciones {
+			precioVenta += o.PrecioDelta
+		}
+		if precioVenta < 0 {
+			return 0, datatype.NewBadRequestError(fmt.Sprintf("El precio de %s con las opciones elegidas no puede ser negativo.", nombreProducto))
 		}
 
 		subtotalBrutoLinea := precioVenta * float64(detalleReq.Cantidad)
 		if detalleReq.Descuento > subtotalBrutoLinea {
-			return nil, datatype.NewBadRequestError(fmt.Sprintf("Descuento excesivo en %s", nombreProducto))
+			return 0, datatype.NewBadRequestError(fmt.Sprintf("Descuento excesivo en %s", nombreProducto))
 		}
 
-		descuentoUnitario := detalleReq.Descuento / float64(detalleReq.Cantidad)
-		totalVenta += subtotalBrutoLinea - detalleReq.Descuento
-
-		// --- Lógica de Inventario ---
-		if esInventariable {
-			rows, err := tx.Query(ctx, queryFindStock, detalleReq.ProductoId, request.SucursalId)
-			if err != nil {
-				return nil, datatype.NewInternalServerErrorGeneric()
+		// La promoción se suma al descuento manual sin superar el subtotal de la línea
+		var promocionId *int
+		promocion, descuentoPromocion := mejorPromocion(promociones, detalleReq.ProductoId, categoriaId, precioVenta, detalleReq.Cantidad)
+		if promocion != nil {
+			descuentoPromocion = math.Min(descuentoPromocion, subtotalBrutoLinea-detalleReq.Descuento)
+		}
+		if promocion != nil && descuentoPromocion > 0 {
+			promocionId = &promocion.Id
+			if promocion.CodigoCupon != nil {
+				cuponPromocionId = &promocion.Id
 			}
+		} else {
+			descuentoPromocion = 0
+		}
+		descuentoLinea := detalleReq.Descuento + descuentoPromocion
+		subtotalBrutoVenta += subtotalBrutoLinea
+		descuentoManual += detalleReq.Descuento
+
+		// Cada unidad de un producto tarjeta de regalo emite una tarjeta con saldo igual al neto cobrado por unidad
+		if esTarjetaRegalo {
+			tarjetasParaEmitir = append(tarjetasParaEmitir, tarjetaPorEmitir{
+				ProductoId:   detalleReq.ProductoId,
+				Monto:        (subtotalBrutoLinea - descuentoLinea) / float64(detalleReq.Cantidad),
+				Cantidad:     int(detalleReq.Cantidad),
+				VigenciaDias: vigenciaDiasTarjeta,
+			})
+		}
 
-			var stockDisponibleList []stockDisponible
-			var stockTotalVendible = 0
-			for rows.Next() {
-				var s stockDisponible
-				err := rows.Scan(&s.Stock, &s.UbicacionId)
-				if err != nil {
-					log.Println("Error al escanear:", err)
-					return nil, datatype.NewInternalServerErrorGeneric()
-				}
-				if s.Stock > 0 {
-					stockDisponibleList = append(stockDisponibleList, s)
-					stockTotalVendible += s.Stock
-				}
-			}
-			rows.Close()
+		descuentoUnitario := descuentoLinea / float64(detalleReq.Cantidad)
+		descuentoPromocionUnitario := descuentoPromocion / float64(detalleReq.Cantidad)
+		totalVenta += subtotalBrutoLinea - descuentoLinea
 
-			if stockTotalVendible < int(detalleReq.Cantidad) {
-				return nil, datatype.NewBadRequestError(fmt.Sprintf("Stock insuficiente para %s. Disponible: %d", nombreProducto, stockTotalVendible))
+		// --- Lógica de Inventario ---
+		componentes, err := obtenerComponentesKit(ctx, tx, detalleReq.ProductoId, request.SucursalId)
+		if err != nil {
+			return 0, err
+		}
+		if len(componentes) > 0 || len(opciones) > 0 {
+			// Línea compuesta (kit u opciones): se guarda sin ubicación y el stock consumido queda en detalle_venta_componente
+			var requeridos []stockRequerido
+			varianteConStock := false
+			for _, o := range opciones {
+				if o.TipoGrupo == domain.GrupoOpcionVariante && o.ProductoStockId != nil {
+					varianteConStock = true
+				}
 			}
-
-			cantidadARestar := int(detalleReq.Cantidad)
-			for _, s := range stockDisponibleList {
-				if cantidadARestar == 0 {
-					break
+			if !varianteConStock {
+				if len(componentes) > 0 {
+					for _, comp := range componentes {
+						if comp.EsInventariable {
+							requeridos = append(requeridos, stockRequerido{
+								ProductoId: comp.ProductoId,
+								Nombre:     fmt.Sprintf("%s (componente de %s)", comp.Nombre, nombreProducto),
+								Cantidad:   comp.Cantidad * int(detalleReq.Cantidad),
+							})
+						}
+					}
+				} else if esInventariable {
+					requeridos = append(requeridos, stockRequerido{ProductoId: detalleReq.ProductoId, Nombre: nombreProducto, Cantidad: int(detalleReq.Cantidad)})
 				}
-
-				cantidadDescontada := 0
-				if cantidadARestar <= s.Stock {
-					cantidadDescontada = cantidadARestar
-					cantidadARestar = 0
-				} else {
-					cantidadDescontada = s.Stock
-					cantidadARestar -= s.Stock
+			}
+			// Las variantes con stock propio reemplazan al producto base; los modificadores con stock se descuentan además
+			for _, o := range opciones {
+				if o.ProductoStockId != nil && o.StockInventariable {
+					requeridos = append(requeridos, stockRequerido{
+						ProductoId: *o.ProductoStockId,
+						Nombre:     fmt.Sprintf("%s (%s de %s)", o.NombreStock, o.Nombre, nombreProducto),
+						Cantidad:   o.CantidadStock * int(detalleReq.Cantidad),
+					})
 				}
+			}
 
-				_, err = tx.Exec(ctx, `UPDATE inventario SET stock = stock - $1 WHERE producto_id = $2 AND ubicacion_id = $3`,
-					cantidadDescontada, detalleReq.ProductoId, s.UbicacionId)
+			var consumos []consumoStock
+			for _, r := range requeridos {
+				consumo, err := descontarStockVendible(ctx, tx, r.ProductoId, request.SucursalId, r.Cantidad, r.Nombre, faltantes)
 				if err != nil {
-					return nil, datatype.NewInternalServerErrorGeneric()
+					return 0, err
 				}
-
+				consumos = append(consumos, consumo...)
+			}
+			movimientos = append(movimientos, movimientosDeConsumos(consumos)...)
+			consumosVenta = append(consumosVenta, consumos...)
+			compuestosParaGuardar = append(compuestosParaGuardar, detalleCompuesto{
+				Fila: []interface{}{
+					nil, detalleReq.ProductoId, nil, int(detalleReq.Cantidad), precioVenta, descuentoLinea,
+					promocionId, descuentoPromocion, tasaImpuesto, nil,
+				},
+				Componentes: consumos,
+				Opciones:    opciones,
+			})
+		} else if esInventariable {
+			consumos, err := descontarStockVendible(ctx, tx, detalleReq.ProductoId, request.SucursalId, int(detalleReq.Cantidad), nombreProducto, faltantes)
+			if err != nil {
+				return 0, err
+			}
+			movimientos = append(movimientos, movimientosDeConsumos(consumos)...)
+			consumosVenta = append(consumosVenta, consumos...)
+			sinDescontar := int(detalleReq.Cantidad)
+			for _, c := range consumos {
+				sinDescontar -= c.Cantidad
+				detallesParaGuardar = append(detallesParaGuardar, []interface{}{
+					nil, detalleReq.ProductoId, c.UbicacionId, c.Cantidad, precioVenta, descuentoUnitario * float64(c.Cantidad),
+					promocionId, descuentoPromocionUnitario * float64(c.Cantidad), tasaImpuesto,
+				})
+			}
+			// Faltante marcado para revisión: se vende sin ubicación para que anular la venta no devuelva stock que no salió
+			if sinDescontar > 0 {
 				detallesParaGuardar = append(detallesParaGuardar, []interface{}{
-					nil, detalleReq.ProductoId, s.UbicacionId, cantidadDescontada, precioVenta, descuentoUnitario * float64(cantidadDescontada),
+					nil, detalleReq.ProductoId, nil, sinDescontar, precioVenta, descuentoUnitario * float64(sinDescontar),
+					promocionId, descuentoPromocionUnitario * float64(sinDescontar), tasaImpuesto,
 				})
 			}
 		} else {
 			// Producto NO inventariable (Servicio): No resta stock, ubicación es NULL
 			detallesParaGuardar = append(detallesParaGuardar, []interface{}{
-				nil, detalleReq.ProductoId, nil, int(detalleReq.Cantidad), precioVenta, detalleReq.Descuento,
+				nil, detalleReq.ProductoId, nil, int(detalleReq.Cantidad), precioVenta, descuentoLinea,
+				promocionId, descuentoPromocion, tasaImpuesto,
 			})
 		}
 	}
 
-	// 5. Totales Finales y Descuento General
-	totalVenta += request.CostoTiempo
+	// 4. Totales Finales, Happy Hour sobre el tiempo de sala y Descuento General
+	var promocionTiempoId *int
+	promocionTiempo, descuentoTiempo := mejorPromocionTiempo(promociones, request.CostoTiempo)
+	if promocionTiempo != nil {
+		promocionTiempoId = &promocionTiempo.Id
+		if promocionTiempo.CodigoCupon != nil {
+			cuponPromocionId = &promocionTiempo.Id
+		}
+	}
+	totalVenta += request.CostoTiempo - descuentoTiempo
 	if request.DescuentoGeneral > totalVenta {
-		return nil, datatype.NewBadRequestError("El descuento general supera el total de la venta.")
+		return 0, datatype.NewBadRequestError("El descuento general supera el total de la venta.")
+	}
+	// El descuento general se prorratea sobre el saldo de las tarjetas de regalo en proporción al total
+	for i := range tarjetasParaEmitir {
+		if request.DescuentoGeneral > 0 {
+			tarjetasParaEmitir[i].Monto -= tarjetasParaEmitir[i].Monto * request.DescuentoGeneral / totalVenta
+		}
+		tarjetasParaEmitir[i].Monto = math.Round(tarjetasParaEmitir[i].Monto*100) / 100
 	}
 	totalVenta -= request.DescuentoGeneral
 
-	// 6. Insertar Encabezado
+	// Un descuento manual por encima del máximo de la sucursal necesita autorización de un supervisor
+	var autorizacionDescuento *autorizacionSupervisor
+	var detalleDescuento string
+	descuentoManual += request.DescuentoGeneral
+	subtotalBrutoVenta += request.CostoTiempo
+	if descuentoManual > 0 && subtotalBrutoVenta > 0 {
+		configuracion, err := obtenerConfiguracionSucursal(ctx, tx, request.SucursalId)
+		if err != nil {
+			return 0, err
+		}
+		porcentaje := descuentoManual / subtotalBrutoVenta * 100
+		if configuracion.DescuentoMaximo != nil && porcentaje > *configuracion.DescuentoMaximo+0.005 {
+			detalleDescuento = fmt.Sprintf("Descuento de %.2f (%.2f%%) sobre %.2f; máximo de la sucursal %.2f%%", descuentoManual, porcentaje, subtotalBrutoVenta, *configuracion.DescuentoMaximo)
+			autorizacionDescuento, err = validarAutorizacion(ctx, tx, request.SucursalId, request.Autorizacion,
+				fmt.Sprintf("El descuento de %.2f%% supera el máximo de %.2f%%; requiere autorización de un supervisor.", porcentaje, *configuracion.DescuentoMaximo))
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// El cupón debe haber producido algún descuento y se consume respetando su límite de usos
+	if request.CodigoCupon != nil && strings.TrimSpace(*request.CodigoCupon) != "" {
+		if cuponPromocionId == nil {
+			return 0, datatype.NewBadRequestError(fmt.Sprintf("El cupón %s no aplica a los productos de esta venta.", *request.CodigoCupon))
+		}
+		queryUsoCupon := `UPDATE promocion SET usos = usos + 1 WHERE id = $1 AND (uso_maximo IS NULL OR usos < uso_maximo)`
+		ct, err := tx.Exec(ctx, queryUsoCupon, *cuponPromocionId)
+		if err != nil {
+			log.Println("Error al registrar uso del cupón:", err)
+			return 0, datatype.NewInternalServerErrorGeneric()
+		}
+		if ct.RowsAffected() == 0 {
+			return 0, datatype.NewBadRequestError(fmt.Sprintf("El cupón %s alcanzó su límite de usos.", *request.CodigoCupon))
+		}
+	}
+
+	// 5. Insertar Encabezado
 	var ventaId int
 	queryVenta := `
-        INSERT INTO venta (codigo_venta, sucursal_id, sala_id, uso_sala_id, usuario_id, cliente_id, total, descuento_general, costo_tiempo_venta, observacion, estado, creado_en)
-        VALUES (nextval('seq_codigo_venta'), $1, $2, $3, $4, $5, $6, $7, $8, $9, 'Completada', NOW())
+        INSERT INTO venta (codigo_venta, sucursal_id, sala_id, uso_sala_id, usuario_id, cliente_id, total, descuento_general, costo_tiempo_venta, observacion, estado, creado_en,
+                           descuento_tiempo, promocion_tiempo_id, cupon_promocion_id, nit, razon_social)
+        VALUES (nextval('seq_codigo_venta'), $1, $2, $3, $4, $5, $6, $7, $8, $9, 'Completada', COALESCE($15, NOW()), $10, $11, $12, $13, $14)
         RETURNING id`
 
-	err = tx.QueryRow(ctx, queryVenta, request.SucursalId, request.SalaId, request.UsoSalaId, request.UsuarioId, request.ClienteId, totalVenta, request.DescuentoGeneral, request.CostoTiempo, request.Observacion).Scan(&ventaId)
+	err = tx.QueryRow(ctx, queryVenta, request.SucursalId, request.SalaId, request.UsoSalaId, request.UsuarioId, request.ClienteId, totalVenta, request.DescuentoGeneral, request.CostoTiempo, request.Observacion,
+		descuentoTiempo, promocionTiempoId, cuponPromocionId, request.Nit, request.RazonSocial, creadoEn).Scan(&ventaId)
 	if err != nil {
-		return nil, datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+
+	if autorizacionDescuento != nil {
+		if err = registrarAutorizacionVenta(ctx, tx, ventaId, domain.AutorizacionDescuento, autorizacionDescuento, &request.UsuarioId, detalleDescuento); err != nil {
+			return 0, err
+		}
 	}
 
-	// 7. Inserción Masiva de Detalles
+	// 6. Inserción Masiva de Detalles
 	for i := range detallesParaGuardar {
 		detallesParaGuardar[i][0] = ventaId
 	}
 
 	_, err = tx.CopyFrom(ctx, pgx.Identifier{"detalle_venta"},
-		[]string{"venta_id", "producto_id", "ubicacion_id", "cantidad", "precio_venta", "descuento"},
+		[]string{"venta_id", "producto_id", "ubicacion_id", "cantidad", "precio_venta", "descuento", "promocion_id", "descuento_promocion", "tasa_impuesto"},
 		pgx.CopyFromRows(detallesParaGuardar))
 
 	if err != nil {
-		return nil, datatype.NewInternalServerErrorGeneric()
+		return 0, datatype.NewInternalServerErrorGeneric()
 	}
 
-	// 8. Confirmar Transacción
-	if err = tx.Commit(ctx); err != nil {
-		return nil, datatype.NewInternalServerErrorGeneric()
+	for _, k := range compuestosParaGuardar {
+		k.Fila[0] = ventaId
+		if err = insertarDetalleCompuesto(ctx, tx, k); err != nil {
+			return 0, err
+		}
 	}
-	committed = true
 
-	return &ventaId, nil
+	if err = registrarMovimientosInventario(ctx, tx, domain.DocumentoVenta, ventaId, &request.UsuarioId, movimientos); err != nil {
+		return 0, err
+	}
+
+	if err = registrarLotesVenta(ctx, tx, ventaId, consumosVenta); err != nil {
+		return 0, err
+	}
+
+	if err = registrarCostoVenta(ctx, tx, ventaId, request.SucursalId); err != nil {
+		return 0, err
+	}
+
+	if err = calcularImpuestosVenta(ctx, tx, ventaId, tasas.general); err != nil {
+		return 0, err
+	}
+
+	if err = emitirTarjetasRegalo(ctx, tx, ventaId, request.SucursalId, request.UsuarioId, tarjetasParaEmitir); err != nil {
+		return 0, err
+	}
+
+	return ventaId, nil
 }
 
-func (v VentaRepository) AnularVentaById(ctx context.Context, id *int) error {
+func (v VentaRepository) AnularVentaById(ctx context.Context, id *int, request *domain.AnularVentaRequest) error {
 	type detalleVentaSimple struct {
 		ProductoId  int
-		UbicacionId int
+		UbicacionId *int
 		Cantidad    int
 	}
 
@@ -528,15 +887,21 @@ func (v VentaRepository) AnularVentaById(ctx context.Context, id *int) error {
 	var estadoActual string
 	var usoSalaId *int64
 	var costoTiempoVenta float64
+	var cuponPromocionId *int
+	var usuarioId int
+	var sucursalId int
+	var minutosTranscurridos float64
 
 	// MODIFICADO: Ahora traemos también el uso_sala_id y el costo_tiempo_venta
 	queryDatosVenta := `
-        SELECT estado, uso_sala_id, costo_tiempo_venta 
+        SELECT estado, uso_sala_id, costo_tiempo_venta, cupon_promocion_id, usuario_id, sucursal_id,
+               EXTRACT(EPOCH FROM (NOW() - creado_en)) / 60
         FROM venta 
         WHERE id = $1 
         FOR UPDATE`
 
-	err = tx.QueryRow(ctx, queryDatosVenta, *id).Scan(&estadoActual, &usoSalaId, &costoTiempoVenta)
+	err = tx.QueryRow(ctx, queryDatosVenta, *id).Scan(&estadoActual, &usoSalaId, &costoTiempoVenta, &cuponPromocionId, &usuarioId, &sucursalId,
+		&minutosTranscurridos)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -549,6 +914,23 @@ func (v VentaRepository) AnularVentaById(ctx context.Context, id *int) error {
 	if estadoActual == "Anulada" {
 		return datatype.NewBadRequestError("Esta venta ya fue anulada anteriormente.")
 	}
+	if estadoActual == "Dividida" {
+		return datatype.NewBadRequestError("Esta venta fue dividida; anule cada una de las ventas resultantes.")
+	}
+
+	// Fuera de la ventana de anulación de la sucursal se necesita la autorización de un supervisor
+	configuracion, err := obtenerConfiguracionSucursal(ctx, tx, sucursalId)
+	if err != nil {
+		return err
+	}
+	var autorizacion *autorizacionSupervisor
+	if configuracion.VentanaAnulacionMinutos != nil && minutosTranscurridos > float64(*configuracion.VentanaAnulacionMinutos) {
+		autorizacion, err = validarAutorizacion(ctx, tx, sucursalId, request.Autorizacion,
+			fmt.Sprintf("La venta tiene más de %d minutos; su anulación requiere autorización de un supervisor.", *configuracion.VentanaAnulacionMinutos))
+		if err != nil {
+			return err
+		}
+	}
 
 	// --- NUEVA LÓGICA: REVERTIR COSTO DE TIEMPO EN USO_SALA ---
 	// Si la venta estaba ligada a una sala y tenía un costo de tiempo asociado, lo restamos.
@@ -568,11 +950,16 @@ func (v VentaRepository) AnularVentaById(ctx context.Context, id *int) error {
 	}
 	// -----------------------------------------------------------
 
-	// 3. Obtener los detalles de la venta (Productos)
+	// 3. Obtener los detalles de la venta (Productos y componentes de kits)
 	queryDetalles := `
         SELECT producto_id, ubicacion_id, cantidad 
         FROM detalle_venta 
-        WHERE venta_id = $1`
+        WHERE venta_id = $1
+        UNION ALL
+        SELECT dvc.producto_id, dvc.ubicacion_id, dvc.cantidad
+        FROM detalle_venta_componente dvc
+        JOIN detalle_venta dv ON dvc.detalle_venta_id = dv.id
+        WHERE dv.venta_id = $1`
 
 	rows, err := tx.Query(ctx, queryDetalles, *id)
 	if err != nil {
@@ -591,29 +978,63 @@ func (v VentaRepository) AnularVentaById(ctx context.Context, id *int) error {
 	}
 	rows.Close()
 
-	// 4. REVERTIR EL INVENTARIO (Devolver el stock)
-	querySumaInventario := `
-       INSERT INTO inventario (producto_id, ubicacion_id, stock)
-       VALUES ($1, $2, $3)
-       ON CONFLICT (producto_id, ubicacion_id) 
-       DO UPDATE SET
-          stock = inventario.stock + EXCLUDED.stock;
-    `
+	// 4. REVERTIR EL INVENTARIO (Devolver el stock a los lotes de los que salió)
+	lotesVenta, err := obtenerLotesVenta(ctx, tx, *id)
+	if err != nil {
+		return err
+	}
+	var movimientos []movimientoInventario
 	for _, d := range detalles {
 		if d.Cantidad > 0 {
-			// Nota: Si el producto no es inventariable (ubicacion_id es NULL), esto fallaría.
-			// Deberías validar que d.UbicacionId > 0 o manejar el NULL en la query.
-			// Asumiendo que detalle_venta guarda NULL en ubicacion_id para servicios:
-			if d.UbicacionId > 0 {
-				_, err = tx.Exec(ctx, querySumaInventario, d.ProductoId, d.UbicacionId, d.Cantidad)
+			// Servicios y kits guardan NULL en ubicacion_id; el stock de los kits vuelve por sus componentes
+			if d.UbicacionId != nil {
+				saldo, err := sumarStock(ctx, tx, d.ProductoId, *d.UbicacionId, d.Cantidad)
 				if err != nil {
 					log.Println("Error al devolver stock (UPSERT):", err)
 					return datatype.NewInternalServerErrorGeneric()
 				}
+				lotes := tomarLotesVenta(lotesVenta, d.ProductoId, *d.UbicacionId, d.Cantidad)
+				if err = sumarLotes(ctx, tx, d.ProductoId, *d.UbicacionId, lotes); err != nil {
+					return err
+				}
+				movimientos = append(movimientos, movimientoInventario{ProductoId: d.ProductoId, UbicacionId: *d.UbicacionId, Cantidad: d.Cantidad, Saldo: saldo})
 			}
 		}
 	}
 
+	// La devolución y las reversiones de saldos quedan a nombre de quien anula; sin sesión, a nombre del vendedor
+	anuladoPor := &usuarioId
+	if uid, ok := ctx.Value(util.ContextUserIdKey).(int); ok {
+		anuladoPor = &uid
+	}
+	if err = registrarMovimientosInventario(ctx, tx, domain.DocumentoAnulacionVenta, *id, anuladoPor, movimientos); err != nil {
+		return err
+	}
+
+	// Liberar el uso del cupón para que vuelva a estar disponible
+	if cuponPromocionId != nil {
+		_, err = tx.Exec(ctx, `UPDATE promocion SET usos = GREATEST(usos - 1, 0) WHERE id = $1`, *cuponPromocionId)
+		if err != nil {
+			log.Println("Error al liberar uso del cupón:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+
+	// Devolver a la cuenta corriente del cliente lo cobrado a cuenta
+	if err = revertirCargosCuenta(ctx, tx, *id, *anuladoPor); err != nil {
+		return err
+	}
+
+	// Anular las tarjetas de regalo vendidas y devolver el saldo de las usadas como pago
+	if err = revertirTarjetasRegaloVenta(ctx, tx, *id, *anuladoPor); err != nil {
+		return err
+	}
+
+	// Quitar los puntos ganados y devolver los canjeados en la venta
+	if err = revertirPuntosVenta(ctx, tx, *id, *anuladoPor); err != nil {
+		return err
+	}
+
 	// 5. Actualizar estado de la Venta
 	queryUpdateVenta := `UPDATE venta SET estado = 'Anulada', actualizado_en = NOW() WHERE id = $1`
 
@@ -623,6 +1044,13 @@ func (v VentaRepository) AnularVentaById(ctx context.Context, id *int) error {
 		return datatype.NewInternalServerErrorGeneric()
 	}
 
+	if autorizacion != nil {
+		detalle := fmt.Sprintf("Anulación %.0f minutos después de la venta (ventana de %d minutos)", minutosTranscurridos, *configuracion.VentanaAnulacionMinutos)
+		if err = registrarAutorizacionVenta(ctx, tx, *id, domain.AutorizacionAnulacion, autorizacion, anuladoPor, detalle); err != nil {
+			return err
+		}
+	}
+
 	// 6. Commit
 	err = tx.Commit(ctx)
 	if err != nil {
@@ -634,21 +1062,6 @@ func (v VentaRepository) AnularVentaById(ctx context.Context, id *int) error {
 }
 
 func (v VentaRepository) RegistrarPagoVenta(ctx context.Context, ventaId *int, request *domain.RegistrarPagosRequest) (*[]int, error) {
-	var pagosValidos []domain.PagoRequest
-	for _, pago := range request.Pagos {
-		if pago.Monto > 0 {
-			pagosValidos = append(pagosValidos, pago)
-		}
-	}
-	// Reemplazamos la lista original con la lista limpia
-	request.Pagos = pagosValidos
-
-	// 0. Validar el input
-	if len(request.Pagos) == 0 {
-		return nil, datatype.NewBadRequestError("Se debe proporcionar al menos un método de pago.")
-	}
-
-	// 1. Iniciar transacción y defer rollback
 	tx, err := v.pool.Begin(ctx)
 	if err != nil {
 		log.Println("Error al iniciar transacción:", err)
@@ -663,23 +1076,58 @@ func (v VentaRepository) RegistrarPagoVenta(ctx context.Context, ventaId *int, r
 		}
 	}()
 
-	// 2. Obtener la Venta y BLOQUEAR LA FILA
-	var totalVenta float64
-	var estadoVenta string
-	queryLockVenta := `SELECT total, estado FROM venta WHERE id = $1 FOR UPDATE`
-
-	err = tx.QueryRow(ctx, queryLockVenta, *ventaId).Scan(&totalVenta, &estadoVenta)
+	pagoIds, err := registrarPagoVenta(ctx, tx, *ventaId, request)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, datatype.NewNotFoundError("La venta no fue encontrada.")
-		}
-		log.Println("Error al bloquear la venta:", err)
-		return nil, datatype.NewInternalServerErrorGeneric()
+		return nil, err
 	}
 
-	// Validar el estado de la venta
-	if estadoVenta == "Completado" || estadoVenta == "Anulada" {
-		return nil, datatype.NewBadRequestError(fmt.Sprintf("Esta venta está en estado '%s' y no se puede pagar.", estadoVenta))
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Println("Error al confirmar transacción de pago:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+
+	return &pagoIds, nil
+}
+
+// registrarPagoVenta registra el pago exacto de una venta pendiente dentro de una transacción abierta.
+func registrarPagoVenta(ctx context.Context, tx pgx.Tx, ventaId int, request *domain.RegistrarPagosRequest) ([]int, error) {
+	var pagosValidos []domain.PagoRequest
+	for _, pago := range request.Pagos {
+		if pago.Monto > 0 {
+			pagosValidos = append(pagosValidos, pago)
+		}
+	}
+	// Reemplazamos la lista original con la lista limpia
+	request.Pagos = pagosValidos
+
+	// 1. Validar el input
+	if len(request.Pagos) == 0 {
+		return nil, datatype.NewBadRequestError("Se debe proporcionar al menos un método de pago.")
+	}
+
+	var err error
+
+	// 2. Obtener la Venta y BLOQUEAR LA FILA
+	var totalVenta float64
+	var estadoVenta string
+	var clienteId *int64
+	var sucursalId, usuarioId int
+	queryLockVenta := `SELECT total, estado, cliente_id, sucursal_id, usuario_id FROM venta WHERE id = $1 FOR UPDATE`
+
+	err = tx.QueryRow(ctx, queryLockVenta, ventaId).Scan(&totalVenta, &estadoVenta, &clienteId, &sucursalId, &usuarioId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("La venta no fue encontrada.")
+		}
+		log.Println("Error al bloquear la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	// Validar el estado de la venta
+	if estadoVenta == "Completado" || estadoVenta == "Anulada" || estadoVenta == "Dividida" {
+		return nil, datatype.NewBadRequestError(fmt.Sprintf("Esta venta está en estado '%s' y no se puede pagar.", estadoVenta))
 	}
 
 	// 3. Validar PAGO EXACTO (Ni más, ni menos)
@@ -698,6 +1146,33 @@ func (v VentaRepository) RegistrarPagoVenta(ctx context.Context, ventaId *int, r
 		)
 	}
 
+	// Los métodos con código se liquidan contra saldos internos; solo el efectivo puede superar el total (vuelto),
+	// así que lo pagado con saldos no puede exceder el total de la venta
+	queryCodigoMetodo := `SELECT codigo FROM metodo_pago WHERE id = $1`
+	codigosMetodo := make([]*string, len(request.Pagos))
+	var totalSaldos float64
+	for i, pago := range request.Pagos {
+		err = tx.QueryRow(ctx, queryCodigoMetodo, pago.MetodoPagoId).Scan(&codigosMetodo[i])
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, datatype.NewBadRequestError("El método de pago seleccionado no existe.")
+			}
+			log.Println("Error al obtener el método de pago:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if codigosMetodo[i] != nil {
+			switch *codigosMetodo[i] {
+			case domain.MetodoPagoCuentaCorriente, domain.MetodoPagoPuntos, domain.MetodoPagoTarjetaRegalo:
+				totalSaldos += pago.Monto
+			}
+		}
+	}
+	if totalSaldos > totalVenta+epsilon {
+		return nil, datatype.NewBadRequestError(
+			fmt.Sprintf("Lo pagado con saldos (%.2f) supera el total de la venta (%.2f); solo el efectivo admite vuelto.", totalSaldos, totalVenta),
+		)
+	}
+
 	// 4. Insertar los nuevos pagos
 	var pagoIds []int
 	queryPago := `
@@ -705,9 +1180,24 @@ func (v VentaRepository) RegistrarPagoVenta(ctx context.Context, ventaId *int, r
         VALUES ($1, $2, $3, $4)
         RETURNING id`
 
-	for _, pago := range request.Pagos {
+	for i, pago := range request.Pagos {
+		// Los métodos con código se liquidan contra saldos internos en la misma transacción
+		if codigoMetodo := codigosMetodo[i]; codigoMetodo != nil {
+			switch *codigoMetodo {
+			case domain.MetodoPagoCuentaCorriente:
+				err = cargarCuentaCorriente(ctx, tx, clienteId, sucursalId, pago.Monto, ventaId, pago.MetodoPagoId, pago.Referencia, usuarioId)
+			case domain.MetodoPagoPuntos:
+				err = pagarConPuntos(ctx, tx, clienteId, sucursalId, pago.Monto, ventaId, usuarioId)
+			case domain.MetodoPagoTarjetaRegalo:
+				err = canjearTarjetaRegalo(ctx, tx, pago.Referencia, pago.Monto, ventaId, usuarioId)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		var pagoId int
-		err = tx.QueryRow(ctx, queryPago, *ventaId, pago.MetodoPagoId, pago.Monto, pago.Referencia).Scan(&pagoId)
+		err = tx.QueryRow(ctx, queryPago, ventaId, pago.MetodoPagoId, pago.Monto, pago.Referencia).Scan(&pagoId)
 
 		if err != nil {
 			var pgErr *pgconn.PgError
@@ -722,39 +1212,48 @@ func (v VentaRepository) RegistrarPagoVenta(ctx context.Context, ventaId *int, r
 
 	// 5. Actualizar estado de la Venta a 'Completado'
 	queryUpdateVenta := `UPDATE venta SET estado = 'Completado', actualizado_en = NOW() WHERE id = $1`
-	_, err = tx.Exec(ctx, queryUpdateVenta, *ventaId)
+	_, err = tx.Exec(ctx, queryUpdateVenta, ventaId)
 	if err != nil {
 		log.Println("Error al actualizar estado de la venta:", err)
 		return nil, datatype.NewInternalServerErrorGeneric()
 	}
 
+	// Activar las tarjetas de regalo vendidas en esta venta
+	if err = activarTarjetasRegalo(ctx, tx, ventaId); err != nil {
+		return nil, err
+	}
+
+	// Acumular los puntos de fidelización del cliente (si la sucursal tiene programa activo)
+	if err = acumularPuntosVenta(ctx, tx, ventaId, clienteId, sucursalId, usuarioId); err != nil {
+		return nil, err
+	}
+
 	// 6. Sincronizar el USO DE SALA (Finalizar la sesión si la venta la incluye)
-	// Cuando el pago es exacto, cerramos la sesión (solo si aún está activa)
+	// Cuando el pago es exacto, cerramos la sesión (solo si aún está activa y si no quedan
+	// otras ventas de la misma sesión pendientes de pago, p. ej. partes de una cuenta dividida)
 	queryFinalizeUsoSala := `
         UPDATE uso_sala us 
         SET actualizado_en = NOW() 
         FROM venta v 
         WHERE v.id = $1 
           AND us.id = v.uso_sala_id 
-          AND us.estado NOT IN ('Finalizado', 'Cancelado');
+          AND us.estado NOT IN ('Finalizado', 'Cancelado')
+          AND NOT EXISTS (
+              SELECT 1 FROM venta v2
+              WHERE v2.uso_sala_id = us.id
+                AND v2.id <> v.id
+                AND v2.estado NOT IN ('Completado', 'Anulada', 'Dividida')
+          );
     `
 	// Si la venta está ligada a un uso_sala, este UPDATE lo marca como Finalizado.
 	// Si la venta es 'al paso' (uso_sala_id es NULL), esta consulta no hace nada (lo cual es correcto).
-	_, err = tx.Exec(ctx, queryFinalizeUsoSala, *ventaId)
+	_, err = tx.Exec(ctx, queryFinalizeUsoSala, ventaId)
 	if err != nil {
 		log.Println("Error al finalizar uso_sala:", err)
 		return nil, datatype.NewInternalServerErrorGeneric()
 	}
 
-	// 7. Commit
-	err = tx.Commit(ctx)
-	if err != nil {
-		log.Println("Error al confirmar transacción de pago:", err)
-		return nil, datatype.NewInternalServerErrorGeneric()
-	}
-	committed = true
-
-	return &pagoIds, nil
+	return pagoIds, nil
 }
 
 func (v VentaRepository) ObtenerVenta(ctx context.Context, id *int) (*domain.Venta, error) {
@@ -770,7 +1269,15 @@ SELECT
     v.actualizado_en, 
 	v.costo_tiempo_venta,
 	v.descuento_general,
+	v.descuento_tiempo,
+	v.venta_origen_id,
+	(CASE WHEN pt.id IS NOT NULL THEN json_build_object('id', pt.id, 'nombre', pt.nombre) END) AS promocion_tiempo,
 	v.observacion,
+	v.base_imponible,
+	v.impuesto,
+	v.nit,
+	v.razon_social,
+	v.tasa_impuesto_tiempo,
     -- Construye el objeto 'usuario' (el admin/cajero)
     json_build_object(
        'id',ua.id,
@@ -869,12 +1376,35 @@ SELECT
             'id',dv.id,
             'cantidad',dv.cantidad,
             'descuento',dv.descuento,
+            'descuentoPromocion',dv.descuento_promocion,
+            'tasaImpuesto',dv.tasa_impuesto,
+            'baseImponible',dv.base_imponible,
+            'impuesto',dv.impuesto,
+            'promocion',(CASE WHEN pr.id IS NOT NULL THEN json_build_object('id',pr.id,'nombre',pr.nombre) END),
+            'componentes',(SELECT json_agg(json_build_object(
+                'producto', json_build_object('id',pc.id,'nombre',pc.nombre),
+                'ubicacion', json_build_object('id',uc.id,'nombre',uc.nombre),
+                'cantidad', dvc.cantidad
+            ) ORDER BY dvc.id)
+             FROM public.detalle_venta_componente dvc
+             JOIN public.producto pc on dvc.producto_id = pc.id
+             LEFT JOIN public.ubicacion uc on dvc.ubicacion_id = uc.id
+             WHERE dvc.detalle_venta_id = dv.id),
+            'opciones',(SELECT json_agg(json_build_object(
+                'opcionId', dvo.opcion_producto_id,
+                'grupo', dvo.grupo,
+                'nombre', dvo.nombre,
+                'precioDelta', dvo.precio_delta
+            ) ORDER BY dvo.id)
+             FROM public.detalle_venta_opcion dvo
+             WHERE dvo.detalle_venta_id = dv.id),
             'precioVenta',dv.precio_venta
         )
     ORDER BY dv.id), '[]')
      FROM public.detalle_venta dv
      LEFT JOIN public.producto p on dv.producto_id = p.id
      LEFT JOIN public.ubicacion u2 on dv.ubicacion_id = u2.id
+     LEFT JOIN public.promocion pr on dv.promocion_id = pr.id
      WHERE dv.venta_id = v.id
     ) AS detalles,
     
@@ -884,6 +1414,7 @@ SELECT
            'metodoPago', json_build_object(
                'id',mp.id,
                'nombre',mp.nombre,
+               'codigo',mp.codigo,
                'estado',mp.estado
            ),
              'monto',vp.monto,
@@ -893,7 +1424,54 @@ SELECT
      FROM public.venta_pago vp
      LEFT JOIN public.metodo_pago mp on vp.metodo_pago_id = mp.id
      WHERE vp.venta_id = v.id
-    ) AS pagos
+    ) AS pagos,
+
+    -- SUB-CONSULTA 3: Tarjetas de regalo emitidas por la venta
+    (SELECT COALESCE(json_agg(
+        json_build_object(
+           'codigo', tr.codigo,
+           'montoInicial', tr.monto_inicial,
+           'estado', tr.estado,
+           'venceEn', tr.vence_en
+        )
+    ORDER BY tr.id), '[]')
+     FROM public.tarjeta_regalo tr
+     WHERE tr.venta_id = v.id
+    ) AS tarjetas_regalo,
+
+    -- SUB-CONSULTA 4: Documento fiscal emitido por el proveedor
+    (SELECT json_build_object(
+        'proveedor', df.proveedor,
+        'estado', df.estado,
+        'numero', df.numero,
+        'codigoAutorizacion', df.codigo_autorizacion,
+        'mensaje', df.mensaje,
+        'emitidoEn', df.emitido_en
+     )
+     FROM public.documento_fiscal df
+     WHERE df.venta_id = v.id
+    ) AS documento_fiscal,
+
+    -- SUB-CONSULTA 5: Excepciones autorizadas por un supervisor
+    (SELECT json_agg(
+        json_build_object(
+           'id', av.id,
+           'ventaId', av.venta_id,
+           'codigoVenta', v.codigo_venta,
+           'tipo', av.tipo,
+           'metodo', av.metodo,
+           'detalle', av.detalle,
+           'motivo', av.motivo,
+           'supervisor', json_build_object('id', sup.id, 'username', sup.username),
+           'usuario', CASE WHEN ua2.id IS NOT NULL THEN json_build_object('id', ua2.id, 'username', ua2.username) END,
+           'creadoEn', av.creado_en
+        )
+    ORDER BY av.id)
+     FROM public.autorizacion_venta av
+     JOIN public.usuario_admin sup ON av.supervisor_id = sup.id
+     LEFT JOIN public.usuario_admin ua2 ON av.usuario_id = ua2.id
+     WHERE av.venta_id = v.id
+    ) AS autorizaciones
 FROM venta v
 LEFT JOIN public.usuario_admin ua on v.usuario_id = ua.id
 LEFT JOIN public.cliente c on v.cliente_id = c.id
@@ -903,13 +1481,16 @@ LEFT JOIN public.dispositivo d on s2.dispositivo_id = d.id
 LEFT JOIN public.usuario u on d.usuario_id = u.id
 LEFT JOIN public.uso_sala us on v.uso_sala_id = us.id
 LEFT JOIN public.cliente c1 on us.cliente_id = c1.id
+LEFT JOIN public.promocion pt on v.promocion_tiempo_id = pt.id
 WHERE v.id = $2
-GROUP BY v.id, ua.id, c.id, s1.id, s2.id, d.id, u.id, us.id, c1.id
+GROUP BY v.id, ua.id, c.id, s1.id, s2.id, d.id, u.id, us.id, c1.id, pt.id
 LIMIT 1
 	`
 	var item domain.Venta
 	err := v.pool.QueryRow(ctx, query, fullHostname, *id).
-		Scan(&item.Id, &item.CodigoVenta, &item.Total, &item.Estado, &item.CreadoEn, &item.ActualizadoEn, &item.CostoTiempoVenta, &item.DescuentoGeneral, &item.Observacion, &item.Usuario, &item.Cliente, &item.Sucursal, &item.Sala, &item.UsoSala, &item.Detalles, &item.Pagos)
+		Scan(&item.Id, &item.CodigoVenta, &item.Total, &item.Estado, &item.CreadoEn, &item.ActualizadoEn, &item.CostoTiempoVenta, &item.DescuentoGeneral, &item.DescuentoTiempo, &item.VentaOrigenId, &item.PromocionTiempo, &item.Observacion,
+			&item.BaseImponible, &item.Impuesto, &item.Nit, &item.RazonSocial, &item.TasaImpuestoTiempo, &item.Usuario, &item.Cliente, &item.Sucursal, &item.Sala, &item.UsoSala, &item.Detalles, &item.Pagos, &item.TarjetasRegalo,
+			&item.DocumentoFiscal, &item.Autorizaciones)
 	if err != nil {
 		log.Println("Error al obtener compra:", err)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -920,6 +1501,1107 @@ LIMIT 1
 	return &item, nil
 }
 
+func (v VentaRepository) DividirVenta(ctx context.Context, ventaId *int, request *domain.DividirVentaRequest) (*[]int, error) {
+	type detalleOrigen struct {
+		ProductoId         int
+		UbicacionId        *int
+		Cantidad           int64
+		PrecioVenta        float64
+		Descuento          float64
+		PromocionId        *int
+		DescuentoPromocion float64
+		TasaImpuesto       float64
+		CostoUnitario      *float64
+		Asignado           int64
+		Componentes        []consumoStock
+		PorKit             map[int]int
+		Opciones           []opcionVenta
+	}
+	const epsilon = 0.001
+
+	// 1. Iniciar transacción
+	tx, err := v.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	// 2. Bloquear la venta original; solo se dividen ventas pendientes de pago
+	var estado string
+	var sucursalId, usuarioId int
+	var salaId *int
+	var usoSalaId *int64
+	var clienteId *int64
+	var costoTiempo, descuentoTiempo, descuentoGeneral float64
+	var promocionTiempoId, cuponPromocionId *int
+	var observacion, nit, razonSocial *string
+	var tasaTiempo float64
+	queryVenta := `
+        SELECT estado, sucursal_id, usuario_id, sala_id, uso_sala_id, cliente_id, costo_tiempo_venta, descuento_tiempo,
+               descuento_general, promocion_tiempo_id, observacion, nit, razon_social, tasa_impuesto_tiempo, cupon_promocion_id
+        FROM venta
+        WHERE id = $1
+        FOR UPDATE`
+	err = tx.QueryRow(ctx, queryVenta, *ventaId).Scan(&estado, &sucursalId, &usuarioId, &salaId, &usoSalaId, &clienteId, &costoTiempo,
+		&descuentoTiempo, &descuentoGeneral, &promocionTiempoId, &observacion, &nit, &razonSocial, &tasaTiempo, &cuponPromocionId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, datatype.NewNotFoundError("La venta no fue encontrada.")
+		}
+		log.Println("Error al bloquear la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if estado == "Completado" || estado == "Anulada" || estado == "Dividida" {
+		return nil, datatype.NewBadRequestError(fmt.Sprintf("Esta venta está en estado '%s' y no se puede dividir.", estado))
+	}
+
+	var tienePagos bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM venta_pago WHERE venta_id = $1)`, *ventaId).Scan(&tienePagos)
+	if err != nil {
+		log.Println("Error al verificar pagos de la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if tienePagos {
+		return nil, datatype.NewBadRequestError("La venta ya tiene pagos registrados y no se puede dividir.")
+	}
+
+	// Las tarjetas de regalo quedan ligadas a la venta que las emitió
+	var tieneTarjetas bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM tarjeta_regalo WHERE venta_id = $1)`, *ventaId).Scan(&tieneTarjetas)
+	if err != nil {
+		log.Println("Error al verificar tarjetas de regalo de la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if tieneTarjetas {
+		return nil, datatype.NewBadRequestError("La venta incluye tarjetas de regalo y no se puede dividir.")
+	}
+
+	// 3. Detalles originales
+	queryDetalles := `
+        SELECT id, producto_id, ubicacion_id, cantidad, precio_venta, descuento, promocion_id, descuento_promocion, tasa_impuesto, costo_unitario
+        FROM detalle_venta
+        WHERE venta_id = $1`
+	rows, err := tx.Query(ctx, queryDetalles, *ventaId)
+	if err != nil {
+		log.Println("Error al obtener detalles de la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	detalles := make(map[int]*detalleOrigen)
+	for rows.Next() {
+		var id int
+		var d detalleOrigen
+		if err := rows.Scan(&id, &d.ProductoId, &d.UbicacionId, &d.Cantidad, &d.PrecioVenta, &d.Descuento, &d.PromocionId, &d.DescuentoPromocion, &d.TasaImpuesto, &d.CostoUnitario); err != nil {
+			rows.Close()
+			log.Println("Error al escanear detalle de venta:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		detalles[id] = &d
+	}
+	rows.Close()
+
+	// Stock consumido por las líneas compuestas (kits y opciones); se reparte entre las partes según las unidades asignadas
+	queryComponentes := `
+        SELECT dvc.detalle_venta_id, dvc.producto_id, dvc.ubicacion_id, dvc.cantidad
+        FROM detalle_venta_componente dvc
+        JOIN detalle_venta dv ON dvc.detalle_venta_id = dv.id
+        WHERE dv.venta_id = $1
+        ORDER BY dvc.id`
+	rows, err = tx.Query(ctx, queryComponentes, *ventaId)
+	if err != nil {
+		log.Println("Error al obtener componentes de la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	for rows.Next() {
+		var detalleId int
+		var c consumoStock
+		if err := rows.Scan(&detalleId, &c.ProductoId, &c.UbicacionId, &c.Cantidad); err != nil {
+			rows.Close()
+			log.Println("Error al escanear componente de venta:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if d, ok := detalles[detalleId]; ok {
+			d.Componentes = append(d.Componentes, c)
+		}
+	}
+	rows.Close()
+
+	// Lotes que consumió la venta; cada parte se lleva los de las unidades que recibe
+	lotesVenta, err := obtenerLotesVenta(ctx, tx, *ventaId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Opciones elegidas en cada línea; se copian tal cual a las nuevas ventas
+	queryOpciones := `
+        SELECT dvo.detalle_venta_id, dvo.opcion_producto_id, dvo.grupo, dvo.nombre, dvo.precio_delta
+        FROM detalle_venta_opcion dvo
+        JOIN detalle_venta dv ON dvo.detalle_venta_id = dv.id
+        WHERE dv.venta_id = $1
+        ORDER BY dvo.id`
+	rows, err = tx.Query(ctx, queryOpciones, *ventaId)
+	if err != nil {
+		log.Println("Error al obtener opciones de la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	for rows.Next() {
+		var detalleId int
+		var o opcionVenta
+		if err := rows.Scan(&detalleId, &o.OpcionId, &o.Grupo, &o.Nombre, &o.PrecioDelta); err != nil {
+			rows.Close()
+			log.Println("Error al escanear opción de venta:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if d, ok := detalles[detalleId]; ok {
+			d.Opciones = append(d.Opciones, o)
+		}
+	}
+	rows.Close()
+
+	for _, d := range detalles {
+		if len(d.Componentes) == 0 {
+			continue
+		}
+		d.PorKit = make(map[int]int)
+		for _, c := range d.Componentes {
+			d.PorKit[c.ProductoId] += c.Cantidad
+		}
+		for productoId := range d.PorKit {
+			d.PorKit[productoId] /= int(d.Cantidad)
+		}
+	}
+
+	// 4. Validar que todo el tiempo y todas las cantidades queden asignadas exactamente una vez
+	var tiempoAsignado float64
+	subtotales := make([]float64, len(request.Partes))
+	var subtotalTotal float64
+	for i, parte := range request.Partes {
+		tiempoAsignado += parte.CostoTiempo
+		for _, pd := range parte.Detalles {
+			d, ok := detalles[pd.DetalleVentaId]
+			if !ok {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El detalle %d no pertenece a la venta.", pd.DetalleVentaId))
+			}
+			d.Asignado += pd.Cantidad
+			proporcion := float64(pd.Cantidad) / float64(d.Cantidad)
+			subtotales[i] += float64(pd.Cantidad)*d.PrecioVenta - d.Descuento*proporcion
+		}
+		if costoTiempo > 0 {
+			subtotales[i] += parte.CostoTiempo - descuentoTiempo*parte.CostoTiempo/costoTiempo
+		}
+		subtotalTotal += subtotales[i]
+	}
+	if math.Abs(tiempoAsignado-costoTiempo) > epsilon {
+		return nil, datatype.NewBadRequestError(fmt.Sprintf("El tiempo de sala asignado (%.2f) no coincide con el de la venta (%.2f).", tiempoAsignado, costoTiempo))
+	}
+	for id, d := range detalles {
+		if d.Asignado != d.Cantidad {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("La cantidad asignada del detalle %d (%d) no coincide con la vendida (%d).", id, d.Asignado, d.Cantidad))
+		}
+	}
+
+	// 5. Crear una venta por parte; los descuentos se reparten en proporción al importe de cada parte
+	queryInsertVenta := `
+        INSERT INTO venta (codigo_venta, sucursal_id, sala_id, uso_sala_id, usuario_id, cliente_id, total, descuento_general, costo_tiempo_venta, observacion, estado, creado_en,
+                           descuento_tiempo, promocion_tiempo_id, venta_origen_id, nit, razon_social, cupon_promocion_id)
+        VALUES (nextval('seq_codigo_venta'), $1, $2, $3, $4, $5, $6, $7, $8, $9, 'Completada', NOW(), $10, $11, $12, $13, $14, $15)
+        RETURNING id`
+
+	// El redondeo del descuento de tiempo lo absorbe la última parte que lleva tiempo de sala
+	ultimaParteTiempo := -1
+	for i, parte := range request.Partes {
+		if parte.CostoTiempo > 0 {
+			ultimaParteTiempo = i
+		}
+	}
+
+	var ventaIds []int
+	var descuentoGeneralAsignado, descuentoTiempoAsignado float64
+	for i, parte := range request.Partes {
+		var descuentoGeneralParte, descuentoTiempoParte float64
+		if i == len(request.Partes)-1 {
+			// La última parte absorbe el redondeo
+			descuentoGeneralParte = descuentoGeneral - descuentoGeneralAsignado
+		} else if subtotalTotal > 0 {
+			descuentoGeneralParte = math.Round(descuentoGeneral*subtotales[i]/subtotalTotal*100) / 100
+		}
+		if i == ultimaParteTiempo {
+			descuentoTiempoParte = descuentoTiempo - descuentoTiempoAsignado
+		} else if costoTiempo > 0 {
+			descuentoTiempoParte = math.Round(descuentoTiempo*parte.CostoTiempo/costoTiempo*100) / 100
+		}
+		descuentoGeneralAsignado += descuentoGeneralParte
+		descuentoTiempoAsignado += descuentoTiempoParte
+
+		var totalParte float64
+		var filas [][]interface{}
+		var compuestos []detalleCompuesto
+		var consumosParte []consumoStock
+		for _, pd := range parte.Detalles {
+			d := detalles[pd.DetalleVentaId]
+			proporcion := float64(pd.Cantidad) / float64(d.Cantidad)
+			descuentoLinea := d.Descuento * proporcion
+			totalParte += float64(pd.Cantidad)*d.PrecioVenta - descuentoLinea
+			fila := []interface{}{
+				nil, d.ProductoId, d.UbicacionId, pd.Cantidad, d.PrecioVenta, descuentoLinea, d.PromocionId, d.DescuentoPromocion * proporcion,
+				d.TasaImpuesto, d.CostoUnitario,
+			}
+			if len(d.Componentes) > 0 || len(d.Opciones) > 0 {
+				componentes := tomarComponentes(d.Componentes, d.PorKit, int(pd.Cantidad))
+				for _, c := range componentes {
+					c.Lotes = tomarLotesVenta(lotesVenta, c.ProductoId, c.UbicacionId, c.Cantidad)
+					consumosParte = append(consumosParte, c)
+				}
+				compuestos = append(compuestos, detalleCompuesto{
+					Fila:        fila,
+					Componentes: componentes,
+					Opciones:    d.Opciones,
+				})
+			} else {
+				if d.UbicacionId != nil {
+					consumosParte = append(consumosParte, consumoStock{ProductoId: d.ProductoId, UbicacionId: *d.UbicacionId, Cantidad: int(pd.Cantidad),
+						Lotes: tomarLotesVenta(lotesVenta, d.ProductoId, *d.UbicacionId, int(pd.Cantidad))})
+				}
+				filas = append(filas, fila)
+			}
+		}
+		totalParte += parte.CostoTiempo - descuentoTiempoParte - descuentoGeneralParte
+
+		// Los datos de facturación solo se conservan en las partes que mantienen al cliente original
+		clienteParte := clienteId
+		nitParte, razonSocialParte := nit, razonSocial
+		if parte.ClienteId != nil {
+			clienteParte = parte.ClienteId
+			if clienteId == nil || *parte.ClienteId != *clienteId {
+				nitParte, razonSocialParte = nil, nil
+			}
+		}
+		var promocionTiempoParte *int
+		if descuentoTiempoParte > 0 {
+			promocionTiempoParte = promocionTiempoId
+		}
+
+		// El uso del cupón queda en la primera parte, así su anulación lo libera una sola vez
+		var cuponParte *int
+		if i == 0 {
+			cuponParte = cuponPromocionId
+		}
+
+		var nuevaVentaId int
+		err = tx.QueryRow(ctx, queryInsertVenta, sucursalId, salaId, usoSalaId, usuarioId, clienteParte, totalParte, descuentoGeneralParte,
+			parte.CostoTiempo, observacion, descuentoTiempoParte, promocionTiempoParte, *ventaId, nitParte, razonSocialParte, cuponParte).Scan(&nuevaVentaId)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El cliente de la parte %d no existe.", i+1))
+			}
+			log.Println("Error al registrar venta dividida:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+
+		if len(filas) > 0 {
+			for j := range filas {
+				filas[j][0] = nuevaVentaId
+			}
+			_, err = tx.CopyFrom(ctx, pgx.Identifier{"detalle_venta"},
+				[]string{"venta_id", "producto_id", "ubicacion_id", "cantidad", "precio_venta", "descuento", "promocion_id", "descuento_promocion", "tasa_impuesto",
+					"costo_unitario"},
+				pgx.CopyFromRows(filas))
+			if err != nil {
+				log.Println("Error al registrar detalles de venta dividida:", err)
+				return nil, datatype.NewInternalServerErrorGeneric()
+			}
+		}
+		for _, k := range compuestos {
+			k.Fila[0] = nuevaVentaId
+			if err = insertarDetalleCompuesto(ctx, tx, k); err != nil {
+				return nil, err
+			}
+		}
+		if err = registrarLotesVenta(ctx, tx, nuevaVentaId, consumosParte); err != nil {
+			return nil, err
+		}
+		if err = calcularImpuestosVenta(ctx, tx, nuevaVentaId, tasaTiempo); err != nil {
+			return nil, err
+		}
+		ventaIds = append(ventaIds, nuevaVentaId)
+	}
+
+	// 6. La venta original queda como referencia; el stock y el tiempo de sala ya están registrados en ella
+	_, err = tx.Exec(ctx, `UPDATE venta SET estado = 'Dividida', actualizado_en = NOW() WHERE id = $1`, *ventaId)
+	if err != nil {
+		log.Println("Error al actualizar estado de la venta dividida:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	// 7. Commit
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar transacción de división:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+
+	return &ventaIds, nil
+}
+
+// consumoStock es lo descontado de una ubicación vendible para un producto.
+type consumoStock struct {
+	ProductoId  int
+	UbicacionId int
+	Cantidad    int
+	// Saldo es el stock que quedó en la ubicación, para el kardex
+	Saldo int
+	// Lotes es lo que salió de cada lote, para devolverlo al anular
+	Lotes []consumoLote
+}
+
+// stockRequerido es lo que una línea compuesta debe descontar de un producto.
+type stockRequerido struct {
+	ProductoId int
+	Nombre     string
+	Cantidad   int
+}
+
+type componenteKit struct {
+	ProductoId      int
+	Nombre          string
+	EsInventariable bool
+	Cantidad        int
+}
+
+// detalleCompuesto es una línea de kit o con opciones, pendiente de insertar junto con el stock que consumió y las opciones elegidas.
+type detalleCompuesto struct {
+	Fila        []interface{}
+	Componentes []consumoStock
+	Opciones    []opcionVenta
+}
+
+// opcionVenta es una opción elegida en la línea; el nombre y el precio se guardan como copia en detalle_venta_opcion.
+type opcionVenta struct {
+	OpcionId           int
+	GrupoId            int
+	Grupo              string
+	TipoGrupo          string
+	Nombre             string
+	PrecioDelta        float64
+	ProductoStockId    *int
+	NombreStock        string
+	StockInventariable bool
+	CantidadStock      int
+}
+
+// obtenerComponentesKit devuelve la receta del producto en la sucursal; vacía si no es un kit.
+func obtenerComponentesKit(ctx context.Context, tx pgx.Tx, productoId, sucursalId int) ([]componenteKit, error) {
+	query := `
+        SELECT pc.componente_id, p.nombre, p.es_inventariable, pc.cantidad
+        FROM producto_componente pc
+        JOIN producto p ON pc.componente_id = p.id
+        WHERE pc.producto_id = $1 AND pc.sucursal_id = $2
+        ORDER BY pc.componente_id`
+	rows, err := tx.Query(ctx, query, productoId, sucursalId)
+	if err != nil {
+		log.Println("Error al obtener componentes del kit:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	var componentes []componenteKit
+	for rows.Next() {
+		var c componenteKit
+		if err := rows.Scan(&c.ProductoId, &c.Nombre, &c.EsInventariable, &c.Cantidad); err != nil {
+			log.Println("Error al escanear componente del kit:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		componentes = append(componentes, c)
+	}
+	return componentes, nil
+}
+
+// faltantesStock acumula lo vendido sin stock suficiente en una venta sincronizada desde un POS sin conexión.
+// La venta ya ocurrió, así que en lugar de rechazarla el stock queda en negativo o el faltante se marca para revisión.
+type faltantesStock struct {
+	politica string
+	items    []domain.FaltanteStock
+}
+
+// descontarStockVendible resta la cantidad de las ubicaciones vendibles de la sucursal siguiendo prioridad_venta y,
+// dentro de cada ubicación, los lotes por fecha de vencimiento. Los lotes vencidos no se venden. Sin faltantes
+// (venta en línea) el stock insuficiente es un error.
+func descontarStockVendible(ctx context.Context, tx pgx.Tx, productoId, sucursalId, cantidad int, nombreProducto string, faltantes *faltantesStock) ([]consumoStock, error) {
+	type stockDisponible struct {
+		UbicacionId int
+		Stock       int
+	}
+
+	queryFindStock := `
+        SELECT i.stock - COALESCE((
+                   SELECT SUM(il.stock) FROM inventario_lote il
+                   WHERE il.producto_id = i.producto_id AND il.ubicacion_id = i.ubicacion_id
+                     AND il.fecha_vencimiento < CURRENT_DATE
+               ), 0)::int, i.ubicacion_id
+        FROM inventario i
+        JOIN ubicacion u ON i.ubicacion_id = u.id
+        WHERE i.producto_id = $1 AND u.sucursal_id = $2 AND u.es_vendible = true AND u.estado = 'Activo'
+        ORDER BY u.prioridad_venta FOR UPDATE OF i`
+	rows, err := tx.Query(ctx, queryFindStock, productoId, sucursalId)
+	if err != nil {
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	var stockDisponibleList []stockDisponible
+	var stockTotalVendible = 0
+	var ubicacionPrincipalId *int
+	for rows.Next() {
+		var s stockDisponible
+		err := rows.Scan(&s.Stock, &s.UbicacionId)
+		if err != nil {
+			rows.Close()
+			log.Println("Error al escanear:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		if ubicacionPrincipalId == nil {
+			ubicacionPrincipalId = &s.UbicacionId
+		}
+		if s.Stock > 0 {
+			stockDisponibleList = append(stockDisponibleList, s)
+			stockTotalVendible += s.Stock
+		}
+	}
+	rows.Close()
+
+	var faltante int
+	if stockTotalVendible < cantidad {
+		if faltantes == nil {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("Stock insuficiente para %s. Disponible: %d", nombreProducto, max(stockTotalVendible, 0)))
+		}
+		faltante = cantidad - stockTotalVendible
+		cantidad = stockTotalVendible
+		faltantes.items = append(faltantes.items, domain.FaltanteStock{ProductoId: productoId, Nombre: nombreProducto, Cantidad: faltante})
+	}
+
+	var consumos []consumoStock
+	cantidadARestar := cantidad
+	for _, s := range stockDisponibleList {
+		if cantidadARestar == 0 {
+			break
+		}
+		cantidadDescontada := min(cantidadARestar, s.Stock)
+		cantidadARestar -= cantidadDescontada
+
+		var saldo int
+		err = tx.QueryRow(ctx, `UPDATE inventario SET stock = stock - $1 WHERE producto_id = $2 AND ubicacion_id = $3 RETURNING stock`,
+			cantidadDescontada, productoId, s.UbicacionId).Scan(&saldo)
+		if err != nil {
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		lotes, err := descontarLotes(ctx, tx, productoId, s.UbicacionId, cantidadDescontada, saldo, false)
+		if err != nil {
+			return nil, err
+		}
+		consumos = append(consumos, consumoStock{ProductoId: productoId, UbicacionId: s.UbicacionId, Cantidad: cantidadDescontada, Saldo: saldo, Lotes: lotes})
+	}
+
+	// Con la política de stock negativo el faltante sale de la ubicación vendible de mayor prioridad
+	if faltante > 0 && faltantes.politica == domain.PoliticaStockPermitirNegativo {
+		if ubicacionPrincipalId == nil {
+			var ubicacionId int
+			queryUbicacion := `
+                SELECT id FROM ubicacion
+                WHERE sucursal_id = $1 AND es_vendible = true AND estado = 'Activo'
+                ORDER BY prioridad_venta LIMIT 1`
+			err = tx.QueryRow(ctx, queryUbicacion, sucursalId).Scan(&ubicacionId)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, datatype.NewBadRequestError("La sucursal no tiene ubicaciones vendibles activas.")
+				}
+				log.Println("Error al obtener ubicación vendible:", err)
+				return nil, datatype.NewInternalServerErrorGeneric()
+			}
+			ubicacionPrincipalId = &ubicacionId
+		}
+		saldo, err := sumarStock(ctx, tx, productoId, *ubicacionPrincipalId, -faltante)
+		if err != nil {
+			log.Println("Error al dejar stock negativo:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		lotes, err := descontarLotes(ctx, tx, productoId, *ubicacionPrincipalId, faltante, saldo, false)
+		if err != nil {
+			return nil, err
+		}
+		consumos = append(consumos, consumoStock{ProductoId: productoId, UbicacionId: *ubicacionPrincipalId, Cantidad: faltante, Saldo: saldo, Lotes: lotes})
+	}
+	return consumos, nil
+}
+
+// movimientosDeConsumos convierte lo descontado por una venta en salidas del kardex.
+func movimientosDeConsumos(consumos []consumoStock) []movimientoInventario {
+	movimientos := make([]movimientoInventario, 0, len(consumos))
+	for _, c := range consumos {
+		movimientos = append(movimientos, movimientoInventario{ProductoId: c.ProductoId, UbicacionId: c.UbicacionId, Cantidad: -c.Cantidad, Saldo: c.Saldo})
+	}
+	return movimientos
+}
+
+// ubicacionProducto identifica el stock de un producto en una ubicación.
+type ubicacionProducto struct {
+	ProductoId  int
+	UbicacionId int
+}
+
+// registrarLotesVenta guarda los lotes de los que salió el stock de la venta para devolverlos al anularla.
+func registrarLotesVenta(ctx context.Context, tx pgx.Tx, ventaId int, consumos []consumoStock) error {
+	var filas [][]interface{}
+	for _, c := range consumos {
+		for _, l := range c.Lotes {
+			filas = append(filas, []interface{}{ventaId, c.ProductoId, c.UbicacionId, l.Lote, l.FechaVencimiento, l.Cantidad})
+		}
+	}
+	if len(filas) == 0 {
+		return nil
+	}
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"venta_lote"},
+		[]string{"venta_id", "producto_id", "ubicacion_id", "lote", "fecha_vencimiento", "cantidad"},
+		pgx.CopyFromRows(filas))
+	if err != nil {
+		log.Println("Error al registrar lotes de la venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+func obtenerLotesVenta(ctx context.Context, tx pgx.Tx, ventaId int) (map[ubicacionProducto][]consumoLote, error) {
+	query := `SELECT producto_id, ubicacion_id, lote, fecha_vencimiento, cantidad FROM venta_lote WHERE venta_id = $1 ORDER BY id`
+	rows, err := tx.Query(ctx, query, ventaId)
+	if err != nil {
+		log.Println("Error al obtener lotes de la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	lotes := make(map[ubicacionProducto][]consumoLote)
+	for rows.Next() {
+		var clave ubicacionProducto
+		var l consumoLote
+		if err := rows.Scan(&clave.ProductoId, &clave.UbicacionId, &l.Lote, &l.FechaVencimiento, &l.Cantidad); err != nil {
+			log.Println("Error al escanear lote de la venta:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		lotes[clave] = append(lotes[clave], l)
+	}
+	if err = rows.Err(); err != nil {
+		log.Println("Error al obtener lotes de la venta:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return lotes, nil
+}
+
+// tomarLotesVenta saca de los lotes de la venta hasta cantidad unidades del producto en la ubicación; lo que no
+// salió de un lote vuelve como stock sin lote.
+func tomarLotesVenta(lotes map[ubicacionProducto][]consumoLote, productoId, ubicacionId, cantidad int) []consumoLote {
+	clave := ubicacionProducto{ProductoId: productoId, UbicacionId: ubicacionId}
+	var tomados []consumoLote
+	pendientes := lotes[clave]
+	for cantidad > 0 && len(pendientes) > 0 {
+		l := pendientes[0]
+		l.Cantidad = min(cantidad, pendientes[0].Cantidad)
+		tomados = append(tomados, l)
+		cantidad -= l.Cantidad
+		pendientes[0].Cantidad -= l.Cantidad
+		if pendientes[0].Cantidad == 0 {
+			pendientes = pendientes[1:]
+		}
+	}
+	lotes[clave] = pendientes
+	return tomados
+}
+
+// registrarCostoVenta fija en cada línea de la venta el costo promedio vigente en la sucursal. Las líneas compuestas
+// cuestan lo que cuestan los componentes que consumieron; un producto sin costo registrado, o una línea compuesta con
+// algún componente sin costo, queda en NULL para contarse como vendido sin costo en lugar de subestimar el costo.
+func registrarCostoVenta(ctx context.Context, tx pgx.Tx, ventaId, sucursalId int) error {
+	query := `
+        UPDATE detalle_venta dv
+        SET costo_unitario = CASE
+            WHEN EXISTS (SELECT 1 FROM detalle_venta_componente dvc WHERE dvc.detalle_venta_id = dv.id) THEN
+                (SELECT CASE WHEN COUNT(*) FILTER (WHERE cp.producto_id IS NULL) = 0
+                             THEN ROUND(SUM(dvc.cantidad * cp.costo_promedio) / dv.cantidad, 4) END
+                 FROM detalle_venta_componente dvc
+                 LEFT JOIN costo_producto cp ON cp.producto_id = dvc.producto_id AND cp.sucursal_id = $2
+                 WHERE dvc.detalle_venta_id = dv.id)
+            ELSE (SELECT cp.costo_promedio FROM costo_producto cp WHERE cp.producto_id = dv.producto_id AND cp.sucursal_id = $2)
+        END
+        WHERE dv.venta_id = $1 AND dv.costo_unitario IS NULL`
+	if _, err := tx.Exec(ctx, query, ventaId, sucursalId); err != nil {
+		log.Println("Error al registrar costo de la venta:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// insertarDetalleCompuesto guarda la línea del kit y el stock que consumió cada componente.
+func insertarDetalleCompuesto(ctx context.Context, tx pgx.Tx, detalle detalleCompuesto) error {
+	queryDetalle := `
+        INSERT INTO detalle_venta (venta_id, producto_id, ubicacion_id, cantidad, precio_venta, descuento, promocion_id, descuento_promocion, tasa_impuesto,
+                                   costo_unitario)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id`
+	var detalleVentaId int
+	if err := tx.QueryRow(ctx, queryDetalle, detalle.Fila...).Scan(&detalleVentaId); err != nil {
+		log.Println("Error al registrar detalle de kit:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	if len(detalle.Componentes) > 0 {
+		var filas [][]interface{}
+		for _, c := range detalle.Componentes {
+			filas = append(filas, []interface{}{detalleVentaId, c.ProductoId, c.UbicacionId, c.Cantidad})
+		}
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"detalle_venta_componente"},
+			[]string{"detalle_venta_id", "producto_id", "ubicacion_id", "cantidad"},
+			pgx.CopyFromRows(filas))
+		if err != nil {
+			log.Println("Error al registrar componentes del kit:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+	if len(detalle.Opciones) > 0 {
+		var filas [][]interface{}
+		for _, o := range detalle.Opciones {
+			filas = append(filas, []interface{}{detalleVentaId, o.OpcionId, o.Grupo, o.Nombre, o.PrecioDelta})
+		}
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"detalle_venta_opcion"},
+			[]string{"detalle_venta_id", "opcion_producto_id", "grupo", "nombre", "precio_delta"},
+			pgx.CopyFromRows(filas))
+		if err != nil {
+			log.Println("Error al registrar opciones del detalle:", err)
+			return datatype.NewInternalServerErrorGeneric()
+		}
+	}
+	return nil
+}
+
+// resolverOpciones valida las opciones elegidas contra los grupos activos del producto (mínimos, máximos y pertenencia).
+func resolverOpciones(ctx context.Context, tx pgx.Tx, productoId int, opcionIds []int, nombreProducto string) ([]opcionVenta, error) {
+	type grupoSeleccion struct {
+		Nombre   string
+		Minima   int
+		Maxima   int
+		Elegidas int
+	}
+
+	query := `
+        SELECT g.id, g.nombre, g.tipo, g.seleccion_minima, g.seleccion_maxima,
+               o.id, o.nombre, o.precio_delta, o.producto_stock_id, o.cantidad_stock,
+               COALESCE(ps.nombre, ''), COALESCE(ps.es_inventariable, false)
+        FROM grupo_opcion g
+        JOIN opcion_producto o ON o.grupo_opcion_id = g.id AND o.estado = 'Activo'
+        LEFT JOIN producto ps ON o.producto_stock_id = ps.id
+        WHERE g.producto_id = $1 AND g.estado = 'Activo'
+        ORDER BY g.id, o.id`
+	rows, err := tx.Query(ctx, query, productoId)
+	if err != nil {
+		log.Println("Error al obtener opciones del producto:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	grupos := make(map[int]*grupoSeleccion)
+	var ordenGrupos []int
+	disponibles := make(map[int]opcionVenta)
+	for rows.Next() {
+		var g grupoSeleccion
+		var o opcionVenta
+		err := rows.Scan(&o.GrupoId, &g.Nombre, &o.TipoGrupo, &g.Minima, &g.Maxima,
+			&o.OpcionId, &o.Nombre, &o.PrecioDelta, &o.ProductoStockId, &o.CantidadStock, &o.NombreStock, &o.StockInventariable)
+		if err != nil {
+			rows.Close()
+			log.Println("Error al escanear opción del producto:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		o.Grupo = g.Nombre
+		if _, ok := grupos[o.GrupoId]; !ok {
+			grupos[o.GrupoId] = &g
+			ordenGrupos = append(ordenGrupos, o.GrupoId)
+		}
+		disponibles[o.OpcionId] = o
+	}
+	rows.Close()
+
+	var elegidas []opcionVenta
+	vistas := make(map[int]bool)
+	for _, id := range opcionIds {
+		o, ok := disponibles[id]
+		if !ok {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("La opción %d no está disponible para %s.", id, nombreProducto))
+		}
+		if vistas[id] {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("La opción '%s' está repetida en %s.", o.Nombre, nombreProducto))
+		}
+		vistas[id] = true
+		grupos[o.GrupoId].Elegidas++
+		elegidas = append(elegidas, o)
+	}
+	for _, id := range ordenGrupos {
+		g := grupos[id]
+		if g.Elegidas < g.Minima {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("Debe elegir al menos %d opción(es) de '%s' para %s.", g.Minima, g.Nombre, nombreProducto))
+		}
+		if g.Elegidas > g.Maxima {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("Puede elegir como máximo %d opción(es) de '%s' para %s.", g.Maxima, g.Nombre, nombreProducto))
+		}
+	}
+	return elegidas, nil
+}
+
+// tomarComponentes retira de los consumos de un kit lo que corresponde a 'kits' unidades.
+func tomarComponentes(consumos []consumoStock, porKit map[int]int, kits int) []consumoStock {
+	pendiente := make(map[int]int)
+	for productoId, cantidad := range porKit {
+		pendiente[productoId] = cantidad * kits
+	}
+	var tomados []consumoStock
+	for i := range consumos {
+		c := &consumos[i]
+		cantidad := min(pendiente[c.ProductoId], c.Cantidad)
+		if cantidad <= 0 {
+			continue
+		}
+		c.Cantidad -= cantidad
+		pendiente[c.ProductoId] -= cantidad
+		tomados = append(tomados, consumoStock{ProductoId: c.ProductoId, UbicacionId: c.UbicacionId, Cantidad: cantidad})
+	}
+	return tomados
+}
+
+// ObtenerDocumentoFiscal devuelve el documento fiscal de la venta o nil si aún no se emitió.
+func (v VentaRepository) ObtenerDocumentoFiscal(ctx context.Context, ventaId *int) (*domain.DocumentoFiscal, error) {
+	var doc domain.DocumentoFiscal
+	query := `
+        SELECT proveedor, estado, numero, codigo_autorizacion, mensaje, emitido_en
+        FROM documento_fiscal
+        WHERE venta_id = $1`
+	err := v.pool.QueryRow(ctx, query, *ventaId).Scan(&doc.Proveedor, &doc.Estado, &doc.Numero, &doc.CodigoAutorizacion, &doc.Mensaje, &doc.EmitidoEn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		log.Println("Error al obtener documento fiscal:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &doc, nil
+}
+
+// ObtenerUltimoNumeroFiscal devuelve el mayor número de factura emitido por el proveedor en la sucursal (0 si no hay).
+func (v VentaRepository) ObtenerUltimoNumeroFiscal(ctx context.Context, sucursalId int, proveedor string) (int64, error) {
+	var numero int64
+	query := `
+        SELECT COALESCE(MAX(CASE WHEN df.numero ~ '^[0-9]{1,18}$' THEN df.numero::bigint END), 0)
+        FROM documento_fiscal df
+        JOIN venta v ON v.id = df.venta_id
+        WHERE v.sucursal_id = $1 AND df.proveedor = $2`
+	err := v.pool.QueryRow(ctx, query, sucursalId, proveedor).Scan(&numero)
+	if err != nil {
+		log.Println("Error al obtener último número fiscal:", err)
+		return 0, datatype.NewInternalServerErrorGeneric()
+	}
+	return numero, nil
+}
+
+// GuardarDocumentoFiscal registra o actualiza la respuesta del proveedor fiscal para la venta.
+func (v VentaRepository) GuardarDocumentoFiscal(ctx context.Context, ventaId *int, documento *domain.DocumentoFiscal) error {
+	query := `
+        INSERT INTO documento_fiscal (venta_id, proveedor, estado, numero, codigo_autorizacion, mensaje, emitido_en)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (venta_id) DO UPDATE
+        SET proveedor = EXCLUDED.proveedor,
+            estado = EXCLUDED.estado,
+            numero = EXCLUDED.numero,
+            codigo_autorizacion = EXCLUDED.codigo_autorizacion,
+            mensaje = EXCLUDED.mensaje,
+            emitido_en = EXCLUDED.emitido_en,
+            anulado_en = CASE WHEN EXCLUDED.estado = 'ANULADO' THEN NOW() END,
+            actualizado_en = NOW()`
+	_, err := v.pool.Exec(ctx, query, *ventaId, documento.Proveedor, documento.Estado, documento.Numero, documento.CodigoAutorizacion,
+		documento.Mensaje, documento.EmitidoEn)
+	if err != nil {
+		log.Println("Error al guardar documento fiscal:", err)
+		return datatype.NewInternalServerErrorGeneric()
+	}
+	return nil
+}
+
+// AplicarOperacionOffline registra en su propia transacción una venta o un pago hecho sin conexión. El UUID se reserva
+// al inicio: si otra sincronización ya lo aplicó (o lo está aplicando) la operación se devuelve como duplicada.
+func (v VentaRepository) AplicarOperacionOffline(ctx context.Context, sucursalId int, operacion *domain.OperacionOffline) (*domain.ResultadoOperacionOffline, error) {
+	tx, err := v.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Error al iniciar transacción:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				log.Println("Error durante rollback:", rollErr)
+			}
+		}
+	}()
+
+	resultado := domain.ResultadoOperacionOffline{Uuid: operacion.Uuid, Tipo: operacion.Tipo, Estado: domain.SincronizacionAceptada}
+
+	// 1. Reservar el UUID; una sincronización concurrente del mismo UUID espera aquí hasta que esta termine
+	queryReservar := `
+        INSERT INTO operacion_offline (uuid, sucursal_id, tipo, estado, creado_en_cliente, sincronizado_en)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        ON CONFLICT (uuid) DO NOTHING`
+	ct, err := tx.Exec(ctx, queryReservar, operacion.Uuid, sucursalId, operacion.Tipo, resultado.Estado, operacion.CreadoEn)
+	if err != nil {
+		log.Println("Error al reservar operación offline:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	if ct.RowsAffected() == 0 {
+		queryAplicada := `SELECT tipo, venta_id, pago_ids, faltantes FROM operacion_offline WHERE uuid = $1`
+		err = tx.QueryRow(ctx, queryAplicada, operacion.Uuid).Scan(&resultado.Tipo, &resultado.VentaId, &resultado.PagoIds, &resultado.Faltantes)
+		if err != nil {
+			log.Println("Error al obtener operación offline aplicada:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		resultado.Estado = domain.SincronizacionDuplicada
+		return &resultado, nil
+	}
+
+	// 2. Aplicar la operación con la misma lógica que en línea
+	switch operacion.Tipo {
+	case domain.OperacionOfflineVenta:
+		configuracion, err := obtenerConfiguracionSucursal(ctx, tx, sucursalId)
+		if err != nil {
+			return nil, err
+		}
+		faltantes := &faltantesStock{politica: configuracion.PoliticaStockOffline}
+		ventaId, err := registrarVenta(ctx, tx, operacion.Venta, operacion.CreadoEn, faltantes)
+		if err != nil {
+			return nil, err
+		}
+		resultado.VentaId = &ventaId
+		resultado.Faltantes = faltantes.items
+		if len(faltantes.items) > 0 {
+			resultado.Estado = domain.SincronizacionObservada
+		}
+	case domain.OperacionOfflinePago:
+		var ventaId int
+		if operacion.VentaUuid != nil {
+			queryVentaOffline := `SELECT venta_id FROM operacion_offline WHERE uuid = $1 AND tipo = $2 AND sucursal_id = $3`
+			err = tx.QueryRow(ctx, queryVentaOffline, *operacion.VentaUuid, domain.OperacionOfflineVenta, sucursalId).Scan(&ventaId)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, datatype.NewBadRequestError(fmt.Sprintf("La venta %s no fue sincronizada.", *operacion.VentaUuid))
+				}
+				log.Println("Error al obtener venta offline:", err)
+				return nil, datatype.NewInternalServerErrorGeneric()
+			}
+		} else {
+			var existe bool
+			err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM venta WHERE id = $1 AND sucursal_id = $2)`, *operacion.VentaId, sucursalId).Scan(&existe)
+			if err != nil {
+				log.Println("Error al validar venta:", err)
+				return nil, datatype.NewInternalServerErrorGeneric()
+			}
+			if !existe {
+				return nil, datatype.NewNotFoundError("La venta no fue encontrada en la sucursal.")
+			}
+			ventaId = *operacion.VentaId
+		}
+		pagoIds, err := registrarPagoVenta(ctx, tx, ventaId, operacion.Pago)
+		if err != nil {
+			return nil, err
+		}
+		resultado.VentaId = &ventaId
+		resultado.PagoIds = pagoIds
+	}
+
+	// 3. Guardar el resultado para responder igual a los reintentos
+	queryResultado := `UPDATE operacion_offline SET estado = $1, venta_id = $2, pago_ids = $3, faltantes = $4 WHERE uuid = $5`
+	_, err = tx.Exec(ctx, queryResultado, resultado.Estado, resultado.VentaId, resultado.PagoIds, resultado.Faltantes, operacion.Uuid)
+	if err != nil {
+		log.Println("Error al guardar resultado de operación offline:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Error al confirmar operación offline:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	committed = true
+
+	return &resultado, nil
+}
+
+func (v VentaRepository) ListarOperacionesOffline(ctx context.Context, filtros map[string]string) (*[]domain.OperacionOfflineInfo, error) {
+	var filters []string
+	var args []interface{}
+	var i = 1
+
+	if val := filtros["sucursalId"]; val != "" {
+		sucursalId, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		filters = append(filters, fmt.Sprintf("sucursal_id = $%d", i))
+		args = append(args, sucursalId)
+		i++
+	}
+	for _, filtro := range []struct{ clave, columna string }{
+		{"estado", "estado"},
+		{"tipo", "tipo"},
+	} {
+		if val := filtros[filtro.clave]; val != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", filtro.columna, i))
+			args = append(args, val)
+			i++
+		}
+	}
+	if val := filtros["fechaInicio"]; val != "" {
+		filters = append(filters, fmt.Sprintf("sincronizado_en >= $%d::date", i))
+		args = append(args, val)
+		i++
+	}
+	if val := filtros["fechaFin"]; val != "" {
+		filters = append(filters, fmt.Sprintf("sincronizado_en < $%d::date + INTERVAL '1 day'", i))
+		args = append(args, val)
+	}
+
+	query := `SELECT uuid, sucursal_id, tipo, estado, venta_id, pago_ids, faltantes, creado_en_cliente, sincronizado_en FROM operacion_offline`
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY sincronizado_en DESC LIMIT 500"
+
+	rows, err := v.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error al listar operaciones offline:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	defer rows.Close()
+	list := make([]domain.OperacionOfflineInfo, 0)
+	for rows.Next() {
+		var item domain.OperacionOfflineInfo
+		err := rows.Scan(&item.Uuid, &item.SucursalId, &item.Tipo, &item.Estado, &item.VentaId, &item.PagoIds, &item.Faltantes, &item.CreadoEnCliente, &item.SincronizadoEn)
+		if err != nil {
+			log.Println("Error al escanear operación offline:", err)
+			return nil, datatype.NewInternalServerErrorGeneric()
+		}
+		list = append(list, item)
+	}
+	return &list, nil
+}
+
+// ObtenerEstadisticasVentas agrega en SQL las ventas del periodo. fechaInicio y fechaFin son días de calendario en la
+// zona horaria pedida (por defecto la de la sesión), de modo que una venta de las 23:30 cae en su día local.
+func (v VentaRepository) ObtenerEstadisticasVentas(ctx context.Context, filtros map[string]string) (*domain.EstadisticasVentas, error) {
+	var sucursalIds []int
+	if val := filtros["sucursalIds"]; val != "" {
+		for _, parte := range strings.Split(val, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(parte))
+			if err != nil || id <= 0 {
+				return nil, datatype.NewBadRequestError("El valor de sucursalIds no es válido")
+			}
+			sucursalIds = append(sucursalIds, id)
+		}
+	}
+
+	query := `
+    WITH zona AS (
+        SELECT COALESCE(NULLIF($1, ''), current_setting('TimeZone')) AS tz
+    ),
+    vf AS (
+        SELECT v.id, v.estado, v.total, v.usuario_id, v.descuento_general,
+               v.costo_tiempo_venta - v.descuento_tiempo AS ingreso_tiempo,
+               (v.creado_en::timestamptz AT TIME ZONE zona.tz) AS creado_local
+        FROM venta v, zona
+        WHERE v.estado IN ('Completado', 'Anulada')
+          AND v.creado_en::timestamptz >= ($2::date::timestamp AT TIME ZONE zona.tz)
+          AND v.creado_en::timestamptz < (($3::date + 1)::timestamp AT TIME ZONE zona.tz)
+          AND ($4::int[] IS NULL OR v.sucursal_id = ANY($4))
+    ),
+    vc AS (
+        SELECT * FROM vf WHERE estado = 'Completado'
+    )
+    SELECT
+        (SELECT tz FROM zona),
+        (SELECT COUNT(*) FROM vc),
+        (SELECT COALESCE(SUM(total), 0) FROM vc),
+        (SELECT COALESCE(ROUND(AVG(total)::numeric, 2), 0) FROM vc),
+        (SELECT COALESCE(SUM(ingreso_tiempo), 0) FROM vc),
+        (SELECT COALESCE(SUM(dv.cantidad * dv.precio_venta - dv.descuento), 0) FROM detalle_venta dv JOIN vc ON dv.venta_id = vc.id),
+        (SELECT COALESCE(SUM(descuento_general), 0) FROM vc),
+        (SELECT COUNT(*) FROM vf WHERE estado = 'Anulada'),
+        (SELECT COALESCE(SUM(total), 0) FROM vf WHERE estado = 'Anulada'),
+
+        -- Un registro por día del rango, aunque no tenga ventas
+        (SELECT json_agg(json_build_object('periodo', to_char(d.dia, 'YYYY-MM-DD'), 'cantidadVentas', COALESCE(x.cantidad, 0), 'total', COALESCE(x.total, 0))
+                ORDER BY d.dia)
+         FROM generate_series($2::date, $3::date, interval '1 day') AS d(dia)
+         LEFT JOIN (
+            SELECT creado_local::date AS dia, COUNT(*) AS cantidad, SUM(total) AS total FROM vc GROUP BY 1
+         ) x ON x.dia = d.dia::date),
+
+        -- Las 24 horas del día sumando todo el rango
+        (SELECT json_agg(json_build_object('periodo', lpad(h.hora::text, 2, '0'), 'cantidadVentas', COALESCE(x.cantidad, 0), 'total', COALESCE(x.total, 0))
+                ORDER BY h.hora)
+         FROM generate_series(0, 23) AS h(hora)
+         LEFT JOIN (
+            SELECT EXTRACT(HOUR FROM creado_local)::int AS hora, COUNT(*) AS cantidad, SUM(total) AS total FROM vc GROUP BY 1
+         ) x ON x.hora = h.hora),
+
+        (SELECT COALESCE(json_agg(json_build_object('metodoPagoId', x.id, 'nombre', x.nombre, 'cantidadPagos', x.cantidad, 'total', x.total)
+                ORDER BY x.total DESC), '[]')
+         FROM (
+            SELECT mp.id, mp.nombre, COUNT(*) AS cantidad, SUM(vp.monto) AS total
+            FROM venta_pago vp
+            JOIN vc ON vp.venta_id = vc.id
+            JOIN metodo_pago mp ON vp.metodo_pago_id = mp.id
+            GROUP BY mp.id, mp.nombre
+         ) x),
+
+        (SELECT COALESCE(json_agg(json_build_object('usuario', json_build_object('id', x.id, 'username', x.username),
+                'cantidadVentas', x.cantidad, 'total', x.total, 'ticketPromedio', x.promedio)
+                ORDER BY x.total DESC), '[]')
+         FROM (
+            SELECT ua.id, ua.username, COUNT(*) AS cantidad, SUM(vc.total) AS total, ROUND(AVG(vc.total)::numeric, 2) AS promedio
+            FROM vc
+            JOIN usuario_admin ua ON vc.usuario_id = ua.id
+            GROUP BY ua.id, ua.username
+         ) x),
+
+        (SELECT COALESCE(json_agg(json_build_object('categoriaId', x.id, 'nombre', x.nombre, 'cantidad', x.cantidad, 'total', x.total)
+                ORDER BY x.total DESC), '[]')
+         FROM (
+            SELECT cp.id, COALESCE(cp.nombre, 'Sin categoría') AS nombre, SUM(dv.cantidad) AS cantidad,
+                   SUM(dv.cantidad * dv.precio_venta - dv.descuento) AS total
+            FROM detalle_venta dv
+            JOIN vc ON dv.venta_id = vc.id
+            JOIN producto p ON dv.producto_id = p.id
+            LEFT JOIN categoria_producto cp ON p.categoria_id = cp.id
+            GROUP BY cp.id, cp.nombre
+         ) x)`
+
+	item := domain.EstadisticasVentas{FechaInicio: filtros["fechaInicio"], FechaFin: filtros["fechaFin"]}
+	err := v.pool.QueryRow(ctx, query, filtros["zonaHoraria"], filtros["fechaInicio"], filtros["fechaFin"], sucursalIds).Scan(&item.ZonaHoraria,
+		&item.CantidadVentas, &item.TotalVentas, &item.TicketPromedio, &item.IngresoTiempo, &item.IngresoProductos, &item.DescuentoGeneral,
+		&item.CantidadAnuladas, &item.TotalAnulado, &item.PorDia, &item.PorHora, &item.PorMetodoPago, &item.PorUsuario, &item.PorCategoria)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "22023" {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("La zona horaria %s no es válida.", filtros["zonaHoraria"]))
+		}
+		log.Println("Error al obtener estadísticas de ventas:", err)
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	return &item, nil
+}
+
 func NewVentaRepository(pool *pgxpool.Pool) *VentaRepository {
 	return &VentaRepository{pool: pool}
 }
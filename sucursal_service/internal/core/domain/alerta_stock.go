@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+const (
+	AlertaStockPuntoReorden = "PUNTO_REORDEN"
+	AlertaStockBajoMinimo   = "BAJO_MINIMO"
+)
+
+// AlertaStock se genera cuando un documento deja el stock de la sucursal por debajo de un umbral que antes
+// superaba. Se publica en la cola de la sucursal y llega al WebSocket de administración.
+type AlertaStock struct {
+	Id            int       `json:"id"`
+	Tipo          string    `json:"tipo"`
+	ProductoId    int       `json:"productoId"`
+	Producto      string    `json:"producto"`
+	SucursalId    int       `json:"sucursalId"`
+	Stock         int       `json:"stock"`
+	StockMinimo   *int      `json:"stockMinimo"`
+	PuntoReorden  *int      `json:"puntoReorden"`
+	StockMaximo   *int      `json:"stockMaximo"`
+	TipoDocumento string    `json:"tipoDocumento"`
+	DocumentoId   int       `json:"documentoId"`
+	CreadoEn      time.Time `json:"creadoEn"`
+}
+
+// ProductoStockBajo es un producto de la sucursal bajo su mínimo (o en su punto de reorden). CantidadSugerida
+// completa hasta el stock máximo, o hasta el mínimo si no tiene máximo.
+type ProductoStockBajo struct {
+	ProductoSucursalId int          `json:"productoSucursalId"`
+	Producto           ProductoInfo `json:"producto"`
+	SucursalId         int          `json:"sucursalId"`
+	Sucursal           string       `json:"sucursal"`
+	Stock              int          `json:"stock"`
+	StockMinimo        *int         `json:"stockMinimo"`
+	PuntoReorden       *int         `json:"puntoReorden"`
+	StockMaximo        *int         `json:"stockMaximo"`
+	CantidadSugerida   int          `json:"cantidadSugerida"`
+}
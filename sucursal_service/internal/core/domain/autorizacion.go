@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// Excepciones que requieren autorización de un supervisor
+const (
+	AutorizacionDescuento = "DESCUENTO"
+	AutorizacionAnulacion = "ANULACION"
+)
+
+// Medios con los que el supervisor autoriza
+const (
+	AutorizacionPin   = "PIN"
+	AutorizacionToken = "TOKEN"
+)
+
+// PermisoAutorizarVenta identifica a los supervisores que pueden autorizar excepciones
+const PermisoAutorizarVenta = "venta:autorizar"
+
+// AutorizacionRequest acompaña una operación que supera las políticas de la sucursal: supervisorId con su PIN,
+// o un token de un solo uso que el supervisor generó desde su sesión.
+type AutorizacionRequest struct {
+	SupervisorId *int    `json:"supervisorId,omitempty"`
+	Pin          *string `json:"pin,omitempty"`
+	Token        *string `json:"token,omitempty"`
+	Motivo       *string `json:"motivo,omitempty"`
+}
+
+// AutorizacionVenta es una excepción aprobada, guardada con la venta
+type AutorizacionVenta struct {
+	Id          int            `json:"id"`
+	VentaId     int            `json:"ventaId"`
+	CodigoVenta int64          `json:"codigoVenta"`
+	Sucursal    *SucursalInfo  `json:"sucursal,omitempty"`
+	Tipo        string         `json:"tipo"`
+	Metodo      string         `json:"metodo"`
+	Detalle     string         `json:"detalle"`
+	Motivo      *string        `json:"motivo,omitempty"`
+	Supervisor  UsuarioSimple  `json:"supervisor"`
+	Usuario     *UsuarioSimple `json:"usuario,omitempty"`
+	CreadoEn    time.Time      `json:"creadoEn"`
+}
+
+// AnularVentaRequest es opcional; solo se necesita fuera de la ventana de anulación
+type AnularVentaRequest struct {
+	Autorizacion *AutorizacionRequest `json:"autorizacion,omitempty"`
+}
+
+type PinSupervisorRequest struct {
+	Pin string `json:"pin"`
+}
+
+type TokenAutorizacionRequest struct {
+	SucursalId int `json:"sucursalId"`
+}
+
+// TokenAutorizacion se muestra una sola vez al supervisor; solo se guarda su hash
+type TokenAutorizacion struct {
+	Token    string    `json:"token"`
+	ExpiraEn time.Time `json:"expiraEn"`
+}
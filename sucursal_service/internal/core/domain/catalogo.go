@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// Entidades del catálogo que se sincronizan con los clientes POS y dispositivos
+const (
+	EntidadProducto   = "producto"
+	EntidadCategoria  = "categoria"
+	EntidadSala       = "sala"
+	EntidadMetodoPago = "metodoPago"
+	EntidadUbicacion  = "ubicacion"
+)
+
+// CambiosCatalogo son las entidades creadas, modificadas o eliminadas en una sucursal desde un cursor.
+// Completo indica que se pidió sin cursor y el cliente debe reemplazar su caché en lugar de aplicar los cambios.
+type CambiosCatalogo struct {
+	Cursor      string               `json:"cursor"`
+	Completo    bool                 `json:"completo"`
+	Productos   []ProductoCatalogo   `json:"productos"`
+	Categorias  []ProductoCategoria  `json:"categorias"`
+	Salas       []Sala               `json:"salas"`
+	MetodosPago []MetodoPagoCatalogo `json:"metodosPago"`
+	Ubicaciones []UbicacionCatalogo  `json:"ubicaciones"`
+	Eliminados  []EntidadEliminada   `json:"eliminados"`
+}
+
+// ProductoCatalogo es un producto con su precio y estado en la sucursal
+type ProductoCatalogo struct {
+	Id                 int       `json:"id"`
+	ProductoSucursalId int       `json:"productoSucursalId"`
+	Nombre             string    `json:"nombre"`
+	UrlFoto            string    `json:"urlFoto,omitempty"`
+	EsInventariable    bool      `json:"esInventariable"`
+	EsTarjetaRegalo    bool      `json:"esTarjetaRegalo"`
+	CategoriaId        *int      `json:"categoriaId,omitempty"`
+	Precio             float64   `json:"precio"`
+	Estado             string    `json:"estado"`
+	EstadoSucursal     string    `json:"estadoSucursal"`
+	ActualizadoEn      time.Time `json:"actualizadoEn"`
+}
+
+type MetodoPagoCatalogo struct {
+	MetodoPago
+	ActualizadoEn time.Time `json:"actualizadoEn"`
+}
+
+type UbicacionCatalogo struct {
+	Id             int       `json:"id"`
+	Nombre         string    `json:"nombre"`
+	Estado         string    `json:"estado"`
+	EsVendible     bool      `json:"esVendible"`
+	PrioridadVenta int       `json:"prioridadVenta"`
+	ActualizadoEn  time.Time `json:"actualizadoEn"`
+}
+
+// EntidadEliminada es la marca (tombstone) de una entidad borrada que el cliente debe quitar de su caché
+type EntidadEliminada struct {
+	Entidad     string    `json:"entidad"`
+	Id          int       `json:"id"`
+	EliminadoEn time.Time `json:"eliminadoEn"`
+}
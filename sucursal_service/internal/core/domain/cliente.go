@@ -16,3 +16,64 @@ type ClienteInfo struct {
 	Estado          string      `json:"estado"`
 	CreadoEn        time.Time   `json:"creadoEn"`
 }
+
+// Tipos de movimiento de la cuenta corriente del cliente
+const (
+	MovimientoCuentaRecarga = "RECARGA" // Abono o recarga de saldo
+	MovimientoCuentaCargo   = "CARGO"   // Venta cobrada a cuenta
+	MovimientoCuentaReverso = "REVERSO" // Devolución de un cargo por anulación
+)
+
+type CuentaClienteId struct {
+	Id int `json:"id"`
+}
+
+// CuentaCliente es la cuenta corriente del cliente en una sucursal. Un saldo negativo es deuda;
+// el cliente puede endeudarse hasta LimiteCredito.
+type CuentaCliente struct {
+	CuentaClienteId
+	Cliente       ClienteInfo  `json:"cliente"`
+	Sucursal      SucursalInfo `json:"sucursal"`
+	Saldo         float64      `json:"saldo"`
+	LimiteCredito float64      `json:"limiteCredito"`
+	Disponible    float64      `json:"disponible"`
+	Estado        string       `json:"estado"`
+	CreadoEn      time.Time    `json:"creadoEn"`
+	ActualizadoEn time.Time    `json:"actualizadoEn"`
+}
+
+type CuentaClienteRequest struct {
+	ClienteId     int64   `json:"clienteId"`
+	SucursalId    int     `json:"sucursalId"`
+	LimiteCredito float64 `json:"limiteCredito"`
+	Estado        string  `json:"estado"`
+}
+
+type RecargaCuentaRequest struct {
+	UsuarioId    int     `json:"usuarioId"`
+	MetodoPagoId int     `json:"metodoPagoId"`
+	Monto        float64 `json:"monto"`
+	Referencia   *string `json:"referencia,omitempty"`
+}
+
+type MovimientoCuentaCliente struct {
+	Id              int           `json:"id"`
+	Tipo            string        `json:"tipo"`
+	Monto           float64       `json:"monto"`
+	SaldoResultante float64       `json:"saldoResultante"`
+	VentaId         *int          `json:"ventaId"`
+	MetodoPago      *MetodoPago   `json:"metodoPago,omitempty"`
+	Referencia      *string       `json:"referencia,omitempty"`
+	Usuario         UsuarioSimple `json:"usuario"`
+	CreadoEn        time.Time     `json:"creadoEn"`
+}
+
+// EstadoCuentaCliente es el extracto de la cuenta en un rango de fechas
+type EstadoCuentaCliente struct {
+	Cuenta       CuentaCliente             `json:"cuenta"`
+	SaldoInicial float64                   `json:"saldoInicial"`
+	TotalCargos  float64                   `json:"totalCargos"`
+	TotalAbonos  float64                   `json:"totalAbonos"`
+	SaldoFinal   float64                   `json:"saldoFinal"`
+	Movimientos  []MovimientoCuentaCliente `json:"movimientos"`
+}
@@ -2,6 +2,15 @@ package domain
 
 import "time"
 
+// Estados de una compra. Cada recepción suma lo recibido por línea; la compra queda Parcialmente recibida hasta
+// recibir todo o cancelar el saldo pendiente, que la cierra como Completado (o Cancelado si no se recibió nada).
+const (
+	CompraPendiente            = "Pendiente"
+	CompraParcialmenteRecibida = "Parcialmente recibida"
+	CompraCompletada           = "Completado"
+	CompraCancelada            = "Cancelado"
+)
+
 type CompraId struct {
 	Id int `json:"id"`
 }
@@ -18,6 +27,9 @@ type DetalleCompraRequest struct {
 	PrecioCompra float64 `json:"precioCompra"`
 	PrecioVenta  float64 `json:"precioVenta"`
 	UbicacionId  int     `json:"ubicacionId"`
+	// Lote y FechaVencimiento (YYYY-MM-DD) son opcionales; con fecha el stock recibido queda en ese lote
+	Lote             *string `json:"lote,omitempty"`
+	FechaVencimiento *string `json:"fechaVencimiento,omitempty"`
 }
 
 type CompraInfo struct {
@@ -34,13 +46,68 @@ type CompraInfo struct {
 
 type Compra struct {
 	CompraInfo
-	Detalles []DetalleCompra `json:"detalles"`
+	SaldoCanceladoEn  *time.Time      `json:"saldoCanceladoEn"`
+	SaldoCanceladoPor *UsuarioSimple  `json:"saldoCanceladoPor"`
+	MotivoCancelacion *string         `json:"motivoCancelacion"`
+	Detalles          []DetalleCompra `json:"detalles"`
 }
 
+// DetalleCompra: CantidadPendiente es lo pedido que falta recibir y no fue cancelado
 type DetalleCompra struct {
-	Producto     Producto  `json:"producto"`
-	Cantidad     int       `json:"cantidad"`
-	PrecioCompra float64   `json:"precioCompra"`
-	PrecioVenta  float64   `json:"precioVenta"`
-	Ubicacion    Ubicacion `json:"ubicacion"`
+	Id                int       `json:"id"`
+	Producto          Producto  `json:"producto"`
+	Cantidad          int       `json:"cantidad"`
+	CantidadRecibida  int       `json:"cantidadRecibida"`
+	CantidadCancelada int       `json:"cantidadCancelada"`
+	CantidadPendiente int       `json:"cantidadPendiente"`
+	PrecioCompra      float64   `json:"precioCompra"`
+	PrecioVenta       float64   `json:"precioVenta"`
+	Ubicacion         Ubicacion `json:"ubicacion"`
+	Lote              *string   `json:"lote"`
+	FechaVencimiento  *string   `json:"fechaVencimiento"`
+}
+
+type RecepcionCompraId struct {
+	Id int `json:"id"`
+}
+
+// RecepcionCompraRequest registra una entrega del proveedor. UbicacionId, si se indica, reemplaza la ubicación de
+// las líneas de la compra; cada detalle puede indicar la suya.
+type RecepcionCompraRequest struct {
+	UbicacionId *int                            `json:"ubicacionId,omitempty"`
+	Observacion *string                         `json:"observacion,omitempty"`
+	Detalles    []DetalleRecepcionCompraRequest `json:"detalles"`
+}
+
+// DetalleRecepcionCompraRequest: sin Lote ni FechaVencimiento se usan los de la línea de la compra
+type DetalleRecepcionCompraRequest struct {
+	DetalleCompraId  int     `json:"detalleCompraId"`
+	Cantidad         int     `json:"cantidad"`
+	UbicacionId      *int    `json:"ubicacionId,omitempty"`
+	Lote             *string `json:"lote,omitempty"`
+	FechaVencimiento *string `json:"fechaVencimiento,omitempty"`
+}
+
+type RecepcionCompra struct {
+	RecepcionCompraId
+	CompraId    int                      `json:"compraId"`
+	Usuario     *UsuarioSimple           `json:"usuario"`
+	Observacion *string                  `json:"observacion"`
+	CreadoEn    time.Time                `json:"creadoEn"`
+	Detalles    []DetalleRecepcionCompra `json:"detalles"`
+}
+
+type DetalleRecepcionCompra struct {
+	Id               int       `json:"id"`
+	DetalleCompraId  int       `json:"detalleCompraId"`
+	Producto         Producto  `json:"producto"`
+	Ubicacion        Ubicacion `json:"ubicacion"`
+	Cantidad         int       `json:"cantidad"`
+	CostoUnitario    float64   `json:"costoUnitario"`
+	Lote             *string   `json:"lote"`
+	FechaVencimiento *string   `json:"fechaVencimiento"`
+}
+
+type CancelacionSaldoCompraRequest struct {
+	Motivo string `json:"motivo"`
 }
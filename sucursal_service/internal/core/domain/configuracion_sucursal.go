@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Políticas ante stock insuficiente en ventas sincronizadas desde un POS sin conexión
+const (
+	PoliticaStockPermitirNegativo = "PERMITIR_NEGATIVO"
+	PoliticaStockMarcar           = "MARCAR"
+)
+
+// ConfiguracionSucursal agrupa las opciones operativas de una sucursal.
+// Si la sucursal no tiene una registrada se usan los valores por defecto.
+// DescuentoMaximo (porcentaje) y VentanaAnulacionMinutos en nil no tienen límite; superarlos exige autorización de un supervisor.
+type ConfiguracionSucursal struct {
+	SucursalId              int        `json:"sucursalId"`
+	PoliticaStockOffline    string     `json:"politicaStockOffline"`
+	DescuentoMaximo         *float64   `json:"descuentoMaximo"`
+	VentanaAnulacionMinutos *int       `json:"ventanaAnulacionMinutos"`
+	ActualizadoEn           *time.Time `json:"actualizadoEn,omitempty"`
+}
+
+type ConfiguracionSucursalRequest struct {
+	PoliticaStockOffline    string   `json:"politicaStockOffline"`
+	DescuentoMaximo         *float64 `json:"descuentoMaximo"`
+	VentanaAnulacionMinutos *int     `json:"ventanaAnulacionMinutos"`
+}
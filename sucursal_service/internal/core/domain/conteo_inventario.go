@@ -0,0 +1,87 @@
+package domain
+
+import "time"
+
+const (
+	ConteoAbierto       = "Abierto"
+	ConteoEnRevision    = "En revisión"
+	ConteoContabilizado = "Contabilizado"
+	ConteoCancelado     = "Cancelado"
+
+	// TipoAjusteConteo es el tipo del ajuste que genera la contabilización de un conteo
+	TipoAjusteConteo = "ERROR_CONTEO"
+)
+
+type ConteoInventarioId struct {
+	Id int `json:"id"`
+}
+
+type ConteoInventarioRequest struct {
+	SucursalId  int    `json:"sucursalId"`
+	UbicacionId *int   `json:"ubicacionId"`
+	CategoriaId *int   `json:"categoriaId"`
+	Ciego       bool   `json:"ciego"`
+	Motivo      string `json:"motivo"`
+}
+
+// CapturaConteoRequest registra cantidades contadas. Las capturas de una línea se suman (varias personas pueden
+// contar partes de la misma ubicación); con Reemplazar se descartan las anteriores de esas líneas.
+type CapturaConteoRequest struct {
+	Reemplazar bool                   `json:"reemplazar"`
+	Capturas   []DetalleCapturaConteo `json:"capturas"`
+}
+
+type DetalleCapturaConteo struct {
+	ProductoId  int `json:"productoId"`
+	UbicacionId int `json:"ubicacionId"`
+	Cantidad    int `json:"cantidad"`
+}
+
+// ContabilizarConteoRequest con IncluirNoContados trata las líneas sin captura como contadas en cero
+type ContabilizarConteoRequest struct {
+	IncluirNoContados bool `json:"incluirNoContados"`
+	// Autorizacion solo se pide si el tipo ERROR_CONTEO requiere aprobación
+	Autorizacion *AutorizacionRequest `json:"autorizacion,omitempty"`
+}
+
+type ConteoInventarioInfo struct {
+	Id              int           `json:"id"`
+	Sucursal        SucursalInfo  `json:"sucursal"`
+	Ubicacion       *Ubicacion    `json:"ubicacion"`
+	CategoriaId     *int          `json:"categoriaId"`
+	Estado          string        `json:"estado"`
+	Ciego           bool          `json:"ciego"`
+	Motivo          string        `json:"motivo"`
+	Usuario         UsuarioSimple `json:"usuario"`
+	AjusteId        *int          `json:"ajusteId"`
+	LineasTotales   int           `json:"lineasTotales"`
+	LineasContadas  int           `json:"lineasContadas"`
+	CreadoEn        time.Time     `json:"creadoEn"`
+	ContabilizadoEn *time.Time    `json:"contabilizadoEn"`
+}
+
+type ConteoInventario struct {
+	ConteoInventarioInfo
+	Detalles []DetalleConteoInventario `json:"detalles"`
+}
+
+// DetalleConteoInventario compara lo contado con el stock esperado de la línea: la foto tomada al abrir el conteo
+// más los movimientos registrados hasta la última captura, así las ventas hechas durante el conteo no se toman
+// como diferencia. En un conteo ciego abierto el stock y la diferencia no se muestran.
+type DetalleConteoInventario struct {
+	Id                int             `json:"id"`
+	Producto          ProductoInfo    `json:"producto"`
+	Ubicacion         Ubicacion       `json:"ubicacion"`
+	StockSistema      *int            `json:"stockSistema"`
+	MovimientosConteo *int            `json:"movimientosConteo"`
+	StockEsperado     *int            `json:"stockEsperado"`
+	CantidadContada   *int            `json:"cantidadContada"`
+	Diferencia        *int            `json:"diferencia"`
+	Capturas          []CapturaConteo `json:"capturas"`
+}
+
+type CapturaConteo struct {
+	Cantidad int           `json:"cantidad"`
+	Usuario  UsuarioSimple `json:"usuario"`
+	CreadoEn time.Time     `json:"creadoEn"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+// EstadisticasVentas resume las ventas cobradas de un periodo. Los totales por día y hora se agrupan en la zona
+// horaria pedida; las anuladas solo suman en CantidadAnuladas y TotalAnulado.
+type EstadisticasVentas struct {
+	ZonaHoraria      string                    `json:"zonaHoraria"`
+	FechaInicio      string                    `json:"fechaInicio"`
+	FechaFin         string                    `json:"fechaFin"`
+	CantidadVentas   int                       `json:"cantidadVentas"`
+	TotalVentas      float64                   `json:"totalVentas"`
+	TicketPromedio   float64                   `json:"ticketPromedio"`
+	IngresoTiempo    float64                   `json:"ingresoTiempo"`
+	IngresoProductos float64                   `json:"ingresoProductos"`
+	DescuentoGeneral float64                   `json:"descuentoGeneral"`
+	CantidadAnuladas int                       `json:"cantidadAnuladas"`
+	TotalAnulado     float64                   `json:"totalAnulado"`
+	PorDia           []EstadisticaPeriodo      `json:"porDia"`
+	PorHora          []EstadisticaPeriodo      `json:"porHora"`
+	PorMetodoPago    []EstadisticaMetodoPago   `json:"porMetodoPago"`
+	PorUsuario       []EstadisticaUsuarioVenta `json:"porUsuario"`
+	PorCategoria     []EstadisticaCategoria    `json:"porCategoria"`
+}
+
+// EstadisticaPeriodo es un día (YYYY-MM-DD) o una hora del día (00 a 23)
+type EstadisticaPeriodo struct {
+	Periodo        string  `json:"periodo"`
+	CantidadVentas int     `json:"cantidadVentas"`
+	Total          float64 `json:"total"`
+}
+
+type EstadisticaMetodoPago struct {
+	MetodoPagoId  int     `json:"metodoPagoId"`
+	Nombre        string  `json:"nombre"`
+	CantidadPagos int     `json:"cantidadPagos"`
+	Total         float64 `json:"total"`
+}
+
+type EstadisticaUsuarioVenta struct {
+	Usuario        UsuarioSimple `json:"usuario"`
+	CantidadVentas int           `json:"cantidadVentas"`
+	Total          float64       `json:"total"`
+	TicketPromedio float64       `json:"ticketPromedio"`
+}
+
+// EstadisticaCategoria suma las líneas de producto netas de descuentos; el tiempo de sala no tiene categoría
+type EstadisticaCategoria struct {
+	CategoriaId *int    `json:"categoriaId"`
+	Nombre      string  `json:"nombre"`
+	Cantidad    int     `json:"cantidad"`
+	Total       float64 `json:"total"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Estados del documento fiscal de una venta
+const (
+	DocumentoFiscalEmitido = "EMITIDO"
+	DocumentoFiscalError   = "ERROR"
+	DocumentoFiscalAnulado = "ANULADO"
+)
+
+// FacturaFiscal son los datos de una venta pagada que se envían al proveedor fiscal
+type FacturaFiscal struct {
+	VentaId       int            `json:"ventaId"`
+	CodigoVenta   int64          `json:"codigoVenta"`
+	SucursalId    int            `json:"sucursalId"`
+	Nit           string         `json:"nit"`
+	RazonSocial   string         `json:"razonSocial"`
+	Fecha         time.Time      `json:"fecha"`
+	Lineas        []LineaFactura `json:"lineas"`
+	BaseImponible float64        `json:"baseImponible"`
+	Impuesto      float64        `json:"impuesto"`
+	Total         float64        `json:"total"`
+}
+
+type LineaFactura struct {
+	Descripcion    string  `json:"descripcion"`
+	Cantidad       int64   `json:"cantidad"`
+	PrecioUnitario float64 `json:"precioUnitario"`
+	Descuento      float64 `json:"descuento"`
+	TasaImpuesto   float64 `json:"tasaImpuesto"`
+	BaseImponible  float64 `json:"baseImponible"`
+	Impuesto       float64 `json:"impuesto"`
+}
+
+// DocumentoFiscal es la respuesta del proveedor fiscal guardada junto a la venta
+type DocumentoFiscal struct {
+	Proveedor          string     `json:"proveedor"`
+	Estado             string     `json:"estado"`
+	Numero             *string    `json:"numero,omitempty"`
+	CodigoAutorizacion *string    `json:"codigoAutorizacion,omitempty"`
+	Mensaje            *string    `json:"mensaje,omitempty"`
+	EmitidoEn          *time.Time `json:"emitidoEn,omitempty"`
+}
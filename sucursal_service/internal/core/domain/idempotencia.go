@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Idempotencia representa una petición registrada bajo una cabecera Idempotency-Key.
+// Mientras CodigoEstado sea nil la petición original sigue en proceso.
+type Idempotencia struct {
+	Clave        string    `json:"clave"`
+	UsuarioId    int       `json:"usuarioId"`
+	Metodo       string    `json:"metodo"`
+	Ruta         string    `json:"ruta"`
+	HashPeticion string    `json:"hashPeticion"`
+	CodigoEstado *int      `json:"codigoEstado"`
+	ContentType  *string   `json:"contentType"`
+	Respuesta    []byte    `json:"-"`
+	CreadoEn     time.Time `json:"creadoEn"`
+	ExpiraEn     time.Time `json:"expiraEn"`
+}
+
+type IdempotenciaRequest struct {
+	Clave        string
+	UsuarioId    int
+	Metodo       string
+	Ruta         string
+	HashPeticion string
+	// Reserva es la vigencia de la clave mientras la petición se procesa; vencida, un reintento puede tomarla
+	Reserva time.Duration
+}
+
+type IdempotenciaRespuestaRequest struct {
+	Clave        string
+	UsuarioId    int
+	CodigoEstado int
+	ContentType  string
+	Respuesta    []byte
+	Retencion    time.Duration
+}
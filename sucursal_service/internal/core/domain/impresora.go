@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Estados de un trabajo de impresión
+const (
+	TrabajoImpresionPendiente = "Pendiente"
+	TrabajoImpresionImpreso   = "Impreso"
+	TrabajoImpresionError     = "Error"
+	TrabajoImpresionCancelado = "Cancelado"
+)
+
+// MaxIntentosImpresion antes de dejar el trabajo en Error
+const MaxIntentosImpresion = 5
+
+type ImpresoraId struct {
+	Id int `json:"id"`
+}
+
+// Impresora es una impresora térmica ESC/POS de la sucursal accesible por TCP (puerto 9100 por defecto)
+type Impresora struct {
+	ImpresoraId
+	Nombre        string        `json:"nombre"`
+	Direccion     string        `json:"direccion"`
+	Puerto        int           `json:"puerto"`
+	AnchoPapel    string        `json:"anchoPapel"`
+	Estado        string        `json:"estado"`
+	Sucursal      *SucursalInfo `json:"sucursal"`
+	CreadoEn      time.Time     `json:"creadoEn"`
+	ActualizadoEn time.Time     `json:"actualizadoEn"`
+}
+
+type ImpresoraRequest struct {
+	SucursalId int    `json:"sucursalId"`
+	Nombre     string `json:"nombre"`
+	Direccion  string `json:"direccion"`
+	Puerto     int    `json:"puerto"`
+	AnchoPapel string `json:"anchoPapel"`
+	Estado     string `json:"estado"`
+}
+
+// TrabajoImpresion es un ticket encolado para una impresora; el contenido ESC/POS se genera al encolar
+type TrabajoImpresion struct {
+	Id               int        `json:"id"`
+	ImpresoraId      int        `json:"impresoraId"`
+	NombreImpresora  string     `json:"nombreImpresora"`
+	VentaId          *int       `json:"ventaId,omitempty"`
+	Estado           string     `json:"estado"`
+	Intentos         int        `json:"intentos"`
+	UltimoError      *string    `json:"ultimoError,omitempty"`
+	ProximoIntentoEn *time.Time `json:"proximoIntentoEn,omitempty"`
+	CreadoEn         time.Time  `json:"creadoEn"`
+	ImpresoEn        *time.Time `json:"impresoEn,omitempty"`
+}
+
+// TrabajoImpresionEnvio incluye lo necesario para enviar el trabajo a su impresora
+type TrabajoImpresionEnvio struct {
+	Id          int
+	ImpresoraId int
+	Direccion   string
+	Puerto      int
+	Contenido   []byte
+	Intentos    int
+}
+
+type ImprimirVentaRequest struct {
+	ImpresoraId *int `json:"impresoraId,omitempty"`
+}
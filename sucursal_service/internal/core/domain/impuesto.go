@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+type ImpuestoId struct {
+	Id int `json:"id"`
+}
+
+// Impuesto es una tasa aplicable a las ventas. Se define por país o por sucursal y, opcionalmente,
+// solo para una categoría de producto. Los precios de venta incluyen el impuesto.
+type Impuesto struct {
+	ImpuestoId
+	Nombre        string                 `json:"nombre"`
+	Porcentaje    float64                `json:"porcentaje"`
+	Exento        bool                   `json:"exento"`
+	Pais          *PaisInfo              `json:"pais,omitempty"`
+	Sucursal      *SucursalInfo          `json:"sucursal,omitempty"`
+	Categoria     *ProductoCategoriaInfo `json:"categoria,omitempty"`
+	Estado        string                 `json:"estado"`
+	CreadoEn      time.Time              `json:"creadoEn"`
+	ActualizadoEn time.Time              `json:"actualizadoEn"`
+}
+
+type ImpuestoRequest struct {
+	Nombre      string  `json:"nombre"`
+	Porcentaje  float64 `json:"porcentaje"`
+	Exento      bool    `json:"exento"`
+	PaisId      *int    `json:"paisId"`
+	SucursalId  *int    `json:"sucursalId"`
+	CategoriaId *int    `json:"categoriaId"`
+	Estado      string  `json:"estado"`
+}
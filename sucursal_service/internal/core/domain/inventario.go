@@ -9,57 +9,44 @@ type Inventario struct {
 	Stock     int64      `json:"stock"`
 }
 
-// Definimos las "direcciones" del ajuste
-type ajusteDirection int
-
-const (
-	AjusteSalida  ajusteDirection = -1 // Solo permite cantidades negativas
-	AjusteEntrada ajusteDirection = 1  // Solo permite cantidades positivas
-	AjusteMixto   ajusteDirection = 0  // Permite ambas (para conteo físico)
-)
-
-// ValidAjusteTypes Define la dirección permitida para cada tipo de ajuste
-var ValidAjusteTypes = map[string]ajusteDirection{
-	// Tipos de Salida
-	"VENCIMIENTO":          AjusteSalida,
-	"MERMA":                AjusteSalida,
-	"CONSUMO_INTERNO":      AjusteSalida,
-	"ROBO_HURTO":           AjusteSalida,
-	"DEVOLUCION_PROVEEDOR": AjusteSalida,
-
-	// Tipos de Entrada
-	"CARGA_INICIAL":        AjusteEntrada,
-	"REINGRESO_SIN_COMPRA": AjusteEntrada,
-
-	// Tipos Mixtos ( +/- )
-	"ERROR_CONTEO": AjusteMixto,
-}
-
 type AjusteId struct {
 	Id int `json:"id"`
 }
 
+// AjusteInventarioRequest: TipoAjuste es el código de un tipo_ajuste activo; Autorizacion solo se pide si el tipo
+// requiere aprobación de un supervisor.
 type AjusteInventarioRequest struct {
-	TipoAjuste string                           `json:"tipoAjuste"`
-	Motivo     string                           `json:"motivo"`
-	SucursalId int                              `json:"sucursalId"`
-	UsuarioId  int                              `json:"usuarioId"`
-	Detalles   []DetalleAjusteInventarioRequest `json:"detalles"`
+	TipoAjuste   string                           `json:"tipoAjuste"`
+	Motivo       string                           `json:"motivo"`
+	SucursalId   int                              `json:"sucursalId"`
+	UsuarioId    int                              `json:"usuarioId"`
+	Detalles     []DetalleAjusteInventarioRequest `json:"detalles"`
+	Autorizacion *AutorizacionRequest             `json:"autorizacion,omitempty"`
 }
 
 type DetalleAjusteInventarioRequest struct {
 	ProductoId  int   `json:"productoId"`
 	Cantidad    int64 `json:"cantidad"`
 	UbicacionId int   `json:"ubicacionId"`
+	// CostoUnitario es opcional en las entradas (p. ej. CARGA_INICIAL) y recalcula el costo promedio
+	CostoUnitario *float64 `json:"costoUnitario,omitempty"`
+	// En una entrada, FechaVencimiento (YYYY-MM-DD) y Lote la asignan a un lote; en una salida, LoteId la
+	// descuenta de ese lote en lugar de seguir el orden de vencimiento.
+	Lote             *string `json:"lote,omitempty"`
+	FechaVencimiento *string `json:"fechaVencimiento,omitempty"`
+	LoteId           *int    `json:"loteId,omitempty"`
 }
 
 type AjusteInventarioInfo struct {
 	AjusteId
-	TipoAjuste string        `json:"tipoAjuste"`
-	Motivo     string        `json:"motivo"`
-	Usuario    UsuarioSimple `json:"usuario"`
-	Sucursal   SucursalInfo  `json:"sucursal"`
-	Fecha      time.Time     `json:"fecha"`
+	TipoAjuste        string         `json:"tipoAjuste"`
+	TipoAjusteNombre  string         `json:"tipoAjusteNombre"`
+	CategoriaContable *string        `json:"categoriaContable"`
+	Motivo            string         `json:"motivo"`
+	Usuario           UsuarioSimple  `json:"usuario"`
+	AutorizadoPor     *UsuarioSimple `json:"autorizadoPor"`
+	Sucursal          SucursalInfo   `json:"sucursal"`
+	Fecha             time.Time      `json:"fecha"`
 	//Detalles   []DetalleAjusteInventario `json:"detalles"`
 }
 
@@ -74,6 +61,20 @@ type DetalleAjusteInventario struct {
 	Cantidad  int64     `json:"cantidad"`
 }
 
+// Estados de una transferencia. Entre ubicaciones de la misma sucursal se completa al registrarla; entre
+// sucursales el stock sale del origen al despachar y queda en tránsito hasta que el destino confirma la recepción.
+const (
+	TransferenciaSolicitada = "Solicitada"
+	TransferenciaAprobada   = "Aprobada"
+	TransferenciaRechazada  = "Rechazada"
+	TransferenciaEnTransito = "En tránsito"
+	TransferenciaRecibida   = "Recibida"
+	TransferenciaCompletada = "Completada"
+
+	// TipoAjusteMerma es el tipo del ajuste que registra el faltante de una transferencia recibida
+	TipoAjusteMerma = "MERMA"
+)
+
 type TransferenciaId struct {
 	Id int `json:"id"`
 }
@@ -90,20 +91,50 @@ type DetalleTransferenciaRequest struct {
 	Cantidad   int64 `json:"cantidad"`
 }
 
+// DespachoTransferenciaRequest permite enviar cantidades distintas a las solicitadas; un producto omitido se
+// envía completo y uno con cantidad cero no se envía.
+type DespachoTransferenciaRequest struct {
+	Detalles []DetalleTransferenciaRequest `json:"detalles"`
+}
+
+// RecepcionTransferenciaRequest trae lo que llegó realmente; un producto omitido se da por recibido completo
+type RecepcionTransferenciaRequest struct {
+	Detalles []DetalleRecepcionTransferencia `json:"detalles"`
+	// Autorizacion solo se pide si hay faltantes y el tipo MERMA requiere aprobación
+	Autorizacion *AutorizacionRequest `json:"autorizacion,omitempty"`
+}
+
+type DetalleRecepcionTransferencia struct {
+	ProductoId       int   `json:"productoId"`
+	CantidadRecibida int64 `json:"cantidadRecibida"`
+}
+
+type RechazoTransferenciaRequest struct {
+	Motivo string `json:"motivo"`
+}
+
 type TransferenciaInventarioInfo struct {
 	TransferenciaId
 	UbicacionOrigen  Ubicacion     `json:"ubicacionOrigen"`
 	UbicacionDestino Ubicacion     `json:"ubicacionDestino"`
 	Usuario          UsuarioSimple `json:"usuario"`
 	Motivo           string        `json:"motivo"`
+	Estado           string        `json:"estado"`
+	MotivoRechazo    *string       `json:"motivoRechazo"`
+	AjusteId         *int          `json:"ajusteId"`
 	Fecha            time.Time     `json:"fecha"`
+	RevisadoEn       *time.Time    `json:"revisadoEn"`
+	DespachadoEn     *time.Time    `json:"despachadoEn"`
+	RecibidoEn       *time.Time    `json:"recibidoEn"`
 }
 type TransferenciaInventario struct {
 	TransferenciaInventarioInfo
 	Detalles []DetalleTransferenciaInventario `json:"detalles"`
 }
 type DetalleTransferenciaInventario struct {
-	Id       int      `json:"id"`
-	Producto Producto `json:"producto"`
-	Cantidad int64    `json:"cantidad"`
+	Id                 int      `json:"id"`
+	Producto           Producto `json:"producto"`
+	CantidadSolicitada *int64   `json:"cantidadSolicitada"`
+	Cantidad           int64    `json:"cantidad"`
+	CantidadRecibida   *int64   `json:"cantidadRecibida"`
 }
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// TipoAjusteVencimiento es el tipo del ajuste que da de baja lotes vencidos
+const TipoAjusteVencimiento = "VENCIMIENTO"
+
+// LoteInventario es la parte del stock de una ubicación que pertenece a un lote. El stock de la ubicación que
+// no está en ningún lote (anterior al registro de lotes o ingresado sin vencimiento) se informa como "sin lote".
+type LoteInventario struct {
+	Id               int          `json:"id"`
+	Producto         ProductoInfo `json:"producto"`
+	Ubicacion        Ubicacion    `json:"ubicacion"`
+	Lote             string       `json:"lote"`
+	FechaVencimiento time.Time    `json:"fechaVencimiento"`
+	DiasParaVencer   int          `json:"diasParaVencer"`
+	Vencido          bool         `json:"vencido"`
+	Stock            int          `json:"stock"`
+	CostoUnitario    *float64     `json:"costoUnitario"`
+	ValorStock       *float64     `json:"valorStock"`
+}
+
+// BajaLotesRequest da de baja el stock de los lotes indicados o, sin LoteIds, el de todos los lotes vencidos de
+// la sucursal (o de la ubicación).
+type BajaLotesRequest struct {
+	SucursalId  int    `json:"sucursalId"`
+	UbicacionId *int   `json:"ubicacionId"`
+	LoteIds     []int  `json:"loteIds"`
+	Motivo      string `json:"motivo"`
+	// Autorizacion solo se pide si el tipo VENCIMIENTO requiere aprobación
+	Autorizacion *AutorizacionRequest `json:"autorizacion,omitempty"`
+}
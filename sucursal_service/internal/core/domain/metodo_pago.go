@@ -1,7 +1,15 @@
 package domain
 
+// Códigos de métodos de pago con tratamiento especial al cobrar; el resto se registra tal cual
+const (
+	MetodoPagoCuentaCorriente = "CUENTA_CORRIENTE"
+	MetodoPagoPuntos          = "PUNTOS"
+	MetodoPagoTarjetaRegalo   = "TARJETA_REGALO" // El código de la tarjeta va en la referencia del pago
+)
+
 type MetodoPago struct {
-	Id     int    `json:"id"`
-	Nombre string `json:"nombre"`
-	Estado string `json:"estado"`
+	Id     int     `json:"id"`
+	Nombre string  `json:"nombre"`
+	Codigo *string `json:"codigo,omitempty"`
+	Estado string  `json:"estado"`
 }
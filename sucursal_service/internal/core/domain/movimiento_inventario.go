@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Documentos que originan un movimiento de inventario
+const (
+	DocumentoVenta          = "VENTA"
+	DocumentoAnulacionVenta = "ANULACION_VENTA"
+	DocumentoCompra         = "COMPRA"
+	DocumentoAjuste         = "AJUSTE"
+	DocumentoTransferencia  = "TRANSFERENCIA"
+)
+
+// MovimientoInventario es una línea del kardex. Cantidad lleva signo (entrada positiva, salida negativa) y
+// SaldoUbicacion es el stock de la ubicación justo después del movimiento.
+type MovimientoInventario struct {
+	Id             int            `json:"id"`
+	Fecha          time.Time      `json:"fecha"`
+	TipoDocumento  string         `json:"tipoDocumento"`
+	DocumentoId    int            `json:"documentoId"`
+	UbicacionId    int            `json:"ubicacionId"`
+	Ubicacion      string         `json:"ubicacion"`
+	Cantidad       int            `json:"cantidad"`
+	SaldoUbicacion int            `json:"saldoUbicacion"`
+	Saldo          int            `json:"saldo"`
+	CostoUnitario  *float64       `json:"costoUnitario"`
+	Usuario        *UsuarioSimple `json:"usuario"`
+}
+
+// Kardex de un producto en una ubicación o en toda la sucursal. Saldo de cada movimiento es el acumulado del
+// alcance pedido, partiendo de SaldoInicial.
+type Kardex struct {
+	ProductoId   int                    `json:"productoId"`
+	Producto     string                 `json:"producto"`
+	SucursalId   *int                   `json:"sucursalId"`
+	UbicacionId  *int                   `json:"ubicacionId"`
+	FechaInicio  *string                `json:"fechaInicio"`
+	FechaFin     *string                `json:"fechaFin"`
+	SaldoInicial int                    `json:"saldoInicial"`
+	Entradas     int                    `json:"entradas"`
+	Salidas      int                    `json:"salidas"`
+	SaldoFinal   int                    `json:"saldoFinal"`
+	Movimientos  []MovimientoInventario `json:"movimientos"`
+}
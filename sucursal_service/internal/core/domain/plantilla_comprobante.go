@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Anchos de papel admitidos por la plantilla de comprobante
+const (
+	AnchoPapel58mm = "58mm"
+	AnchoPapel80mm = "80mm"
+	AnchoPapelA4   = "A4"
+)
+
+// PlantillaComprobante define la marca y el formato del comprobante de venta de una sucursal.
+// Si la sucursal no tiene una registrada se usan los valores por defecto.
+type PlantillaComprobante struct {
+	SucursalId       int        `json:"sucursalId"`
+	NombreComercial  string     `json:"nombreComercial"`
+	UrlLogo          *string    `json:"urlLogo,omitempty"`
+	RutaLogo         *string    `json:"-"`
+	Direccion        *string    `json:"direccion,omitempty"`
+	Telefono         *string    `json:"telefono,omitempty"`
+	Nit              *string    `json:"nit,omitempty"`
+	PiePagina        *string    `json:"piePagina,omitempty"`
+	AnchoPapel       string     `json:"anchoPapel"`
+	ImprimirAlCobrar bool       `json:"imprimirAlCobrar"`
+	ImpresoraId      *int       `json:"impresoraId,omitempty"`
+	ActualizadoEn    *time.Time `json:"actualizadoEn,omitempty"`
+}
+
+type PlantillaComprobanteRequest struct {
+	NombreComercial  string  `json:"nombreComercial"`
+	Direccion        *string `json:"direccion"`
+	Telefono         *string `json:"telefono"`
+	Nit              *string `json:"nit"`
+	PiePagina        *string `json:"piePagina"`
+	AnchoPapel       string  `json:"anchoPapel"`
+	ImprimirAlCobrar bool    `json:"imprimirAlCobrar"`
+	ImpresoraId      *int    `json:"impresoraId"`
+	EliminarLogo     bool    `json:"eliminarLogo"`
+}
@@ -14,15 +14,26 @@ type ProductoInfo struct {
 }
 type Producto struct {
 	ProductoInfo
-	Categoria *ProductoCategoriaInfo `json:"categoria"`
+	Categoria           *ProductoCategoriaInfo `json:"categoria"`
+	EsTarjetaRegalo     bool                   `json:"esTarjetaRegalo"`
+	VigenciaDiasTarjeta int                    `json:"vigenciaDiasTarjeta"`
+	Sku                 *string                `json:"sku"`
+	CodigosBarras       []CodigoBarras         `json:"codigosBarras"`
 }
 
+// ProductoRequest: un producto EsTarjetaRegalo emite al venderse una tarjeta por unidad con saldo igual a su precio.
+// Al modificar, Sku en null o CodigosBarras ausente dejan los valores actuales; "" quita el SKU y una lista vacía
+// quita los códigos.
 type ProductoRequest struct {
-	Nombre          string  `json:"nombre"`
-	Estado          string  `json:"estado"`
-	Precio          float64 `json:"precio"`
-	EsInventariable bool    `json:"esInventariable"`
-	CategoriaId     *int    `json:"categoriaId"`
+	Nombre              string                 `json:"nombre"`
+	Estado              string                 `json:"estado"`
+	Precio              float64                `json:"precio"`
+	EsInventariable     bool                   `json:"esInventariable"`
+	CategoriaId         *int                   `json:"categoriaId"`
+	EsTarjetaRegalo     bool                   `json:"esTarjetaRegalo"`
+	VigenciaDiasTarjeta int                    `json:"vigenciaDiasTarjeta"`
+	Sku                 *string                `json:"sku"`
+	CodigosBarras       *[]CodigoBarrasRequest `json:"codigosBarras"`
 }
 
 type ProductoId struct {
@@ -59,13 +70,17 @@ type CompraDiaria struct {
 	Total float64 `json:"total"`
 }
 type ProductoStat struct {
-	TotalVentas     float64        `json:"totalVentas"`
-	CantidadVentas  int            `json:"cantidadVentas"`
-	TotalCompras    float64        `json:"totalCompras"`
-	CantidadCompras int            `json:"cantidadCompras"`
-	Producto        ProductoInfo   `json:"producto"`
-	VentasDiarias   []VentaDiaria  `json:"ventasDiarias"`
-	ComprasDiarias  []CompraDiaria `json:"comprasDiarias"`
+	TotalVentas      float64        `json:"totalVentas"`
+	CantidadVentas   int            `json:"cantidadVentas"`
+	VentasNetas      float64        `json:"ventasNetas"`
+	CostoVentas      float64        `json:"costoVentas"`
+	MargenBruto      float64        `json:"margenBruto"`
+	MargenPorcentaje float64        `json:"margenPorcentaje"`
+	TotalCompras     float64        `json:"totalCompras"`
+	CantidadCompras  int            `json:"cantidadCompras"`
+	Producto         ProductoInfo   `json:"producto"`
+	VentasDiarias    []VentaDiaria  `json:"ventasDiarias"`
+	ComprasDiarias   []CompraDiaria `json:"comprasDiarias"`
 }
 
 type ProductoVentaStat struct {
@@ -74,15 +89,104 @@ type ProductoVentaStat struct {
 	CantidadVentas int          `json:"cantidadVentas"`
 }
 
+// MargenProducto es el margen bruto de un producto en una sucursal: venta neta de descuentos de línea y de la parte
+// prorrateada del descuento general contra el costo guardado al vender. UnidadesSinCosto cuenta lo vendido cuando el
+// producto, o algún componente del kit, aún no tenía costo registrado.
+type MargenProducto struct {
+	SucursalId       int     `json:"sucursalId"`
+	Sucursal         string  `json:"sucursal"`
+	CategoriaId      *int    `json:"categoriaId"`
+	Categoria        *string `json:"categoria"`
+	ProductoId       int     `json:"productoId"`
+	Producto         string  `json:"producto"`
+	CantidadVentas   int     `json:"cantidadVentas"`
+	VentasNetas      float64 `json:"ventasNetas"`
+	CostoVentas      float64 `json:"costoVentas"`
+	MargenBruto      float64 `json:"margenBruto"`
+	MargenPorcentaje float64 `json:"margenPorcentaje"`
+	UnidadesSinCosto int     `json:"unidadesSinCosto"`
+}
+
 type ProductoSucursalInfo struct {
-	Id       int          `json:"id"`
-	Precio   float64      `json:"precio"`
-	Estado   string       `json:"estado"`
-	Stock    int          `json:"stock"`
-	Producto ProductoInfo `json:"producto"`
+	Id           int          `json:"id"`
+	Precio       float64      `json:"precio"`
+	Estado       string       `json:"estado"`
+	Stock        int          `json:"stock"`
+	StockMinimo  *int         `json:"stockMinimo"`
+	PuntoReorden *int         `json:"puntoReorden"`
+	StockMaximo  *int         `json:"stockMaximo"`
+	Producto     ProductoInfo `json:"producto"`
 }
 
 type ProductoSucursalUpdateRequest struct {
 	Precio float64 `json:"precio"`
 	Estado string  `json:"estado"`
 }
+
+// NivelesStockRequest reemplaza los umbrales del producto en la sucursal; un nivel en null queda sin configurar.
+type NivelesStockRequest struct {
+	StockMinimo  *int `json:"stockMinimo"`
+	PuntoReorden *int `json:"puntoReorden"`
+	StockMaximo  *int `json:"stockMaximo"`
+}
+
+// ProductoComponente es un componente de un kit/combo en una sucursal; Cantidad es lo que consume cada unidad del kit.
+type ProductoComponente struct {
+	Componente ProductoInfo `json:"componente"`
+	Cantidad   int          `json:"cantidad"`
+}
+
+type ProductoComponentesRequest struct {
+	SucursalId  int                         `json:"sucursalId"`
+	Componentes []ProductoComponenteRequest `json:"componentes"`
+}
+
+type ProductoComponenteRequest struct {
+	ProductoId int `json:"productoId"`
+	Cantidad   int `json:"cantidad"`
+}
+
+type ComponenteConsumoStat struct {
+	Kit               ProductoInfo `json:"kit"`
+	Componente        ProductoInfo `json:"componente"`
+	CantidadKits      int          `json:"cantidadKits"`
+	CantidadConsumida int          `json:"cantidadConsumida"`
+}
+
+const (
+	CodigoBarrasEAN13  = "EAN13"
+	CodigoBarrasUPC    = "UPC"
+	CodigoBarrasPropio = "PROPIO"
+	// CodigoSku marca una búsqueda o etiqueta resuelta por el SKU del producto
+	CodigoSku = "SKU"
+)
+
+type CodigoBarras struct {
+	Id     int    `json:"id"`
+	Codigo string `json:"codigo"`
+	Tipo   string `json:"tipo"`
+}
+
+type CodigoBarrasRequest struct {
+	Codigo string `json:"codigo"`
+	Tipo   string `json:"tipo"`
+}
+
+// ProductoEscaneado es el resultado de buscar un código en una sucursal. Codigo es el código de barras o SKU que
+// coincidió; StockVendible solo suma las ubicaciones de las que se vende.
+type ProductoEscaneado struct {
+	ProductoSucursalInfo
+	Sku           *string `json:"sku"`
+	Codigo        string  `json:"codigo"`
+	TipoCodigo    string  `json:"tipoCodigo"`
+	StockVendible int     `json:"stockVendible"`
+}
+
+// EtiquetaProducto es una etiqueta de góndola; sin código de barras se imprime el SKU en Code128.
+type EtiquetaProducto struct {
+	ProductoId int     `json:"productoId"`
+	Nombre     string  `json:"nombre"`
+	Precio     float64 `json:"precio"`
+	Codigo     string  `json:"codigo"`
+	Tipo       string  `json:"tipo"`
+}
@@ -0,0 +1,48 @@
+package domain
+
+// Tipos de grupo de opciones de un producto
+const (
+	GrupoOpcionVariante    = "VARIANTE"    // Se elige exactamente una opción (tamaño, sabor)
+	GrupoOpcionModificador = "MODIFICADOR" // Extras opcionales entre un mínimo y un máximo
+)
+
+type OpcionProducto struct {
+	Id              int     `json:"id"`
+	Nombre          string  `json:"nombre"`
+	PrecioDelta     float64 `json:"precioDelta"`
+	ProductoStockId *int    `json:"productoStockId"`
+	CantidadStock   int     `json:"cantidadStock"`
+	Estado          string  `json:"estado"`
+}
+
+type GrupoOpcion struct {
+	Id              int              `json:"id"`
+	Nombre          string           `json:"nombre"`
+	Tipo            string           `json:"tipo"`
+	SeleccionMinima int              `json:"seleccionMinima"`
+	SeleccionMaxima int              `json:"seleccionMaxima"`
+	Estado          string           `json:"estado"`
+	Opciones        []OpcionProducto `json:"opciones"`
+}
+
+type ProductoOpcionesRequest struct {
+	Grupos []GrupoOpcionRequest `json:"grupos"`
+}
+
+type GrupoOpcionRequest struct {
+	Id              *int                    `json:"id"`
+	Nombre          string                  `json:"nombre"`
+	Tipo            string                  `json:"tipo"`
+	SeleccionMinima int                     `json:"seleccionMinima"`
+	SeleccionMaxima int                     `json:"seleccionMaxima"`
+	Opciones        []OpcionProductoRequest `json:"opciones"`
+}
+
+// OpcionProductoRequest: si ProductoStockId es nil la opción comparte el stock del producto padre.
+type OpcionProductoRequest struct {
+	Id              *int    `json:"id"`
+	Nombre          string  `json:"nombre"`
+	PrecioDelta     float64 `json:"precioDelta"`
+	ProductoStockId *int    `json:"productoStockId"`
+	CantidadStock   int     `json:"cantidadStock"`
+}
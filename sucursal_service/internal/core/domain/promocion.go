@@ -0,0 +1,81 @@
+package domain
+
+import "time"
+
+// Tipos de promoción soportados
+const (
+	PromocionPorcentaje    = "PORCENTAJE"
+	PromocionMontoFijo     = "MONTO_FIJO"
+	PromocionCompraXLlevaY = "COMPRA_X_LLEVA_Y"
+)
+
+// Alcances de promoción: a qué parte de la venta se aplica el descuento
+const (
+	AlcancePromocionTodos      = "TODOS"
+	AlcancePromocionProducto   = "PRODUCTO"
+	AlcancePromocionCategoria  = "CATEGORIA"
+	AlcancePromocionTiempoSala = "TIEMPO_SALA"
+)
+
+type PromocionId struct {
+	Id int `json:"id"`
+}
+
+type PromocionSimple struct {
+	PromocionId
+	Nombre string `json:"nombre"`
+}
+
+type PromocionInfo struct {
+	PromocionId
+	Nombre         string     `json:"nombre"`
+	Tipo           string     `json:"tipo"`
+	Alcance        string     `json:"alcance"`
+	Valor          float64    `json:"valor"`
+	CantidadCompra *int64     `json:"cantidadCompra"`
+	CantidadGratis *int64     `json:"cantidadGratis"`
+	FechaInicio    *time.Time `json:"fechaInicio"`
+	FechaFin       *time.Time `json:"fechaFin"`
+	HoraInicio     *string    `json:"horaInicio"`
+	HoraFin        *string    `json:"horaFin"`
+	DiasSemana     []int      `json:"diasSemana"`
+	CodigoCupon    *string    `json:"codigoCupon"`
+	UsoMaximo      *int       `json:"usoMaximo"`
+	Usos           int        `json:"usos"`
+	Estado         string     `json:"estado"`
+	CreadoEn       time.Time  `json:"creadoEn"`
+}
+
+type Promocion struct {
+	PromocionInfo
+	Sucursal  SucursalInfo           `json:"sucursal"`
+	Producto  *ProductoInfo          `json:"producto"`
+	Categoria *ProductoCategoriaInfo `json:"categoria"`
+}
+
+type PromocionRequest struct {
+	Nombre         string     `json:"nombre"`
+	Tipo           string     `json:"tipo"`
+	Alcance        string     `json:"alcance"`
+	Valor          float64    `json:"valor"`
+	CantidadCompra *int64     `json:"cantidadCompra"`
+	CantidadGratis *int64     `json:"cantidadGratis"`
+	SucursalId     int        `json:"sucursalId"`
+	ProductoId     *int       `json:"productoId"`
+	CategoriaId    *int       `json:"categoriaId"`
+	FechaInicio    *time.Time `json:"fechaInicio"`
+	FechaFin       *time.Time `json:"fechaFin"`
+	HoraInicio     *string    `json:"horaInicio"`
+	HoraFin        *string    `json:"horaFin"`
+	DiasSemana     []int      `json:"diasSemana"`
+	CodigoCupon    *string    `json:"codigoCupon"`
+	UsoMaximo      *int       `json:"usoMaximo"`
+	Estado         string     `json:"estado"`
+}
+
+type PromocionDescuentoStat struct {
+	Promocion       PromocionSimple `json:"promocion"`
+	Tipo            string          `json:"tipo"`
+	CantidadVentas  int             `json:"cantidadVentas"`
+	TotalDescuentos float64         `json:"totalDescuentos"`
+}
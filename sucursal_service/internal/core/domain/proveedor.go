@@ -9,17 +9,20 @@ type Proveedor struct {
 	Email         *string    `json:"email"`
 	Telefono      *string    `json:"telefono"`
 	Celular       *string    `json:"celular"`
+	DiasEntrega   int        `json:"diasEntrega"`
 	CreadoEn      time.Time  `json:"creadoEn"`
 	ActualizadoEn time.Time  `json:"actualizadoEn"`
 	EliminadoEn   *time.Time `json:"eliminadoEn"`
 }
 
+// ProveedorRequest: DiasEntrega es el plazo habitual entre el pedido y la recepción, usado al sugerir compras
 type ProveedorRequest struct {
-	Nombre   string  `json:"nombre"`
-	Estado   string  `json:"estado"`
-	Email    *string `json:"email"`
-	Telefono *string `json:"telefono"`
-	Celular  *string `json:"celular"`
+	Nombre      string  `json:"nombre"`
+	Estado      string  `json:"estado"`
+	Email       *string `json:"email"`
+	Telefono    *string `json:"telefono"`
+	Celular     *string `json:"celular"`
+	DiasEntrega int     `json:"diasEntrega"`
 }
 
 type ProveedorId struct {
@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Tipos de movimiento del libro de puntos de un cliente
+const (
+	MovimientoPuntosAcumulacion = "ACUMULACION" // Puntos ganados al pagar una venta
+	MovimientoPuntosCanje       = "CANJE"       // Puntos usados como pago o como tiempo gratis
+	MovimientoPuntosReverso     = "REVERSO"     // Anulación de una acumulación o devolución de un canje
+	MovimientoPuntosVencimiento = "VENCIMIENTO" // Puntos caducados
+)
+
+// ProgramaPuntos es la configuración de fidelización de una sucursal.
+// PuntosPorBs aplica a productos sin tasa propia de categoría; ValorPunto es lo que vale un punto
+// al pagar con el método PUNTOS y PuntosPorHoraGratis lo que cuesta una hora de sala gratis.
+type ProgramaPuntos struct {
+	Sucursal            SucursalInfo              `json:"sucursal"`
+	PuntosPorBs         float64                   `json:"puntosPorBs"`
+	PuntosPorHora       float64                   `json:"puntosPorHora"`
+	ValorPunto          float64                   `json:"valorPunto"`
+	PuntosPorHoraGratis int                       `json:"puntosPorHoraGratis"`
+	DiasVigencia        int                       `json:"diasVigencia"`
+	Estado              string                    `json:"estado"`
+	Categorias          []ProgramaPuntosCategoria `json:"categorias"`
+	ActualizadoEn       time.Time                 `json:"actualizadoEn"`
+}
+
+type ProgramaPuntosCategoria struct {
+	Categoria   ProductoCategoriaInfo `json:"categoria"`
+	PuntosPorBs float64               `json:"puntosPorBs"`
+}
+
+type ProgramaPuntosRequest struct {
+	PuntosPorBs         float64                          `json:"puntosPorBs"`
+	PuntosPorHora       float64                          `json:"puntosPorHora"`
+	ValorPunto          float64                          `json:"valorPunto"`
+	PuntosPorHoraGratis int                              `json:"puntosPorHoraGratis"`
+	DiasVigencia        int                              `json:"diasVigencia"`
+	Estado              string                           `json:"estado"`
+	Categorias          []ProgramaPuntosCategoriaRequest `json:"categorias"`
+}
+
+// ProgramaPuntosCategoriaRequest: PuntosPorBs en 0 excluye la categoría de la acumulación.
+type ProgramaPuntosCategoriaRequest struct {
+	CategoriaId int     `json:"categoriaId"`
+	PuntosPorBs float64 `json:"puntosPorBs"`
+}
+
+type MovimientoPuntos struct {
+	Id        int           `json:"id"`
+	Tipo      string        `json:"tipo"`
+	Puntos    int           `json:"puntos"`
+	Sucursal  *SucursalInfo `json:"sucursal,omitempty"`
+	VentaId   *int          `json:"ventaId"`
+	UsoSalaId *int64        `json:"usoSalaId"`
+	VenceEn   *time.Time    `json:"venceEn"`
+	CreadoEn  time.Time     `json:"creadoEn"`
+}
+
+// PuntosCliente es el saldo de puntos del cliente con su libro de movimientos
+type PuntosCliente struct {
+	ClienteId   int64              `json:"clienteId"`
+	Saldo       int                `json:"saldo"`
+	PorVencer   int                `json:"porVencer"`
+	Movimientos []MovimientoPuntos `json:"movimientos"`
+}
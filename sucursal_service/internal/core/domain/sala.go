@@ -21,6 +21,8 @@ type UsoSalaRequest struct {
 	SalaId    int    `json:"salaId"`
 	ClienteId int    `json:"clienteId"`
 	TiempoUso int64  `json:"tiempoUso"`
+	// PuntosCanje son puntos del cliente canjeados por tiempo gratis que se suma a TiempoUso
+	PuntosCanje int `json:"puntosCanje,omitempty"`
 }
 type UsoSalaId struct {
 	Id int64 `json:"id"`
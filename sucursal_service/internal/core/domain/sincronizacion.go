@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Tipos de operación que un POS registra sin conexión
+const (
+	OperacionOfflineVenta = "VENTA"
+	OperacionOfflinePago  = "PAGO"
+)
+
+// Resultados de aplicar una operación sincronizada
+const (
+	SincronizacionAceptada  = "ACEPTADA"
+	SincronizacionObservada = "OBSERVADA"
+	SincronizacionDuplicada = "DUPLICADA"
+	SincronizacionRechazada = "RECHAZADA"
+)
+
+// SincronizacionRequest es el lote de operaciones que un POS registró sin conexión, en el orden en que ocurrieron
+type SincronizacionRequest struct {
+	SucursalId  int                `json:"sucursalId"`
+	Operaciones []OperacionOffline `json:"operaciones"`
+}
+
+// OperacionOffline es una venta o un pago identificado por un UUID generado en el POS. Un pago referencia
+// la venta por ventaUuid si también se registró sin conexión o por ventaId si ya existía en el servidor.
+type OperacionOffline struct {
+	Uuid      string                 `json:"uuid"`
+	Tipo      string                 `json:"tipo"`
+	CreadoEn  *time.Time             `json:"creadoEn"`
+	Venta     *VentaRequest          `json:"venta,omitempty"`
+	VentaUuid *string                `json:"ventaUuid,omitempty"`
+	VentaId   *int                   `json:"ventaId,omitempty"`
+	Pago      *RegistrarPagosRequest `json:"pago,omitempty"`
+}
+
+// ResultadoOperacionOffline informa al POS qué pasó con cada operación del lote
+type ResultadoOperacionOffline struct {
+	Uuid      string          `json:"uuid"`
+	Tipo      string          `json:"tipo"`
+	Estado    string          `json:"estado"`
+	VentaId   *int            `json:"ventaId,omitempty"`
+	PagoIds   []int           `json:"pagoIds,omitempty"`
+	Faltantes []FaltanteStock `json:"faltantes,omitempty"`
+	Mensaje   *string         `json:"mensaje,omitempty"`
+}
+
+// FaltanteStock es lo que una venta sincronizada vendió por encima del stock disponible
+type FaltanteStock struct {
+	ProductoId int    `json:"productoId"`
+	Nombre     string `json:"nombre"`
+	Cantidad   int    `json:"cantidad"`
+}
+
+// OperacionOfflineInfo es una operación ya aplicada; las OBSERVADA quedan para revisar sus faltantes de stock
+type OperacionOfflineInfo struct {
+	Uuid            string          `json:"uuid"`
+	SucursalId      int             `json:"sucursalId"`
+	Tipo            string          `json:"tipo"`
+	Estado          string          `json:"estado"`
+	VentaId         *int            `json:"ventaId,omitempty"`
+	PagoIds         []int           `json:"pagoIds,omitempty"`
+	Faltantes       []FaltanteStock `json:"faltantes,omitempty"`
+	CreadoEnCliente *time.Time      `json:"creadoEnCliente,omitempty"`
+	SincronizadoEn  time.Time       `json:"sincronizadoEn"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+// ProductoReposicion son los datos de un producto en la sucursal con los que se calcula su reposición. El
+// proveedor y el precio de compra salen de la última compra del producto en la sucursal; PedidoPendiente suma lo
+// que falta recibir de compras Pendiente o Parcialmente recibida.
+type ProductoReposicion struct {
+	Producto         ProductoInfo `json:"producto"`
+	Stock            int          `json:"stock"`
+	PedidoPendiente  int          `json:"pedidoPendiente"`
+	UnidadesVendidas int          `json:"unidadesVendidas"`
+	VentaDiaria      float64      `json:"ventaDiaria"`
+	StockMinimo      *int         `json:"stockMinimo"`
+	PuntoReorden     *int         `json:"puntoReorden"`
+	StockMaximo      *int         `json:"stockMaximo"`
+	StockAlLlegar    float64      `json:"stockAlLlegar"`
+	CantidadSugerida int          `json:"cantidadSugerida"`
+	PrecioCompra     float64      `json:"precioCompra"`
+	Subtotal         float64      `json:"subtotal"`
+	ProveedorId      *int         `json:"-"`
+	Proveedor        *string      `json:"-"`
+	DiasEntrega      int          `json:"-"`
+}
+
+// SugerenciaCompra agrupa por proveedor los productos a reponer; ProveedorId nulo reúne los que nunca se compraron
+type SugerenciaCompra struct {
+	ProveedorId *int                 `json:"proveedorId"`
+	Proveedor   *string              `json:"proveedor"`
+	DiasEntrega int                  `json:"diasEntrega"`
+	Total       float64              `json:"total"`
+	Productos   []ProductoReposicion `json:"productos"`
+}
+
+// OrdenSugeridaRequest convierte la sugerencia de un proveedor en una orden de compra Pendiente. Dias y
+// DiasCobertura deben ser los mismos de la consulta; ProductoIds limita la orden a esos productos y con
+// IncluirSinProveedor se suman los productos sin compras anteriores.
+type OrdenSugeridaRequest struct {
+	SucursalId          int   `json:"sucursalId"`
+	ProveedorId         int   `json:"proveedorId"`
+	UbicacionId         int   `json:"ubicacionId"`
+	Dias                int   `json:"dias"`
+	DiasCobertura       int   `json:"diasCobertura"`
+	ProductoIds         []int `json:"productoIds"`
+	IncluirSinProveedor bool  `json:"incluirSinProveedor"`
+}
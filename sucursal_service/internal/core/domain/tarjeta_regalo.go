@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// Estados de una tarjeta de regalo. Una tarjeta vencida conserva su estado y se informa como 'Vencida'.
+const (
+	TarjetaRegaloPendiente = "Pendiente" // Vendida en una venta aún no pagada
+	TarjetaRegaloActiva    = "Activa"
+	TarjetaRegaloAgotada   = "Agotada"
+	TarjetaRegaloAnulada   = "Anulada"
+)
+
+// Tipos de movimiento del saldo de una tarjeta de regalo
+const (
+	MovimientoTarjetaEmision   = "EMISION"
+	MovimientoTarjetaCanje     = "CANJE"
+	MovimientoTarjetaReverso   = "REVERSO"   // Devolución de un canje por anulación de la venta que pagó
+	MovimientoTarjetaAnulacion = "ANULACION" // Anulación de la venta que emitió la tarjeta
+)
+
+type TarjetaRegalo struct {
+	Id           int                       `json:"id"`
+	Codigo       string                    `json:"codigo"`
+	Producto     ProductoInfo              `json:"producto"`
+	Sucursal     SucursalInfo              `json:"sucursal"`
+	VentaId      int                       `json:"ventaId"`
+	MontoInicial float64                   `json:"montoInicial"`
+	Saldo        float64                   `json:"saldo"`
+	Estado       string                    `json:"estado"`
+	VenceEn      *time.Time                `json:"venceEn"`
+	CreadoEn     time.Time                 `json:"creadoEn"`
+	Movimientos  []MovimientoTarjetaRegalo `json:"movimientos,omitempty"`
+}
+
+// TarjetaRegaloSimple es la tarjeta emitida por una venta, tal como se imprime en el comprobante
+type TarjetaRegaloSimple struct {
+	Codigo       string     `json:"codigo"`
+	MontoInicial float64    `json:"montoInicial"`
+	Estado       string     `json:"estado"`
+	VenceEn      *time.Time `json:"venceEn"`
+}
+
+type MovimientoTarjetaRegalo struct {
+	Id              int           `json:"id"`
+	Tipo            string        `json:"tipo"`
+	Monto           float64       `json:"monto"`
+	SaldoResultante float64       `json:"saldoResultante"`
+	VentaId         *int          `json:"ventaId"`
+	Usuario         UsuarioSimple `json:"usuario"`
+	CreadoEn        time.Time     `json:"creadoEn"`
+}
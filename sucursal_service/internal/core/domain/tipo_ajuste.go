@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Dirección que admite un tipo de ajuste: solo entradas, solo salidas o ambas (p. ej. un conteo)
+const (
+	DireccionAjusteEntrada = "ENTRADA"
+	DireccionAjusteSalida  = "SALIDA"
+	DireccionAjusteMixto   = "MIXTO"
+)
+
+type TipoAjusteId struct {
+	Id int `json:"id"`
+}
+
+// TipoAjuste define un tipo de ajuste de inventario. Los EsSistema los generan otros procesos (MERMA de
+// transferencias, VENCIMIENTO de lotes, ERROR_CONTEO de conteos): no se deshabilitan ni cambian de dirección.
+type TipoAjuste struct {
+	Id                 int       `json:"id"`
+	Codigo             string    `json:"codigo"`
+	Nombre             string    `json:"nombre"`
+	Direccion          string    `json:"direccion"`
+	RequiereMotivo     bool      `json:"requiereMotivo"`
+	RequiereAprobacion bool      `json:"requiereAprobacion"`
+	CategoriaContable  *string   `json:"categoriaContable"`
+	Estado             string    `json:"estado"`
+	EsSistema          bool      `json:"esSistema"`
+	CreadoEn           time.Time `json:"creadoEn"`
+	ActualizadoEn      time.Time `json:"actualizadoEn"`
+}
+
+// TipoAjusteRequest: el código identifica al tipo en los ajustes y no se modifica después del alta
+type TipoAjusteRequest struct {
+	Codigo             string  `json:"codigo"`
+	Nombre             string  `json:"nombre"`
+	Direccion          string  `json:"direccion"`
+	RequiereMotivo     bool    `json:"requiereMotivo"`
+	RequiereAprobacion bool    `json:"requiereAprobacion"`
+	CategoriaContable  *string `json:"categoriaContable"`
+}
+
+// ResumenTipoAjuste totaliza los ajustes de un tipo; los valores salen del costo registrado en el kardex
+type ResumenTipoAjuste struct {
+	Codigo            string  `json:"codigo"`
+	Nombre            string  `json:"nombre"`
+	Direccion         string  `json:"direccion"`
+	CategoriaContable *string `json:"categoriaContable"`
+	CantidadAjustes   int     `json:"cantidadAjustes"`
+	UnidadesEntrada   int     `json:"unidadesEntrada"`
+	UnidadesSalida    int     `json:"unidadesSalida"`
+	ValorEntrada      float64 `json:"valorEntrada"`
+	ValorSalida       float64 `json:"valorSalida"`
+}
@@ -10,7 +10,12 @@ type VentaInfo struct {
 	CodigoVenta      int64         `json:"codigoVenta"`
 	CostoTiempoVenta float64       `json:"costoTiempoVenta"`
 	DescuentoGeneral float64       `json:"descuentoGeneral"`
+	DescuentoTiempo  float64       `json:"descuentoTiempo"`
 	Total            float64       `json:"total"`
+	BaseImponible    float64       `json:"baseImponible"`
+	Impuesto         float64       `json:"impuesto"`
+	Nit              *string       `json:"nit,omitempty"`
+	RazonSocial      *string       `json:"razonSocial,omitempty"`
 	Estado           string        `json:"estado"`
 	Observacion      *string       `json:"observacion"`
 	CreadoEn         time.Time     `json:"creadoEn"`
@@ -23,18 +28,46 @@ type VentaInfo struct {
 
 type Venta struct {
 	VentaInfo
-	UsoSala  *UsoSala       `json:"usoSala,omitempty"`
-	Detalles []DetalleVenta `json:"detalles"`
-	Pagos    *[]VentaPago   `json:"pagos"`
+	VentaOrigenId      *int                  `json:"ventaOrigenId,omitempty"`
+	PromocionTiempo    *PromocionSimple      `json:"promocionTiempo,omitempty"`
+	TasaImpuestoTiempo float64               `json:"tasaImpuestoTiempo"`
+	UsoSala            *UsoSala              `json:"usoSala,omitempty"`
+	Detalles           []DetalleVenta        `json:"detalles"`
+	Pagos              *[]VentaPago          `json:"pagos"`
+	TarjetasRegalo     []TarjetaRegaloSimple `json:"tarjetasRegalo,omitempty"`
+	DocumentoFiscal    *DocumentoFiscal      `json:"documentoFiscal,omitempty"`
+	Autorizaciones     []AutorizacionVenta   `json:"autorizaciones,omitempty"`
 }
 
 type DetalleVenta struct {
-	Id          int       `json:"id"`
-	Producto    Producto  `json:"producto"`
-	Ubicacion   Ubicacion `json:"ubicacion"`
-	Cantidad    int64     `json:"cantidad"`
-	PrecioVenta float64   `json:"precioVenta"`
-	Descuento   float64   `json:"descuento"`
+	Id                 int                      `json:"id"`
+	Producto           Producto                 `json:"producto"`
+	Ubicacion          Ubicacion                `json:"ubicacion"`
+	Cantidad           int64                    `json:"cantidad"`
+	PrecioVenta        float64                  `json:"precioVenta"`
+	Descuento          float64                  `json:"descuento"`
+	DescuentoPromocion float64                  `json:"descuentoPromocion"`
+	TasaImpuesto       float64                  `json:"tasaImpuesto"`
+	BaseImponible      float64                  `json:"baseImponible"`
+	Impuesto           float64                  `json:"impuesto"`
+	Promocion          *PromocionSimple         `json:"promocion,omitempty"`
+	Componentes        []DetalleVentaComponente `json:"componentes,omitempty"`
+	Opciones           []DetalleVentaOpcion     `json:"opciones,omitempty"`
+}
+
+// DetalleVentaOpcion es la variante o modificador elegido, con nombre y precio al momento de la venta.
+type DetalleVentaOpcion struct {
+	OpcionId    int     `json:"opcionId"`
+	Grupo       string  `json:"grupo"`
+	Nombre      string  `json:"nombre"`
+	PrecioDelta float64 `json:"precioDelta"`
+}
+
+// DetalleVentaComponente es el stock descontado de un componente al vender un kit.
+type DetalleVentaComponente struct {
+	Producto  ProductoInfo `json:"producto"`
+	Ubicacion Ubicacion    `json:"ubicacion"`
+	Cantidad  int64        `json:"cantidad"`
 }
 
 type VentaRequest struct {
@@ -46,6 +79,10 @@ type VentaRequest struct {
 	ClienteId        *int64                `json:"clienteId,omitempty"`
 	DescuentoGeneral float64               `json:"descuentoGeneral"`
 	Observacion      *string               `json:"observacion"`
+	CodigoCupon      *string               `json:"codigoCupon,omitempty"`
+	Nit              *string               `json:"nit,omitempty"`
+	RazonSocial      *string               `json:"razonSocial,omitempty"`
+	Autorizacion     *AutorizacionRequest  `json:"autorizacion,omitempty"`
 	Detalles         []DetalleVentaRequest `json:"detalles"`
 }
 
@@ -53,6 +90,7 @@ type DetalleVentaRequest struct {
 	ProductoId int     `json:"productoId"`
 	Cantidad   int64   `json:"cantidad"`
 	Descuento  float64 `json:"descuento"`
+	Opciones   []int   `json:"opciones"`
 }
 
 type VentaPago struct {
@@ -70,3 +108,19 @@ type PagoRequest struct {
 	Monto        float64 `json:"monto"`
 	Referencia   *string `json:"referencia,omitempty"`
 }
+
+// DividirVentaRequest reparte las líneas y el tiempo de sala de una venta pendiente en varias ventas
+type DividirVentaRequest struct {
+	Partes []ParteVentaRequest `json:"partes"`
+}
+
+type ParteVentaRequest struct {
+	ClienteId   *int64                     `json:"clienteId,omitempty"`
+	CostoTiempo float64                    `json:"costoTiempo"`
+	Detalles    []ParteDetalleVentaRequest `json:"detalles"`
+}
+
+type ParteDetalleVentaRequest struct {
+	DetalleVentaId int   `json:"detalleVentaId"`
+	Cantidad       int64 `json:"cantidad"`
+}
@@ -0,0 +1,27 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AutorizacionRepository interface {
+	RegistrarPinSupervisor(ctx context.Context, usuarioId int, pin string) error
+	RegistrarTokenAutorizacion(ctx context.Context, usuarioId int, sucursalId int, token string, expiraEn time.Time) error
+	ListarAutorizacionesVenta(ctx context.Context, filtros map[string]string) (*[]domain.AutorizacionVenta, error)
+}
+
+type AutorizacionService interface {
+	RegistrarPinSupervisor(ctx context.Context, request *domain.PinSupervisorRequest) error
+	GenerarTokenAutorizacion(ctx context.Context, request *domain.TokenAutorizacionRequest) (*domain.TokenAutorizacion, error)
+	ListarAutorizacionesVenta(ctx context.Context, filtros map[string]string) (*[]domain.AutorizacionVenta, error)
+}
+
+type AutorizacionHandler interface {
+	RegistrarPinSupervisor(c *fiber.Ctx) error
+	GenerarTokenAutorizacion(c *fiber.Ctx) error
+	ListarAutorizacionesVenta(c *fiber.Ctx) error
+}
@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CatalogoRepository interface {
+	ObtenerCambiosCatalogo(ctx context.Context, sucursalId *int, cursor *string) (*domain.CambiosCatalogo, error)
+}
+
+type CatalogoService interface {
+	ObtenerCambiosCatalogo(ctx context.Context, sucursalId *int, cursor *string) (*domain.CambiosCatalogo, error)
+}
+
+type CatalogoHandler interface {
+	ObtenerCambiosCatalogo(c *fiber.Ctx) error
+}
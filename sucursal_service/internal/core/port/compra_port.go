@@ -13,6 +13,10 @@ type CompraRepository interface {
 	RegistrarOrdenCompra(ctx context.Context, request *domain.CompraRequest) (*int, error)
 	ModificarOrdenCompra(ctx context.Context, id *int, request *domain.CompraRequest) error
 	ConfirmarRecepcionCompra(ctx context.Context, id *int) error
+	RegistrarRecepcionCompra(ctx context.Context, compraId int, request *domain.RecepcionCompraRequest, usuarioId int) (*int, error)
+	ListarRecepcionesCompra(ctx context.Context, compraId int) (*[]domain.RecepcionCompra, error)
+	CancelarSaldoCompra(ctx context.Context, compraId int, motivo string, usuarioId int) (string, error)
+	ListarDatosReposicion(ctx context.Context, sucursalId int, dias int, categoriaId *int) (*[]domain.ProductoReposicion, error)
 }
 
 type CompraService interface {
@@ -21,6 +25,11 @@ type CompraService interface {
 	RegistrarOrdenCompra(ctx context.Context, request *domain.CompraRequest) (*int, error)
 	ModificarOrdenCompra(ctx context.Context, id *int, request *domain.CompraRequest) error
 	ConfirmarRecepcionCompra(ctx context.Context, id *int) error
+	RegistrarRecepcionCompra(ctx context.Context, compraId int, request *domain.RecepcionCompraRequest) (*int, error)
+	ListarRecepcionesCompra(ctx context.Context, compraId int) (*[]domain.RecepcionCompra, error)
+	CancelarSaldoCompra(ctx context.Context, compraId int, request *domain.CancelacionSaldoCompraRequest) (string, error)
+	ListarSugerenciasCompra(ctx context.Context, filtros map[string]string) (*[]domain.SugerenciaCompra, error)
+	RegistrarOrdenSugerida(ctx context.Context, request *domain.OrdenSugeridaRequest) (*int, error)
 }
 
 type CompraHandler interface {
@@ -29,4 +38,9 @@ type CompraHandler interface {
 	RegistrarOrdenCompra(c *fiber.Ctx) error
 	ModificarOrdenCompra(c *fiber.Ctx) error
 	ConfirmarRecepcionCompra(c *fiber.Ctx) error
+	RegistrarRecepcionCompra(c *fiber.Ctx) error
+	ListarRecepcionesCompra(c *fiber.Ctx) error
+	CancelarSaldoCompra(c *fiber.Ctx) error
+	ListarSugerenciasCompra(c *fiber.Ctx) error
+	RegistrarOrdenSugerida(c *fiber.Ctx) error
 }
@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConfiguracionSucursalRepository interface {
+	ObtenerConfiguracionSucursal(ctx context.Context, sucursalId *int) (*domain.ConfiguracionSucursal, error)
+	ModificarConfiguracionSucursal(ctx context.Context, sucursalId *int, request *domain.ConfiguracionSucursalRequest) error
+}
+
+type ConfiguracionSucursalService interface {
+	ObtenerConfiguracionSucursal(ctx context.Context, sucursalId *int) (*domain.ConfiguracionSucursal, error)
+	ModificarConfiguracionSucursal(ctx context.Context, sucursalId *int, request *domain.ConfiguracionSucursalRequest) error
+}
+
+type ConfiguracionSucursalHandler interface {
+	ObtenerConfiguracionSucursal(c *fiber.Ctx) error
+	ModificarConfiguracionSucursal(c *fiber.Ctx) error
+}
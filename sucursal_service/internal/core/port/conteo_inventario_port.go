@@ -0,0 +1,39 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConteoInventarioRepository interface {
+	AbrirConteo(ctx context.Context, request *domain.ConteoInventarioRequest, usuarioId int) (*int, error)
+	RegistrarCapturasConteo(ctx context.Context, conteoId int, request *domain.CapturaConteoRequest, usuarioId int) error
+	CambiarEstadoConteo(ctx context.Context, conteoId int, estadosPermitidos []string, estadoNuevo string) error
+	ContabilizarConteo(ctx context.Context, conteoId int, request *domain.ContabilizarConteoRequest, usuarioId int) (*int, error)
+	ListarConteos(ctx context.Context, filtros map[string]string) (*[]domain.ConteoInventarioInfo, error)
+	ObtenerConteo(ctx context.Context, conteoId int) (*domain.ConteoInventario, error)
+}
+
+type ConteoInventarioService interface {
+	AbrirConteo(ctx context.Context, request *domain.ConteoInventarioRequest) (*int, error)
+	RegistrarCapturasConteo(ctx context.Context, conteoId int, request *domain.CapturaConteoRequest) error
+	CerrarConteo(ctx context.Context, conteoId int) error
+	ReabrirConteo(ctx context.Context, conteoId int) error
+	CancelarConteo(ctx context.Context, conteoId int) error
+	ContabilizarConteo(ctx context.Context, conteoId int, request *domain.ContabilizarConteoRequest) (*int, error)
+	ListarConteos(ctx context.Context, filtros map[string]string) (*[]domain.ConteoInventarioInfo, error)
+	ObtenerConteo(ctx context.Context, conteoId int) (*domain.ConteoInventario, error)
+}
+
+type ConteoInventarioHandler interface {
+	AbrirConteo(c *fiber.Ctx) error
+	RegistrarCapturasConteo(c *fiber.Ctx) error
+	CerrarConteo(c *fiber.Ctx) error
+	ReabrirConteo(c *fiber.Ctx) error
+	CancelarConteo(c *fiber.Ctx) error
+	ContabilizarConteo(c *fiber.Ctx) error
+	ListarConteos(c *fiber.Ctx) error
+	ObtenerConteo(c *fiber.Ctx) error
+}
@@ -0,0 +1,35 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CuentaClienteRepository interface {
+	RegistrarCuentaCliente(ctx context.Context, request *domain.CuentaClienteRequest) (*int, error)
+	ModificarCuentaCliente(ctx context.Context, id *int, request *domain.CuentaClienteRequest) error
+	ListarCuentasCliente(ctx context.Context, filtros map[string]string) (*[]domain.CuentaCliente, error)
+	ObtenerCuentaClienteById(ctx context.Context, id *int) (*domain.CuentaCliente, error)
+	RecargarCuentaCliente(ctx context.Context, id *int, request *domain.RecargaCuentaRequest) (*int, error)
+	ObtenerEstadoCuentaCliente(ctx context.Context, id *int, filtros map[string]string) (*domain.EstadoCuentaCliente, error)
+}
+
+type CuentaClienteService interface {
+	RegistrarCuentaCliente(ctx context.Context, request *domain.CuentaClienteRequest) (*int, error)
+	ModificarCuentaCliente(ctx context.Context, id *int, request *domain.CuentaClienteRequest) error
+	ListarCuentasCliente(ctx context.Context, filtros map[string]string) (*[]domain.CuentaCliente, error)
+	ObtenerCuentaClienteById(ctx context.Context, id *int) (*domain.CuentaCliente, error)
+	RecargarCuentaCliente(ctx context.Context, id *int, request *domain.RecargaCuentaRequest) (*int, error)
+	ObtenerEstadoCuentaCliente(ctx context.Context, id *int, filtros map[string]string) (*domain.EstadoCuentaCliente, error)
+}
+
+type CuentaClienteHandler interface {
+	RegistrarCuentaCliente(c *fiber.Ctx) error
+	ModificarCuentaCliente(c *fiber.Ctx) error
+	ListarCuentasCliente(c *fiber.Ctx) error
+	ObtenerCuentaClienteById(c *fiber.Ctx) error
+	RecargarCuentaCliente(c *fiber.Ctx) error
+	ObtenerEstadoCuentaCliente(c *fiber.Ctx) error
+}
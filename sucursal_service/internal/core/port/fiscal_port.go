@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+)
+
+// ProveedorFiscal abstrae el servicio de facturación electrónica. Cada adaptador (SIN, proveedor
+// privado, simulado) implementa la emisión y anulación de la factura de una venta.
+type ProveedorFiscal interface {
+	Nombre() string
+	EmitirFactura(ctx context.Context, factura *domain.FacturaFiscal) (*domain.DocumentoFiscal, error)
+	AnularFactura(ctx context.Context, documento *domain.DocumentoFiscal, motivo string) error
+}
@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+)
+
+type IdempotenciaRepository interface {
+	ReservarIdempotencia(ctx context.Context, request *domain.IdempotenciaRequest) (*domain.Idempotencia, error)
+	GuardarRespuestaIdempotencia(ctx context.Context, request *domain.IdempotenciaRespuestaRequest) error
+	LiberarIdempotencia(ctx context.Context, clave string, usuarioId int) error
+	EliminarIdempotenciasExpiradas(ctx context.Context) (int64, error)
+}
+
+type IdempotenciaService interface {
+	ReservarIdempotencia(ctx context.Context, request *domain.IdempotenciaRequest) (*domain.Idempotencia, error)
+	GuardarRespuestaIdempotencia(ctx context.Context, request *domain.IdempotenciaRespuestaRequest) error
+	LiberarIdempotencia(ctx context.Context, clave string, usuarioId int) error
+	EliminarIdempotenciasExpiradas(ctx context.Context) (int64, error)
+}
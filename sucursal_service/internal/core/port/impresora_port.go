@@ -0,0 +1,56 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImpresoraTermica genera tickets en el lenguaje de la impresora y los envía por red
+type ImpresoraTermica interface {
+	RenderizarTicket(venta *domain.Venta, plantilla *domain.PlantillaComprobante, anchoPapel string) []byte
+	RenderizarPrueba(impresora *domain.Impresora) []byte
+	Enviar(ctx context.Context, direccion string, puerto int, datos []byte) error
+}
+
+type ImpresoraRepository interface {
+	RegistrarImpresora(ctx context.Context, request *domain.ImpresoraRequest) (*int, error)
+	ModificarImpresora(ctx context.Context, id *int, request *domain.ImpresoraRequest) error
+	ListarImpresoras(ctx context.Context, filtros map[string]string) (*[]domain.Impresora, error)
+	ObtenerImpresoraById(ctx context.Context, id *int) (*domain.Impresora, error)
+	RegistrarTrabajoImpresion(ctx context.Context, impresoraId int, ventaId *int, contenido []byte) (*int, error)
+	ListarTrabajosImpresion(ctx context.Context, filtros map[string]string) (*[]domain.TrabajoImpresion, error)
+	ReintentarTrabajoImpresion(ctx context.Context, id *int) error
+	CancelarTrabajoImpresion(ctx context.Context, id *int) error
+	ReservarTrabajosImpresion(ctx context.Context) ([]domain.TrabajoImpresionEnvio, error)
+	MarcarTrabajoImpreso(ctx context.Context, id int) error
+	MarcarTrabajoFallido(ctx context.Context, id int, mensaje string, proximoIntento *time.Time) error
+}
+
+type ImpresoraService interface {
+	RegistrarImpresora(ctx context.Context, request *domain.ImpresoraRequest) (*int, error)
+	ModificarImpresora(ctx context.Context, id *int, request *domain.ImpresoraRequest) error
+	ListarImpresoras(ctx context.Context, filtros map[string]string) (*[]domain.Impresora, error)
+	ObtenerImpresoraById(ctx context.Context, id *int) (*domain.Impresora, error)
+	ProbarImpresora(ctx context.Context, id *int) error
+	ImprimirVenta(ctx context.Context, ventaId *int, request *domain.ImprimirVentaRequest) (*int, error)
+	ImprimirVentaAlCobrar(ctx context.Context, ventaId *int) error
+	ListarTrabajosImpresion(ctx context.Context, filtros map[string]string) (*[]domain.TrabajoImpresion, error)
+	ReintentarTrabajoImpresion(ctx context.Context, id *int) error
+	CancelarTrabajoImpresion(ctx context.Context, id *int) error
+	ProcesarTrabajosImpresion(ctx context.Context) (int, error)
+}
+
+type ImpresoraHandler interface {
+	RegistrarImpresora(c *fiber.Ctx) error
+	ModificarImpresora(c *fiber.Ctx) error
+	ListarImpresoras(c *fiber.Ctx) error
+	ObtenerImpresoraById(c *fiber.Ctx) error
+	ProbarImpresora(c *fiber.Ctx) error
+	ImprimirVenta(c *fiber.Ctx) error
+	ListarTrabajosImpresion(c *fiber.Ctx) error
+	ReintentarTrabajoImpresion(c *fiber.Ctx) error
+	CancelarTrabajoImpresion(c *fiber.Ctx) error
+}
@@ -0,0 +1,29 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImpuestoRepository interface {
+	RegistrarImpuesto(ctx context.Context, request *domain.ImpuestoRequest) (*int, error)
+	ModificarImpuesto(ctx context.Context, id *int, request *domain.ImpuestoRequest) error
+	ListarImpuestos(ctx context.Context, filtros map[string]string) (*[]domain.Impuesto, error)
+	ObtenerImpuestoById(ctx context.Context, id *int) (*domain.Impuesto, error)
+}
+
+type ImpuestoService interface {
+	RegistrarImpuesto(ctx context.Context, request *domain.ImpuestoRequest) (*int, error)
+	ModificarImpuesto(ctx context.Context, id *int, request *domain.ImpuestoRequest) error
+	ListarImpuestos(ctx context.Context, filtros map[string]string) (*[]domain.Impuesto, error)
+	ObtenerImpuestoById(ctx context.Context, id *int) (*domain.Impuesto, error)
+}
+
+type ImpuestoHandler interface {
+	RegistrarImpuesto(c *fiber.Ctx) error
+	ModificarImpuesto(c *fiber.Ctx) error
+	ListarImpuestos(c *fiber.Ctx) error
+	ObtenerImpuestoById(c *fiber.Ctx) error
+}
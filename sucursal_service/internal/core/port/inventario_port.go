@@ -4,6 +4,7 @@ import (
 	"context"
 	"multiroom/sucursal-service/internal/core/domain"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -11,28 +12,65 @@ type InventarioRepository interface {
 	ListarInventario(ctx context.Context, filtros map[string]string) (*[]domain.Inventario, error)
 	RegistrarAjusteConDetalle(ctx context.Context, request *domain.AjusteInventarioRequest) (*int, error)
 	RegistrarTransferencia(ctx context.Context, request *domain.TransferenciaRequest) (*int, error)
+	RegistrarSolicitudTransferencia(ctx context.Context, request *domain.TransferenciaRequest) (*int, error)
+	AprobarTransferencia(ctx context.Context, transferenciaId int, usuarioId int) error
+	RechazarTransferencia(ctx context.Context, transferenciaId int, motivo string, usuarioId int) error
+	DespacharTransferencia(ctx context.Context, transferenciaId int, request *domain.DespachoTransferenciaRequest, usuarioId int) (string, error)
+	RecibirTransferencia(ctx context.Context, transferenciaId int, request *domain.RecepcionTransferenciaRequest, usuarioId int) (*int, error)
+	DarDeBajaLotes(ctx context.Context, request *domain.BajaLotesRequest, usuarioId int) (*int, error)
 	ListarTransferencias(ctx context.Context, filtros map[string]string) (*[]domain.TransferenciaInventarioInfo, error)
 	ListarAjustes(ctx context.Context, filtros map[string]string) (*[]domain.AjusteInventarioInfo, error)
+	ResumenAjustesPorTipo(ctx context.Context, filtros map[string]string) (*[]domain.ResumenTipoAjuste, error)
 	ObtenerAjusteById(ctx context.Context, id *int) (*domain.AjusteInventario, error)
 	ObtenerTransferenciaById(ctx context.Context, id *int) (*domain.TransferenciaInventario, error)
+	ObtenerKardex(ctx context.Context, filtros map[string]string) (*domain.Kardex, error)
+	ListarProductosStockBajo(ctx context.Context, filtros map[string]string) (*[]domain.ProductoStockBajo, error)
+	ListarLotes(ctx context.Context, filtros map[string]string) (*[]domain.LoteInventario, error)
+	ListarAlertasStockPendientes(ctx context.Context, limite int) ([]domain.AlertaStock, error)
+	MarcarAlertaStockPublicada(ctx context.Context, id int) error
 }
 
 type InventarioService interface {
 	ListarInventario(ctx context.Context, filtros map[string]string) (*[]domain.Inventario, error)
 	RegistrarAjusteConDetalle(ctx context.Context, request *domain.AjusteInventarioRequest) (*int, error)
 	RegistrarTransferencia(ctx context.Context, request *domain.TransferenciaRequest) (*int, error)
+	RegistrarSolicitudTransferencia(ctx context.Context, request *domain.TransferenciaRequest) (*int, error)
+	AprobarTransferencia(ctx context.Context, transferenciaId int) error
+	RechazarTransferencia(ctx context.Context, transferenciaId int, request *domain.RechazoTransferenciaRequest) error
+	DespacharTransferencia(ctx context.Context, transferenciaId int, request *domain.DespachoTransferenciaRequest) (string, error)
+	RecibirTransferencia(ctx context.Context, transferenciaId int, request *domain.RecepcionTransferenciaRequest) (*int, error)
+	DarDeBajaLotes(ctx context.Context, request *domain.BajaLotesRequest) (*int, error)
 	ListarTransferencias(ctx context.Context, filtros map[string]string) (*[]domain.TransferenciaInventarioInfo, error)
 	ListarAjustes(ctx context.Context, filtros map[string]string) (*[]domain.AjusteInventarioInfo, error)
+	ResumenAjustesPorTipo(ctx context.Context, filtros map[string]string) (*[]domain.ResumenTipoAjuste, error)
 	ObtenerAjusteById(ctx context.Context, id *int) (*domain.AjusteInventario, error)
 	ObtenerTransferenciaById(ctx context.Context, id *int) (*domain.TransferenciaInventario, error)
+	ObtenerKardex(ctx context.Context, filtros map[string]string) (*domain.Kardex, error)
+	ListarProductosStockBajo(ctx context.Context, filtros map[string]string) (*[]domain.ProductoStockBajo, error)
+	ListarLotes(ctx context.Context, filtros map[string]string) (*[]domain.LoteInventario, error)
+	PublicarAlertasStock(ctx context.Context) (int, error)
 }
 
 type InventarioHandler interface {
 	ListarInventario(c *fiber.Ctx) error
 	RegistrarAjusteConDetalle(c *fiber.Ctx) error
 	RegistrarTransferencia(c *fiber.Ctx) error
+	RegistrarSolicitudTransferencia(c *fiber.Ctx) error
+	AprobarTransferencia(c *fiber.Ctx) error
+	RechazarTransferencia(c *fiber.Ctx) error
+	DespacharTransferencia(c *fiber.Ctx) error
+	RecibirTransferencia(c *fiber.Ctx) error
 	ListarTransferencias(c *fiber.Ctx) error
 	ListarAjustes(c *fiber.Ctx) error
+	ResumenAjustesPorTipo(c *fiber.Ctx) error
 	ObtenerAjusteById(c *fiber.Ctx) error
 	ObtenerTransferenciaById(c *fiber.Ctx) error
+	ObtenerKardex(c *fiber.Ctx) error
+	ListarProductosStockBajo(c *fiber.Ctx) error
+	ListarLotes(c *fiber.Ctx) error
+	DarDeBajaLotes(c *fiber.Ctx) error
+}
+
+type InventarioHandlerWS interface {
+	AlertasStock(c *websocket.Conn)
 }
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+	"mime/multipart"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PlantillaComprobanteRepository interface {
+	ObtenerPlantillaComprobante(ctx context.Context, sucursalId *int) (*domain.PlantillaComprobante, error)
+	ModificarPlantillaComprobante(ctx context.Context, sucursalId *int, request *domain.PlantillaComprobanteRequest, fileHeader *multipart.FileHeader) error
+}
+
+type PlantillaComprobanteService interface {
+	ObtenerPlantillaComprobante(ctx context.Context, sucursalId *int) (*domain.PlantillaComprobante, error)
+	ModificarPlantillaComprobante(ctx context.Context, sucursalId *int, request *domain.PlantillaComprobanteRequest, fileHeader *multipart.FileHeader) error
+}
+
+type PlantillaComprobanteHandler interface {
+	ObtenerPlantillaComprobante(c *fiber.Ctx) error
+	ModificarPlantillaComprobante(c *fiber.Ctx) error
+}
@@ -13,12 +13,19 @@ type ProductoRepository interface {
 	ModificarProductoById(ctx context.Context, productoId *int, request *domain.ProductoRequest, fileHeader *multipart.FileHeader) error
 	ListarProductos(ctx context.Context, filtros map[string]string) (*[]domain.ProductoInfo, error)
 	ObtenerProductoById(ctx context.Context, productoId *int) (*domain.Producto, error)
+	ObtenerProductoPorCodigo(ctx context.Context, codigo string, sucursalId int) (*domain.ProductoEscaneado, error)
+	ListarEtiquetasProductos(ctx context.Context, sucursalId int, productoIds []int) (*[]domain.EtiquetaProducto, error)
 	ListarProductosMasVendidos(ctx context.Context, filtros map[string]string) (*[]domain.ProductoStat, error)
 	HabilitarProductoById(ctx context.Context, productoId *int) error
 	DeshabilitarProductoById(ctx context.Context, productoId *int) error
 	ListarProductosPorSucursal(ctx context.Context, filtros map[string]string) (*[]domain.ProductoSucursalInfo, error)
 	ObtenerProductoSucursalById(ctx context.Context, id *int) (*domain.ProductoSucursalInfo, error)
 	ActualizarProductoSucursal(ctx context.Context, id *int, req *domain.ProductoSucursalUpdateRequest) error
+	ActualizarNivelesStock(ctx context.Context, id *int, req *domain.NivelesStockRequest) error
+	ListarComponentesProducto(ctx context.Context, productoId *int, filtros map[string]string) (*[]domain.ProductoComponente, error)
+	ModificarComponentesProducto(ctx context.Context, productoId *int, request *domain.ProductoComponentesRequest) error
+	ListarOpcionesProducto(ctx context.Context, productoId *int) (*[]domain.GrupoOpcion, error)
+	ModificarOpcionesProducto(ctx context.Context, productoId *int, request *domain.ProductoOpcionesRequest) error
 }
 
 type ProductoService interface {
@@ -26,12 +33,18 @@ type ProductoService interface {
 	ModificarProductoById(ctx context.Context, productoId *int, request *domain.ProductoRequest, fileHeader *multipart.FileHeader) error
 	ListarProductos(ctx context.Context, filtros map[string]string) (*[]domain.ProductoInfo, error)
 	ObtenerProductoById(ctx context.Context, productoId *int) (*domain.Producto, error)
+	ObtenerProductoPorCodigo(ctx context.Context, codigo string, filtros map[string]string) (*domain.ProductoEscaneado, error)
 	ListarProductosMasVendidos(ctx context.Context, filtros map[string]string) (*[]domain.ProductoStat, error)
 	HabilitarProductoById(ctx context.Context, productoId *int) error
 	DeshabilitarProductoById(ctx context.Context, productoId *int) error
 	ListarProductosPorSucursal(ctx context.Context, filtros map[string]string) (*[]domain.ProductoSucursalInfo, error)
 	ObtenerProductoSucursalById(ctx context.Context, id *int) (*domain.ProductoSucursalInfo, error)
 	ActualizarProductoSucursal(ctx context.Context, id *int, req *domain.ProductoSucursalUpdateRequest) error
+	ActualizarNivelesStock(ctx context.Context, id *int, req *domain.NivelesStockRequest) error
+	ListarComponentesProducto(ctx context.Context, productoId *int, filtros map[string]string) (*[]domain.ProductoComponente, error)
+	ModificarComponentesProducto(ctx context.Context, productoId *int, request *domain.ProductoComponentesRequest) error
+	ListarOpcionesProducto(ctx context.Context, productoId *int) (*[]domain.GrupoOpcion, error)
+	ModificarOpcionesProducto(ctx context.Context, productoId *int, request *domain.ProductoOpcionesRequest) error
 }
 
 type ProductoHandler interface {
@@ -39,10 +52,16 @@ type ProductoHandler interface {
 	ModificarProductoById(c *fiber.Ctx) error
 	ListarProductos(c *fiber.Ctx) error
 	ObtenerProductoById(c *fiber.Ctx) error
+	ObtenerProductoPorCodigo(c *fiber.Ctx) error
 	ListarProductosMasVendidos(c *fiber.Ctx) error
 	HabilitarProductoById(c *fiber.Ctx) error
 	DeshabilitarProductoById(c *fiber.Ctx) error
 	ListarProductosPorSucursal(c *fiber.Ctx) error
 	ObtenerProductoSucursalById(c *fiber.Ctx) error
 	ActualizarProductoSucursal(c *fiber.Ctx) error
+	ActualizarNivelesStock(c *fiber.Ctx) error
+	ListarComponentesProducto(c *fiber.Ctx) error
+	ModificarComponentesProducto(c *fiber.Ctx) error
+	ListarOpcionesProducto(c *fiber.Ctx) error
+	ModificarOpcionesProducto(c *fiber.Ctx) error
 }
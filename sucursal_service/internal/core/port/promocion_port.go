@@ -0,0 +1,38 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PromocionRepository interface {
+	RegistrarPromocion(ctx context.Context, request *domain.PromocionRequest) (*int, error)
+	ModificarPromocionById(ctx context.Context, id *int, request *domain.PromocionRequest) error
+	HabilitarPromocionById(ctx context.Context, id *int) error
+	DeshabilitarPromocionById(ctx context.Context, id *int) error
+	ListarPromociones(ctx context.Context, filtros map[string]string) (*[]domain.PromocionInfo, error)
+	ObtenerPromocionById(ctx context.Context, id *int) (*domain.Promocion, error)
+	ListarDescuentosPorPromocion(ctx context.Context, filtros map[string]string) (*[]domain.PromocionDescuentoStat, error)
+}
+
+type PromocionService interface {
+	RegistrarPromocion(ctx context.Context, request *domain.PromocionRequest) (*int, error)
+	ModificarPromocionById(ctx context.Context, id *int, request *domain.PromocionRequest) error
+	HabilitarPromocionById(ctx context.Context, id *int) error
+	DeshabilitarPromocionById(ctx context.Context, id *int) error
+	ListarPromociones(ctx context.Context, filtros map[string]string) (*[]domain.PromocionInfo, error)
+	ObtenerPromocionById(ctx context.Context, id *int) (*domain.Promocion, error)
+	ListarDescuentosPorPromocion(ctx context.Context, filtros map[string]string) (*[]domain.PromocionDescuentoStat, error)
+}
+
+type PromocionHandler interface {
+	RegistrarPromocion(c *fiber.Ctx) error
+	ModificarPromocionById(c *fiber.Ctx) error
+	HabilitarPromocionById(c *fiber.Ctx) error
+	DeshabilitarPromocionById(c *fiber.Ctx) error
+	ListarPromociones(c *fiber.Ctx) error
+	ObtenerPromocionById(c *fiber.Ctx) error
+	ListarDescuentosPorPromocion(c *fiber.Ctx) error
+}
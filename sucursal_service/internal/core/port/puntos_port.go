@@ -0,0 +1,28 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PuntosRepository interface {
+	ObtenerProgramaPuntos(ctx context.Context, sucursalId *int) (*domain.ProgramaPuntos, error)
+	ModificarProgramaPuntos(ctx context.Context, sucursalId *int, request *domain.ProgramaPuntosRequest) error
+	ObtenerPuntosCliente(ctx context.Context, clienteId *int64, filtros map[string]string) (*domain.PuntosCliente, error)
+	VencerPuntos(ctx context.Context) (int64, error)
+}
+
+type PuntosService interface {
+	ObtenerProgramaPuntos(ctx context.Context, sucursalId *int) (*domain.ProgramaPuntos, error)
+	ModificarProgramaPuntos(ctx context.Context, sucursalId *int, request *domain.ProgramaPuntosRequest) error
+	ObtenerPuntosCliente(ctx context.Context, clienteId *int64, filtros map[string]string) (*domain.PuntosCliente, error)
+	VencerPuntos(ctx context.Context) (int64, error)
+}
+
+type PuntosHandler interface {
+	ObtenerProgramaPuntos(c *fiber.Ctx) error
+	ModificarProgramaPuntos(c *fiber.Ctx) error
+	ObtenerPuntosCliente(c *fiber.Ctx) error
+}
@@ -1,6 +1,10 @@
 package port
 
-import amqp "github.com/rabbitmq/amqp091-go"
+import (
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
 
 type RabbitMQService interface {
 	//GetChannel() (*amqp.Channel, error)
@@ -8,3 +12,15 @@ type RabbitMQService interface {
 	PublishToExchange(exchange string, body interface{}) error
 	Publish(queueName string, body interface{}, args amqp.Table) error
 }
+
+// ColaAlertasStock es la cola de alertas de stock de una sucursal. Quien publica y quien consume deben declararla
+// con ArgsColaAlertasStock.
+func ColaAlertasStock(sucursalId int) string {
+	return fmt.Sprintf("sucursal_%d_alertas_stock", sucursalId)
+}
+
+// ArgsColaAlertasStock conserva hasta 100 alertas mientras la cola no tiene consumidor
+var ArgsColaAlertasStock = amqp.Table{
+	amqp.QueueMaxLenArg:   int32(100),
+	amqp.QueueOverflowArg: amqp.QueueOverflowDropHead,
+}
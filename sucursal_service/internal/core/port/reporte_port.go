@@ -11,10 +11,26 @@ type ReporteService interface {
 	ComprobantePDFVentaById(ctx context.Context, ventaId *int) (core.Document, error)
 	ReportePDFVentas(ctx context.Context, filtros map[string]string) (core.Document, error)
 	ReportePDFProductosVendidos(ctx context.Context, filtros map[string]string) (core.Document, error)
+	ReportePDFSaldosPendientes(ctx context.Context, filtros map[string]string) (core.Document, error)
+	ReportePDFExcepciones(ctx context.Context, filtros map[string]string) (core.Document, error)
+	ReportePDFKardex(ctx context.Context, filtros map[string]string) (core.Document, error)
+	ReportePDFLotesPorVencer(ctx context.Context, filtros map[string]string) (core.Document, error)
+	ReportePDFAjustesPorTipo(ctx context.Context, filtros map[string]string) (core.Document, error)
+	ReportePDFMargenes(ctx context.Context, filtros map[string]string) (core.Document, error)
+	ReportePDFEtiquetasProductos(ctx context.Context, filtros map[string]string) (core.Document, error)
+	VistaPreviaComprobante(ctx context.Context, sucursalId *int) (core.Document, error)
 }
 
 type ReporteHandler interface {
 	ComprobantePDFVentaById(c *fiber.Ctx) error
 	ReportePDFVentas(c *fiber.Ctx) error
 	ReportePDFProductosVendidos(c *fiber.Ctx) error
+	ReportePDFSaldosPendientes(c *fiber.Ctx) error
+	ReportePDFExcepciones(c *fiber.Ctx) error
+	ReportePDFKardex(c *fiber.Ctx) error
+	ReportePDFLotesPorVencer(c *fiber.Ctx) error
+	ReportePDFAjustesPorTipo(c *fiber.Ctx) error
+	ReportePDFMargenes(c *fiber.Ctx) error
+	ReportePDFEtiquetasProductos(c *fiber.Ctx) error
+	VistaPreviaComprobante(c *fiber.Ctx) error
 }
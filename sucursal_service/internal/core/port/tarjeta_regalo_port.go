@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TarjetaRegaloRepository interface {
+	ListarTarjetasRegalo(ctx context.Context, filtros map[string]string) (*[]domain.TarjetaRegalo, error)
+	ObtenerTarjetaRegaloByCodigo(ctx context.Context, codigo *string) (*domain.TarjetaRegalo, error)
+}
+
+type TarjetaRegaloService interface {
+	ListarTarjetasRegalo(ctx context.Context, filtros map[string]string) (*[]domain.TarjetaRegalo, error)
+	ObtenerTarjetaRegaloByCodigo(ctx context.Context, codigo *string) (*domain.TarjetaRegalo, error)
+}
+
+type TarjetaRegaloHandler interface {
+	ListarTarjetasRegalo(c *fiber.Ctx) error
+	ObtenerTarjetaRegaloByCodigo(c *fiber.Ctx) error
+}
@@ -0,0 +1,36 @@
+package port
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TipoAjusteRepository interface {
+	RegistrarTipoAjuste(ctx context.Context, request *domain.TipoAjusteRequest) (*int, error)
+	ModificarTipoAjusteById(ctx context.Context, id *int, request *domain.TipoAjusteRequest) error
+	ListarTiposAjuste(ctx context.Context, filtros map[string]string) (*[]domain.TipoAjuste, error)
+	ObtenerTipoAjusteById(ctx context.Context, id *int) (*domain.TipoAjuste, error)
+	ObtenerTipoAjustePorCodigo(ctx context.Context, codigo string) (*domain.TipoAjuste, error)
+	HabilitarTipoAjuste(ctx context.Context, id *int) error
+	DeshabilitarTipoAjuste(ctx context.Context, id *int) error
+}
+
+type TipoAjusteService interface {
+	RegistrarTipoAjuste(ctx context.Context, request *domain.TipoAjusteRequest) (*int, error)
+	ModificarTipoAjusteById(ctx context.Context, id *int, request *domain.TipoAjusteRequest) error
+	ListarTiposAjuste(ctx context.Context, filtros map[string]string) (*[]domain.TipoAjuste, error)
+	ObtenerTipoAjusteById(ctx context.Context, id *int) (*domain.TipoAjuste, error)
+	HabilitarTipoAjuste(ctx context.Context, id *int) error
+	DeshabilitarTipoAjuste(ctx context.Context, id *int) error
+}
+
+type TipoAjusteHandler interface {
+	RegistrarTipoAjuste(c *fiber.Ctx) error
+	ModificarTipoAjusteById(c *fiber.Ctx) error
+	ListarTiposAjuste(c *fiber.Ctx) error
+	ObtenerTipoAjusteById(c *fiber.Ctx) error
+	HabilitarTipoAjuste(c *fiber.Ctx) error
+	DeshabilitarTipoAjuste(c *fiber.Ctx) error
+}
@@ -9,20 +9,35 @@ import (
 
 type VentaRepository interface {
 	RegistrarVenta(ctx context.Context, request *domain.VentaRequest) (*int, error)
-	AnularVentaById(ctx context.Context, id *int) error
+	AnularVentaById(ctx context.Context, id *int, request *domain.AnularVentaRequest) error
 	RegistrarPagoVenta(ctx context.Context, ventaId *int, request *domain.RegistrarPagosRequest) (*[]int, error)
 	ObtenerVenta(ctx context.Context, id *int) (*domain.Venta, error)
 	ListarVentas(ctx context.Context, filtros map[string]string) (*[]domain.VentaInfo, error)
 	ListarProductosVentas(ctx context.Context, filtros map[string]string) (*[]domain.ProductoVentaStat, error)
+	DividirVenta(ctx context.Context, ventaId *int, request *domain.DividirVentaRequest) (*[]int, error)
+	AplicarOperacionOffline(ctx context.Context, sucursalId int, operacion *domain.OperacionOffline) (*domain.ResultadoOperacionOffline, error)
+	ListarOperacionesOffline(ctx context.Context, filtros map[string]string) (*[]domain.OperacionOfflineInfo, error)
+	ListarConsumoComponentes(ctx context.Context, filtros map[string]string) (*[]domain.ComponenteConsumoStat, error)
+	ListarMargenesProductos(ctx context.Context, filtros map[string]string) (*[]domain.MargenProducto, error)
+	ObtenerEstadisticasVentas(ctx context.Context, filtros map[string]string) (*domain.EstadisticasVentas, error)
+	ObtenerDocumentoFiscal(ctx context.Context, ventaId *int) (*domain.DocumentoFiscal, error)
+	GuardarDocumentoFiscal(ctx context.Context, ventaId *int, documento *domain.DocumentoFiscal) error
+	ObtenerUltimoNumeroFiscal(ctx context.Context, sucursalId int, proveedor string) (int64, error)
 }
 
 type VentaService interface {
 	RegistrarVenta(ctx context.Context, request *domain.VentaRequest) (*int, error)
-	AnularVentaById(ctx context.Context, id *int) error
+	AnularVentaById(ctx context.Context, id *int, request *domain.AnularVentaRequest) error
 	RegistrarPagoVenta(ctx context.Context, ventaId *int, request *domain.RegistrarPagosRequest) (*[]int, error)
 	ObtenerVenta(ctx context.Context, id *int) (*domain.Venta, error)
 	ListarVentas(ctx context.Context, filtros map[string]string) (*[]domain.VentaInfo, error)
 	ListarProductosVentas(ctx context.Context, filtros map[string]string) (*[]domain.ProductoVentaStat, error)
+	DividirVenta(ctx context.Context, ventaId *int, request *domain.DividirVentaRequest) (*[]int, error)
+	SincronizarVentas(ctx context.Context, request *domain.SincronizacionRequest) (*[]domain.ResultadoOperacionOffline, error)
+	ListarOperacionesOffline(ctx context.Context, filtros map[string]string) (*[]domain.OperacionOfflineInfo, error)
+	ListarConsumoComponentes(ctx context.Context, filtros map[string]string) (*[]domain.ComponenteConsumoStat, error)
+	ListarMargenesProductos(ctx context.Context, filtros map[string]string) (*[]domain.MargenProducto, error)
+	ObtenerEstadisticasVentas(ctx context.Context, filtros map[string]string) (*domain.EstadisticasVentas, error)
 }
 
 type VentaHandler interface {
@@ -32,4 +47,10 @@ type VentaHandler interface {
 	ObtenerVenta(c *fiber.Ctx) error
 	ListarVentas(c *fiber.Ctx) error
 	ListarProductosVentas(c *fiber.Ctx) error
+	DividirVenta(c *fiber.Ctx) error
+	SincronizarVentas(c *fiber.Ctx) error
+	ListarOperacionesOffline(c *fiber.Ctx) error
+	ListarConsumoComponentes(c *fiber.Ctx) error
+	ListarMargenesProductos(c *fiber.Ctx) error
+	ObtenerEstadisticasVentas(c *fiber.Ctx) error
 }
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"regexp"
+	"time"
+)
+
+type AutorizacionService struct {
+	autorizacionRepository port.AutorizacionRepository
+}
+
+// vigenciaTokenAutorizacion es el tiempo que el cajero tiene para usar el token que le dicta el supervisor
+const vigenciaTokenAutorizacion = 10 * time.Minute
+
+var pinRegexp = regexp.MustCompile(`^[0-9]{4,8}$`)
+
+func (a AutorizacionService) RegistrarPinSupervisor(ctx context.Context, request *domain.PinSupervisorRequest) error {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if !pinRegexp.MatchString(request.Pin) {
+		return datatype.NewBadRequestError("El PIN debe tener entre 4 y 8 dígitos.")
+	}
+	return a.autorizacionRepository.RegistrarPinSupervisor(ctx, usuarioId, request.Pin)
+}
+
+func (a AutorizacionService) GenerarTokenAutorizacion(ctx context.Context, request *domain.TokenAutorizacionRequest) (*domain.TokenAutorizacion, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return nil, datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if request.SucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("El ID de la sucursal es obligatorio.")
+	}
+
+	// Código numérico para dictarlo o teclearlo en el POS
+	n, err := rand.Int(rand.Reader, big.NewInt(100000000))
+	if err != nil {
+		return nil, datatype.NewInternalServerErrorGeneric()
+	}
+	token := fmt.Sprintf("%08d", n.Int64())
+	expiraEn := time.Now().Add(vigenciaTokenAutorizacion)
+
+	if err = a.autorizacionRepository.RegistrarTokenAutorizacion(ctx, usuarioId, request.SucursalId, token, expiraEn); err != nil {
+		return nil, err
+	}
+	return &domain.TokenAutorizacion{Token: token, ExpiraEn: expiraEn}, nil
+}
+
+func (a AutorizacionService) ListarAutorizacionesVenta(ctx context.Context, filtros map[string]string) (*[]domain.AutorizacionVenta, error) {
+	return a.autorizacionRepository.ListarAutorizacionesVenta(ctx, filtros)
+}
+
+func NewAutorizacionService(autorizacionRepository port.AutorizacionRepository) *AutorizacionService {
+	return &AutorizacionService{autorizacionRepository: autorizacionRepository}
+}
+
+var _ port.AutorizacionService = (*AutorizacionService)(nil)
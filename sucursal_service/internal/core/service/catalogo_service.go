@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/port"
+)
+
+type CatalogoService struct {
+	catalogoRepository port.CatalogoRepository
+}
+
+func (c CatalogoService) ObtenerCambiosCatalogo(ctx context.Context, sucursalId *int, cursor *string) (*domain.CambiosCatalogo, error) {
+	return c.catalogoRepository.ObtenerCambiosCatalogo(ctx, sucursalId, cursor)
+}
+
+func NewCatalogoService(catalogoRepository port.CatalogoRepository) *CatalogoService {
+	return &CatalogoService{catalogoRepository: catalogoRepository}
+}
+
+var _ port.CatalogoService = (*CatalogoService)(nil)
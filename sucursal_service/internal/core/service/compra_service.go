@@ -3,9 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
+	"math"
 	"multiroom/sucursal-service/internal/core/domain"
 	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type CompraService struct {
@@ -52,8 +57,10 @@ func (c CompraService) RegistrarOrdenCompra(ctx context.Context, request *domain
 
 	// Definimos una clave para nuestro map que representa la combinación (producto + ubicación)
 	type detalleKey struct {
-		ProductoId  int
-		UbicacionId int
+		ProductoId       int
+		UbicacionId      int
+		Lote             string
+		FechaVencimiento string
 	}
 
 	// Creamos un map para rastrear las combinaciones que ya hemos visto.
@@ -61,16 +68,26 @@ func (c CompraService) RegistrarOrdenCompra(ctx context.Context, request *domain
 	vistos := make(map[detalleKey]struct{})
 
 	for _, detalle := range request.Detalles {
+		if err := validarLote(detalle.Lote, detalle.FechaVencimiento); err != nil {
+			return nil, err
+		}
 		key := detalleKey{
 			ProductoId:  detalle.ProductoId,
 			UbicacionId: detalle.UbicacionId,
 		}
+		// Un producto puede llegar en varios lotes a la misma ubicación
+		if detalle.Lote != nil {
+			key.Lote = *detalle.Lote
+		}
+		if detalle.FechaVencimiento != nil {
+			key.FechaVencimiento = *detalle.FechaVencimiento
+		}
 
 		// ¿Ya hemos visto esta combinación?
 		if _, ok := vistos[key]; ok {
 			// ¡Sí! Es un duplicado.
 			errMsg := fmt.Sprintf(
-				"Producto duplicado (productoId: %d) en la misma ubicación (ubicacionId: %d). Agrupe las cantidades en una sola línea.",
+				"Producto duplicado (productoId: %d) en la misma ubicación (ubicacionId: %d) y lote. Agrupe las cantidades en una sola línea.",
 				detalle.ProductoId,
 				detalle.UbicacionId,
 			)
@@ -117,8 +134,10 @@ func (c CompraService) ModificarOrdenCompra(ctx context.Context, id *int, reques
 
 	// Definimos una clave para nuestro map que representa la combinación (producto + ubicación)
 	type detalleKey struct {
-		ProductoId  int
-		UbicacionId int
+		ProductoId       int
+		UbicacionId      int
+		Lote             string
+		FechaVencimiento string
 	}
 
 	// Creamos un map para rastrear las combinaciones que ya hemos visto.
@@ -126,16 +145,26 @@ func (c CompraService) ModificarOrdenCompra(ctx context.Context, id *int, reques
 	vistos := make(map[detalleKey]struct{})
 
 	for _, detalle := range request.Detalles {
+		if err := validarLote(detalle.Lote, detalle.FechaVencimiento); err != nil {
+			return err
+		}
 		key := detalleKey{
 			ProductoId:  detalle.ProductoId,
 			UbicacionId: detalle.UbicacionId,
 		}
+		// Un producto puede llegar en varios lotes a la misma ubicación
+		if detalle.Lote != nil {
+			key.Lote = *detalle.Lote
+		}
+		if detalle.FechaVencimiento != nil {
+			key.FechaVencimiento = *detalle.FechaVencimiento
+		}
 
 		// ¿Ya hemos visto esta combinación?
 		if _, ok := vistos[key]; ok {
 			// ¡Sí! Es un duplicado.
 			errMsg := fmt.Sprintf(
-				"Producto duplicado (productoId: %d) en la misma ubicación (ubicacionId: %d). Agrupe las cantidades en una sola línea.",
+				"Producto duplicado (productoId: %d) en la misma ubicación (ubicacionId: %d) y lote. Agrupe las cantidades en una sola línea.",
 				detalle.ProductoId,
 				detalle.UbicacionId,
 			)
@@ -155,6 +184,225 @@ func (c CompraService) ConfirmarRecepcionCompra(ctx context.Context, id *int) er
 	return c.compraRepository.ConfirmarRecepcionCompra(ctx, id)
 }
 
+func (c CompraService) RegistrarRecepcionCompra(ctx context.Context, compraId int, request *domain.RecepcionCompraRequest) (*int, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return nil, datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if len(request.Detalles) == 0 {
+		return nil, datatype.NewBadRequestError("La recepción debe contener al menos un detalle.")
+	}
+	vistos := make(map[int]bool, len(request.Detalles))
+	for _, detalle := range request.Detalles {
+		if vistos[detalle.DetalleCompraId] {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("La línea %d está repetida en la recepción.", detalle.DetalleCompraId))
+		}
+		vistos[detalle.DetalleCompraId] = true
+		if detalle.Cantidad <= 0 {
+			return nil, datatype.NewBadRequestError("La cantidad recibida debe ser mayor a cero.")
+		}
+		if err := validarLote(detalle.Lote, detalle.FechaVencimiento); err != nil {
+			return nil, err
+		}
+	}
+	return c.compraRepository.RegistrarRecepcionCompra(ctx, compraId, request, usuarioId)
+}
+
+func (c CompraService) ListarRecepcionesCompra(ctx context.Context, compraId int) (*[]domain.RecepcionCompra, error) {
+	return c.compraRepository.ListarRecepcionesCompra(ctx, compraId)
+}
+
+func (c CompraService) CancelarSaldoCompra(ctx context.Context, compraId int, request *domain.CancelacionSaldoCompraRequest) (string, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return "", datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if strings.TrimSpace(request.Motivo) == "" {
+		return "", datatype.NewBadRequestError("Debe indicar el motivo de la cancelación.")
+	}
+	return c.compraRepository.CancelarSaldoCompra(ctx, compraId, request.Motivo, usuarioId)
+}
+
+func (c CompraService) ListarSugerenciasCompra(ctx context.Context, filtros map[string]string) (*[]domain.SugerenciaCompra, error) {
+	sucursalId, err := strconv.Atoi(filtros["sucursalId"])
+	if err != nil || sucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("El ID de la sucursal es obligatorio")
+	}
+	dias, diasCobertura := 0, 0
+	for _, parametro := range []struct {
+		clave string
+		valor *int
+	}{{"dias", &dias}, {"diasCobertura", &diasCobertura}} {
+		if val := filtros[parametro.clave]; val != "" {
+			if *parametro.valor, err = strconv.Atoi(val); err != nil {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("El valor de %s no es válido", parametro.clave))
+			}
+		}
+	}
+	var categoriaId *int
+	if val := filtros["categoriaId"]; val != "" {
+		id, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de categoriaId no es válido")
+		}
+		categoriaId = &id
+	}
+	sugerencias, err := c.calcularSugerencias(ctx, sucursalId, dias, diasCobertura, categoriaId)
+	if err != nil {
+		return nil, err
+	}
+	if val := filtros["proveedorId"]; val != "" {
+		proveedorId, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de proveedorId no es válido")
+		}
+		filtradas := make([]domain.SugerenciaCompra, 0)
+		for _, sugerencia := range sugerencias {
+			if sugerencia.ProveedorId != nil && *sugerencia.ProveedorId == proveedorId {
+				filtradas = append(filtradas, sugerencia)
+			}
+		}
+		sugerencias = filtradas
+	}
+	return &sugerencias, nil
+}
+
+// RegistrarOrdenSugerida vuelve a calcular la sugerencia y registra lo sugerido para el proveedor como una orden
+// Pendiente, que se puede editar antes de recibirla
+func (c CompraService) RegistrarOrdenSugerida(ctx context.Context, request *domain.OrdenSugeridaRequest) (*int, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return nil, datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if request.SucursalId <= 0 || request.ProveedorId <= 0 || request.UbicacionId <= 0 {
+		return nil, datatype.NewBadRequestError("Debe indicar la sucursal, el proveedor y la ubicación de recepción")
+	}
+	sugerencias, err := c.calcularSugerencias(ctx, request.SucursalId, request.Dias, request.DiasCobertura, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	seleccion := make(map[int]bool)
+	for _, productoId := range request.ProductoIds {
+		seleccion[productoId] = false
+	}
+	compra := domain.CompraRequest{ProveedorId: request.ProveedorId, UsuarioId: usuarioId, SucursalId: request.SucursalId}
+	for _, sugerencia := range sugerencias {
+		delProveedor := sugerencia.ProveedorId != nil && *sugerencia.ProveedorId == request.ProveedorId
+		if !delProveedor && !(sugerencia.ProveedorId == nil && request.IncluirSinProveedor) {
+			continue
+		}
+		for _, producto := range sugerencia.Productos {
+			if len(seleccion) > 0 {
+				if _, ok := seleccion[producto.Producto.Id]; !ok {
+					continue
+				}
+				seleccion[producto.Producto.Id] = true
+			}
+			compra.Detalles = append(compra.Detalles, domain.DetalleCompraRequest{
+				ProductoId:   producto.Producto.Id,
+				Cantidad:     producto.CantidadSugerida,
+				PrecioCompra: producto.PrecioCompra,
+				UbicacionId:  request.UbicacionId,
+			})
+		}
+	}
+	for productoId, incluido := range seleccion {
+		if !incluido {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("El producto %d no tiene reposición sugerida para este proveedor", productoId))
+		}
+	}
+	if len(compra.Detalles) == 0 {
+		return nil, datatype.NewBadRequestError("No hay productos sugeridos para este proveedor")
+	}
+	return c.RegistrarOrdenCompra(ctx, &compra)
+}
+
+// calcularSugerencias estima la venta diaria con lo vendido en los últimos dias (30 por defecto) y proyecta el
+// stock al llegar el pedido: stock + pedido pendiente − venta diaria × días de entrega del proveedor. Se repone
+// cuando esa proyección queda en el punto de reorden (o el mínimo) o por debajo, hasta el stock máximo o, sin
+// máximo, hasta el reorden más la venta de diasCobertura días (30 por defecto). Un producto sin niveles
+// configurados se repone cuando no alcanza para la cobertura.
+func (c CompraService) calcularSugerencias(ctx context.Context, sucursalId, dias, diasCobertura int, categoriaId *int) ([]domain.SugerenciaCompra, error) {
+	if dias == 0 {
+		dias = 30
+	}
+	if diasCobertura == 0 {
+		diasCobertura = 30
+	}
+	if dias < 1 || dias > 365 || diasCobertura < 1 || diasCobertura > 365 {
+		return nil, datatype.NewBadRequestError("dias y diasCobertura deben estar entre 1 y 365")
+	}
+	productos, err := c.compraRepository.ListarDatosReposicion(ctx, sucursalId, dias, categoriaId)
+	if err != nil {
+		return nil, err
+	}
+
+	sugerencias := make([]domain.SugerenciaCompra, 0)
+	indices := make(map[int]int) // proveedorId (0 sin proveedor) -> posición en sugerencias
+	for _, producto := range *productos {
+		producto.VentaDiaria = float64(producto.UnidadesVendidas) / float64(dias)
+		stockAlLlegar := float64(producto.Stock+producto.PedidoPendiente) - producto.VentaDiaria*float64(producto.DiasEntrega)
+
+		reorden, configurado := 0.0, false
+		if producto.PuntoReorden != nil {
+			reorden, configurado = float64(*producto.PuntoReorden), true
+		} else if producto.StockMinimo != nil {
+			reorden, configurado = float64(*producto.StockMinimo), true
+		}
+		objetivo := reorden + producto.VentaDiaria*float64(diasCobertura)
+		if producto.StockMaximo != nil {
+			objetivo, configurado = float64(*producto.StockMaximo), true
+		}
+		if !configurado {
+			reorden = objetivo
+		}
+		if stockAlLlegar > reorden {
+			continue
+		}
+		cantidad := int(math.Ceil(objetivo - stockAlLlegar))
+		if cantidad <= 0 {
+			continue
+		}
+		producto.CantidadSugerida = cantidad
+		producto.StockAlLlegar = math.Round(stockAlLlegar*100) / 100
+		producto.VentaDiaria = math.Round(producto.VentaDiaria*100) / 100
+		producto.Subtotal = math.Round(float64(cantidad)*producto.PrecioCompra*100) / 100
+
+		clave := 0
+		if producto.ProveedorId != nil {
+			clave = *producto.ProveedorId
+		}
+		i, ok := indices[clave]
+		if !ok {
+			i = len(sugerencias)
+			indices[clave] = i
+			sugerencias = append(sugerencias, domain.SugerenciaCompra{
+				ProveedorId: producto.ProveedorId,
+				Proveedor:   producto.Proveedor,
+				DiasEntrega: producto.DiasEntrega,
+			})
+		}
+		sugerencias[i].Productos = append(sugerencias[i].Productos, producto)
+		sugerencias[i].Total = math.Round((sugerencias[i].Total+producto.Subtotal)*100) / 100
+	}
+	return sugerencias, nil
+}
+
+// validarLote exige la fecha de vencimiento cuando se indica un lote; la fecha sola basta para el orden de salida
+func validarLote(lote, fechaVencimiento *string) error {
+	if fechaVencimiento == nil {
+		if lote != nil && *lote != "" {
+			return datatype.NewBadRequestError(fmt.Sprintf("El lote '%s' debe tener fecha de vencimiento.", *lote))
+		}
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", *fechaVencimiento); err != nil {
+		return datatype.NewBadRequestError("fechaVencimiento debe tener formato YYYY-MM-DD.")
+	}
+	return nil
+}
+
 func NewCompraService(compraRepository port.CompraRepository) *CompraService {
 	return &CompraService{compraRepository: compraRepository}
 }
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+)
+
+type ConfiguracionSucursalService struct {
+	configuracionSucursalRepository port.ConfiguracionSucursalRepository
+}
+
+func (c ConfiguracionSucursalService) ObtenerConfiguracionSucursal(ctx context.Context, sucursalId *int) (*domain.ConfiguracionSucursal, error) {
+	return c.configuracionSucursalRepository.ObtenerConfiguracionSucursal(ctx, sucursalId)
+}
+
+func (c ConfiguracionSucursalService) ModificarConfiguracionSucursal(ctx context.Context, sucursalId *int, request *domain.ConfiguracionSucursalRequest) error {
+	switch request.PoliticaStockOffline {
+	case "":
+		request.PoliticaStockOffline = domain.PoliticaStockMarcar
+	case domain.PoliticaStockPermitirNegativo, domain.PoliticaStockMarcar:
+	default:
+		return datatype.NewBadRequestError("La política de stock sin conexión debe ser PERMITIR_NEGATIVO o MARCAR.")
+	}
+	if request.DescuentoMaximo != nil && (*request.DescuentoMaximo < 0 || *request.DescuentoMaximo > 100) {
+		return datatype.NewBadRequestError("El descuento máximo debe estar entre 0 y 100 por ciento.")
+	}
+	if request.VentanaAnulacionMinutos != nil && *request.VentanaAnulacionMinutos < 0 {
+		return datatype.NewBadRequestError("La ventana de anulación no puede ser negativa.")
+	}
+	return c.configuracionSucursalRepository.ModificarConfiguracionSucursal(ctx, sucursalId, request)
+}
+
+func NewConfiguracionSucursalService(configuracionSucursalRepository port.ConfiguracionSucursalRepository) *ConfiguracionSucursalService {
+	return &ConfiguracionSucursalService{configuracionSucursalRepository: configuracionSucursalRepository}
+}
+
+var _ port.ConfiguracionSucursalService = (*ConfiguracionSucursalService)(nil)
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+)
+
+type ConteoInventarioService struct {
+	conteoInventarioRepository port.ConteoInventarioRepository
+}
+
+func (c ConteoInventarioService) AbrirConteo(ctx context.Context, request *domain.ConteoInventarioRequest) (*int, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return nil, datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if request.SucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("Debe indicar la sucursal del conteo.")
+	}
+	return c.conteoInventarioRepository.AbrirConteo(ctx, request, usuarioId)
+}
+
+func (c ConteoInventarioService) RegistrarCapturasConteo(ctx context.Context, conteoId int, request *domain.CapturaConteoRequest) error {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if len(request.Capturas) == 0 {
+		return datatype.NewBadRequestError("Debe registrar al menos una captura.")
+	}
+	for _, captura := range request.Capturas {
+		if captura.ProductoId <= 0 || captura.UbicacionId <= 0 {
+			return datatype.NewBadRequestError("Cada captura debe indicar producto y ubicación.")
+		}
+		if captura.Cantidad < 0 {
+			return datatype.NewBadRequestError(fmt.Sprintf("La cantidad contada del producto %d no puede ser negativa.", captura.ProductoId))
+		}
+	}
+	return c.conteoInventarioRepository.RegistrarCapturasConteo(ctx, conteoId, request, usuarioId)
+}
+
+func (c ConteoInventarioService) CerrarConteo(ctx context.Context, conteoId int) error {
+	return c.conteoInventarioRepository.CambiarEstadoConteo(ctx, conteoId, []string{domain.ConteoAbierto}, domain.ConteoEnRevision)
+}
+
+func (c ConteoInventarioService) ReabrirConteo(ctx context.Context, conteoId int) error {
+	return c.conteoInventarioRepository.CambiarEstadoConteo(ctx, conteoId, []string{domain.ConteoEnRevision}, domain.ConteoAbierto)
+}
+
+func (c ConteoInventarioService) CancelarConteo(ctx context.Context, conteoId int) error {
+	return c.conteoInventarioRepository.CambiarEstadoConteo(ctx, conteoId, []string{domain.ConteoAbierto, domain.ConteoEnRevision}, domain.ConteoCancelado)
+}
+
+func (c ConteoInventarioService) ContabilizarConteo(ctx context.Context, conteoId int, request *domain.ContabilizarConteoRequest) (*int, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return nil, datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	return c.conteoInventarioRepository.ContabilizarConteo(ctx, conteoId, request, usuarioId)
+}
+
+func (c ConteoInventarioService) ListarConteos(ctx context.Context, filtros map[string]string) (*[]domain.ConteoInventarioInfo, error) {
+	return c.conteoInventarioRepository.ListarConteos(ctx, filtros)
+}
+
+// ObtenerConteo oculta el stock del sistema mientras un conteo ciego sigue abierto
+func (c ConteoInventarioService) ObtenerConteo(ctx context.Context, conteoId int) (*domain.ConteoInventario, error) {
+	conteo, err := c.conteoInventarioRepository.ObtenerConteo(ctx, conteoId)
+	if err != nil {
+		return nil, err
+	}
+	if conteo.Ciego && conteo.Estado == domain.ConteoAbierto {
+		for i := range conteo.Detalles {
+			conteo.Detalles[i].StockSistema = nil
+			conteo.Detalles[i].MovimientosConteo = nil
+			conteo.Detalles[i].StockEsperado = nil
+			conteo.Detalles[i].Diferencia = nil
+		}
+	}
+	return conteo, nil
+}
+
+func NewConteoInventarioService(conteoInventarioRepository port.ConteoInventarioRepository) *ConteoInventarioService {
+	return &ConteoInventarioService{conteoInventarioRepository: conteoInventarioRepository}
+}
+
+var _ port.ConteoInventarioService = (*ConteoInventarioService)(nil)
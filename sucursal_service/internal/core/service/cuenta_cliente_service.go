@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+)
+
+type CuentaClienteService struct {
+	cuentaClienteRepository port.CuentaClienteRepository
+}
+
+func (c CuentaClienteService) RegistrarCuentaCliente(ctx context.Context, request *domain.CuentaClienteRequest) (*int, error) {
+	if request.ClienteId <= 0 || request.SucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("El cliente y la sucursal son obligatorios.")
+	}
+	if err := validarCuentaCliente(request); err != nil {
+		return nil, err
+	}
+	return c.cuentaClienteRepository.RegistrarCuentaCliente(ctx, request)
+}
+
+func (c CuentaClienteService) ModificarCuentaCliente(ctx context.Context, id *int, request *domain.CuentaClienteRequest) error {
+	if err := validarCuentaCliente(request); err != nil {
+		return err
+	}
+	return c.cuentaClienteRepository.ModificarCuentaCliente(ctx, id, request)
+}
+
+func (c CuentaClienteService) ListarCuentasCliente(ctx context.Context, filtros map[string]string) (*[]domain.CuentaCliente, error) {
+	return c.cuentaClienteRepository.ListarCuentasCliente(ctx, filtros)
+}
+
+func (c CuentaClienteService) ObtenerCuentaClienteById(ctx context.Context, id *int) (*domain.CuentaCliente, error) {
+	return c.cuentaClienteRepository.ObtenerCuentaClienteById(ctx, id)
+}
+
+func (c CuentaClienteService) RecargarCuentaCliente(ctx context.Context, id *int, request *domain.RecargaCuentaRequest) (*int, error) {
+	if request.Monto <= 0 {
+		return nil, datatype.NewBadRequestError("El monto de la recarga debe ser mayor a cero.")
+	}
+	if request.MetodoPagoId <= 0 {
+		return nil, datatype.NewBadRequestError("El método de pago de la recarga es obligatorio.")
+	}
+	return c.cuentaClienteRepository.RecargarCuentaCliente(ctx, id, request)
+}
+
+func (c CuentaClienteService) ObtenerEstadoCuentaCliente(ctx context.Context, id *int, filtros map[string]string) (*domain.EstadoCuentaCliente, error) {
+	return c.cuentaClienteRepository.ObtenerEstadoCuentaCliente(ctx, id, filtros)
+}
+
+func validarCuentaCliente(request *domain.CuentaClienteRequest) error {
+	if request.LimiteCredito < 0 {
+		return datatype.NewBadRequestError("El límite de crédito no puede ser negativo.")
+	}
+	if request.Estado == "" {
+		request.Estado = "Activo"
+	}
+	return nil
+}
+
+func NewCuentaClienteService(cuentaClienteRepository port.CuentaClienteRepository) *CuentaClienteService {
+	return &CuentaClienteService{cuentaClienteRepository: cuentaClienteRepository}
+}
+
+var _ port.CuentaClienteService = (*CuentaClienteService)(nil)
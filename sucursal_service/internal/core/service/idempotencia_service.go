@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/port"
+)
+
+type IdempotenciaService struct {
+	idempotenciaRepository port.IdempotenciaRepository
+}
+
+func (i IdempotenciaService) ReservarIdempotencia(ctx context.Context, request *domain.IdempotenciaRequest) (*domain.Idempotencia, error) {
+	return i.idempotenciaRepository.ReservarIdempotencia(ctx, request)
+}
+
+func (i IdempotenciaService) GuardarRespuestaIdempotencia(ctx context.Context, request *domain.IdempotenciaRespuestaRequest) error {
+	return i.idempotenciaRepository.GuardarRespuestaIdempotencia(ctx, request)
+}
+
+func (i IdempotenciaService) LiberarIdempotencia(ctx context.Context, clave string, usuarioId int) error {
+	return i.idempotenciaRepository.LiberarIdempotencia(ctx, clave, usuarioId)
+}
+
+func (i IdempotenciaService) EliminarIdempotenciasExpiradas(ctx context.Context) (int64, error) {
+	return i.idempotenciaRepository.EliminarIdempotenciasExpiradas(ctx)
+}
+
+func NewIdempotenciaService(idempotenciaRepository port.IdempotenciaRepository) *IdempotenciaService {
+	return &IdempotenciaService{idempotenciaRepository: idempotenciaRepository}
+}
+
+var _ port.IdempotenciaService = (*IdempotenciaService)(nil)
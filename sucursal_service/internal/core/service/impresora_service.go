@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ImpresoraService struct {
+	impresoraRepository            port.ImpresoraRepository
+	ventaRepository                port.VentaRepository
+	plantillaComprobanteRepository port.PlantillaComprobanteRepository
+	impresoraTermica               port.ImpresoraTermica
+}
+
+func (i ImpresoraService) RegistrarImpresora(ctx context.Context, request *domain.ImpresoraRequest) (*int, error) {
+	if err := validarImpresora(request); err != nil {
+		return nil, err
+	}
+	return i.impresoraRepository.RegistrarImpresora(ctx, request)
+}
+
+func (i ImpresoraService) ModificarImpresora(ctx context.Context, id *int, request *domain.ImpresoraRequest) error {
+	if err := validarImpresora(request); err != nil {
+		return err
+	}
+	return i.impresoraRepository.ModificarImpresora(ctx, id, request)
+}
+
+func (i ImpresoraService) ListarImpresoras(ctx context.Context, filtros map[string]string) (*[]domain.Impresora, error) {
+	return i.impresoraRepository.ListarImpresoras(ctx, filtros)
+}
+
+func (i ImpresoraService) ObtenerImpresoraById(ctx context.Context, id *int) (*domain.Impresora, error) {
+	return i.impresoraRepository.ObtenerImpresoraById(ctx, id)
+}
+
+// ProbarImpresora envía una página de prueba directamente, sin pasar por la cola.
+func (i ImpresoraService) ProbarImpresora(ctx context.Context, id *int) error {
+	impresora, err := i.impresoraRepository.ObtenerImpresoraById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = i.impresoraTermica.Enviar(ctx, impresora.Direccion, impresora.Puerto, i.impresoraTermica.RenderizarPrueba(impresora)); err != nil {
+		log.Println("Error en la prueba de impresora:", err)
+		return datatype.NewBadRequestError(fmt.Sprintf("La impresora no respondió: %s", err.Error()))
+	}
+	return nil
+}
+
+func (i ImpresoraService) ImprimirVenta(ctx context.Context, ventaId *int, request *domain.ImprimirVentaRequest) (*int, error) {
+	venta, err := i.ventaRepository.ObtenerVenta(ctx, ventaId)
+	if err != nil {
+		return nil, err
+	}
+	plantilla, err := i.plantillaComprobanteRepository.ObtenerPlantillaComprobante(ctx, &venta.Sucursal.Id)
+	if err != nil {
+		return nil, err
+	}
+	impresoraId := plantilla.ImpresoraId
+	if request.ImpresoraId != nil {
+		impresoraId = request.ImpresoraId
+	}
+	if impresoraId == nil {
+		return nil, datatype.NewBadRequestError("La sucursal no tiene una impresora configurada.")
+	}
+	return i.encolarTicket(ctx, venta, plantilla, impresoraId)
+}
+
+// ImprimirVentaAlCobrar encola el ticket cuando la venta queda pagada y la plantilla de la sucursal
+// tiene activa la impresión al cobrar.
+func (i ImpresoraService) ImprimirVentaAlCobrar(ctx context.Context, ventaId *int) error {
+	venta, err := i.ventaRepository.ObtenerVenta(ctx, ventaId)
+	if err != nil {
+		return err
+	}
+	if venta.Estado != "Completado" {
+		return nil
+	}
+	plantilla, err := i.plantillaComprobanteRepository.ObtenerPlantillaComprobante(ctx, &venta.Sucursal.Id)
+	if err != nil {
+		return err
+	}
+	if !plantilla.ImprimirAlCobrar || plantilla.ImpresoraId == nil {
+		return nil
+	}
+	_, err = i.encolarTicket(ctx, venta, plantilla, plantilla.ImpresoraId)
+	return err
+}
+
+func (i ImpresoraService) encolarTicket(ctx context.Context, venta *domain.Venta, plantilla *domain.PlantillaComprobante, impresoraId *int) (*int, error) {
+	impresora, err := i.impresoraRepository.ObtenerImpresoraById(ctx, impresoraId)
+	if err != nil {
+		return nil, err
+	}
+	if impresora.Sucursal == nil || impresora.Sucursal.Id != venta.Sucursal.Id {
+		return nil, datatype.NewBadRequestError("La impresora no pertenece a la sucursal de la venta.")
+	}
+	if impresora.Estado != "Activo" {
+		return nil, datatype.NewBadRequestError("La impresora está inactiva.")
+	}
+	contenido := i.impresoraTermica.RenderizarTicket(venta, plantilla, impresora.AnchoPapel)
+	return i.impresoraRepository.RegistrarTrabajoImpresion(ctx, impresora.Id, &venta.Id, contenido)
+}
+
+func (i ImpresoraService) ListarTrabajosImpresion(ctx context.Context, filtros map[string]string) (*[]domain.TrabajoImpresion, error) {
+	return i.impresoraRepository.ListarTrabajosImpresion(ctx, filtros)
+}
+
+func (i ImpresoraService) ReintentarTrabajoImpresion(ctx context.Context, id *int) error {
+	return i.impresoraRepository.ReintentarTrabajoImpresion(ctx, id)
+}
+
+func (i ImpresoraService) CancelarTrabajoImpresion(ctx context.Context, id *int) error {
+	return i.impresoraRepository.CancelarTrabajoImpresion(ctx, id)
+}
+
+// ProcesarTrabajosImpresion envía los trabajos reservados, uno por impresora en cada vuelta, hasta vaciar
+// las colas que están al día. Un fallo reprograma el trabajo con espera creciente (15 s por intento) hasta
+// agotar domain.MaxIntentosImpresion, y entonces queda en Error.
+func (i ImpresoraService) ProcesarTrabajosImpresion(ctx context.Context) (int, error) {
+	var impresos int
+	for {
+		trabajos, err := i.impresoraRepository.ReservarTrabajosImpresion(ctx)
+		if err != nil {
+			return impresos, err
+		}
+		if len(trabajos) == 0 {
+			return impresos, nil
+		}
+		// Cada trabajo es de una impresora distinta: se envían a la vez para que una impresora caída no
+		// demore a las demás, y los resultados se registran después en orden
+		errores := make([]error, len(trabajos))
+		var wg sync.WaitGroup
+		for n, t := range trabajos {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errores[n] = i.impresoraTermica.Enviar(ctx, t.Direccion, t.Puerto, t.Contenido)
+			}()
+		}
+		wg.Wait()
+
+		for n, t := range trabajos {
+			if err := errores[n]; err != nil {
+				log.Printf("Error al imprimir trabajo %d: %v\n", t.Id, err)
+				var proximo *time.Time
+				if t.Intentos+1 < domain.MaxIntentosImpresion {
+					espera := time.Now().Add(time.Duration(t.Intentos+1) * 15 * time.Second)
+					proximo = &espera
+				}
+				if err := i.impresoraRepository.MarcarTrabajoFallido(ctx, t.Id, err.Error(), proximo); err != nil {
+					return impresos, err
+				}
+				continue
+			}
+			if err := i.impresoraRepository.MarcarTrabajoImpreso(ctx, t.Id); err != nil {
+				return impresos, err
+			}
+			impresos++
+		}
+	}
+}
+
+func validarImpresora(request *domain.ImpresoraRequest) error {
+	request.Nombre = strings.TrimSpace(request.Nombre)
+	request.Direccion = strings.TrimSpace(request.Direccion)
+	if request.SucursalId <= 0 {
+		return datatype.NewBadRequestError("La sucursal es obligatoria.")
+	}
+	if request.Nombre == "" {
+		return datatype.NewBadRequestError("El nombre de la impresora es obligatorio.")
+	}
+	if request.Direccion == "" || strings.ContainsAny(request.Direccion, " /") {
+		return datatype.NewBadRequestError("La dirección de la impresora debe ser una IP o un nombre de host.")
+	}
+	if net.ParseIP(request.Direccion) == nil && strings.Contains(request.Direccion, ":") {
+		return datatype.NewBadRequestError("Indique el puerto en el campo puerto, no en la dirección.")
+	}
+	if request.Puerto == 0 {
+		request.Puerto = 9100
+	}
+	if request.Puerto < 1 || request.Puerto > 65535 {
+		return datatype.NewBadRequestError("El puerto debe estar entre 1 y 65535.")
+	}
+	switch request.AnchoPapel {
+	case "":
+		request.AnchoPapel = domain.AnchoPapel80mm
+	case domain.AnchoPapel58mm, domain.AnchoPapel80mm:
+	default:
+		return datatype.NewBadRequestError("El ancho de papel de la impresora debe ser 58mm u 80mm.")
+	}
+	if request.Estado == "" {
+		request.Estado = "Activo"
+	}
+	return nil
+}
+
+func NewImpresoraService(impresoraRepository port.ImpresoraRepository, ventaRepository port.VentaRepository, plantillaComprobanteRepository port.PlantillaComprobanteRepository, impresoraTermica port.ImpresoraTermica) *ImpresoraService {
+	return &ImpresoraService{impresoraRepository: impresoraRepository, ventaRepository: ventaRepository, plantillaComprobanteRepository: plantillaComprobanteRepository, impresoraTermica: impresoraTermica}
+}
+
+var _ port.ImpresoraService = (*ImpresoraService)(nil)
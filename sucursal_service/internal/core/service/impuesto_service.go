@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strings"
+)
+
+type ImpuestoService struct {
+	impuestoRepository port.ImpuestoRepository
+}
+
+func (i ImpuestoService) RegistrarImpuesto(ctx context.Context, request *domain.ImpuestoRequest) (*int, error) {
+	if err := validarImpuesto(request); err != nil {
+		return nil, err
+	}
+	return i.impuestoRepository.RegistrarImpuesto(ctx, request)
+}
+
+func (i ImpuestoService) ModificarImpuesto(ctx context.Context, id *int, request *domain.ImpuestoRequest) error {
+	if err := validarImpuesto(request); err != nil {
+		return err
+	}
+	return i.impuestoRepository.ModificarImpuesto(ctx, id, request)
+}
+
+func (i ImpuestoService) ListarImpuestos(ctx context.Context, filtros map[string]string) (*[]domain.Impuesto, error) {
+	return i.impuestoRepository.ListarImpuestos(ctx, filtros)
+}
+
+func (i ImpuestoService) ObtenerImpuestoById(ctx context.Context, id *int) (*domain.Impuesto, error) {
+	return i.impuestoRepository.ObtenerImpuestoById(ctx, id)
+}
+
+// validarImpuesto exige un único ámbito (país o sucursal); una tasa exenta se guarda con 0 %
+func validarImpuesto(request *domain.ImpuestoRequest) error {
+	request.Nombre = strings.TrimSpace(request.Nombre)
+	if request.Nombre == "" {
+		return datatype.NewBadRequestError("El nombre del impuesto es obligatorio.")
+	}
+	if (request.PaisId == nil) == (request.SucursalId == nil) {
+		return datatype.NewBadRequestError("El impuesto debe definirse para un país o para una sucursal.")
+	}
+	if request.Exento {
+		request.Porcentaje = 0
+	}
+	if request.Porcentaje < 0 || request.Porcentaje >= 100 {
+		return datatype.NewBadRequestError("El porcentaje del impuesto debe estar entre 0 y 100.")
+	}
+	if request.Estado == "" {
+		request.Estado = "Activo"
+	}
+	return nil
+}
+
+func NewImpuestoService(impuestoRepository port.ImpuestoRepository) *ImpuestoService {
+	return &ImpuestoService{impuestoRepository: impuestoRepository}
+}
+
+var _ port.ImpuestoService = (*ImpuestoService)(nil)
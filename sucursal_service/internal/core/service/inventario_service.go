@@ -2,20 +2,89 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"multiroom/sucursal-service/internal/core/domain"
 	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"net/http"
+	"strings"
+	"time"
 )
 
 type InventarioService struct {
 	inventarioRepository port.InventarioRepository
+	tipoAjusteRepository port.TipoAjusteRepository
+	rabbitMQService      port.RabbitMQService
+}
+
+func (i InventarioService) ListarLotes(ctx context.Context, filtros map[string]string) (*[]domain.LoteInventario, error) {
+	return i.inventarioRepository.ListarLotes(ctx, filtros)
+}
+
+func (i InventarioService) DarDeBajaLotes(ctx context.Context, request *domain.BajaLotesRequest) (*int, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return nil, datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if request.SucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("Debe indicar la sucursal.")
+	}
+	return i.inventarioRepository.DarDeBajaLotes(ctx, request, usuarioId)
+}
+
+func (i InventarioService) ListarProductosStockBajo(ctx context.Context, filtros map[string]string) (*[]domain.ProductoStockBajo, error) {
+	return i.inventarioRepository.ListarProductosStockBajo(ctx, filtros)
+}
+
+// PublicarAlertasStock envía las alertas pendientes a la cola de su sucursal en orden. Si RabbitMQ no está
+// disponible se detiene y las restantes quedan para la siguiente vuelta.
+func (i InventarioService) PublicarAlertasStock(ctx context.Context) (int, error) {
+	var publicadas int
+	for {
+		alertas, err := i.inventarioRepository.ListarAlertasStockPendientes(ctx, 100)
+		if err != nil {
+			return publicadas, err
+		}
+		if len(alertas) == 0 {
+			return publicadas, nil
+		}
+		for _, a := range alertas {
+			if err := i.rabbitMQService.Publish(port.ColaAlertasStock(a.SucursalId), a, port.ArgsColaAlertasStock); err != nil {
+				return publicadas, err
+			}
+			if err := i.inventarioRepository.MarcarAlertaStockPublicada(ctx, a.Id); err != nil {
+				return publicadas, err
+			}
+			publicadas++
+		}
+	}
 }
 
 func (i InventarioService) ObtenerTransferenciaById(ctx context.Context, id *int) (*domain.TransferenciaInventario, error) {
 	return i.inventarioRepository.ObtenerTransferenciaById(ctx, id)
 }
 
+func (i InventarioService) ObtenerKardex(ctx context.Context, filtros map[string]string) (*domain.Kardex, error) {
+	if err := validarRangoFechas(filtros); err != nil {
+		return nil, err
+	}
+	return i.inventarioRepository.ObtenerKardex(ctx, filtros)
+}
+
+// validarRangoFechas revisa el formato de los filtros fechaInicio y fechaFin
+func validarRangoFechas(filtros map[string]string) error {
+	for _, clave := range []string{"fechaInicio", "fechaFin"} {
+		if val := filtros[clave]; val != "" {
+			if _, err := time.Parse("2006-01-02", val); err != nil {
+				return datatype.NewBadRequestError(fmt.Sprintf("%s debe tener formato YYYY-MM-DD.", clave))
+			}
+		}
+	}
+	return nil
+}
+
 func (i InventarioService) ObtenerAjusteById(ctx context.Context, id *int) (*domain.AjusteInventario, error) {
 	return i.inventarioRepository.ObtenerAjusteById(ctx, id)
 }
@@ -25,9 +94,19 @@ func (i InventarioService) ListarTransferencias(ctx context.Context, filtros map
 }
 
 func (i InventarioService) ListarAjustes(ctx context.Context, filtros map[string]string) (*[]domain.AjusteInventarioInfo, error) {
+	if err := validarRangoFechas(filtros); err != nil {
+		return nil, err
+	}
 	return i.inventarioRepository.ListarAjustes(ctx, filtros)
 }
 
+func (i InventarioService) ResumenAjustesPorTipo(ctx context.Context, filtros map[string]string) (*[]domain.ResumenTipoAjuste, error) {
+	if err := validarRangoFechas(filtros); err != nil {
+		return nil, err
+	}
+	return i.inventarioRepository.ResumenAjustesPorTipo(ctx, filtros)
+}
+
 func (i InventarioService) RegistrarAjusteConDetalle(ctx context.Context, request *domain.AjusteInventarioRequest) (*int, error) {
 
 	// Validación de Vacío
@@ -35,11 +114,20 @@ func (i InventarioService) RegistrarAjusteConDetalle(ctx context.Context, reques
 		return nil, datatype.NewBadRequestError("El ajuste debe contener al menos un detalle.")
 	}
 
-	// Validación del Tipo de Ajuste (La nueva lógica)
-	direction, ok := domain.ValidAjusteTypes[request.TipoAjuste]
-	if !ok {
-		// El TipoAjuste enviado no existe en nuestro mapa de reglas
-		return nil, datatype.NewBadRequestError(fmt.Sprintf("El tipo de ajuste '%s' no es válido.", request.TipoAjuste))
+	// Validación del Tipo de Ajuste contra el catálogo tipo_ajuste
+	tipoAjuste, err := i.tipoAjusteRepository.ObtenerTipoAjustePorCodigo(ctx, request.TipoAjuste)
+	if err != nil {
+		var errorResponse *datatype.ErrorResponse
+		if errors.As(err, &errorResponse) && errorResponse.Code == http.StatusNotFound {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("El tipo de ajuste '%s' no es válido.", request.TipoAjuste))
+		}
+		return nil, err
+	}
+	if tipoAjuste.Estado != "Activo" {
+		return nil, datatype.NewBadRequestError(fmt.Sprintf("El tipo de ajuste '%s' está deshabilitado.", request.TipoAjuste))
+	}
+	if tipoAjuste.RequiereMotivo && strings.TrimSpace(request.Motivo) == "" {
+		return nil, datatype.NewBadRequestError(fmt.Sprintf("El tipo de ajuste '%s' requiere indicar el motivo.", request.TipoAjuste))
 	}
 
 	// Validación de Cantidades (La nueva lógica)
@@ -49,9 +137,18 @@ func (i InventarioService) RegistrarAjusteConDetalle(ctx context.Context, reques
 		if detalle.Cantidad == 0 {
 			return nil, datatype.NewBadRequestError(fmt.Sprintf("La cantidad para el producto %d no puede ser cero.", detalle.ProductoId))
 		}
+		if err := validarLote(detalle.Lote, detalle.FechaVencimiento); err != nil {
+			return nil, err
+		}
+		if detalle.Cantidad > 0 && detalle.LoteId != nil {
+			return nil, datatype.NewBadRequestError("loteId solo aplica a salidas; una entrada indica lote y fechaVencimiento.")
+		}
+		if detalle.Cantidad < 0 && (detalle.Lote != nil || detalle.FechaVencimiento != nil) {
+			return nil, datatype.NewBadRequestError("Una salida se descuenta de un lote existente con loteId.")
+		}
 
-		switch direction {
-		case domain.AjusteSalida:
+		switch tipoAjuste.Direccion {
+		case domain.DireccionAjusteSalida:
 			// Si el tipo es de Salida, la cantidad NO PUEDE ser positiva
 			if detalle.Cantidad > 0 {
 				return nil, datatype.NewBadRequestError(fmt.Sprintf(
@@ -59,7 +156,7 @@ func (i InventarioService) RegistrarAjusteConDetalle(ctx context.Context, reques
 					request.TipoAjuste, detalle.ProductoId,
 				))
 			}
-		case domain.AjusteEntrada:
+		case domain.DireccionAjusteEntrada:
 			// Si el tipo es de Entrada, la cantidad NO PUEDE ser negativa
 			if detalle.Cantidad < 0 {
 				return nil, datatype.NewBadRequestError(fmt.Sprintf(
@@ -67,8 +164,8 @@ func (i InventarioService) RegistrarAjusteConDetalle(ctx context.Context, reques
 					request.TipoAjuste, detalle.ProductoId,
 				))
 			}
-		case domain.AjusteMixto:
-			// Tipo "ERROR_CONTEO": Permite tanto positivos como negativos. No hacemos nada.
+		case domain.DireccionAjusteMixto:
+			// Tipos como "ERROR_CONTEO": permiten tanto positivos como negativos. No hacemos nada.
 		}
 	}
 
@@ -80,15 +177,98 @@ func (i InventarioService) RegistrarTransferencia(ctx context.Context, request *
 	if len(request.Detalles) == 0 {
 		return nil, datatype.NewBadRequestError("La transferencia debe contener al menos un detalle.")
 	}
+	if err := validarProductosTransferencia(request.Detalles); err != nil {
+		return nil, err
+	}
 	return i.inventarioRepository.RegistrarTransferencia(ctx, request)
 }
 
+func (i InventarioService) RegistrarSolicitudTransferencia(ctx context.Context, request *domain.TransferenciaRequest) (*int, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return nil, datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if request.UbicacionOrigenId == request.UbicacionDestinoId {
+		return nil, datatype.NewBadRequestError("La ubicación de origen y destino no pueden ser la misma.")
+	}
+	if len(request.Detalles) == 0 {
+		return nil, datatype.NewBadRequestError("La solicitud debe contener al menos un detalle.")
+	}
+	for _, detalle := range request.Detalles {
+		if detalle.Cantidad <= 0 {
+			return nil, datatype.NewBadRequestError("La cantidad solicitada debe ser mayor a cero.")
+		}
+	}
+	if err := validarProductosTransferencia(request.Detalles); err != nil {
+		return nil, err
+	}
+	request.UsuarioId = usuarioId
+	return i.inventarioRepository.RegistrarSolicitudTransferencia(ctx, request)
+}
+
+func (i InventarioService) AprobarTransferencia(ctx context.Context, transferenciaId int) error {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	return i.inventarioRepository.AprobarTransferencia(ctx, transferenciaId, usuarioId)
+}
+
+func (i InventarioService) RechazarTransferencia(ctx context.Context, transferenciaId int, request *domain.RechazoTransferenciaRequest) error {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if strings.TrimSpace(request.Motivo) == "" {
+		return datatype.NewBadRequestError("Debe indicar el motivo del rechazo.")
+	}
+	return i.inventarioRepository.RechazarTransferencia(ctx, transferenciaId, request.Motivo, usuarioId)
+}
+
+func (i InventarioService) DespacharTransferencia(ctx context.Context, transferenciaId int, request *domain.DespachoTransferenciaRequest) (string, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return "", datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	if err := validarProductosTransferencia(request.Detalles); err != nil {
+		return "", err
+	}
+	return i.inventarioRepository.DespacharTransferencia(ctx, transferenciaId, request, usuarioId)
+}
+
+func (i InventarioService) RecibirTransferencia(ctx context.Context, transferenciaId int, request *domain.RecepcionTransferenciaRequest) (*int, error) {
+	usuarioId, ok := ctx.Value(util.ContextUserIdKey).(int)
+	if !ok {
+		return nil, datatype.NewStatusUnauthorizedError("No se pudo identificar al usuario")
+	}
+	vistos := make(map[int]bool, len(request.Detalles))
+	for _, detalle := range request.Detalles {
+		if vistos[detalle.ProductoId] {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("El producto %d está repetido en la recepción.", detalle.ProductoId))
+		}
+		vistos[detalle.ProductoId] = true
+	}
+	return i.inventarioRepository.RecibirTransferencia(ctx, transferenciaId, request, usuarioId)
+}
+
+// validarProductosTransferencia evita líneas repetidas; el despacho y la recepción se concilian por producto
+func validarProductosTransferencia(detalles []domain.DetalleTransferenciaRequest) error {
+	vistos := make(map[int]bool, len(detalles))
+	for _, detalle := range detalles {
+		if vistos[detalle.ProductoId] {
+			return datatype.NewBadRequestError(fmt.Sprintf("El producto %d está repetido en la transferencia.", detalle.ProductoId))
+		}
+		vistos[detalle.ProductoId] = true
+	}
+	return nil
+}
+
 func (i InventarioService) ListarInventario(ctx context.Context, filtros map[string]string) (*[]domain.Inventario, error) {
 	return i.inventarioRepository.ListarInventario(ctx, filtros)
 }
 
-func NewInventarioService(inventarioRepository port.InventarioRepository) *InventarioService {
-	return &InventarioService{inventarioRepository: inventarioRepository}
+func NewInventarioService(inventarioRepository port.InventarioRepository, tipoAjusteRepository port.TipoAjusteRepository, rabbitMQService port.RabbitMQService) *InventarioService {
+	return &InventarioService{inventarioRepository: inventarioRepository, tipoAjusteRepository: tipoAjusteRepository, rabbitMQService: rabbitMQService}
 }
 
 var _ port.InventarioService = (*InventarioService)(nil)
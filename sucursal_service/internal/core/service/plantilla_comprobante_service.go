@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"strings"
+)
+
+type PlantillaComprobanteService struct {
+	plantillaComprobanteRepository port.PlantillaComprobanteRepository
+}
+
+func (p PlantillaComprobanteService) ObtenerPlantillaComprobante(ctx context.Context, sucursalId *int) (*domain.PlantillaComprobante, error) {
+	return p.plantillaComprobanteRepository.ObtenerPlantillaComprobante(ctx, sucursalId)
+}
+
+func (p PlantillaComprobanteService) ModificarPlantillaComprobante(ctx context.Context, sucursalId *int, request *domain.PlantillaComprobanteRequest, fileHeader *multipart.FileHeader) error {
+	request.NombreComercial = strings.TrimSpace(request.NombreComercial)
+	if request.NombreComercial == "" {
+		return datatype.NewBadRequestError("El nombre comercial es obligatorio.")
+	}
+	switch request.AnchoPapel {
+	case "":
+		request.AnchoPapel = domain.AnchoPapel80mm
+	case domain.AnchoPapel58mm, domain.AnchoPapel80mm, domain.AnchoPapelA4:
+	default:
+		return datatype.NewBadRequestError("El ancho de papel debe ser 58mm, 80mm o A4.")
+	}
+	if request.ImprimirAlCobrar && request.ImpresoraId == nil {
+		return datatype.NewBadRequestError("Para imprimir al cobrar se debe indicar la impresora.")
+	}
+	// Maroto solo admite imágenes PNG y JPG en el PDF
+	if fileHeader != nil && !util.File.ValidarTipoArchivo(fileHeader.Filename, ".png", ".jpg", ".jpeg") {
+		return datatype.NewBadRequestError("Tipo de archivo no válido")
+	}
+	return p.plantillaComprobanteRepository.ModificarPlantillaComprobante(ctx, sucursalId, request, fileHeader)
+}
+
+func NewPlantillaComprobanteService(plantillaComprobanteRepository port.PlantillaComprobanteRepository) *PlantillaComprobanteService {
+	return &PlantillaComprobanteService{plantillaComprobanteRepository: plantillaComprobanteRepository}
+}
+
+var _ port.PlantillaComprobanteService = (*PlantillaComprobanteService)(nil)
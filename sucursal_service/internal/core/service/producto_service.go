@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"mime/multipart"
 	"multiroom/sucursal-service/internal/core/domain"
 	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
 	"multiroom/sucursal-service/internal/core/util"
+	"strconv"
+	"strings"
 )
 
 type ProductoService struct {
@@ -21,6 +24,27 @@ func (p ProductoService) ActualizarProductoSucursal(ctx context.Context, id *int
 	return p.productoRepository.ActualizarProductoSucursal(ctx, id, req)
 }
 
+func (p ProductoService) ActualizarNivelesStock(ctx context.Context, id *int, req *domain.NivelesStockRequest) error {
+	niveles := []struct {
+		nombre string
+		valor  *int
+	}{{"stock mínimo", req.StockMinimo}, {"punto de reorden", req.PuntoReorden}, {"stock máximo", req.StockMaximo}}
+	for _, n := range niveles {
+		if n.valor != nil && *n.valor < 0 {
+			return datatype.NewBadRequestError(fmt.Sprintf("El %s no puede ser negativo.", n.nombre))
+		}
+	}
+	// Cada nivel configurado debe ser mayor o igual que los anteriores: mínimo <= reorden <= máximo
+	for i := range niveles {
+		for j := i + 1; j < len(niveles); j++ {
+			if niveles[i].valor != nil && niveles[j].valor != nil && *niveles[i].valor > *niveles[j].valor {
+				return datatype.NewBadRequestError(fmt.Sprintf("El %s no puede ser mayor que el %s.", niveles[i].nombre, niveles[j].nombre))
+			}
+		}
+	}
+	return p.productoRepository.ActualizarNivelesStock(ctx, id, req)
+}
+
 func (p ProductoService) ListarProductosPorSucursal(ctx context.Context, filtros map[string]string) (*[]domain.ProductoSucursalInfo, error) {
 	return p.productoRepository.ListarProductosPorSucursal(ctx, filtros)
 }
@@ -41,6 +65,12 @@ func (p ProductoService) RegistrarProducto(ctx context.Context, request *domain.
 	if !util.File.ValidarTipoArchivo(fileHeader.Filename, ".png", ".jpg", ".jpeg") {
 		return nil, datatype.NewBadRequestError("Tipo de archivo no válido")
 	}
+	if request.VigenciaDiasTarjeta < 0 {
+		return nil, datatype.NewBadRequestError("La vigencia de la tarjeta de regalo no puede ser negativa")
+	}
+	if err := validarCodigosProducto(request); err != nil {
+		return nil, err
+	}
 	return p.productoRepository.RegistrarProducto(ctx, request, fileHeader)
 }
 
@@ -48,6 +78,12 @@ func (p ProductoService) ModificarProductoById(ctx context.Context, productoId *
 	if !util.File.ValidarTipoArchivo(fileHeader.Filename, ".png", ".jpg", ".jpeg") {
 		return datatype.NewBadRequestError("Tipo de archivo no válido")
 	}
+	if request.VigenciaDiasTarjeta < 0 {
+		return datatype.NewBadRequestError("La vigencia de la tarjeta de regalo no puede ser negativa")
+	}
+	if err := validarCodigosProducto(request); err != nil {
+		return err
+	}
 	return p.productoRepository.ModificarProductoById(ctx, productoId, request, fileHeader)
 }
 
@@ -59,6 +95,169 @@ func (p ProductoService) ObtenerProductoById(ctx context.Context, productoId *in
 	return p.productoRepository.ObtenerProductoById(ctx, productoId)
 }
 
+func (p ProductoService) ObtenerProductoPorCodigo(ctx context.Context, codigo string, filtros map[string]string) (*domain.ProductoEscaneado, error) {
+	codigo = strings.TrimSpace(codigo)
+	if codigo == "" {
+		return nil, datatype.NewBadRequestError("El código es obligatorio")
+	}
+	sucursalId, err := strconv.Atoi(filtros["sucursalId"])
+	if err != nil || sucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("El ID de la sucursal es obligatorio")
+	}
+	return p.productoRepository.ObtenerProductoPorCodigo(ctx, codigo, sucursalId)
+}
+
+func (p ProductoService) ListarComponentesProducto(ctx context.Context, productoId *int, filtros map[string]string) (*[]domain.ProductoComponente, error) {
+	return p.productoRepository.ListarComponentesProducto(ctx, productoId, filtros)
+}
+
+func (p ProductoService) ModificarComponentesProducto(ctx context.Context, productoId *int, request *domain.ProductoComponentesRequest) error {
+	if request.SucursalId <= 0 {
+		return datatype.NewBadRequestError("El ID de la sucursal es obligatorio")
+	}
+	vistos := make(map[int]bool)
+	for _, c := range request.Componentes {
+		if c.ProductoId == *productoId {
+			return datatype.NewBadRequestError("Un kit no puede ser componente de sí mismo")
+		}
+		if c.Cantidad <= 0 {
+			return datatype.NewBadRequestError(fmt.Sprintf("La cantidad del componente %d debe ser mayor a cero", c.ProductoId))
+		}
+		if vistos[c.ProductoId] {
+			return datatype.NewBadRequestError(fmt.Sprintf("El componente %d está repetido", c.ProductoId))
+		}
+		vistos[c.ProductoId] = true
+	}
+	return p.productoRepository.ModificarComponentesProducto(ctx, productoId, request)
+}
+
+func (p ProductoService) ListarOpcionesProducto(ctx context.Context, productoId *int) (*[]domain.GrupoOpcion, error) {
+	return p.productoRepository.ListarOpcionesProducto(ctx, productoId)
+}
+
+func (p ProductoService) ModificarOpcionesProducto(ctx context.Context, productoId *int, request *domain.ProductoOpcionesRequest) error {
+	if err := validarGruposOpcion(productoId, request); err != nil {
+		return err
+	}
+	return p.productoRepository.ModificarOpcionesProducto(ctx, productoId, request)
+}
+
+// validarGruposOpcion normaliza los límites de selección: una variante exige exactamente una opción
+func validarGruposOpcion(productoId *int, request *domain.ProductoOpcionesRequest) error {
+	for i := range request.Grupos {
+		g := &request.Grupos[i]
+		g.Nombre = strings.TrimSpace(g.Nombre)
+		if g.Nombre == "" {
+			return datatype.NewBadRequestError("El nombre del grupo de opciones es obligatorio")
+		}
+		if len(g.Opciones) == 0 {
+			return datatype.NewBadRequestError(fmt.Sprintf("El grupo '%s' debe tener al menos una opción", g.Nombre))
+		}
+		switch g.Tipo {
+		case domain.GrupoOpcionVariante:
+			g.SeleccionMinima = 1
+			g.SeleccionMaxima = 1
+		case domain.GrupoOpcionModificador:
+			if g.SeleccionMaxima <= 0 {
+				g.SeleccionMaxima = len(g.Opciones)
+			}
+			if g.SeleccionMinima < 0 || g.SeleccionMinima > g.SeleccionMaxima {
+				return datatype.NewBadRequestError(fmt.Sprintf("La selección mínima del grupo '%s' debe estar entre 0 y la máxima", g.Nombre))
+			}
+		default:
+			return datatype.NewBadRequestError(fmt.Sprintf("El tipo de grupo '%s' no es válido", g.Tipo))
+		}
+		for j := range g.Opciones {
+			o := &g.Opciones[j]
+			o.Nombre = strings.TrimSpace(o.Nombre)
+			if o.Nombre == "" {
+				return datatype.NewBadRequestError(fmt.Sprintf("Las opciones del grupo '%s' deben tener nombre", g.Nombre))
+			}
+			// Apuntar al propio producto equivale a compartir su stock
+			if o.ProductoStockId != nil && *o.ProductoStockId == *productoId {
+				o.ProductoStockId = nil
+			}
+			if o.ProductoStockId == nil {
+				o.CantidadStock = 0
+			} else if o.CantidadStock <= 0 {
+				o.CantidadStock = 1
+			}
+		}
+	}
+	return nil
+}
+
+// validarCodigosProducto normaliza el SKU y los códigos de barras. EAN-13 y UPC-A se validan con su dígito
+// verificador; un código propio se imprime en Code128, que solo admite ASCII imprimible.
+func validarCodigosProducto(request *domain.ProductoRequest) error {
+	if request.Sku != nil {
+		sku := strings.TrimSpace(*request.Sku)
+		if len(sku) > 50 {
+			return datatype.NewBadRequestError("El SKU no puede superar los 50 caracteres")
+		}
+		request.Sku = &sku
+	}
+	if request.CodigosBarras == nil {
+		return nil
+	}
+	vistos := make(map[string]bool)
+	for i := range *request.CodigosBarras {
+		c := &(*request.CodigosBarras)[i]
+		c.Codigo = strings.TrimSpace(c.Codigo)
+		if c.Codigo == "" {
+			return datatype.NewBadRequestError("El código de barras no puede estar vacío")
+		}
+		switch c.Tipo {
+		case domain.CodigoBarrasEAN13:
+			if !digitoVerificadorValido(c.Codigo, 13) {
+				return datatype.NewBadRequestError(fmt.Sprintf("El código %s no es un EAN-13 válido", c.Codigo))
+			}
+		case domain.CodigoBarrasUPC:
+			if !digitoVerificadorValido(c.Codigo, 12) {
+				return datatype.NewBadRequestError(fmt.Sprintf("El código %s no es un UPC-A válido", c.Codigo))
+			}
+		case domain.CodigoBarrasPropio:
+			if len(c.Codigo) > 48 {
+				return datatype.NewBadRequestError(fmt.Sprintf("El código %s supera los 48 caracteres", c.Codigo))
+			}
+			for _, r := range c.Codigo {
+				if r < 32 || r > 126 {
+					return datatype.NewBadRequestError(fmt.Sprintf("El código %s solo puede tener caracteres ASCII imprimibles", c.Codigo))
+				}
+			}
+		default:
+			return datatype.NewBadRequestError(fmt.Sprintf("El tipo de código de barras '%s' no es válido", c.Tipo))
+		}
+		if vistos[c.Codigo] {
+			return datatype.NewBadRequestError(fmt.Sprintf("El código %s está repetido", c.Codigo))
+		}
+		vistos[c.Codigo] = true
+	}
+	return nil
+}
+
+// digitoVerificadorValido comprueba un código GTIN de la longitud dada: desde la derecha, sin contar el verificador,
+// los dígitos pesan 3 y 1 alternadamente
+func digitoVerificadorValido(codigo string, longitud int) bool {
+	if len(codigo) != longitud {
+		return false
+	}
+	suma := 0
+	for i := longitud - 2; i >= 0; i-- {
+		d := codigo[i]
+		if d < '0' || d > '9' {
+			return false
+		}
+		peso := 1
+		if (longitud-2-i)%2 == 0 {
+			peso = 3
+		}
+		suma += int(d-'0') * peso
+	}
+	ultimo := codigo[longitud-1]
+	return ultimo >= '0' && ultimo <= '9' && int(ultimo-'0') == (10-suma%10)%10
+}
+
 func NewProductoService(productoRepository port.ProductoRepository) *ProductoService {
 	return &ProductoService{productoRepository: productoRepository}
 }
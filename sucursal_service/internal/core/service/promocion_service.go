@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"strings"
+	"time"
+)
+
+type PromocionService struct {
+	promocionRepository port.PromocionRepository
+}
+
+func (p PromocionService) RegistrarPromocion(ctx context.Context, request *domain.PromocionRequest) (*int, error) {
+	if err := validarPromocion(request); err != nil {
+		return nil, err
+	}
+	return p.promocionRepository.RegistrarPromocion(ctx, request)
+}
+
+func (p PromocionService) ModificarPromocionById(ctx context.Context, id *int, request *domain.PromocionRequest) error {
+	if err := validarPromocion(request); err != nil {
+		return err
+	}
+	return p.promocionRepository.ModificarPromocionById(ctx, id, request)
+}
+
+func (p PromocionService) HabilitarPromocionById(ctx context.Context, id *int) error {
+	return p.promocionRepository.HabilitarPromocionById(ctx, id)
+}
+
+func (p PromocionService) DeshabilitarPromocionById(ctx context.Context, id *int) error {
+	return p.promocionRepository.DeshabilitarPromocionById(ctx, id)
+}
+
+func (p PromocionService) ListarPromociones(ctx context.Context, filtros map[string]string) (*[]domain.PromocionInfo, error) {
+	return p.promocionRepository.ListarPromociones(ctx, filtros)
+}
+
+func (p PromocionService) ObtenerPromocionById(ctx context.Context, id *int) (*domain.Promocion, error) {
+	return p.promocionRepository.ObtenerPromocionById(ctx, id)
+}
+
+func (p PromocionService) ListarDescuentosPorPromocion(ctx context.Context, filtros map[string]string) (*[]domain.PromocionDescuentoStat, error) {
+	return p.promocionRepository.ListarDescuentosPorPromocion(ctx, filtros)
+}
+
+// validarPromocion revisa la coherencia entre tipo, alcance y ventana de vigencia antes de guardar
+func validarPromocion(request *domain.PromocionRequest) error {
+	if strings.TrimSpace(request.Nombre) == "" {
+		return datatype.NewBadRequestError("El nombre de la promoción es obligatorio.")
+	}
+	if request.SucursalId <= 0 {
+		return datatype.NewBadRequestError("El ID de la sucursal es obligatorio.")
+	}
+
+	switch request.Tipo {
+	case domain.PromocionPorcentaje:
+		if request.Valor <= 0 || request.Valor > 100 {
+			return datatype.NewBadRequestError("El porcentaje de descuento debe estar entre 0 y 100.")
+		}
+	case domain.PromocionMontoFijo:
+		if request.Valor <= 0 {
+			return datatype.NewBadRequestError("El monto de descuento debe ser mayor a cero.")
+		}
+	case domain.PromocionCompraXLlevaY:
+		if request.CantidadCompra == nil || request.CantidadGratis == nil || *request.CantidadCompra <= 0 || *request.CantidadGratis <= 0 {
+			return datatype.NewBadRequestError("Las promociones 'compra X lleva Y' requieren cantidadCompra y cantidadGratis mayores a cero.")
+		}
+		if request.Alcance == domain.AlcancePromocionTiempoSala {
+			return datatype.NewBadRequestError("Las promociones 'compra X lleva Y' no se pueden aplicar al tiempo de sala.")
+		}
+	default:
+		return datatype.NewBadRequestError(fmt.Sprintf("El tipo de promoción '%s' no es válido.", request.Tipo))
+	}
+
+	switch request.Alcance {
+	case domain.AlcancePromocionTodos, domain.AlcancePromocionTiempoSala:
+		request.ProductoId = nil
+		request.CategoriaId = nil
+	case domain.AlcancePromocionProducto:
+		if request.ProductoId == nil {
+			return datatype.NewBadRequestError("Las promociones por producto requieren productoId.")
+		}
+		request.CategoriaId = nil
+	case domain.AlcancePromocionCategoria:
+		if request.CategoriaId == nil {
+			return datatype.NewBadRequestError("Las promociones por categoría requieren categoriaId.")
+		}
+		request.ProductoId = nil
+	default:
+		return datatype.NewBadRequestError(fmt.Sprintf("El alcance de promoción '%s' no es válido.", request.Alcance))
+	}
+
+	if request.FechaInicio != nil && request.FechaFin != nil && request.FechaFin.Before(*request.FechaInicio) {
+		return datatype.NewBadRequestError("La fecha de fin no puede ser anterior a la fecha de inicio.")
+	}
+	if (request.HoraInicio == nil) != (request.HoraFin == nil) {
+		return datatype.NewBadRequestError("Debe indicar tanto la hora de inicio como la hora de fin.")
+	}
+	if request.HoraInicio != nil {
+		if _, err := time.Parse("15:04", *request.HoraInicio); err != nil {
+			return datatype.NewBadRequestError("La hora de inicio debe tener el formato HH:MM.")
+		}
+		if _, err := time.Parse("15:04", *request.HoraFin); err != nil {
+			return datatype.NewBadRequestError("La hora de fin debe tener el formato HH:MM.")
+		}
+	}
+	for _, dia := range request.DiasSemana {
+		if dia < 1 || dia > 7 {
+			return datatype.NewBadRequestError("Los días de la semana deben estar entre 1 (lunes) y 7 (domingo).")
+		}
+	}
+
+	if request.CodigoCupon != nil {
+		codigo := strings.ToUpper(strings.TrimSpace(*request.CodigoCupon))
+		if codigo == "" {
+			request.CodigoCupon = nil
+		} else {
+			request.CodigoCupon = &codigo
+		}
+	}
+	if request.UsoMaximo != nil && *request.UsoMaximo <= 0 {
+		return datatype.NewBadRequestError("El límite de usos debe ser mayor a cero.")
+	}
+	if request.Estado == "" {
+		request.Estado = "Activo"
+	}
+	return nil
+}
+
+func NewPromocionService(promocionRepository port.PromocionRepository) *PromocionService {
+	return &PromocionService{promocionRepository: promocionRepository}
+}
+
+var _ port.PromocionService = (*PromocionService)(nil)
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
 )
 
@@ -11,10 +12,16 @@ type ProveedorService struct {
 }
 
 func (p ProveedorService) RegistrarProveedor(ctx context.Context, request *domain.ProveedorRequest) (*int, error) {
+	if request.DiasEntrega < 0 {
+		return nil, datatype.NewBadRequestError("Los días de entrega no pueden ser negativos")
+	}
 	return p.proveedorRepository.RegistrarProveedor(ctx, request)
 }
 
 func (p ProveedorService) ModificarProveedor(ctx context.Context, id *int, request *domain.ProveedorRequest) error {
+	if request.DiasEntrega < 0 {
+		return datatype.NewBadRequestError("Los días de entrega no pueden ser negativos")
+	}
 	return p.proveedorRepository.ModificarProveedor(ctx, id, request)
 }
 
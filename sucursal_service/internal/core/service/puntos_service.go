@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+)
+
+type PuntosService struct {
+	puntosRepository port.PuntosRepository
+}
+
+func (p PuntosService) ObtenerProgramaPuntos(ctx context.Context, sucursalId *int) (*domain.ProgramaPuntos, error) {
+	return p.puntosRepository.ObtenerProgramaPuntos(ctx, sucursalId)
+}
+
+func (p PuntosService) ModificarProgramaPuntos(ctx context.Context, sucursalId *int, request *domain.ProgramaPuntosRequest) error {
+	if err := validarProgramaPuntos(request); err != nil {
+		return err
+	}
+	return p.puntosRepository.ModificarProgramaPuntos(ctx, sucursalId, request)
+}
+
+func (p PuntosService) ObtenerPuntosCliente(ctx context.Context, clienteId *int64, filtros map[string]string) (*domain.PuntosCliente, error) {
+	return p.puntosRepository.ObtenerPuntosCliente(ctx, clienteId, filtros)
+}
+
+func (p PuntosService) VencerPuntos(ctx context.Context) (int64, error) {
+	return p.puntosRepository.VencerPuntos(ctx)
+}
+
+func validarProgramaPuntos(request *domain.ProgramaPuntosRequest) error {
+	if request.PuntosPorBs < 0 || request.PuntosPorHora < 0 || request.ValorPunto < 0 || request.PuntosPorHoraGratis < 0 {
+		return datatype.NewBadRequestError("Las tasas de acumulación y canje no pueden ser negativas.")
+	}
+	if request.DiasVigencia < 0 {
+		return datatype.NewBadRequestError("Los días de vigencia no pueden ser negativos.")
+	}
+	if request.Estado == "" {
+		request.Estado = "Activo"
+	}
+	vistas := make(map[int]bool)
+	for _, c := range request.Categorias {
+		if c.CategoriaId <= 0 {
+			return datatype.NewBadRequestError("Cada tasa de categoría debe indicar una categoría válida.")
+		}
+		if c.PuntosPorBs < 0 {
+			return datatype.NewBadRequestError("La tasa de una categoría no puede ser negativa.")
+		}
+		if vistas[c.CategoriaId] {
+			return datatype.NewBadRequestError("Una categoría no puede repetirse en el programa de puntos.")
+		}
+		vistas[c.CategoriaId] = true
+	}
+	return nil
+}
+
+func NewPuntosService(puntosRepository port.PuntosRepository) *PuntosService {
+	return &PuntosService{puntosRepository: puntosRepository}
+}
+
+var _ port.PuntosService = (*PuntosService)(nil)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"multiroom/sucursal-service/internal/core/domain"
 	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
 	"strconv"
@@ -11,11 +12,16 @@ import (
 	"time"
 
 	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/code"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
 	"github.com/johnfercher/maroto/v2/pkg/components/line"
 	"github.com/johnfercher/maroto/v2/pkg/components/row"
 	"github.com/johnfercher/maroto/v2/pkg/components/text"
 	"github.com/johnfercher/maroto/v2/pkg/config"
 	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/barcode"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontfamily"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
@@ -25,9 +31,14 @@ import (
 )
 
 type ReporteService struct {
-	ventaRepository    port.VentaRepository
-	sucursalRepository port.SucursalRepository
-	productoRepository port.ProductoRepository
+	ventaRepository                port.VentaRepository
+	sucursalRepository             port.SucursalRepository
+	productoRepository             port.ProductoRepository
+	promocionRepository            port.PromocionRepository
+	cuentaClienteRepository        port.CuentaClienteRepository
+	plantillaComprobanteRepository port.PlantillaComprobanteRepository
+	inventarioRepository           port.InventarioRepository
+	autorizacionRepository         port.AutorizacionRepository
 }
 
 func (r ReporteService) ReportePDFProductosVendidos(ctx context.Context, filtros map[string]string) (core.Document, error) {
@@ -199,6 +210,38 @@ func (r ReporteService) ReportePDFProductosVendidos(ctx context.Context, filtros
 		text.NewCol(3, fmt.Sprintf("Bs %.2f", granTotalDinero), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 11, Top: 1}),
 	)
 
+	// ==========================================
+	// 4. CONSUMO DE COMPONENTES (KITS)
+	// ==========================================
+	consumos, err := r.ventaRepository.ListarConsumoComponentes(ctx, filtros)
+	if err != nil {
+		return nil, err
+	}
+	if consumos != nil && len(*consumos) > 0 {
+		m.AddRow(6)
+		m.AddRow(8, text.NewCol(gridSum, "CONSUMO DE COMPONENTES (KITS)", subTitleStyle))
+		m.AddRow(8,
+			text.NewCol(5, "KIT", props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 9, Top: 1.5}),
+			text.NewCol(2, "KITS", headerCellStyle),
+			text.NewCol(3, "COMPONENTE", props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 9, Top: 1.5}),
+			text.NewCol(2, "CONSUMIDO", headerCellStyle),
+		).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
+
+		for i, c := range *consumos {
+			currentRowColor := colorZebraOdd
+			if i%2 == 0 {
+				currentRowColor = colorZebraEven
+			}
+			m.AddRow(6,
+				text.NewCol(5, c.Kit.Nombre, rowTextStyle),
+				text.NewCol(2, fmt.Sprintf("%d", c.CantidadKits), rowNumStyle),
+				text.NewCol(3, c.Componente.Nombre, rowTextStyle),
+				text.NewCol(2, fmt.Sprintf("%d", c.CantidadConsumida), rowNumStyle),
+			).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
+		}
+		m.AddRow(2, line.NewCol(gridSum, props.Line{Color: colorLine}))
+	}
+
 	// Generar
 	document, err := m.Generate()
 	if err != nil {
@@ -302,12 +345,14 @@ func (r ReporteService) ReportePDFVentas(ctx context.Context, filtros map[string
 	// Fila 4: Encabezados de Tabla (Fondo Gris)
 	// Usamos .WithStyle(&props.Cell{BackgroundColor: ...}) para dar color a toda la franja
 	r4 := row.New(7).Add(
-		text.NewCol(4, "FECHA", tableHeaderStyle),
-		text.NewCol(4, "CÓDIGO", tableHeaderStyle),
+		text.NewCol(3, "FECHA", tableHeaderStyle),
+		text.NewCol(2, "CÓDIGO", tableHeaderStyle),
 		text.NewCol(4, "SUCURSAL", tableHeaderStyle),
-		text.NewCol(5, "VENDEDOR", props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 9, Top: 1.5}),
+		text.NewCol(4, "VENDEDOR", props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 9, Top: 1.5}),
 		text.NewCol(3, "ESTADO", tableHeaderStyle),
-		text.NewCol(4, "TOTAL", props.Text{Style: fontstyle.Bold, Align: align.Right, Size: 9, Top: 1.5}),
+		text.NewCol(3, "BASE", props.Text{Style: fontstyle.Bold, Align: align.Right, Size: 9, Top: 1.5}),
+		text.NewCol(2, "IMP.", props.Text{Style: fontstyle.Bold, Align: align.Right, Size: 9, Top: 1.5}),
+		text.NewCol(3, "TOTAL", props.Text{Style: fontstyle.Bold, Align: align.Right, Size: 9, Top: 1.5}),
 	).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
 
 	// Fila 5: Línea sutil debajo de la cabecera
@@ -323,6 +368,7 @@ func (r ReporteService) ReportePDFVentas(ctx context.Context, filtros map[string
 	// 2. CUERPO (DATOS)
 	// ==========================================
 	var totalGeneral float64 = 0
+	var baseGeneral, impuestoGeneral float64
 	var cantidadVentas = 0
 
 	if ventas != nil {
@@ -343,6 +389,8 @@ func (r ReporteService) ReportePDFVentas(ctx context.Context, filtros map[string
 
 			if v.Estado == "Completado" {
 				totalGeneral += v.Total
+				baseGeneral += v.BaseImponible
+				impuestoGeneral += v.Impuesto
 				cantidadVentas++
 			}
 
@@ -354,12 +402,14 @@ func (r ReporteService) ReportePDFVentas(ctx context.Context, filtros map[string
 
 			// Agregamos la fila con color de fondo alternado
 			m.AddRow(6,
-				text.NewCol(4, v.CreadoEn.Format("02/01/06 15:04"), rowTextStyle),
-				text.NewCol(4, fmt.Sprintf("%07d", v.CodigoVenta), props.Text{Align: align.Center, Size: 8, Top: 1}),
+				text.NewCol(3, v.CreadoEn.Format("02/01/06 15:04"), rowTextStyle),
+				text.NewCol(2, fmt.Sprintf("%07d", v.CodigoVenta), props.Text{Align: align.Center, Size: 8, Top: 1}),
 				text.NewCol(4, nombreSucursal, rowTextStyle),
-				text.NewCol(5, v.Usuario.Username, rowTextStyle),
+				text.NewCol(4, v.Usuario.Username, rowTextStyle),
 				text.NewCol(3, v.Estado, props.Text{Align: align.Center, Size: 8, Top: 1}),
-				text.NewCol(4, fmt.Sprintf("%.2f", v.Total), rowMoneyStyle),
+				text.NewCol(3, fmt.Sprintf("%.2f", v.BaseImponible), rowMoneyStyle),
+				text.NewCol(2, fmt.Sprintf("%.2f", v.Impuesto), rowMoneyStyle),
+				text.NewCol(3, fmt.Sprintf("%.2f", v.Total), rowMoneyStyle),
 			).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
 		}
 	}
@@ -379,6 +429,48 @@ func (r ReporteService) ReportePDFVentas(ctx context.Context, filtros map[string
 		text.NewCol(6, fmt.Sprintf("Transacciones: %d", cantidadVentas), props.Text{Align: align.Right, Size: 9, Top: 2}),
 		text.NewCol(3, fmt.Sprintf("Bs %.2f", totalGeneral), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 10, Top: 1}),
 	)
+	m.AddRow(6,
+		text.NewCol(18, fmt.Sprintf("Base imponible: Bs %.2f", baseGeneral), props.Text{Align: align.Right, Size: 9}),
+		text.NewCol(6, fmt.Sprintf("Impuesto: Bs %.2f", impuestoGeneral), props.Text{Align: align.Right, Size: 9}),
+	)
+
+	// ==========================================
+	// 4. DESCUENTOS POR PROMOCIÓN
+	// ==========================================
+	descuentos, err := r.promocionRepository.ListarDescuentosPorPromocion(ctx, filtros)
+	if err != nil {
+		return nil, err
+	}
+	if descuentos != nil && len(*descuentos) > 0 {
+		m.AddRow(6)
+		m.AddRow(8, text.NewCol(gridSum, "DESCUENTOS POR PROMOCIÓN", subTitleStyle))
+		m.AddRow(7,
+			text.NewCol(12, "PROMOCIÓN", props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 9, Top: 1.5}),
+			text.NewCol(4, "TIPO", tableHeaderStyle),
+			text.NewCol(4, "VENTAS", tableHeaderStyle),
+			text.NewCol(4, "DESCUENTO", props.Text{Style: fontstyle.Bold, Align: align.Right, Size: 9, Top: 1.5}),
+		).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
+
+		var totalDescuentos float64 = 0
+		for i, d := range *descuentos {
+			totalDescuentos += d.TotalDescuentos
+			currentRowColor := colorZebraOdd
+			if i%2 == 0 {
+				currentRowColor = colorZebraEven
+			}
+			m.AddRow(6,
+				text.NewCol(12, d.Promocion.Nombre, rowTextStyle),
+				text.NewCol(4, d.Tipo, props.Text{Align: align.Center, Size: 8, Top: 1}),
+				text.NewCol(4, fmt.Sprintf("%d", d.CantidadVentas), props.Text{Align: align.Center, Size: 8, Top: 1}),
+				text.NewCol(4, fmt.Sprintf("%.2f", d.TotalDescuentos), rowMoneyStyle),
+			).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
+		}
+		m.AddRow(2, line.NewCol(gridSum, props.Line{Color: colorLine}))
+		m.AddRow(8,
+			text.NewCol(20, "TOTAL DESCUENTOS POR PROMOCIÓN:", props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 9, Top: 2}),
+			text.NewCol(4, fmt.Sprintf("Bs %.2f", totalDescuentos), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 10, Top: 1}),
+		)
+	}
 
 	// Generar
 	document, err := m.Generate()
@@ -396,36 +488,105 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 		return nil, err
 	}
 
-	// 2. Configuración para Ticket Térmico (80mm)
+	// 2. Plantilla de la sucursal (marca, pie y ancho de papel)
+	plantilla, err := r.plantillaComprobanteRepository.ObtenerPlantillaComprobante(ctx, &venta.Sucursal.Id)
+	if err != nil {
+		return nil, err
+	}
+	return renderizarComprobante(venta, plantilla)
+}
+
+// VistaPreviaComprobante renderiza la plantilla de la sucursal con una venta de ejemplo.
+func (r ReporteService) VistaPreviaComprobante(ctx context.Context, sucursalId *int) (core.Document, error) {
+	plantilla, err := r.plantillaComprobanteRepository.ObtenerPlantillaComprobante(ctx, sucursalId)
+	if err != nil {
+		return nil, err
+	}
+	sucursal, err := r.sucursalRepository.ObtenerSucursalById(ctx, sucursalId)
+	if err != nil {
+		return nil, err
+	}
+	observacion := "Vista previa"
+	ventaEjemplo := &domain.Venta{
+		VentaInfo: domain.VentaInfo{
+			CodigoVenta:      1,
+			CostoTiempoVenta: 30,
+			Total:            60,
+			BaseImponible:    53.10,
+			Impuesto:         6.90,
+			Estado:           "Completado",
+			Observacion:      &observacion,
+			CreadoEn:         time.Now(),
+			Usuario:          domain.UsuarioSimple{Username: "cajero"},
+			Sucursal:         &domain.SucursalInfo{Nombre: sucursal.Nombre},
+			Sala:             &domain.SalaInfo{Sala: domain.Sala{Nombre: "Sala 1"}},
+		},
+		Detalles: []domain.DetalleVenta{
+			{Producto: domain.Producto{ProductoInfo: domain.ProductoInfo{Nombre: "Gaseosa 500ml"}}, Cantidad: 2, PrecioVenta: 10},
+			{Producto: domain.Producto{ProductoInfo: domain.ProductoInfo{Nombre: "Snack"}}, Cantidad: 1, PrecioVenta: 10},
+		},
+		Pagos: &[]domain.VentaPago{
+			{MetodoPago: domain.MetodoPago{Nombre: "Efectivo"}, Monto: 100},
+		},
+	}
+	return renderizarComprobante(ventaEjemplo, plantilla)
+}
+
+// renderizarComprobante arma el ticket de la venta con el formato de la plantilla.
+func renderizarComprobante(venta *domain.Venta, plantilla *domain.PlantillaComprobante) (core.Document, error) {
+	// Configuración según el ancho de papel; el tamaño de letra y los separadores se ajustan a él
 	gridSum := 24
-	cfg := config.NewBuilder().
+	builder := config.NewBuilder().
 		WithTopMargin(5).
 		WithLeftMargin(2).
 		WithRightMargin(2).
 		WithBottomMargin(5).
 		WithDisableAutoPageBreak(false).
-		WithDimensions(80, 200).
-		WithMaxGridSize(gridSum).
-		Build()
+		WithMaxGridSize(gridSum)
+	anchoSeparador := 34
+	var ajusteFuente float64
+	altoLogo := 15.0
+	switch plantilla.AnchoPapel {
+	case domain.AnchoPapel58mm:
+		builder = builder.WithDimensions(58, 200)
+		anchoSeparador = 24
+		ajusteFuente = -1.5
+		altoLogo = 12
+	case domain.AnchoPapelA4:
+		builder = builder.WithPageSize(pagesize.A4).WithLeftMargin(15).WithRightMargin(15).WithTopMargin(10)
+		anchoSeparador = 100
+		ajusteFuente = 1
+		altoLogo = 25
+	default:
+		builder = builder.WithDimensions(80, 200)
+	}
 
-	mrt := maroto.New(cfg)
+	mrt := maroto.New(builder.Build())
 	m := maroto.NewMetricsDecorator(mrt)
 
 	// --- Helpers ---
 	fMoney := func(val float64) string {
 		return fmt.Sprintf("%.2f", val)
 	}
-	separatorDouble := "=================================="
-	separatorDashed := "----------------------------------"
+	fs := func(size float64) float64 {
+		return size + ajusteFuente
+	}
+	separatorDouble := strings.Repeat("=", anchoSeparador)
+	separatorDashed := strings.Repeat("-", anchoSeparador)
 
 	// ==========================================
 	// 1. CABECERA
 	// ==========================================
+	if plantilla.RutaLogo != nil {
+		m.AddRow(altoLogo,
+			image.NewFromFileCol(gridSum, *plantilla.RutaLogo, props.Rect{Center: true, Percent: 100}),
+		)
+	}
 	m.AddRow(5,
-		text.NewCol(gridSum, "ESCONDITE MULTIROOM", props.Text{
+		text.NewCol(gridSum, plantilla.NombreComercial, props.Text{
 			Style: fontstyle.Bold,
 			Align: align.Center,
-			Size:  11,
+			Size:  fs(11),
 		}),
 	)
 
@@ -434,13 +595,25 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 		nombreSucursal = venta.Sucursal.Nombre
 	}
 	m.AddRow(4,
-		text.NewCol(gridSum, nombreSucursal, props.Text{Align: align.Center, Size: 9}),
-	)
-	m.AddRow(4,
-		text.NewCol(gridSum, "Tel. 76328248", props.Text{Align: align.Center, Size: 8}),
+		text.NewCol(gridSum, nombreSucursal, props.Text{Align: align.Center, Size: fs(9)}),
 	)
+	if plantilla.Direccion != nil && *plantilla.Direccion != "" {
+		m.AddRow(4,
+			text.NewCol(gridSum, *plantilla.Direccion, props.Text{Align: align.Center, Size: fs(8)}),
+		)
+	}
+	if plantilla.Telefono != nil && *plantilla.Telefono != "" {
+		m.AddRow(4,
+			text.NewCol(gridSum, fmt.Sprintf("Tel. %s", *plantilla.Telefono), props.Text{Align: align.Center, Size: fs(8)}),
+		)
+	}
+	if plantilla.Nit != nil && *plantilla.Nit != "" {
+		m.AddRow(4,
+			text.NewCol(gridSum, fmt.Sprintf("NIT: %s", *plantilla.Nit), props.Text{Align: align.Center, Size: fs(8)}),
+		)
+	}
 
-	m.AddRow(3, text.NewCol(gridSum, separatorDouble, props.Text{Align: align.Center, Size: 8}))
+	m.AddRow(3, text.NewCol(gridSum, separatorDouble, props.Text{Align: align.Center, Size: fs(8)}))
 
 	// ==========================================
 	// 2. DATOS DE LA ORDEN
@@ -448,13 +621,13 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 	m.AddRow(4,
 		text.NewCol(gridSum, fmt.Sprintf("ORDEN #: %06d", venta.CodigoVenta), props.Text{
 			Style: fontstyle.Bold,
-			Size:  9,
+			Size:  fs(9),
 			Align: align.Center,
 		}),
 	)
 
-	labelStyle := props.Text{Align: align.Left, Size: 8}
-	valStyle := props.Text{Align: align.Left, Size: 8}
+	labelStyle := props.Text{Align: align.Left, Size: fs(8)}
+	valStyle := props.Text{Align: align.Left, Size: fs(8)}
 
 	m.AddRow(4,
 		text.NewCol(5, "Fecha:", labelStyle),
@@ -479,20 +652,33 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 		text.NewCol(15, nombreSala, valStyle),
 	)
 
-	m.AddRow(3, text.NewCol(gridSum, separatorDouble, props.Text{Align: align.Center, Size: 8}))
+	if venta.Nit != nil && *venta.Nit != "" {
+		m.AddRow(4,
+			text.NewCol(5, "NIT/CI:", labelStyle),
+			text.NewCol(15, *venta.Nit, valStyle),
+		)
+	}
+	if venta.RazonSocial != nil && *venta.RazonSocial != "" {
+		m.AddRow(4,
+			text.NewCol(5, "Señor(es):", labelStyle),
+			text.NewCol(15, *venta.RazonSocial, valStyle),
+		)
+	}
+
+	m.AddRow(3, text.NewCol(gridSum, separatorDouble, props.Text{Align: align.Center, Size: fs(8)}))
 
 	// ==========================================
 	// 3. TABLA DE DETALLES (Productos + Tiempo Sala)
 	// ==========================================
 	m.AddRow(4,
-		text.NewCol(9, "PROD.", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Left}),
-		text.NewCol(3, "PRE.", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Left}),
-		text.NewCol(3, "CANT.", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Center}),
-		text.NewCol(3, "DESC.", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Center}),
-		text.NewCol(6, "SUB.", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Right}),
+		text.NewCol(9, "PROD.", props.Text{Style: fontstyle.Bold, Size: fs(8), Align: align.Left}),
+		text.NewCol(3, "PRE.", props.Text{Style: fontstyle.Bold, Size: fs(8), Align: align.Left}),
+		text.NewCol(3, "CANT.", props.Text{Style: fontstyle.Bold, Size: fs(8), Align: align.Center}),
+		text.NewCol(3, "DESC.", props.Text{Style: fontstyle.Bold, Size: fs(8), Align: align.Center}),
+		text.NewCol(6, "SUB.", props.Text{Style: fontstyle.Bold, Size: fs(8), Align: align.Right}),
 	)
 
-	m.AddRow(2, text.NewCol(gridSum, separatorDashed, props.Text{Align: align.Center, Size: 8}))
+	m.AddRow(2, text.NewCol(gridSum, separatorDashed, props.Text{Align: align.Center, Size: fs(8)}))
 
 	var subTotalAcumulado float64 = 0
 
@@ -502,31 +688,53 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 		subTotalAcumulado += totalLinea
 
 		m.AddRow(4,
-			text.NewCol(9, d.Producto.Nombre, props.Text{Size: 8, Align: align.Left}),
-			text.NewCol(3, fMoney(d.PrecioVenta), props.Text{Size: 8, Align: align.Center}),
-			text.NewCol(3, fmt.Sprintf("%d", d.Cantidad), props.Text{Size: 8, Align: align.Center}),
-			text.NewCol(3, fMoney(d.Descuento), props.Text{Size: 8, Align: align.Center}),
-			text.NewCol(6, fMoney(totalLinea), props.Text{Size: 8, Align: align.Right}),
+			text.NewCol(9, d.Producto.Nombre, props.Text{Size: fs(8), Align: align.Left}),
+			text.NewCol(3, fMoney(d.PrecioVenta), props.Text{Size: fs(8), Align: align.Center}),
+			text.NewCol(3, fmt.Sprintf("%d", d.Cantidad), props.Text{Size: fs(8), Align: align.Center}),
+			text.NewCol(3, fMoney(d.Descuento), props.Text{Size: fs(8), Align: align.Center}),
+			text.NewCol(6, fMoney(totalLinea), props.Text{Size: fs(8), Align: align.Right}),
 		)
+		// Variantes y modificadores elegidos (el precio unitario ya incluye sus diferencias)
+		for _, o := range d.Opciones {
+			delta := ""
+			if o.PrecioDelta != 0 {
+				delta = fmt.Sprintf(" (%+.2f)", o.PrecioDelta)
+			}
+			m.AddRow(3,
+				text.NewCol(gridSum, fmt.Sprintf("  + %s: %s%s", o.Grupo, o.Nombre, delta), props.Text{Size: fs(7), Align: align.Left}),
+			)
+		}
+		if d.Promocion != nil && d.DescuentoPromocion > 0 {
+			m.AddRow(3,
+				text.NewCol(18, fmt.Sprintf("  Promo: %s", d.Promocion.Nombre), props.Text{Size: fs(7), Align: align.Left, Style: fontstyle.Italic}),
+				text.NewCol(6, "-"+fMoney(d.DescuentoPromocion), props.Text{Size: fs(7), Align: align.Right, Style: fontstyle.Italic}),
+			)
+		}
 	}
 
 	// 3.2 Listar Tiempo de Sala (Como si fuera un producto más)
 	if venta.CostoTiempoVenta > 0 {
 		// Agregamos el costo del tiempo al subtotal para que cuadre la suma visual
-		subTotalAcumulado += venta.CostoTiempoVenta
+		subTotalAcumulado += venta.CostoTiempoVenta - venta.DescuentoTiempo
 
 		nombreItemSala := "USO SALA"
 
 		m.AddRow(4,
-			text.NewCol(9, nombreItemSala, props.Text{Size: 8, Align: align.Left}),
-			text.NewCol(3, fMoney(venta.CostoTiempoVenta), props.Text{Size: 8, Align: align.Center}),
-			text.NewCol(3, "1", props.Text{Size: 8, Align: align.Center}),          // Cantidad
-			text.NewCol(3, fMoney(0.00), props.Text{Size: 8, Align: align.Center}), // Descuento
-			text.NewCol(6, fMoney(venta.CostoTiempoVenta), props.Text{Size: 8, Align: align.Right}),
+			text.NewCol(9, nombreItemSala, props.Text{Size: fs(8), Align: align.Left}),
+			text.NewCol(3, fMoney(venta.CostoTiempoVenta), props.Text{Size: fs(8), Align: align.Center}),
+			text.NewCol(3, "1", props.Text{Size: fs(8), Align: align.Center}),                           // Cantidad
+			text.NewCol(3, fMoney(venta.DescuentoTiempo), props.Text{Size: fs(8), Align: align.Center}), // Descuento
+			text.NewCol(6, fMoney(venta.CostoTiempoVenta-venta.DescuentoTiempo), props.Text{Size: fs(8), Align: align.Right}),
 		)
+		if venta.PromocionTiempo != nil && venta.DescuentoTiempo > 0 {
+			m.AddRow(3,
+				text.NewCol(18, fmt.Sprintf("  Promo: %s", venta.PromocionTiempo.Nombre), props.Text{Size: fs(7), Align: align.Left, Style: fontstyle.Italic}),
+				text.NewCol(6, "-"+fMoney(venta.DescuentoTiempo), props.Text{Size: fs(7), Align: align.Right, Style: fontstyle.Italic}),
+			)
+		}
 	}
 
-	m.AddRow(2, text.NewCol(gridSum, separatorDashed, props.Text{Align: align.Center, Size: 8}))
+	m.AddRow(2, text.NewCol(gridSum, separatorDashed, props.Text{Align: align.Center, Size: fs(8)}))
 
 	// ==========================================
 	// 4. TOTALES
@@ -534,22 +742,32 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 
 	// Subtotal (Ahora incluye productos + tiempo sala)
 	m.AddRow(4,
-		text.NewCol(12, "Subtotal:", props.Text{Align: align.Right, Size: 8}),
-		text.NewCol(8, fMoney(subTotalAcumulado), props.Text{Align: align.Right, Size: 8}),
+		text.NewCol(12, "Subtotal:", props.Text{Align: align.Right, Size: fs(8)}),
+		text.NewCol(8, fMoney(subTotalAcumulado), props.Text{Align: align.Right, Size: fs(8)}),
 	)
 
 	// Descuento General
 	if venta.DescuentoGeneral > 0 {
 		m.AddRow(4,
-			text.NewCol(12, "Descuento Adicional:", props.Text{Align: align.Right, Size: 8}),
-			text.NewCol(8, "-"+fMoney(venta.DescuentoGeneral), props.Text{Align: align.Right, Size: 8}),
+			text.NewCol(12, "Descuento Adicional:", props.Text{Align: align.Right, Size: fs(8)}),
+			text.NewCol(8, "-"+fMoney(venta.DescuentoGeneral), props.Text{Align: align.Right, Size: fs(8)}),
 		)
 	}
 
 	// TOTAL FINAL
 	m.AddRow(6,
-		text.NewCol(10, "TOTAL:", props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 11}),
-		text.NewCol(10, fmt.Sprintf("Bs.%s", fMoney(venta.Total)), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 11}),
+		text.NewCol(10, "TOTAL:", props.Text{Align: align.Right, Style: fontstyle.Bold, Size: fs(11)}),
+		text.NewCol(10, fmt.Sprintf("Bs.%s", fMoney(venta.Total)), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: fs(11)}),
+	)
+
+	// Desglose del impuesto incluido en el total
+	m.AddRow(4,
+		text.NewCol(12, "Base imponible:", props.Text{Align: align.Right, Size: fs(8)}),
+		text.NewCol(8, fMoney(venta.BaseImponible), props.Text{Align: align.Right, Size: fs(8)}),
+	)
+	m.AddRow(4,
+		text.NewCol(12, "Impuesto:", props.Text{Align: align.Right, Size: fs(8)}),
+		text.NewCol(8, fMoney(venta.Impuesto), props.Text{Align: align.Right, Size: fs(8)}),
 	)
 
 	// ==========================================
@@ -558,13 +776,13 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 
 	if venta.Pagos != nil && len(*venta.Pagos) > 0 {
 		m.AddRow(4,
-			text.NewCol(gridSum, "PAGADO CON:", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Left}),
+			text.NewCol(gridSum, "PAGADO CON:", props.Text{Style: fontstyle.Bold, Size: fs(8), Align: align.Left}),
 		)
 
 		for _, pago := range *venta.Pagos {
 			m.AddRow(4,
-				text.NewCol(10, pago.MetodoPago.Nombre, props.Text{Size: 8, Align: align.Left}),
-				text.NewCol(10, fMoney(pago.Monto), props.Text{Size: 8, Align: align.Right}),
+				text.NewCol(10, pago.MetodoPago.Nombre, props.Text{Size: fs(8), Align: align.Left}),
+				text.NewCol(10, fMoney(pago.Monto), props.Text{Size: fs(8), Align: align.Right}),
 			)
 		}
 
@@ -576,31 +794,1133 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 		if totalPagado > venta.Total {
 			cambio := totalPagado - venta.Total
 			m.AddRow(4,
-				text.NewCol(10, "CAMBIO:", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Left}),
-				text.NewCol(10, fMoney(cambio), props.Text{Size: 8, Align: align.Right}),
+				text.NewCol(10, "CAMBIO:", props.Text{Style: fontstyle.Bold, Size: fs(8), Align: align.Left}),
+				text.NewCol(10, fMoney(cambio), props.Text{Size: fs(8), Align: align.Right}),
 			)
 		}
 
 	} else {
 		m.AddRow(4,
-			text.NewCol(6, "ESTADO:", props.Text{Size: 8, Align: align.Left}),
-			text.NewCol(14, "Pendiente de Pago", props.Text{Size: 8, Align: align.Right}),
+			text.NewCol(6, "ESTADO:", props.Text{Size: fs(8), Align: align.Left}),
+			text.NewCol(14, "Pendiente de Pago", props.Text{Size: fs(8), Align: align.Right}),
+		)
+	}
+
+	// Códigos de las tarjetas de regalo vendidas
+	if len(venta.TarjetasRegalo) > 0 {
+		m.AddRow(2, text.NewCol(gridSum, separatorDashed, props.Text{Align: align.Center, Size: fs(8)}))
+		m.AddRow(4,
+			text.NewCol(gridSum, "TARJETAS DE REGALO:", props.Text{Style: fontstyle.Bold, Size: fs(8), Align: align.Left}),
 		)
+		for _, t := range venta.TarjetasRegalo {
+			m.AddRow(4,
+				text.NewCol(12, t.Codigo, props.Text{Size: fs(8), Style: fontstyle.Bold, Align: align.Left}),
+				text.NewCol(8, fMoney(t.MontoInicial), props.Text{Size: fs(8), Align: align.Right}),
+			)
+			if t.VenceEn != nil {
+				m.AddRow(3,
+					text.NewCol(gridSum, fmt.Sprintf("  Vence: %s", t.VenceEn.Format("02/01/2006")), props.Text{Size: fs(7), Align: align.Left}),
+				)
+			}
+		}
 	}
 
-	m.AddRow(2, text.NewCol(gridSum, separatorDashed, props.Text{Align: align.Center, Size: 8}))
+	m.AddRow(2, text.NewCol(gridSum, separatorDashed, props.Text{Align: align.Center, Size: fs(8)}))
+
+	// Datos de la factura emitida por el proveedor fiscal
+	if venta.DocumentoFiscal != nil && venta.DocumentoFiscal.Estado == domain.DocumentoFiscalEmitido {
+		if venta.DocumentoFiscal.Numero != nil {
+			m.AddRow(4,
+				text.NewCol(8, "Factura N°:", labelStyle),
+				text.NewCol(16, *venta.DocumentoFiscal.Numero, valStyle),
+			)
+		}
+		if venta.DocumentoFiscal.CodigoAutorizacion != nil {
+			m.AddRow(4,
+				text.NewCol(8, "Cód. Autorización:", labelStyle),
+				text.NewCol(16, *venta.DocumentoFiscal.CodigoAutorizacion, props.Text{Align: align.Left, Size: fs(7)}),
+			)
+		}
+		m.AddRow(2, text.NewCol(gridSum, separatorDashed, props.Text{Align: align.Center, Size: fs(8)}))
+	}
 
 	// ==========================================
 	// 6. PIE DE PÁGINA
 	// ==========================================
-	m.AddRow(4,
-		text.NewCol(gridSum, "¡Gracias por su compra!", props.Text{Align: align.Center, Size: 9}),
+	if plantilla.PiePagina != nil {
+		for _, linea := range strings.Split(*plantilla.PiePagina, "\n") {
+			m.AddRow(4,
+				text.NewCol(gridSum, linea, props.Text{Align: align.Center, Size: fs(9)}),
+			)
+		}
+	}
+
+	m.AddRow(3, text.NewCol(gridSum, separatorDouble, props.Text{Align: align.Center, Size: fs(8)}))
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+func (r ReporteService) ReportePDFSaldosPendientes(ctx context.Context, filtros map[string]string) (core.Document, error) {
+	// 1. Obtener Datos: solo cuentas con deuda, ordenadas por sucursal
+	filtrosCuentas := map[string]string{"conDeuda": "true", "sucursalId": filtros["sucursalId"]}
+	cuentas, err := r.cuentaClienteRepository.ListarCuentasCliente(ctx, filtrosCuentas)
+	if err != nil {
+		return nil, err
+	}
+
+	colorHeaderBg := &props.Color{Red: 230, Green: 230, Blue: 230}
+	colorZebraEven := &props.Color{Red: 250, Green: 250, Blue: 250}
+	colorZebraOdd := &props.Color{Red: 255, Green: 255, Blue: 255}
+	colorLine := &props.Color{Red: 100, Green: 100, Blue: 100}
+
+	// 2. Configurar PDF
+	gridSum := 12
+	pageNumber := props.PageNumber{
+		Pattern: "Página {current} de {total}",
+		Place:   props.RightBottom,
+		Family:  fontfamily.Arial,
+		Style:   fontstyle.Italic,
+		Size:    8,
+		Color:   &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.Letter).
+		WithOrientation(orientation.Vertical).
+		WithTopMargin(10).
+		WithLeftMargin(15).
+		WithRightMargin(15).
+		WithBottomMargin(10).
+		WithMaxGridSize(gridSum).
+		WithPageNumber(pageNumber).
+		Build()
+
+	m := maroto.New(cfg)
+
+	titleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 14}
+	subTitleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 10, Color: colorLine}
+	headerCellStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Size: 9, Top: 1.5}
+	rowTextStyle := props.Text{Align: align.Left, Size: 8, Top: 1}
+	rowMoneyStyle := props.Text{Align: align.Right, Size: 8, Top: 1}
+
+	now := time.Now().Format("02/01/2006 15:04")
+
+	// ==========================================
+	// 1. CABECERA
+	// ==========================================
+	r1 := row.New(12).Add(
+		text.NewCol(8, "ESCONDITE MULTIROOM", titleStyle),
+		text.NewCol(4, fmt.Sprintf("Generado: %s", now), props.Text{Align: align.Right, Size: 8, Style: fontstyle.Italic}),
 	)
-	m.AddRow(4,
-		text.NewCol(gridSum, "Vuelva pronto", props.Text{Align: align.Center, Size: 9}),
+	r2 := row.New(10).Add(
+		text.NewCol(12, "SALDOS PENDIENTES DE CUENTAS CORRIENTES", subTitleStyle),
+	)
+	r3 := row.New(8).Add(
+		text.NewCol(5, "CLIENTE", props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 9, Top: 1.5}),
+		text.NewCol(2, "CELULAR", headerCellStyle),
+		text.NewCol(2, "LÍMITE", props.Text{Style: fontstyle.Bold, Align: align.Right, Size: 9, Top: 1.5}),
+		text.NewCol(3, "SALDO PENDIENTE", props.Text{Style: fontstyle.Bold, Align: align.Right, Size: 9, Top: 1.5}),
+	).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
+	r4 := row.New(1).Add(line.NewCol(gridSum, props.Line{Color: colorLine}))
+
+	if err := m.RegisterHeader(r1, r2, r3, r4); err != nil {
+		return nil, err
+	}
+
+	// ==========================================
+	// 2. CUERPO (agrupado por sucursal)
+	// ==========================================
+	var granTotal, totalSucursal float64
+	sucursalActual := -1
+	cerrarSucursal := func() {
+		if sucursalActual < 0 {
+			return
+		}
+		m.AddRow(7,
+			text.NewCol(9, "Subtotal sucursal:", props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 8, Top: 1.5}),
+			text.NewCol(3, fmt.Sprintf("Bs %.2f", totalSucursal), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 9, Top: 1}),
+		)
+	}
+
+	if cuentas != nil {
+		for i, cuenta := range *cuentas {
+			if cuenta.Sucursal.Id != sucursalActual {
+				cerrarSucursal()
+				sucursalActual = cuenta.Sucursal.Id
+				totalSucursal = 0
+				m.AddRow(4)
+				m.AddRow(7, text.NewCol(gridSum, strings.ToUpper(cuenta.Sucursal.Nombre), props.Text{Style: fontstyle.Bold, Size: 9, Top: 1.5}))
+			}
+			pendiente := -cuenta.Saldo
+			totalSucursal += pendiente
+			granTotal += pendiente
+
+			currentRowColor := colorZebraOdd
+			if i%2 == 0 {
+				currentRowColor = colorZebraEven
+			}
+			m.AddRow(6,
+				text.NewCol(5, strings.TrimSpace(cuenta.Cliente.Nombres+" "+cuenta.Cliente.Apellidos), rowTextStyle),
+				text.NewCol(2, cuenta.Cliente.Celular, props.Text{Align: align.Center, Size: 8, Top: 1}),
+				text.NewCol(2, fmt.Sprintf("%.2f", cuenta.LimiteCredito), rowMoneyStyle),
+				text.NewCol(3, fmt.Sprintf("%.2f", pendiente), rowMoneyStyle),
+			).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
+		}
+	}
+	cerrarSucursal()
+
+	// ==========================================
+	// 3. TOTAL
+	// ==========================================
+	m.AddRow(2, line.NewCol(gridSum, props.Line{Color: colorLine}))
+	cantidad := 0
+	if cuentas != nil {
+		cantidad = len(*cuentas)
+	}
+	m.AddRow(12,
+		text.NewCol(5, fmt.Sprintf("Cuentas con deuda: %d", cantidad), props.Text{Align: align.Left, Size: 9, Top: 2}),
+		text.NewCol(4, "TOTAL PENDIENTE:", props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 9, Top: 2}),
+		text.NewCol(3, fmt.Sprintf("Bs %.2f", granTotal), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 11, Top: 1}),
+	)
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+func (r ReporteService) ReportePDFExcepciones(ctx context.Context, filtros map[string]string) (core.Document, error) {
+	// 1. Obtener Datos: descuentos y anulaciones que un supervisor autorizó
+	autorizaciones, err := r.autorizacionRepository.ListarAutorizacionesVenta(ctx, filtros)
+	if err != nil {
+		return nil, err
+	}
+
+	colorHeaderBg := &props.Color{Red: 230, Green: 230, Blue: 230}
+	colorZebraEven := &props.Color{Red: 250, Green: 250, Blue: 250}
+	colorZebraOdd := &props.Color{Red: 255, Green: 255, Blue: 255}
+	colorLine := &props.Color{Red: 100, Green: 100, Blue: 100}
+
+	// 2. Configurar PDF
+	gridSum := 24
+	pageNumber := props.PageNumber{
+		Pattern: "Página {current} de {total}",
+		Place:   props.RightBottom,
+		Family:  fontfamily.Arial,
+		Style:   fontstyle.Italic,
+		Size:    8,
+		Color:   &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.Letter).
+		WithOrientation(orientation.Horizontal).
+		WithTopMargin(10).
+		WithLeftMargin(15).
+		WithRightMargin(15).
+		WithBottomMargin(10).
+		WithMaxGridSize(gridSum).
+		WithPageNumber(pageNumber).
+		Build()
+
+	m := maroto.New(cfg)
+
+	titleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 14}
+	subTitleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 10, Color: colorLine}
+	tableHeaderStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Size: 9, Top: 1.5}
+	rowTextStyle := props.Text{Align: align.Left, Size: 8, Top: 1}
+	rowCenterStyle := props.Text{Align: align.Center, Size: 8, Top: 1}
+
+	now := time.Now().Format("02/01/2006 15:04")
+
+	// ==========================================
+	// 1. CABECERA
+	// ==========================================
+	r1 := row.New(12).Add(
+		text.NewCol(16, "ESCONDITE MULTIROOM", titleStyle),
+		text.NewCol(8, fmt.Sprintf("Impreso: %s", now), props.Text{Align: align.Right, Size: 8, Style: fontstyle.Italic}),
 	)
 
-	m.AddRow(3, text.NewCol(gridSum, separatorDouble, props.Text{Align: align.Center, Size: 8}))
+	var partesFiltro []string
+	if val, ok := filtros["sucursalId"]; ok && val != "" {
+		sucursalId, err := strconv.Atoi(val)
+		if err != nil {
+			log.Println("Error al convertir sucursalId a int:", err)
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		sucursal, err := r.sucursalRepository.ObtenerSucursalById(ctx, &sucursalId)
+		if err != nil {
+			return nil, err
+		}
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Sucursal: %s, País: %s", sucursal.Nombre, sucursal.Pais.Nombre))
+	}
+	if val, ok := filtros["tipo"]; ok && val != "" {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Tipo: %s", val))
+	}
+	if val, ok := filtros["fechaInicio"]; ok && val != "" {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Desde: %s", val))
+	}
+	if val, ok := filtros["fechaFin"]; ok && val != "" {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Hasta: %s", val))
+	}
+
+	infoFiltros := "Filtros: General"
+	if len(partesFiltro) > 0 {
+		infoFiltros = strings.Join(partesFiltro, " | ")
+	}
+
+	r2 := row.New(10).Add(
+		text.NewCol(12, "REPORTE DE EXCEPCIONES AUTORIZADAS", subTitleStyle),
+		text.NewCol(12, infoFiltros, props.Text{Align: align.Right, Size: 9}),
+	)
+	r3 := row.New(4)
+	r4 := row.New(8).Add(
+		text.NewCol(3, "FECHA", tableHeaderStyle),
+		text.NewCol(2, "CÓDIGO", tableHeaderStyle),
+		text.NewCol(3, "SUCURSAL", tableHeaderStyle),
+		text.NewCol(2, "TIPO", tableHeaderStyle),
+		text.NewCol(2, "MÉTODO", tableHeaderStyle),
+		text.NewCol(3, "SUPERVISOR", tableHeaderStyle),
+		text.NewCol(2, "USUARIO", tableHeaderStyle),
+		text.NewCol(5, "DETALLE", tableHeaderStyle),
+		text.NewCol(2, "MOTIVO", tableHeaderStyle),
+	).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
+	r5 := row.New(1).Add(line.NewCol(gridSum, props.Line{Color: colorLine}))
+
+	if err := m.RegisterHeader(r1, r2, r3, r4, r5); err != nil {
+		return nil, err
+	}
+
+	// ==========================================
+	// 2. CUERPO
+	// ==========================================
+	porTipo := map[string]int{}
+	for i, a := range *autorizaciones {
+		porTipo[a.Tipo]++
+
+		sucursal := ""
+		if a.Sucursal != nil {
+			sucursal = a.Sucursal.Nombre
+		}
+		usuario := "-"
+		if a.Usuario != nil {
+			usuario = a.Usuario.Username
+		}
+		motivo := "-"
+		if a.Motivo != nil && *a.Motivo != "" {
+			motivo = *a.Motivo
+		}
+
+		currentRowColor := colorZebraOdd
+		if i%2 == 0 {
+			currentRowColor = colorZebraEven
+		}
+		m.AddRow(8,
+			text.NewCol(3, a.CreadoEn.Format("02/01/2006 15:04"), rowCenterStyle),
+			text.NewCol(2, strconv.FormatInt(a.CodigoVenta, 10), rowCenterStyle),
+			text.NewCol(3, sucursal, rowTextStyle),
+			text.NewCol(2, a.Tipo, rowCenterStyle),
+			text.NewCol(2, a.Metodo, rowCenterStyle),
+			text.NewCol(3, a.Supervisor.Username, rowTextStyle),
+			text.NewCol(2, usuario, rowTextStyle),
+			text.NewCol(5, a.Detalle, rowTextStyle),
+			text.NewCol(2, motivo, rowTextStyle),
+		).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
+	}
+
+	// ==========================================
+	// 3. TOTALES
+	// ==========================================
+	m.AddRow(2, line.NewCol(gridSum, props.Line{Color: colorLine}))
+	m.AddRow(12,
+		text.NewCol(8, fmt.Sprintf("Descuentos autorizados: %d", porTipo[domain.AutorizacionDescuento]), props.Text{Align: align.Left, Size: 9, Top: 2}),
+		text.NewCol(8, fmt.Sprintf("Anulaciones autorizadas: %d", porTipo[domain.AutorizacionAnulacion]), props.Text{Align: align.Left, Size: 9, Top: 2}),
+		text.NewCol(8, fmt.Sprintf("TOTAL EXCEPCIONES: %d", len(*autorizaciones)), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 10, Top: 2}),
+	)
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+func (r ReporteService) ReportePDFKardex(ctx context.Context, filtros map[string]string) (core.Document, error) {
+	// 1. Obtener Datos
+	if err := validarRangoFechas(filtros); err != nil {
+		return nil, err
+	}
+	kardex, err := r.inventarioRepository.ObtenerKardex(ctx, filtros)
+	if err != nil {
+		return nil, err
+	}
+
+	colorHeaderBg := &props.Color{Red: 230, Green: 230, Blue: 230}
+	colorZebraEven := &props.Color{Red: 250, Green: 250, Blue: 250}
+	colorZebraOdd := &props.Color{Red: 255, Green: 255, Blue: 255}
+	colorLine := &props.Color{Red: 100, Green: 100, Blue: 100}
+
+	// 2. Configurar PDF
+	gridSum := 24
+	pageNumber := props.PageNumber{
+		Pattern: "Página {current} de {total}",
+		Place:   props.RightBottom,
+		Family:  fontfamily.Arial,
+		Style:   fontstyle.Italic,
+		Size:    8,
+		Color:   &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.Letter).
+		WithOrientation(orientation.Horizontal).
+		WithTopMargin(10).
+		WithLeftMargin(15).
+		WithRightMargin(15).
+		WithBottomMargin(10).
+		WithMaxGridSize(gridSum).
+		WithPageNumber(pageNumber).
+		Build()
+
+	m := maroto.New(cfg)
+
+	titleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 14}
+	subTitleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 10, Color: colorLine}
+	tableHeaderStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Size: 9, Top: 1.5}
+	rowTextStyle := props.Text{Align: align.Left, Size: 8, Top: 1}
+	rowNumberStyle := props.Text{Align: align.Right, Size: 8, Top: 1}
+
+	now := time.Now().Format("02/01/2006 15:04")
+
+	// ==========================================
+	// 1. CABECERA
+	// ==========================================
+	r1 := row.New(12).Add(
+		text.NewCol(16, "ESCONDITE MULTIROOM", titleStyle),
+		text.NewCol(8, fmt.Sprintf("Impreso: %s", now), props.Text{Align: align.Right, Size: 8, Style: fontstyle.Italic}),
+	)
+
+	partesFiltro := []string{fmt.Sprintf("Producto: %s", kardex.Producto)}
+	if kardex.SucursalId != nil {
+		sucursal, err := r.sucursalRepository.ObtenerSucursalById(ctx, kardex.SucursalId)
+		if err != nil {
+			return nil, err
+		}
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Sucursal: %s", sucursal.Nombre))
+	}
+	if kardex.UbicacionId != nil {
+		ubicacion := fmt.Sprintf("#%d", *kardex.UbicacionId)
+		if len(kardex.Movimientos) > 0 {
+			ubicacion = kardex.Movimientos[0].Ubicacion
+		}
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Ubicación: %s", ubicacion))
+	}
+	if kardex.FechaInicio != nil {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Desde: %s", *kardex.FechaInicio))
+	}
+	if kardex.FechaFin != nil {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Hasta: %s", *kardex.FechaFin))
+	}
+
+	r2 := row.New(10).Add(
+		text.NewCol(8, "KARDEX DE INVENTARIO", subTitleStyle),
+		text.NewCol(16, strings.Join(partesFiltro, " | "), props.Text{Align: align.Right, Size: 9}),
+	)
+	r3 := row.New(4)
+	r4 := row.New(8).Add(
+		text.NewCol(3, "FECHA", tableHeaderStyle),
+		text.NewCol(4, "DOCUMENTO", tableHeaderStyle),
+		text.NewCol(4, "UBICACIÓN", tableHeaderStyle),
+		text.NewCol(2, "ENTRADA", tableHeaderStyle),
+		text.NewCol(2, "SALIDA", tableHeaderStyle),
+		text.NewCol(2, "SALDO", tableHeaderStyle),
+		text.NewCol(2, "S. UBIC.", tableHeaderStyle),
+		text.NewCol(2, "COSTO U.", tableHeaderStyle),
+		text.NewCol(3, "USUARIO", tableHeaderStyle),
+	).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
+	r5 := row.New(1).Add(line.NewCol(gridSum, props.Line{Color: colorLine}))
+
+	if err := m.RegisterHeader(r1, r2, r3, r4, r5); err != nil {
+		return nil, err
+	}
+
+	// ==========================================
+	// 2. CUERPO
+	// ==========================================
+	m.AddRow(7,
+		text.NewCol(15, "Saldo inicial", props.Text{Style: fontstyle.Bold, Size: 8, Top: 1.5}),
+		text.NewCol(2, strconv.Itoa(kardex.SaldoInicial), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 8, Top: 1.5}),
+	)
+
+	for i, mov := range kardex.Movimientos {
+		entrada, salida := "", ""
+		if mov.Cantidad > 0 {
+			entrada = strconv.Itoa(mov.Cantidad)
+		} else {
+			salida = strconv.Itoa(-mov.Cantidad)
+		}
+		costo := "-"
+		if mov.CostoUnitario != nil {
+			costo = fmt.Sprintf("%.2f", *mov.CostoUnitario)
+		}
+		usuario := "-"
+		if mov.Usuario != nil {
+			usuario = mov.Usuario.Username
+		}
+
+		currentRowColor := colorZebraOdd
+		if i%2 == 0 {
+			currentRowColor = colorZebraEven
+		}
+		m.AddRow(6,
+			text.NewCol(3, mov.Fecha.Format("02/01/2006 15:04"), rowTextStyle),
+			text.NewCol(4, fmt.Sprintf("%s #%d", mov.TipoDocumento, mov.DocumentoId), rowTextStyle),
+			text.NewCol(4, mov.Ubicacion, rowTextStyle),
+			text.NewCol(2, entrada, rowNumberStyle),
+			text.NewCol(2, salida, rowNumberStyle),
+			text.NewCol(2, strconv.Itoa(mov.Saldo), rowNumberStyle),
+			text.NewCol(2, strconv.Itoa(mov.SaldoUbicacion), rowNumberStyle),
+			text.NewCol(2, costo, rowNumberStyle),
+			text.NewCol(3, usuario, rowTextStyle),
+		).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
+	}
+
+	// ==========================================
+	// 3. TOTALES
+	// ==========================================
+	totalStyle := props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 9, Top: 2}
+	m.AddRow(2, line.NewCol(gridSum, props.Line{Color: colorLine}))
+	m.AddRow(10,
+		text.NewCol(11, fmt.Sprintf("Movimientos: %d", len(kardex.Movimientos)), props.Text{Align: align.Left, Size: 9, Top: 2}),
+		text.NewCol(2, strconv.Itoa(kardex.Entradas), totalStyle),
+		text.NewCol(2, strconv.Itoa(kardex.Salidas), totalStyle),
+		text.NewCol(2, strconv.Itoa(kardex.SaldoFinal), totalStyle),
+		text.NewCol(7, "SALDO FINAL", props.Text{Align: align.Left, Style: fontstyle.Bold, Size: 9, Top: 2, Left: 3}),
+	)
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+func (r ReporteService) ReportePDFLotesPorVencer(ctx context.Context, filtros map[string]string) (core.Document, error) {
+	// 1. Obtener Datos (ordenados por fecha de vencimiento)
+	if filtros["diasVencimiento"] == "" {
+		filtros["diasVencimiento"] = "30"
+	}
+	lotes, err := r.inventarioRepository.ListarLotes(ctx, filtros)
+	if err != nil {
+		return nil, err
+	}
+
+	colorHeaderBg := &props.Color{Red: 230, Green: 230, Blue: 230}
+	colorZebraEven := &props.Color{Red: 250, Green: 250, Blue: 250}
+	colorZebraOdd := &props.Color{Red: 255, Green: 255, Blue: 255}
+	colorLine := &props.Color{Red: 100, Green: 100, Blue: 100}
+	colorVencido := &props.Color{Red: 200, Green: 0, Blue: 0}
+
+	// 2. Configurar PDF
+	gridSum := 24
+	pageNumber := props.PageNumber{
+		Pattern: "Página {current} de {total}",
+		Place:   props.RightBottom,
+		Family:  fontfamily.Arial,
+		Style:   fontstyle.Italic,
+		Size:    8,
+		Color:   &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.Letter).
+		WithOrientation(orientation.Horizontal).
+		WithTopMargin(10).
+		WithLeftMargin(15).
+		WithRightMargin(15).
+		WithBottomMargin(10).
+		WithMaxGridSize(gridSum).
+		WithPageNumber(pageNumber).
+		Build()
+
+	m := maroto.New(cfg)
+
+	titleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 14}
+	subTitleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 10, Color: colorLine}
+	tableHeaderStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Size: 9, Top: 1.5}
+	rowTextStyle := props.Text{Align: align.Left, Size: 8, Top: 1}
+	rowNumberStyle := props.Text{Align: align.Right, Size: 8, Top: 1}
+
+	now := time.Now().Format("02/01/2006 15:04")
+
+	// ==========================================
+	// 1. CABECERA
+	// ==========================================
+	r1 := row.New(12).Add(
+		text.NewCol(16, "ESCONDITE MULTIROOM", titleStyle),
+		text.NewCol(8, fmt.Sprintf("Impreso: %s", now), props.Text{Align: align.Right, Size: 8, Style: fontstyle.Italic}),
+	)
+
+	partesFiltro := []string{fmt.Sprintf("Vencen en %s días o menos", filtros["diasVencimiento"])}
+	if val := filtros["sucursalId"]; val != "" {
+		sucursalId, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		sucursal, err := r.sucursalRepository.ObtenerSucursalById(ctx, &sucursalId)
+		if err != nil {
+			return nil, err
+		}
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Sucursal: %s", sucursal.Nombre))
+	}
+
+	r2 := row.New(10).Add(
+		text.NewCol(8, "LOTES POR VENCER", subTitleStyle),
+		text.NewCol(16, strings.Join(partesFiltro, " | "), props.Text{Align: align.Right, Size: 9}),
+	)
+	r3 := row.New(4)
+	r4 := row.New(8).Add(
+		text.NewCol(6, "PRODUCTO", tableHeaderStyle),
+		text.NewCol(4, "UBICACIÓN", tableHeaderStyle),
+		text.NewCol(3, "LOTE", tableHeaderStyle),
+		text.NewCol(3, "VENCE", tableHeaderStyle),
+		text.NewCol(2, "DÍAS", tableHeaderStyle),
+		text.NewCol(2, "STOCK", tableHeaderStyle),
+		text.NewCol(2, "COSTO U.", tableHeaderStyle),
+		text.NewCol(2, "VALOR", tableHeaderStyle),
+	).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
+	r5 := row.New(1).Add(line.NewCol(gridSum, props.Line{Color: colorLine}))
+
+	if err := m.RegisterHeader(r1, r2, r3, r4, r5); err != nil {
+		return nil, err
+	}
+
+	// ==========================================
+	// 2. CUERPO
+	// ==========================================
+	var totalStock, vencidos int
+	var totalValor, valorVencido float64
+	for i, lote := range *lotes {
+		costo, valor := "-", "-"
+		if lote.CostoUnitario != nil {
+			costo = fmt.Sprintf("%.2f", *lote.CostoUnitario)
+		}
+		if lote.ValorStock != nil {
+			valor = fmt.Sprintf("%.2f", *lote.ValorStock)
+			totalValor += *lote.ValorStock
+			if lote.Vencido {
+				valorVencido += *lote.ValorStock
+			}
+		}
+		totalStock += lote.Stock
+
+		dias := strconv.Itoa(lote.DiasParaVencer)
+		diasStyle := rowNumberStyle
+		if lote.Vencido {
+			vencidos++
+			dias = "VENCIDO"
+			diasStyle = props.Text{Align: align.Right, Size: 8, Top: 1, Style: fontstyle.Bold, Color: colorVencido}
+		}
+
+		currentRowColor := colorZebraOdd
+		if i%2 == 0 {
+			currentRowColor = colorZebraEven
+		}
+		m.AddRow(6,
+			text.NewCol(6, lote.Producto.Nombre, rowTextStyle),
+			text.NewCol(4, lote.Ubicacion.Nombre, rowTextStyle),
+			text.NewCol(3, lote.Lote, rowTextStyle),
+			text.NewCol(3, lote.FechaVencimiento.Format("02/01/2006"), rowTextStyle),
+			text.NewCol(2, dias, diasStyle),
+			text.NewCol(2, strconv.Itoa(lote.Stock), rowNumberStyle),
+			text.NewCol(2, costo, rowNumberStyle),
+			text.NewCol(2, valor, rowNumberStyle),
+		).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
+	}
+
+	// ==========================================
+	// 3. TOTALES
+	// ==========================================
+	totalStyle := props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 9, Top: 2}
+	m.AddRow(2, line.NewCol(gridSum, props.Line{Color: colorLine}))
+	m.AddRow(10,
+		text.NewCol(10, fmt.Sprintf("Lotes: %d (vencidos: %d)", len(*lotes), vencidos), props.Text{Align: align.Left, Size: 9, Top: 2}),
+		text.NewCol(10, strconv.Itoa(totalStock), totalStyle),
+		text.NewCol(4, fmt.Sprintf("%.2f", totalValor), totalStyle),
+	)
+	m.AddRow(8,
+		text.NewCol(20, "VALOR VENCIDO", totalStyle),
+		text.NewCol(4, fmt.Sprintf("%.2f", valorVencido), props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 9, Top: 2, Color: colorVencido}),
+	)
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// ReportePDFAjustesPorTipo resume los ajustes del periodo por tipo, agrupados por categoría contable con subtotales
+func (r ReporteService) ReportePDFAjustesPorTipo(ctx context.Context, filtros map[string]string) (core.Document, error) {
+	// 1. Obtener Datos (ordenados por categoría contable y nombre del tipo)
+	if err := validarRangoFechas(filtros); err != nil {
+		return nil, err
+	}
+	resumen, err := r.inventarioRepository.ResumenAjustesPorTipo(ctx, filtros)
+	if err != nil {
+		return nil, err
+	}
+
+	colorHeaderBg := &props.Color{Red: 230, Green: 230, Blue: 230}
+	colorCategoriaBg := &props.Color{Red: 240, Green: 240, Blue: 240}
+	colorZebraEven := &props.Color{Red: 250, Green: 250, Blue: 250}
+	colorZebraOdd := &props.Color{Red: 255, Green: 255, Blue: 255}
+	colorLine := &props.Color{Red: 100, Green: 100, Blue: 100}
+
+	// 2. Configurar PDF
+	gridSum := 24
+	pageNumber := props.PageNumber{
+		Pattern: "Página {current} de {total}",
+		Place:   props.RightBottom,
+		Family:  fontfamily.Arial,
+		Style:   fontstyle.Italic,
+		Size:    8,
+		Color:   &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.Letter).
+		WithOrientation(orientation.Horizontal).
+		WithTopMargin(10).
+		WithLeftMargin(15).
+		WithRightMargin(15).
+		WithBottomMargin(10).
+		WithMaxGridSize(gridSum).
+		WithPageNumber(pageNumber).
+		Build()
+
+	m := maroto.New(cfg)
+
+	titleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 14}
+	subTitleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 10, Color: colorLine}
+	tableHeaderStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Size: 9, Top: 1.5}
+	rowTextStyle := props.Text{Align: align.Left, Size: 8, Top: 1}
+	rowNumberStyle := props.Text{Align: align.Right, Size: 8, Top: 1}
+	subtotalStyle := props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 8, Top: 1}
+
+	now := time.Now().Format("02/01/2006 15:04")
+
+	// ==========================================
+	// 1. CABECERA
+	// ==========================================
+	r1 := row.New(12).Add(
+		text.NewCol(16, "ESCONDITE MULTIROOM", titleStyle),
+		text.NewCol(8, fmt.Sprintf("Impreso: %s", now), props.Text{Align: align.Right, Size: 8, Style: fontstyle.Italic}),
+	)
+
+	var partesFiltro []string
+	if val := filtros["sucursalId"]; val != "" {
+		sucursalId, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, datatype.NewBadRequestError("El valor de sucursalId no es válido")
+		}
+		sucursal, err := r.sucursalRepository.ObtenerSucursalById(ctx, &sucursalId)
+		if err != nil {
+			return nil, err
+		}
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Sucursal: %s", sucursal.Nombre))
+	}
+	if val := filtros["fechaInicio"]; val != "" {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Desde: %s", val))
+	}
+	if val := filtros["fechaFin"]; val != "" {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Hasta: %s", val))
+	}
+
+	r2 := row.New(10).Add(
+		text.NewCol(8, "AJUSTES DE INVENTARIO POR TIPO", subTitleStyle),
+		text.NewCol(16, strings.Join(partesFiltro, " | "), props.Text{Align: align.Right, Size: 9}),
+	)
+	r3 := row.New(4)
+	r4 := row.New(8).Add(
+		text.NewCol(6, "TIPO", tableHeaderStyle),
+		text.NewCol(3, "DIRECCIÓN", tableHeaderStyle),
+		text.NewCol(2, "AJUSTES", tableHeaderStyle),
+		text.NewCol(3, "U. ENTRADA", tableHeaderStyle),
+		text.NewCol(3, "U. SALIDA", tableHeaderStyle),
+		text.NewCol(3, "VALOR ENTRADA", tableHeaderStyle),
+		text.NewCol(4, "VALOR SALIDA", tableHeaderStyle),
+	).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
+	r5 := row.New(1).Add(line.NewCol(gridSum, props.Line{Color: colorLine}))
+
+	if err := m.RegisterHeader(r1, r2, r3, r4, r5); err != nil {
+		return nil, err
+	}
+
+	// ==========================================
+	// 2. CUERPO (un bloque por categoría contable)
+	// ==========================================
+	type totalesAjuste struct {
+		ajustes, unidadesEntrada, unidadesSalida int
+		valorEntrada, valorSalida                float64
+	}
+	filaTotales := func(etiqueta string, t totalesAjuste, estilo props.Text) {
+		m.AddRow(7,
+			text.NewCol(11, etiqueta, estilo),
+			text.NewCol(3, strconv.Itoa(t.unidadesEntrada), estilo),
+			text.NewCol(3, strconv.Itoa(t.unidadesSalida), estilo),
+			text.NewCol(3, fmt.Sprintf("%.2f", t.valorEntrada), estilo),
+			text.NewCol(4, fmt.Sprintf("%.2f", t.valorSalida), estilo),
+		)
+	}
+
+	var total, subtotal totalesAjuste
+	var categoriaActual string
+	for i, item := range *resumen {
+		// Las categorías vacías se guardan como NULL, así que "" identifica a los tipos sin categoría
+		var categoria string
+		if item.CategoriaContable != nil {
+			categoria = *item.CategoriaContable
+		}
+		if i == 0 || categoria != categoriaActual {
+			if i > 0 {
+				filaTotales("Subtotal", subtotal, subtotalStyle)
+			}
+			categoriaActual = categoria
+			subtotal = totalesAjuste{}
+			nombreCategoria := "SIN CATEGORÍA CONTABLE"
+			if categoria != "" {
+				nombreCategoria = strings.ToUpper(categoria)
+			}
+			m.AddRow(7, text.NewCol(gridSum, nombreCategoria, props.Text{Style: fontstyle.Bold, Size: 9, Top: 1.5, Left: 2})).
+				WithStyle(&props.Cell{BackgroundColor: colorCategoriaBg})
+		}
+
+		for _, t := range []*totalesAjuste{&subtotal, &total} {
+			t.ajustes += item.CantidadAjustes
+			t.unidadesEntrada += item.UnidadesEntrada
+			t.unidadesSalida += item.UnidadesSalida
+			t.valorEntrada += item.ValorEntrada
+			t.valorSalida += item.ValorSalida
+		}
+
+		currentRowColor := colorZebraOdd
+		if i%2 == 0 {
+			currentRowColor = colorZebraEven
+		}
+		m.AddRow(6,
+			text.NewCol(6, fmt.Sprintf("%s (%s)", item.Nombre, item.Codigo), rowTextStyle),
+			text.NewCol(3, item.Direccion, props.Text{Align: align.Center, Size: 8, Top: 1}),
+			text.NewCol(2, strconv.Itoa(item.CantidadAjustes), rowNumberStyle),
+			text.NewCol(3, strconv.Itoa(item.UnidadesEntrada), rowNumberStyle),
+			text.NewCol(3, strconv.Itoa(item.UnidadesSalida), rowNumberStyle),
+			text.NewCol(3, fmt.Sprintf("%.2f", item.ValorEntrada), rowNumberStyle),
+			text.NewCol(4, fmt.Sprintf("%.2f", item.ValorSalida), rowNumberStyle),
+		).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
+	}
+	if len(*resumen) > 0 {
+		filaTotales("Subtotal", subtotal, subtotalStyle)
+	}
+
+	// ==========================================
+	// 3. TOTALES
+	// ==========================================
+	m.AddRow(2, line.NewCol(gridSum, props.Line{Color: colorLine}))
+	filaTotales(fmt.Sprintf("TOTAL (%d ajustes)", total.ajustes), total, props.Text{Align: align.Right, Style: fontstyle.Bold, Size: 9, Top: 2})
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// ReportePDFEtiquetasProductos arma una hoja carta de etiquetas de góndola, tres por fila, con nombre, código de
+// barras y precio de la sucursal. Un UPC-A se imprime como EAN-13 con un cero adelante.
+func (r ReporteService) ReportePDFEtiquetasProductos(ctx context.Context, filtros map[string]string) (core.Document, error) {
+	// 1. Obtener Datos
+	sucursalId, err := strconv.Atoi(filtros["sucursalId"])
+	if err != nil || sucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("El ID de la sucursal es obligatorio")
+	}
+	var productoIds []int
+	for _, val := range strings.Split(filtros["productoIds"], ",") {
+		if strings.TrimSpace(val) == "" {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil || id <= 0 {
+			return nil, datatype.NewBadRequestError("El valor de productoIds no es válido")
+		}
+		productoIds = append(productoIds, id)
+	}
+	if len(productoIds) == 0 {
+		return nil, datatype.NewBadRequestError("Debe indicar al menos un producto en productoIds")
+	}
+	copias := 1
+	if val := filtros["copias"]; val != "" {
+		copias, err = strconv.Atoi(val)
+		if err != nil || copias <= 0 || copias > 100 {
+			return nil, datatype.NewBadRequestError("El valor de copias debe estar entre 1 y 100")
+		}
+	}
+	etiquetas, err := r.productoRepository.ListarEtiquetasProductos(ctx, sucursalId, productoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Configurar PDF
+	columnas := 3
+	gridSum := 12
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.Letter).
+		WithTopMargin(10).
+		WithLeftMargin(10).
+		WithRightMargin(10).
+		WithBottomMargin(10).
+		WithMaxGridSize(gridSum).
+		Build()
+
+	m := maroto.New(cfg)
+
+	colorBorde := &props.Color{Red: 200, Green: 200, Blue: 200}
+	nombreStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Size: 8, Top: 1.5, Left: 1, Right: 1}
+	codigoStyle := props.Text{Align: align.Center, Size: 7, Top: 19}
+	precioStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Size: 11, Top: 22.5}
+
+	// ==========================================
+	// 3. ETIQUETAS
+	// ==========================================
+	var cols []core.Col
+	for _, etiqueta := range *etiquetas {
+		tipo, codigo := barcode.Code128, etiqueta.Codigo
+		switch etiqueta.Tipo {
+		case domain.CodigoBarrasEAN13:
+			tipo = barcode.EAN
+		case domain.CodigoBarrasUPC:
+			tipo, codigo = barcode.EAN, "0"+etiqueta.Codigo
+		}
+		for i := 0; i < copias; i++ {
+			cols = append(cols, col.New(gridSum/columnas).Add(
+				text.New(etiqueta.Nombre, nombreStyle),
+				code.NewBar(codigo, props.Barcode{Type: tipo, Percent: 80, Left: 6, Top: 7}),
+				text.New(etiqueta.Codigo, codigoStyle),
+				text.New(fmt.Sprintf("Bs %.2f", etiqueta.Precio), precioStyle),
+			).WithStyle(&props.Cell{BorderType: border.Full, BorderColor: colorBorde, BorderThickness: 0.2}))
+			if len(cols) == columnas {
+				m.AddRow(30, cols...)
+				cols = nil
+			}
+		}
+	}
+	if len(cols) > 0 {
+		m.AddRow(30, cols...)
+	}
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+func (r ReporteService) ReportePDFMargenes(ctx context.Context, filtros map[string]string) (core.Document, error) {
+	// 1. Obtener Datos (ordenados por sucursal, categoría y producto)
+	margenes, err := r.ventaRepository.ListarMargenesProductos(ctx, filtros)
+	if err != nil {
+		return nil, err
+	}
+
+	colorHeaderBg := &props.Color{Red: 230, Green: 230, Blue: 230}
+	colorGroupBg := &props.Color{Red: 240, Green: 240, Blue: 240}
+	colorZebraEven := &props.Color{Red: 250, Green: 250, Blue: 250}
+	colorZebraOdd := &props.Color{Red: 255, Green: 255, Blue: 255}
+	colorLine := &props.Color{Red: 100, Green: 100, Blue: 100}
+
+	// 2. Configurar PDF
+	gridSum := 24
+	pageNumber := props.PageNumber{
+		Pattern: "Página {current} de {total}",
+		Place:   props.RightBottom,
+		Family:  fontfamily.Arial,
+		Style:   fontstyle.Italic,
+		Size:    8,
+		Color:   &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.Letter).
+		WithOrientation(orientation.Horizontal).
+		WithTopMargin(10).
+		WithLeftMargin(15).
+		WithRightMargin(15).
+		WithBottomMargin(10).
+		WithMaxGridSize(gridSum).
+		WithPageNumber(pageNumber).
+		Build()
+
+	m := maroto.New(cfg)
+
+	titleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 14}
+	subTitleStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 10, Color: colorLine}
+	tableHeaderStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Size: 9, Top: 1.5}
+	rowTextStyle := props.Text{Align: align.Left, Size: 8, Top: 1}
+	rowNumberStyle := props.Text{Align: align.Right, Size: 8, Top: 1}
+	groupStyle := props.Text{Style: fontstyle.Bold, Align: align.Left, Size: 9, Top: 1.5}
+	subtotalTextStyle := props.Text{Style: fontstyle.Bold, Align: align.Right, Size: 8, Top: 1}
+
+	now := time.Now().Format("02/01/2006 15:04")
+
+	// ==========================================
+	// 1. CABECERA
+	// ==========================================
+	r1 := row.New(12).Add(
+		text.NewCol(16, "ESCONDITE MULTIROOM", titleStyle),
+		text.NewCol(8, fmt.Sprintf("Impreso: %s", now), props.Text{Align: align.Right, Size: 8, Style: fontstyle.Italic}),
+	)
+
+	var partesFiltro []string
+	if val := filtros["sucursalId"]; val != "" {
+		sucursalId, _ := strconv.Atoi(val)
+		sucursal, _ := r.sucursalRepository.ObtenerSucursalById(ctx, &sucursalId)
+		if sucursal != nil {
+			partesFiltro = append(partesFiltro, fmt.Sprintf("Sucursal: %s", sucursal.Nombre))
+		} else {
+			partesFiltro = append(partesFiltro, fmt.Sprintf("Sucursal ID: %s", val))
+		}
+	} else {
+		partesFiltro = append(partesFiltro, "Sucursal: TODAS")
+	}
+	if val := filtros["categoriaId"]; val != "" {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Cat ID: %s", val))
+	}
+	if val := filtros["fechaInicio"]; val != "" {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Desde: %s", val))
+	}
+	if val := filtros["fechaFin"]; val != "" {
+		partesFiltro = append(partesFiltro, fmt.Sprintf("Hasta: %s", val))
+	}
+
+	r2 := row.New(10).Add(
+		text.NewCol(8, "MARGEN BRUTO POR PRODUCTO", subTitleStyle),
+		text.NewCol(16, strings.Join(partesFiltro, " | "), props.Text{Align: align.Right, Size: 9}),
+	)
+	r3 := row.New(4)
+	r4 := row.New(8).Add(
+		text.NewCol(8, "PRODUCTO", tableHeaderStyle),
+		text.NewCol(2, "CANT.", tableHeaderStyle),
+		text.NewCol(3, "VENTA NETA", tableHeaderStyle),
+		text.NewCol(3, "COSTO", tableHeaderStyle),
+		text.NewCol(3, "MARGEN", tableHeaderStyle),
+		text.NewCol(2, "MARGEN %", tableHeaderStyle),
+		text.NewCol(3, "U. SIN COSTO", tableHeaderStyle),
+	).WithStyle(&props.Cell{BackgroundColor: colorHeaderBg})
+	r5 := row.New(1).Add(line.NewCol(gridSum, props.Line{Color: colorLine}))
+
+	if err := m.RegisterHeader(r1, r2, r3, r4, r5); err != nil {
+		return nil, err
+	}
+
+	// ==========================================
+	// 2. CUERPO
+	// ==========================================
+	type acumulado struct {
+		Cantidad, SinCosto int
+		Ventas, Costo      float64
+	}
+	sumar := func(a *acumulado, item domain.MargenProducto) {
+		a.Cantidad += item.CantidadVentas
+		a.SinCosto += item.UnidadesSinCosto
+		a.Ventas += item.VentasNetas
+		a.Costo += item.CostoVentas
+	}
+	filaTotal := func(etiqueta string, a acumulado) {
+		porcentaje := 0.0
+		if a.Ventas != 0 {
+			porcentaje = (a.Ventas - a.Costo) / a.Ventas * 100
+		}
+		m.AddRow(7,
+			text.NewCol(8, etiqueta, subtotalTextStyle),
+			text.NewCol(2, strconv.Itoa(a.Cantidad), subtotalTextStyle),
+			text.NewCol(3, fmt.Sprintf("%.2f", a.Ventas), subtotalTextStyle),
+			text.NewCol(3, fmt.Sprintf("%.2f", a.Costo), subtotalTextStyle),
+			text.NewCol(3, fmt.Sprintf("%.2f", a.Ventas-a.Costo), subtotalTextStyle),
+			text.NewCol(2, fmt.Sprintf("%.2f%%", porcentaje), subtotalTextStyle),
+			text.NewCol(3, strconv.Itoa(a.SinCosto), subtotalTextStyle),
+		)
+	}
+	nombreCategoria := func(item domain.MargenProducto) string {
+		if item.Categoria == nil {
+			return "Sin categoría"
+		}
+		return *item.Categoria
+	}
+
+	var total, totalSucursal, totalCategoria acumulado
+	list := *margenes
+	for i, item := range list {
+		nuevaSucursal := i == 0 || list[i-1].SucursalId != item.SucursalId
+		if nuevaSucursal {
+			m.AddRow(8, text.NewCol(gridSum, strings.ToUpper(item.Sucursal), groupStyle)).
+				WithStyle(&props.Cell{BackgroundColor: colorGroupBg})
+		}
+		if nuevaSucursal || nombreCategoria(list[i-1]) != nombreCategoria(item) {
+			m.AddRow(7, text.NewCol(gridSum, nombreCategoria(item), props.Text{Style: fontstyle.BoldItalic, Size: 8, Top: 1.5, Left: 3}))
+		}
+
+		currentRowColor := colorZebraOdd
+		if i%2 == 0 {
+			currentRowColor = colorZebraEven
+		}
+		m.AddRow(6,
+			text.NewCol(8, item.Producto, props.Text{Align: align.Left, Size: 8, Top: 1, Left: 6}),
+			text.NewCol(2, strconv.Itoa(item.CantidadVentas), rowNumberStyle),
+			text.NewCol(3, fmt.Sprintf("%.2f", item.VentasNetas), rowNumberStyle),
+			text.NewCol(3, fmt.Sprintf("%.2f", item.CostoVentas), rowNumberStyle),
+			text.NewCol(3, fmt.Sprintf("%.2f", item.MargenBruto), rowNumberStyle),
+			text.NewCol(2, fmt.Sprintf("%.2f%%", item.MargenPorcentaje), rowNumberStyle),
+			text.NewCol(3, strconv.Itoa(item.UnidadesSinCosto), rowNumberStyle),
+		).WithStyle(&props.Cell{BackgroundColor: currentRowColor})
+
+		sumar(&total, item)
+		sumar(&totalSucursal, item)
+		sumar(&totalCategoria, item)
+
+		ultimaDeSucursal := i == len(list)-1 || list[i+1].SucursalId != item.SucursalId
+		if ultimaDeSucursal || nombreCategoria(list[i+1]) != nombreCategoria(item) {
+			filaTotal(fmt.Sprintf("Subtotal %s", nombreCategoria(item)), totalCategoria)
+			totalCategoria = acumulado{}
+		}
+		if ultimaDeSucursal {
+			filaTotal(fmt.Sprintf("Total %s", item.Sucursal), totalSucursal)
+			totalSucursal = acumulado{}
+		}
+	}
+	if len(list) == 0 {
+		m.AddRow(8, text.NewCol(gridSum, "No hay ventas cobradas en el periodo.", rowTextStyle))
+	}
+
+	// ==========================================
+	// 3. TOTALES
+	// ==========================================
+	m.AddRow(2, line.NewCol(gridSum, props.Line{Color: colorLine}))
+	filaTotal("TOTAL GENERAL", total)
+	if total.SinCosto > 0 {
+		m.AddRow(6, text.NewCol(gridSum, "Las unidades sin costo se vendieron antes de registrar un costo del producto y suman costo cero.",
+			props.Text{Align: align.Left, Size: 7, Style: fontstyle.Italic, Top: 1}))
+	}
 
 	document, err := m.Generate()
 	if err != nil {
@@ -610,8 +1930,8 @@ func (r ReporteService) ComprobantePDFVentaById(ctx context.Context, ventaId *in
 	return document, nil
 }
 
-func NewReporteService(ventaRepository port.VentaRepository, sucursalRepository port.SucursalRepository, productoRepository port.ProductoRepository) *ReporteService {
-	return &ReporteService{ventaRepository: ventaRepository, sucursalRepository: sucursalRepository, productoRepository: productoRepository}
+func NewReporteService(ventaRepository port.VentaRepository, sucursalRepository port.SucursalRepository, productoRepository port.ProductoRepository, promocionRepository port.PromocionRepository, cuentaClienteRepository port.CuentaClienteRepository, plantillaComprobanteRepository port.PlantillaComprobanteRepository, autorizacionRepository port.AutorizacionRepository, inventarioRepository port.InventarioRepository) *ReporteService {
+	return &ReporteService{ventaRepository: ventaRepository, sucursalRepository: sucursalRepository, productoRepository: productoRepository, promocionRepository: promocionRepository, cuentaClienteRepository: cuentaClienteRepository, plantillaComprobanteRepository: plantillaComprobanteRepository, autorizacionRepository: autorizacionRepository, inventarioRepository: inventarioRepository}
 }
 
 var _ port.ReporteService = (*ReporteService)(nil)
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/port"
+	"strings"
+)
+
+type TarjetaRegaloService struct {
+	tarjetaRegaloRepository port.TarjetaRegaloRepository
+}
+
+func (t TarjetaRegaloService) ListarTarjetasRegalo(ctx context.Context, filtros map[string]string) (*[]domain.TarjetaRegalo, error) {
+	return t.tarjetaRegaloRepository.ListarTarjetasRegalo(ctx, filtros)
+}
+
+func (t TarjetaRegaloService) ObtenerTarjetaRegaloByCodigo(ctx context.Context, codigo *string) (*domain.TarjetaRegalo, error) {
+	normalizado := strings.ToUpper(strings.TrimSpace(*codigo))
+	return t.tarjetaRegaloRepository.ObtenerTarjetaRegaloByCodigo(ctx, &normalizado)
+}
+
+func NewTarjetaRegaloService(tarjetaRegaloRepository port.TarjetaRegaloRepository) *TarjetaRegaloService {
+	return &TarjetaRegaloService{tarjetaRegaloRepository: tarjetaRegaloRepository}
+}
+
+var _ port.TarjetaRegaloService = (*TarjetaRegaloService)(nil)
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"regexp"
+	"strings"
+)
+
+var codigoTipoAjusteRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,39}$`)
+
+type TipoAjusteService struct {
+	tipoAjusteRepository port.TipoAjusteRepository
+}
+
+func (t TipoAjusteService) RegistrarTipoAjuste(ctx context.Context, request *domain.TipoAjusteRequest) (*int, error) {
+	request.Codigo = strings.ToUpper(strings.TrimSpace(request.Codigo))
+	if !codigoTipoAjusteRegexp.MatchString(request.Codigo) {
+		return nil, datatype.NewBadRequestError("El código debe tener entre 2 y 40 caracteres: letras, números y guion bajo, empezando por una letra.")
+	}
+	if err := validarTipoAjuste(request); err != nil {
+		return nil, err
+	}
+	return t.tipoAjusteRepository.RegistrarTipoAjuste(ctx, request)
+}
+
+func (t TipoAjusteService) ModificarTipoAjusteById(ctx context.Context, id *int, request *domain.TipoAjusteRequest) error {
+	if err := validarTipoAjuste(request); err != nil {
+		return err
+	}
+	return t.tipoAjusteRepository.ModificarTipoAjusteById(ctx, id, request)
+}
+
+func (t TipoAjusteService) ListarTiposAjuste(ctx context.Context, filtros map[string]string) (*[]domain.TipoAjuste, error) {
+	return t.tipoAjusteRepository.ListarTiposAjuste(ctx, filtros)
+}
+
+func (t TipoAjusteService) ObtenerTipoAjusteById(ctx context.Context, id *int) (*domain.TipoAjuste, error) {
+	return t.tipoAjusteRepository.ObtenerTipoAjusteById(ctx, id)
+}
+
+func (t TipoAjusteService) HabilitarTipoAjuste(ctx context.Context, id *int) error {
+	return t.tipoAjusteRepository.HabilitarTipoAjuste(ctx, id)
+}
+
+func (t TipoAjusteService) DeshabilitarTipoAjuste(ctx context.Context, id *int) error {
+	return t.tipoAjusteRepository.DeshabilitarTipoAjuste(ctx, id)
+}
+
+func validarTipoAjuste(request *domain.TipoAjusteRequest) error {
+	request.Nombre = strings.TrimSpace(request.Nombre)
+	if request.Nombre == "" {
+		return datatype.NewBadRequestError("Debe indicar el nombre del tipo de ajuste.")
+	}
+	request.Direccion = strings.ToUpper(strings.TrimSpace(request.Direccion))
+	switch request.Direccion {
+	case domain.DireccionAjusteEntrada, domain.DireccionAjusteSalida, domain.DireccionAjusteMixto:
+	default:
+		return datatype.NewBadRequestError("La dirección debe ser ENTRADA, SALIDA o MIXTO.")
+	}
+	if request.CategoriaContable != nil {
+		categoria := strings.TrimSpace(*request.CategoriaContable)
+		if categoria == "" {
+			request.CategoriaContable = nil
+		} else {
+			request.CategoriaContable = &categoria
+		}
+	}
+	return nil
+}
+
+func NewTipoAjusteService(tipoAjusteRepository port.TipoAjusteRepository) *TipoAjusteService {
+	return &TipoAjusteService{tipoAjusteRepository: tipoAjusteRepository}
+}
+
+var _ port.TipoAjusteService = (*TipoAjusteService)(nil)
@@ -2,40 +2,299 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
 	"multiroom/sucursal-service/internal/core/port"
+	"regexp"
+	"time"
 )
 
+// maxOperacionesOffline limita el tamaño de un lote de sincronización
+const maxOperacionesOffline = 500
+
+// maxAntiguedadOffline es lo más atrás que puede fecharse una operación sin conexión; toleranciaRelojOffline admite
+// un pequeño adelanto del reloj del POS respecto al servidor.
+const (
+	maxAntiguedadOffline   = 72 * time.Hour
+	toleranciaRelojOffline = 2 * time.Minute
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 type VentaService struct {
-	ventaService port.VentaService
+	ventaRepository  port.VentaRepository
+	proveedorFiscal  port.ProveedorFiscal
+	impresoraService port.ImpresoraService
 }
 
 func (v VentaService) ListarProductosVentas(ctx context.Context, filtros map[string]string) (*[]domain.ProductoVentaStat, error) {
-	return v.ventaService.ListarProductosVentas(ctx, filtros)
+	return v.ventaRepository.ListarProductosVentas(ctx, filtros)
+}
+
+func (v VentaService) ListarConsumoComponentes(ctx context.Context, filtros map[string]string) (*[]domain.ComponenteConsumoStat, error) {
+	return v.ventaRepository.ListarConsumoComponentes(ctx, filtros)
+}
+
+func (v VentaService) ListarMargenesProductos(ctx context.Context, filtros map[string]string) (*[]domain.MargenProducto, error) {
+	return v.ventaRepository.ListarMargenesProductos(ctx, filtros)
 }
 
 func (v VentaService) RegistrarVenta(ctx context.Context, request *domain.VentaRequest) (*int, error) {
-	return v.ventaService.RegistrarVenta(ctx, request)
+	return v.ventaRepository.RegistrarVenta(ctx, request)
 }
 
-func (v VentaService) AnularVentaById(ctx context.Context, id *int) error {
-	return v.ventaService.AnularVentaById(ctx, id)
+func (v VentaService) AnularVentaById(ctx context.Context, id *int, request *domain.AnularVentaRequest) error {
+	if err := v.ventaRepository.AnularVentaById(ctx, id, request); err != nil {
+		return err
+	}
+	v.anularDocumentoFiscal(ctx, id)
+	return nil
 }
 
 func (v VentaService) RegistrarPagoVenta(ctx context.Context, ventaId *int, request *domain.RegistrarPagosRequest) (*[]int, error) {
-	return v.ventaService.RegistrarPagoVenta(ctx, ventaId, request)
+	ids, err := v.ventaRepository.RegistrarPagoVenta(ctx, ventaId, request)
+	if err != nil {
+		return nil, err
+	}
+	v.emitirDocumentoFiscal(ctx, ventaId)
+	// La impresión no debe revertir un cobro ya registrado
+	if err = v.impresoraService.ImprimirVentaAlCobrar(ctx, ventaId); err != nil {
+		log.Println("Error al encolar ticket de la venta:", err)
+	}
+	return ids, nil
 }
 
 func (v VentaService) ObtenerVenta(ctx context.Context, id *int) (*domain.Venta, error) {
-	return v.ventaService.ObtenerVenta(ctx, id)
+	return v.ventaRepository.ObtenerVenta(ctx, id)
 }
 
 func (v VentaService) ListarVentas(ctx context.Context, filtros map[string]string) (*[]domain.VentaInfo, error) {
-	return v.ventaService.ListarVentas(ctx, filtros)
+	return v.ventaRepository.ListarVentas(ctx, filtros)
+}
+
+func (v VentaService) DividirVenta(ctx context.Context, ventaId *int, request *domain.DividirVentaRequest) (*[]int, error) {
+	if len(request.Partes) < 2 {
+		return nil, datatype.NewBadRequestError("La venta se debe dividir en al menos dos partes.")
+	}
+	for i, parte := range request.Partes {
+		if parte.CostoTiempo < 0 {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("El costo de tiempo de la parte %d no puede ser negativo.", i+1))
+		}
+		if len(parte.Detalles) == 0 && parte.CostoTiempo == 0 {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("La parte %d no tiene productos ni tiempo de sala asignado.", i+1))
+		}
+		for _, detalle := range parte.Detalles {
+			if detalle.Cantidad <= 0 {
+				return nil, datatype.NewBadRequestError(fmt.Sprintf("La cantidad del detalle %d en la parte %d debe ser mayor a cero.", detalle.DetalleVentaId, i+1))
+			}
+		}
+	}
+	return v.ventaRepository.DividirVenta(ctx, ventaId, request)
+}
+
+// SincronizarVentas aplica en orden las operaciones que un POS registró sin conexión. Cada una va en su propia
+// transacción, así que un rechazo no detiene el lote; el POS reenvía las rechazadas o las descarta según el mensaje.
+func (v VentaService) SincronizarVentas(ctx context.Context, request *domain.SincronizacionRequest) (*[]domain.ResultadoOperacionOffline, error) {
+	if request.SucursalId <= 0 {
+		return nil, datatype.NewBadRequestError("El ID de la sucursal es obligatorio.")
+	}
+	if len(request.Operaciones) == 0 {
+		return nil, datatype.NewBadRequestError("El lote no tiene operaciones.")
+	}
+	if len(request.Operaciones) > maxOperacionesOffline {
+		return nil, datatype.NewBadRequestError(fmt.Sprintf("El lote no puede superar las %d operaciones.", maxOperacionesOffline))
+	}
+
+	resultados := make([]domain.ResultadoOperacionOffline, 0, len(request.Operaciones))
+	for i := range request.Operaciones {
+		operacion := &request.Operaciones[i]
+		resultado, err := v.aplicarOperacionOffline(ctx, request.SucursalId, operacion)
+		if err != nil {
+			mensaje := "Error interno al aplicar la operación."
+			var errorResponse *datatype.ErrorResponse
+			if errors.As(err, &errorResponse) {
+				mensaje = errorResponse.Message
+			} else {
+				log.Println("Error al aplicar operación offline:", err)
+			}
+			resultado = &domain.ResultadoOperacionOffline{Uuid: operacion.Uuid, Tipo: operacion.Tipo, Estado: domain.SincronizacionRechazada, Mensaje: &mensaje}
+		}
+		resultados = append(resultados, *resultado)
+	}
+	return &resultados, nil
+}
+
+func (v VentaService) aplicarOperacionOffline(ctx context.Context, sucursalId int, operacion *domain.OperacionOffline) (*domain.ResultadoOperacionOffline, error) {
+	if !uuidRegexp.MatchString(operacion.Uuid) {
+		return nil, datatype.NewBadRequestError("El uuid de la operación no es válido.")
+	}
+	// La hora del POS decide promociones y horarios: se acota para que no se pueda fechar una venta a conveniencia
+	if operacion.CreadoEn != nil {
+		ahora := time.Now()
+		if operacion.CreadoEn.After(ahora.Add(toleranciaRelojOffline)) {
+			return nil, datatype.NewBadRequestError("La fecha de la operación (creadoEn) no puede ser futura.")
+		}
+		if operacion.CreadoEn.Before(ahora.Add(-maxAntiguedadOffline)) {
+			return nil, datatype.NewBadRequestError(fmt.Sprintf("La operación tiene más de %.0f horas; regístrela manualmente.", maxAntiguedadOffline.Hours()))
+		}
+	}
+	switch operacion.Tipo {
+	case domain.OperacionOfflineVenta:
+		if operacion.Venta == nil {
+			return nil, datatype.NewBadRequestError("La operación VENTA debe incluir la venta.")
+		}
+		if operacion.Venta.SucursalId != 0 && operacion.Venta.SucursalId != sucursalId {
+			return nil, datatype.NewBadRequestError("La venta pertenece a otra sucursal.")
+		}
+		operacion.Venta.SucursalId = sucursalId
+	case domain.OperacionOfflinePago:
+		if operacion.Pago == nil {
+			return nil, datatype.NewBadRequestError("La operación PAGO debe incluir los pagos.")
+		}
+		if (operacion.VentaUuid == nil) == (operacion.VentaId == nil) {
+			return nil, datatype.NewBadRequestError("El pago debe indicar ventaUuid o ventaId.")
+		}
+	default:
+		return nil, datatype.NewBadRequestError("El tipo de operación debe ser VENTA o PAGO.")
+	}
+
+	resultado, err := v.ventaRepository.AplicarOperacionOffline(ctx, sucursalId, operacion)
+	if err != nil {
+		return nil, err
+	}
+	if resultado.Tipo == domain.OperacionOfflinePago && resultado.Estado != domain.SincronizacionDuplicada {
+		v.emitirDocumentoFiscal(ctx, resultado.VentaId)
+		// Igual que en el cobro en línea, la impresión no revierte el pago sincronizado
+		if err = v.impresoraService.ImprimirVentaAlCobrar(ctx, resultado.VentaId); err != nil {
+			log.Println("Error al encolar ticket de la venta:", err)
+		}
+	}
+	return resultado, nil
+}
+
+// maxDiasEstadisticas limita el rango del tablero para que la serie diaria no crezca sin control
+const maxDiasEstadisticas = 366
+
+func (v VentaService) ObtenerEstadisticasVentas(ctx context.Context, filtros map[string]string) (*domain.EstadisticasVentas, error) {
+	inicio, err := time.Parse("2006-01-02", filtros["fechaInicio"])
+	if err != nil {
+		return nil, datatype.NewBadRequestError("fechaInicio es obligatoria con formato YYYY-MM-DD.")
+	}
+	fin, err := time.Parse("2006-01-02", filtros["fechaFin"])
+	if err != nil {
+		return nil, datatype.NewBadRequestError("fechaFin es obligatoria con formato YYYY-MM-DD.")
+	}
+	if fin.Before(inicio) {
+		return nil, datatype.NewBadRequestError("fechaFin no puede ser anterior a fechaInicio.")
+	}
+	if fin.Sub(inicio) > maxDiasEstadisticas*24*time.Hour {
+		return nil, datatype.NewBadRequestError(fmt.Sprintf("El rango no puede superar %d días.", maxDiasEstadisticas))
+	}
+	return v.ventaRepository.ObtenerEstadisticasVentas(ctx, filtros)
+}
+
+func (v VentaService) ListarOperacionesOffline(ctx context.Context, filtros map[string]string) (*[]domain.OperacionOfflineInfo, error) {
+	return v.ventaRepository.ListarOperacionesOffline(ctx, filtros)
+}
+
+// emitirDocumentoFiscal envía la venta pagada al proveedor fiscal. Un fallo del proveedor no revierte el
+// cobro: queda registrado con estado ERROR para reintentarlo o emitirlo manualmente.
+func (v VentaService) emitirDocumentoFiscal(ctx context.Context, ventaId *int) {
+	venta, err := v.ventaRepository.ObtenerVenta(ctx, ventaId)
+	if err != nil {
+		log.Println("Error al obtener venta para facturar:", err)
+		return
+	}
+	if venta.Estado != "Completado" {
+		return
+	}
+	documento, err := v.proveedorFiscal.EmitirFactura(ctx, facturaFiscalDeVenta(venta))
+	if err != nil {
+		log.Println("Error al emitir documento fiscal:", err)
+		mensaje := err.Error()
+		documento = &domain.DocumentoFiscal{
+			Proveedor: v.proveedorFiscal.Nombre(),
+			Estado:    domain.DocumentoFiscalError,
+			Mensaje:   &mensaje,
+		}
+	}
+	if err = v.ventaRepository.GuardarDocumentoFiscal(ctx, ventaId, documento); err != nil {
+		log.Println("Error al guardar documento fiscal:", err)
+	}
+}
+
+func (v VentaService) anularDocumentoFiscal(ctx context.Context, ventaId *int) {
+	documento, err := v.ventaRepository.ObtenerDocumentoFiscal(ctx, ventaId)
+	if err != nil || documento == nil || documento.Estado != domain.DocumentoFiscalEmitido {
+		return
+	}
+	if err = v.proveedorFiscal.AnularFactura(ctx, documento, "Venta anulada"); err != nil {
+		log.Println("Error al anular documento fiscal:", err)
+		mensaje := err.Error()
+		documento.Mensaje = &mensaje
+	} else {
+		documento.Estado = domain.DocumentoFiscalAnulado
+	}
+	if err = v.ventaRepository.GuardarDocumentoFiscal(ctx, ventaId, documento); err != nil {
+		log.Println("Error al guardar documento fiscal:", err)
+	}
+}
+
+// facturaFiscalDeVenta arma la factura con las líneas de la venta y el tiempo de sala como una línea más.
+// Sin NIT se factura a consumidor final.
+func facturaFiscalDeVenta(venta *domain.Venta) *domain.FacturaFiscal {
+	factura := &domain.FacturaFiscal{
+		VentaId:       venta.Id,
+		CodigoVenta:   venta.CodigoVenta,
+		Nit:           "0",
+		RazonSocial:   "S/N",
+		Fecha:         venta.CreadoEn,
+		BaseImponible: venta.BaseImponible,
+		Impuesto:      venta.Impuesto,
+		Total:         venta.Total,
+	}
+	if venta.Sucursal != nil {
+		factura.SucursalId = venta.Sucursal.Id
+	}
+	if venta.Nit != nil && *venta.Nit != "" {
+		factura.Nit = *venta.Nit
+	}
+	if venta.RazonSocial != nil && *venta.RazonSocial != "" {
+		factura.RazonSocial = *venta.RazonSocial
+	}
+	for _, d := range venta.Detalles {
+		factura.Lineas = append(factura.Lineas, domain.LineaFactura{
+			Descripcion:    d.Producto.Nombre,
+			Cantidad:       d.Cantidad,
+			PrecioUnitario: d.PrecioVenta,
+			Descuento:      d.Descuento,
+			TasaImpuesto:   d.TasaImpuesto,
+			BaseImponible:  d.BaseImponible,
+			Impuesto:       d.Impuesto,
+		})
+	}
+	if venta.CostoTiempoVenta > 0 {
+		neto := venta.CostoTiempoVenta - venta.DescuentoTiempo
+		impuesto := math.Round(neto*venta.TasaImpuestoTiempo/(100+venta.TasaImpuestoTiempo)*100) / 100
+		factura.Lineas = append(factura.Lineas, domain.LineaFactura{
+			Descripcion:    "Tiempo de sala",
+			Cantidad:       1,
+			PrecioUnitario: venta.CostoTiempoVenta,
+			Descuento:      venta.DescuentoTiempo,
+			TasaImpuesto:   venta.TasaImpuestoTiempo,
+			BaseImponible:  neto - impuesto,
+			Impuesto:       impuesto,
+		})
+	}
+	return factura
 }
 
-func NewVentaService(ventaService port.VentaService) *VentaService {
-	return &VentaService{ventaService: ventaService}
+func NewVentaService(ventaRepository port.VentaRepository, proveedorFiscal port.ProveedorFiscal, impresoraService port.ImpresoraService) *VentaService {
+	return &VentaService{ventaRepository: ventaRepository, proveedorFiscal: proveedorFiscal, impresoraService: impresoraService}
 }
 
 var _ port.VentaService = (*VentaService)(nil)
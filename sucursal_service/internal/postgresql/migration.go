@@ -19,6 +19,25 @@ func (db DB) Migration() error {
 		return fmt.Errorf("error al ejecutar la migración SQL: %w", err)
 	}
 
+	// Tipos de ajuste que usan la carga inicial, los conteos, las transferencias y los lotes; los demás son los que
+	// existían antes del catálogo. No se pisan si ya fueron configurados.
+	_, err = db.Pool.Exec(context.Background(), queryTiposAjusteIniciales)
+	if err != nil {
+		return fmt.Errorf("error al registrar los tipos de ajuste iniciales: %w", err)
+	}
+
 	logger.Info("✅ Migración ejecutada correctamente.")
 	return nil
 }
+
+const queryTiposAjusteIniciales = `
+INSERT INTO tipo_ajuste (codigo, nombre, direccion, requiere_motivo, requiere_aprobacion, es_sistema)
+VALUES ('CARGA_INICIAL', 'Carga inicial', 'ENTRADA', false, false, true),
+       ('ERROR_CONTEO', 'Error de conteo', 'MIXTO', false, false, true),
+       ('MERMA', 'Merma', 'SALIDA', false, false, true),
+       ('VENCIMIENTO', 'Vencimiento', 'SALIDA', false, false, true),
+       ('CONSUMO_INTERNO', 'Consumo interno', 'SALIDA', false, false, false),
+       ('ROBO_HURTO', 'Robo o hurto', 'SALIDA', true, false, false),
+       ('DEVOLUCION_PROVEEDOR', 'Devolución a proveedor', 'SALIDA', false, false, false),
+       ('REINGRESO_SIN_COMPRA', 'Reingreso sin compra', 'ENTRADA', false, false, false)
+ON CONFLICT (codigo) DO NOTHING`
@@ -0,0 +1,27 @@
+package routine
+
+import (
+	"context"
+	"log"
+	"multiroom/sucursal-service/internal/core/port"
+	"time"
+)
+
+// AlertasStockPublicar envía periódicamente a RabbitMQ las alertas de stock registradas por ventas, ajustes y
+// transferencias
+func AlertasStockPublicar(ctx context.Context, inventarioService port.InventarioService) {
+	tickerAlertas := time.NewTicker(5 * time.Second)
+	defer tickerAlertas.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("AlertasStockPublicar detenido por cancelación del contexto")
+			return
+		case <-tickerAlertas.C:
+			if _, err := inventarioService.PublicarAlertasStock(ctx); err != nil {
+				log.Println("Error al publicar alertas de stock:", err)
+			}
+		}
+	}
+}
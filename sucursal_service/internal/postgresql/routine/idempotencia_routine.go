@@ -0,0 +1,31 @@
+package routine
+
+import (
+	"context"
+	"log"
+	"multiroom/sucursal-service/internal/core/port"
+	"time"
+)
+
+// IdempotenciaLimpiar elimina periódicamente las claves de idempotencia fuera de la ventana de retención
+func IdempotenciaLimpiar(ctx context.Context, idempotenciaService port.IdempotenciaService) {
+	tickerIdempotencia := time.NewTicker(1 * time.Hour)
+	defer tickerIdempotencia.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("IdempotenciaLimpiar detenido por cancelación del contexto")
+			return
+		case <-tickerIdempotencia.C:
+			eliminados, err := idempotenciaService.EliminarIdempotenciasExpiradas(ctx)
+			if err != nil {
+				log.Println("Error al eliminar claves de idempotencia expiradas:", err)
+				continue
+			}
+			if eliminados > 0 {
+				log.Printf("Claves de idempotencia eliminadas: %d\n", eliminados)
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package routine
+
+import (
+	"context"
+	"log"
+	"multiroom/sucursal-service/internal/core/port"
+	"time"
+)
+
+// TrabajosImpresionProcesar envía periódicamente los tickets pendientes a las impresoras térmicas
+func TrabajosImpresionProcesar(ctx context.Context, impresoraService port.ImpresoraService) {
+	tickerImpresion := time.NewTicker(3 * time.Second)
+	defer tickerImpresion.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("TrabajosImpresionProcesar detenido por cancelación del contexto")
+			return
+		case <-tickerImpresion.C:
+			if _, err := impresoraService.ProcesarTrabajosImpresion(ctx); err != nil {
+				log.Println("Error al procesar trabajos de impresión:", err)
+			}
+		}
+	}
+}
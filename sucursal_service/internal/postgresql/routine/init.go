@@ -8,4 +8,8 @@ import (
 func Init(ctx context.Context) {
 	deps := setup.GetDependencies()
 	go UsoSalasActualizar(ctx, deps.Service.Sala, deps.Service.RabbitMQ)
+	go IdempotenciaLimpiar(ctx, deps.Service.Idempotencia)
+	go PuntosVencer(ctx, deps.Service.Puntos)
+	go TrabajosImpresionProcesar(ctx, deps.Service.Impresora)
+	go AlertasStockPublicar(ctx, deps.Service.Inventario)
 }
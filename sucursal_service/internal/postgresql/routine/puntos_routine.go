@@ -0,0 +1,31 @@
+package routine
+
+import (
+	"context"
+	"log"
+	"multiroom/sucursal-service/internal/core/port"
+	"time"
+)
+
+// PuntosVencer registra periódicamente el vencimiento de los puntos de fidelización caducados
+func PuntosVencer(ctx context.Context, puntosService port.PuntosService) {
+	tickerPuntos := time.NewTicker(1 * time.Hour)
+	defer tickerPuntos.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("PuntosVencer detenido por cancelación del contexto")
+			return
+		case <-tickerPuntos.C:
+			vencidos, err := puntosService.VencerPuntos(ctx)
+			if err != nil {
+				log.Println("Error al vencer puntos:", err)
+				continue
+			}
+			if vencidos > 0 {
+				log.Printf("Puntos vencidos: %d\n", vencidos)
+			}
+		}
+	}
+}
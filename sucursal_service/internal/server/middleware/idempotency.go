@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"multiroom/sucursal-service/internal/core/domain"
+	"multiroom/sucursal-service/internal/core/domain/datatype"
+	"multiroom/sucursal-service/internal/core/port"
+	"multiroom/sucursal-service/internal/core/util"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	// Una reserva sin respuesta (proceso caído a mitad de la petición) vence a los pocos minutos
+	reservaIdempotencia = 5 * time.Minute
+)
+
+// retencionIdempotencia obtiene la ventana de retención desde IDEMPOTENCY_TTL_HORAS (24 horas por defecto)
+func retencionIdempotencia() time.Duration {
+	if horas, err := strconv.Atoi(os.Getenv("IDEMPOTENCY_TTL_HORAS")); err == nil && horas > 0 {
+		return time.Duration(horas) * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Idempotency permite que un endpoint POST sea reintentado de forma segura. Si la petición
+// trae la cabecera Idempotency-Key, la primera respuesta se guarda y los reintentos con el mismo
+// cuerpo la reciben nuevamente; un cuerpo distinto con la misma clave responde 409.
+// Debe registrarse después de VerifyPermission para que la clave quede asociada al usuario.
+func Idempotency(idempotenciaService port.IdempotenciaService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		clave := c.Get(HeaderIdempotencyKey)
+		if clave == "" {
+			return c.Next()
+		}
+		if len(clave) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(util.NewMessage("La cabecera Idempotency-Key no debe superar los 255 caracteres"))
+		}
+
+		usuarioId, _ := c.Locals(util.ContextUserIdKey).(int)
+		hash := sha256.New()
+		hash.Write([]byte(c.Method()))
+		hash.Write([]byte(c.Path()))
+		hash.Write(c.Body())
+
+		request := domain.IdempotenciaRequest{
+			Clave:        clave,
+			UsuarioId:    usuarioId,
+			Metodo:       c.Method(),
+			Ruta:         c.Path(),
+			HashPeticion: hex.EncodeToString(hash.Sum(nil)),
+			Reserva:      reservaIdempotencia,
+		}
+		existente, err := idempotenciaService.ReservarIdempotencia(c.UserContext(), &request)
+		if err != nil {
+			log.Print(err.Error())
+			var errorResponse *datatype.ErrorResponse
+			if errors.As(err, &errorResponse) {
+				return c.Status(errorResponse.Code).JSON(util.NewMessage(errorResponse.Message))
+			}
+			return datatype.NewInternalServerErrorGeneric()
+		}
+
+		if existente != nil {
+			if existente.HashPeticion != request.HashPeticion {
+				return c.Status(fiber.StatusConflict).JSON(util.NewMessage("La clave de idempotencia ya fue usada con una petición diferente"))
+			}
+			if existente.CodigoEstado == nil {
+				return c.Status(fiber.StatusConflict).JSON(util.NewMessage("La petición con esta clave de idempotencia se está procesando, intente nuevamente"))
+			}
+			if existente.ContentType != nil {
+				c.Set(fiber.HeaderContentType, *existente.ContentType)
+			}
+			c.Set(HeaderIdempotencyReplayed, "true")
+			return c.Status(*existente.CodigoEstado).Send(existente.Respuesta)
+		}
+
+		liberar := func() {
+			if errLiberar := idempotenciaService.LiberarIdempotencia(c.UserContext(), clave, usuarioId); errLiberar != nil {
+				log.Print(errLiberar.Error())
+			}
+		}
+		// Un panic del handler libera la clave antes de llegar al recover
+		defer func() {
+			if r := recover(); r != nil {
+				liberar()
+				panic(r)
+			}
+		}()
+
+		err = c.Next()
+		status := c.Response().StatusCode()
+		if err != nil || status >= fiber.StatusInternalServerError {
+			// Los errores no se guardan para que el cliente pueda reintentar con la misma clave
+			liberar()
+			return err
+		}
+
+		respuesta := domain.IdempotenciaRespuestaRequest{
+			Clave:        clave,
+			UsuarioId:    usuarioId,
+			CodigoEstado: status,
+			ContentType:  string(c.Response().Header.ContentType()),
+			Respuesta:    append([]byte(nil), c.Response().Body()...),
+			Retencion:    retencionIdempotencia(),
+		}
+		if errGuardar := idempotenciaService.GuardarRespuestaIdempotencia(c.UserContext(), &respuesta); errGuardar != nil {
+			// Sin respuesta guardada la clave no debe quedar en proceso: el reintento vuelve a ejecutarse
+			log.Print(errGuardar.Error())
+			liberar()
+		}
+		return nil
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"multiroom/sucursal-service/internal/core/util"
 	"multiroom/sucursal-service/internal/server/middleware"
+	"multiroom/sucursal-service/internal/server/setup"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
@@ -29,6 +30,8 @@ func (s *Server) initEndPointsHTTP(app *fiber.App) {
 
 func (s *Server) endPointsAPI(api fiber.Router) {
 	v1 := api.Group("/v1")
+	// Reintentos seguros para POST con cabecera Idempotency-Key
+	idempotency := middleware.Idempotency(setup.GetDependencies().Service.Idempotencia)
 
 	// ==========================================
 	// PAÍSES (Recurso: pais)
@@ -53,6 +56,12 @@ func (s *Server) endPointsAPI(api fiber.Router) {
 	v1Sucursales.Put("/:sucursalId", middleware.VerifyPermission("sucursal:editar"), s.handlers.Sucursal.ModificarSucursal)
 	v1Sucursales.Patch("/:sucursalId/habilitar", middleware.VerifyPermission("sucursal:editar"), s.handlers.Sucursal.HabilitarSucursal)
 	v1Sucursales.Patch("/:sucursalId/deshabilitar", middleware.VerifyPermission("sucursal:editar"), s.handlers.Sucursal.DeshabilitarSucursal)
+	v1Sucursales.Get("/:sucursalId/comprobante", middleware.VerifyPermission("sucursal:ver"), s.handlers.PlantillaComprobante.ObtenerPlantillaComprobante)
+	v1Sucursales.Put("/:sucursalId/comprobante", middleware.VerifyPermission("sucursal:editar"), s.handlers.PlantillaComprobante.ModificarPlantillaComprobante)
+	v1Sucursales.Get("/:sucursalId/comprobante/vista-previa", middleware.VerifyPermission("sucursal:ver"), s.handlers.Reporte.VistaPreviaComprobante)
+	v1Sucursales.Get("/:sucursalId/configuracion", middleware.VerifyPermission("sucursal:ver"), s.handlers.ConfiguracionSucursal.ObtenerConfiguracionSucursal)
+	v1Sucursales.Put("/:sucursalId/configuracion", middleware.VerifyPermission("sucursal:editar"), s.handlers.ConfiguracionSucursal.ModificarConfiguracionSucursal)
+	v1Sucursales.Get("/:sucursalId/catalogo/cambios", middleware.VerifyPermission("catalogo:sincronizar"), s.handlers.Catalogo.ObtenerCambiosCatalogo)
 
 	// ==========================================
 	// SALAS (Recurso: sala)
@@ -72,7 +81,7 @@ func (s *Server) endPointsAPI(api fiber.Router) {
 	v1AccionesSalas := v1.Group("/acciones/salas")
 	v1AccionesSalas.Use(middleware.HostnameMiddleware)
 	// 'sala:controlar' es para pausar, reanudar, asignar tiempo
-	v1AccionesSalas.Post("", middleware.VerifyPermission("sala:controlar"), s.handlers.Sala.AsignarTiempoUsoSala)
+	v1AccionesSalas.Post("", middleware.VerifyPermission("sala:controlar"), idempotency, s.handlers.Sala.AsignarTiempoUsoSala)
 	v1AccionesSalas.Patch("/cancelar/:salaId", middleware.VerifyPermission("sala:controlar"), s.handlers.Sala.CancelarSala)
 	v1AccionesSalas.Patch("/pausar/:salaId", middleware.VerifyPermission("sala:controlar"), s.handlers.Sala.PausarTiempoUsoSala)
 	v1AccionesSalas.Patch("/reanudar/:salaId", middleware.VerifyPermission("sala:controlar"), s.handlers.Sala.ReanudarTiempoUsoSala)
@@ -97,11 +106,17 @@ func (s *Server) endPointsAPI(api fiber.Router) {
 	v1Productos.Get("/stats/topProductos", middleware.VerifyPermission("producto:ver"), s.handlers.Producto.ListarProductosMasVendidos)
 	v1Productos.Get("/sucursales", middleware.VerifyPermission("producto:ver"), s.handlers.Producto.ListarProductosPorSucursal)
 	v1Productos.Get("/sucursales/:productoSucursalId", middleware.VerifyPermission("producto:ver"), s.handlers.Producto.ObtenerProductoSucursalById)
+	v1Productos.Get("/barcode/:code", middleware.VerifyPermission("producto:ver"), s.handlers.Producto.ObtenerProductoPorCodigo)
 	v1Productos.Get("/:productoId", middleware.VerifyPermission("producto:ver"), s.handlers.Producto.ObtenerProductoById)
+	v1Productos.Get("/:productoId/componentes", middleware.VerifyPermission("producto:ver"), s.handlers.Producto.ListarComponentesProducto)
+	v1Productos.Get("/:productoId/opciones", middleware.VerifyPermission("producto:ver"), s.handlers.Producto.ListarOpcionesProducto)
 
 	v1Productos.Put("/sucursales/:productoSucursalId", middleware.VerifyPermission("producto:editar"), s.handlers.Producto.ActualizarProductoSucursal)
+	v1Productos.Put("/sucursales/:productoSucursalId/niveles-stock", middleware.VerifyPermission("producto:editar"), s.handlers.Producto.ActualizarNivelesStock)
 	v1Productos.Post("", middleware.VerifyPermission("producto:crear"), s.handlers.Producto.RegistrarProducto)
 	v1Productos.Put("/:productoId", middleware.VerifyPermission("producto:editar"), s.handlers.Producto.ModificarProductoById)
+	v1Productos.Put("/:productoId/componentes", middleware.VerifyPermission("producto:editar"), s.handlers.Producto.ModificarComponentesProducto)
+	v1Productos.Put("/:productoId/opciones", middleware.VerifyPermission("producto:editar"), s.handlers.Producto.ModificarOpcionesProducto)
 	v1Productos.Patch("/:productoId/habilitar", middleware.VerifyPermission("producto:editar"), s.handlers.Producto.HabilitarProductoById)
 	v1Productos.Patch("/:productoId/deshabilitar", middleware.VerifyPermission("producto:editar"), s.handlers.Producto.DeshabilitarProductoById)
 	// Categorias
@@ -130,11 +145,16 @@ func (s *Server) endPointsAPI(api fiber.Router) {
 	v1Compras := v1.Group("/compras")
 	v1Compras.Use(middleware.HostnameMiddleware)
 	v1Compras.Get("", middleware.VerifyPermission("compra:ver"), s.handlers.Compra.ListarCompras)
+	v1Compras.Get("/sugerencias", middleware.VerifyPermission("compra:ver"), s.handlers.Compra.ListarSugerenciasCompra)
 	v1Compras.Get("/:compraId", middleware.VerifyPermission("compra:ver"), s.handlers.Compra.ObtenerCompraById)
 	v1Compras.Post("", middleware.VerifyPermission("compra:crear"), s.handlers.Compra.RegistrarOrdenCompra)
+	v1Compras.Post("/sugerencias/orden", middleware.VerifyPermission("compra:crear"), s.handlers.Compra.RegistrarOrdenSugerida)
 	v1Compras.Put("/:compraId", middleware.VerifyPermission("compra:editar"), s.handlers.Compra.ModificarOrdenCompra)
 	// Recepcionar/Completar compra puede requerir un permiso especial
 	v1Compras.Post("/:compraId/completar", middleware.VerifyPermission("compra:procesar"), s.handlers.Compra.ConfirmarRecepcionCompra)
+	v1Compras.Get("/:compraId/recepciones", middleware.VerifyPermission("compra:ver"), s.handlers.Compra.ListarRecepcionesCompra)
+	v1Compras.Post("/:compraId/recepciones", middleware.VerifyPermission("compra:procesar"), s.handlers.Compra.RegistrarRecepcionCompra)
+	v1Compras.Post("/:compraId/cancelar-saldo", middleware.VerifyPermission("compra:procesar"), s.handlers.Compra.CancelarSaldoCompra)
 
 	// ==========================================
 	// INVENTARIO (Recurso: inventario / ajuste / transferencia)
@@ -142,12 +162,36 @@ func (s *Server) endPointsAPI(api fiber.Router) {
 	v1Inventario := v1.Group("/inventario")
 	v1Inventario.Use(middleware.HostnameMiddleware)
 	v1Inventario.Get("", middleware.VerifyPermission("inventario:ver"), s.handlers.Inventario.ListarInventario)
+	v1Inventario.Get("/kardex", middleware.VerifyPermission("inventario:ver"), s.handlers.Inventario.ObtenerKardex)
 	v1Inventario.Get("/ajustes", middleware.VerifyPermission("ajuste_inventario:ver"), s.handlers.Inventario.ListarAjustes)
+	v1Inventario.Get("/ajustes/resumen", middleware.VerifyPermission("ajuste_inventario:ver"), s.handlers.Inventario.ResumenAjustesPorTipo)
 	v1Inventario.Get("/ajustes/:ajusteId", middleware.VerifyPermission("ajuste_inventario:ver"), s.handlers.Inventario.ObtenerAjusteById)
 	v1Inventario.Post("/ajustes", middleware.VerifyPermission("ajuste_inventario:crear"), s.handlers.Inventario.RegistrarAjusteConDetalle)
+	v1Inventario.Get("/tipos-ajuste", middleware.VerifyPermission("ajuste_inventario:ver"), s.handlers.TipoAjuste.ListarTiposAjuste)
+	v1Inventario.Get("/tipos-ajuste/:tipoAjusteId", middleware.VerifyPermission("ajuste_inventario:ver"), s.handlers.TipoAjuste.ObtenerTipoAjusteById)
+	v1Inventario.Post("/tipos-ajuste", middleware.VerifyPermission("tipo_ajuste:gestionar"), s.handlers.TipoAjuste.RegistrarTipoAjuste)
+	v1Inventario.Put("/tipos-ajuste/:tipoAjusteId", middleware.VerifyPermission("tipo_ajuste:gestionar"), s.handlers.TipoAjuste.ModificarTipoAjusteById)
+	v1Inventario.Patch("/tipos-ajuste/:tipoAjusteId/habilitar", middleware.VerifyPermission("tipo_ajuste:gestionar"), s.handlers.TipoAjuste.HabilitarTipoAjuste)
+	v1Inventario.Patch("/tipos-ajuste/:tipoAjusteId/deshabilitar", middleware.VerifyPermission("tipo_ajuste:gestionar"), s.handlers.TipoAjuste.DeshabilitarTipoAjuste)
+	v1Inventario.Get("/stock-bajo", middleware.VerifyPermission("inventario:ver"), s.handlers.Inventario.ListarProductosStockBajo)
+	v1Inventario.Get("/lotes", middleware.VerifyPermission("inventario:ver"), s.handlers.Inventario.ListarLotes)
+	v1Inventario.Post("/lotes/baja-vencidos", middleware.VerifyPermission("ajuste_inventario:crear"), s.handlers.Inventario.DarDeBajaLotes)
 	v1Inventario.Get("/transferencias", middleware.VerifyPermission("transferencia:ver"), s.handlers.Inventario.ListarTransferencias)
 	v1Inventario.Get("/transferencias/:transferenciaId", middleware.VerifyPermission("transferencia:ver"), s.handlers.Inventario.ObtenerTransferenciaById)
 	v1Inventario.Post("/transferencias", middleware.VerifyPermission("transferencia:crear"), s.handlers.Inventario.RegistrarTransferencia)
+	v1Inventario.Post("/transferencias/solicitudes", middleware.VerifyPermission("transferencia:solicitar"), s.handlers.Inventario.RegistrarSolicitudTransferencia)
+	v1Inventario.Post("/transferencias/:transferenciaId/aprobar", middleware.VerifyPermission("transferencia:aprobar"), s.handlers.Inventario.AprobarTransferencia)
+	v1Inventario.Post("/transferencias/:transferenciaId/rechazar", middleware.VerifyPermission("transferencia:aprobar"), s.handlers.Inventario.RechazarTransferencia)
+	v1Inventario.Post("/transferencias/:transferenciaId/despachar", middleware.VerifyPermission("transferencia:crear"), s.handlers.Inventario.DespacharTransferencia)
+	v1Inventario.Post("/transferencias/:transferenciaId/recibir", middleware.VerifyPermission("transferencia:recibir"), s.handlers.Inventario.RecibirTransferencia)
+	v1Inventario.Get("/conteos", middleware.VerifyPermission("conteo:ver"), s.handlers.ConteoInventario.ListarConteos)
+	v1Inventario.Get("/conteos/:conteoId", middleware.VerifyPermission("conteo:ver"), s.handlers.ConteoInventario.ObtenerConteo)
+	v1Inventario.Post("/conteos", middleware.VerifyPermission("conteo:crear"), s.handlers.ConteoInventario.AbrirConteo)
+	v1Inventario.Post("/conteos/:conteoId/capturas", middleware.VerifyPermission("conteo:contar"), s.handlers.ConteoInventario.RegistrarCapturasConteo)
+	v1Inventario.Post("/conteos/:conteoId/cerrar", middleware.VerifyPermission("conteo:crear"), s.handlers.ConteoInventario.CerrarConteo)
+	v1Inventario.Post("/conteos/:conteoId/reabrir", middleware.VerifyPermission("conteo:crear"), s.handlers.ConteoInventario.ReabrirConteo)
+	v1Inventario.Post("/conteos/:conteoId/cancelar", middleware.VerifyPermission("conteo:crear"), s.handlers.ConteoInventario.CancelarConteo)
+	v1Inventario.Post("/conteos/:conteoId/contabilizar", middleware.VerifyPermission("conteo:contabilizar"), s.handlers.ConteoInventario.ContabilizarConteo)
 
 	// ==========================================
 	// VENTAS (Recurso: venta)
@@ -156,16 +200,105 @@ func (s *Server) endPointsAPI(api fiber.Router) {
 	v1Ventas.Use(middleware.HostnameMiddleware)
 	v1Ventas.Get("", middleware.VerifyPermission("venta:ver"), s.handlers.Venta.ListarVentas)
 	v1Ventas.Get("/productos", middleware.VerifyPermission("venta:ver"), s.handlers.Venta.ListarProductosVentas)
+	v1Ventas.Get("/productos/componentes", middleware.VerifyPermission("venta:ver"), s.handlers.Venta.ListarConsumoComponentes)
+	v1Ventas.Get("/productos/margenes", middleware.VerifyPermission("venta:ver"), s.handlers.Venta.ListarMargenesProductos)
+	v1Ventas.Get("/stats", middleware.VerifyPermission("venta:ver"), s.handlers.Venta.ObtenerEstadisticasVentas)
+	v1Ventas.Get("/sincronizacion", middleware.VerifyPermission("venta:ver"), s.handlers.Venta.ListarOperacionesOffline)
 	v1Ventas.Get("/:ventaId/comprobante", middleware.VerifyPermission("venta:ver"), s.handlers.Reporte.ComprobantePDFVentaById)
 	v1Ventas.Get("/:ventaId", middleware.VerifyPermission("venta:ver"), s.handlers.Venta.ObtenerVenta)
-	v1Ventas.Post("", middleware.VerifyPermission("venta:crear"), s.handlers.Venta.RegistrarVenta)
-	v1Ventas.Post("/:ventaId/pagar", middleware.VerifyPermission("venta:cobrar"), s.handlers.Venta.RegistrarPagoVenta)
+	v1Ventas.Post("", middleware.VerifyPermission("venta:crear"), idempotency, s.handlers.Venta.RegistrarVenta)
+	v1Ventas.Post("/sincronizar", middleware.VerifyPermission("venta:sincronizar"), s.handlers.Venta.SincronizarVentas)
+	v1Ventas.Post("/:ventaId/pagar", middleware.VerifyPermission("venta:cobrar"), idempotency, s.handlers.Venta.RegistrarPagoVenta)
+	v1Ventas.Post("/:ventaId/dividir", middleware.VerifyPermission("venta:crear"), idempotency, s.handlers.Venta.DividirVenta)
 	v1Ventas.Post("/:ventaId/anular", middleware.VerifyPermission("venta:anular"), s.handlers.Venta.AnularVentaById)
+	v1Ventas.Post("/:ventaId/imprimir", middleware.VerifyPermission("impresora:imprimir"), s.handlers.Impresora.ImprimirVenta)
+
+	// ==========================================
+	// PROMOCIONES Y CUPONES (Recurso: promocion)
+	// ==========================================
+	v1Promociones := v1.Group("/promociones")
+	v1Promociones.Use(middleware.HostnameMiddleware)
+	v1Promociones.Get("", middleware.VerifyPermission("promocion:ver"), s.handlers.Promocion.ListarPromociones)
+	v1Promociones.Get("/stats/descuentos", middleware.VerifyPermission("promocion:ver"), s.handlers.Promocion.ListarDescuentosPorPromocion)
+	v1Promociones.Get("/:promocionId", middleware.VerifyPermission("promocion:ver"), s.handlers.Promocion.ObtenerPromocionById)
+	v1Promociones.Post("", middleware.VerifyPermission("promocion:crear"), s.handlers.Promocion.RegistrarPromocion)
+	v1Promociones.Put("/:promocionId", middleware.VerifyPermission("promocion:editar"), s.handlers.Promocion.ModificarPromocionById)
+	v1Promociones.Patch("/:promocionId/habilitar", middleware.VerifyPermission("promocion:editar"), s.handlers.Promocion.HabilitarPromocionById)
+	v1Promociones.Patch("/:promocionId/deshabilitar", middleware.VerifyPermission("promocion:editar"), s.handlers.Promocion.DeshabilitarPromocionById)
+
+	// ==========================================
+	// CUENTAS CORRIENTES DE CLIENTES (Recurso: cuenta_cliente)
+	// ==========================================
+	v1CuentasCliente := v1.Group("/cuentas-cliente")
+	v1CuentasCliente.Use(middleware.HostnameMiddleware)
+	v1CuentasCliente.Get("", middleware.VerifyPermission("cuenta_cliente:ver"), s.handlers.CuentaCliente.ListarCuentasCliente)
+	v1CuentasCliente.Get("/:cuentaId", middleware.VerifyPermission("cuenta_cliente:ver"), s.handlers.CuentaCliente.ObtenerCuentaClienteById)
+	v1CuentasCliente.Get("/:cuentaId/movimientos", middleware.VerifyPermission("cuenta_cliente:ver"), s.handlers.CuentaCliente.ObtenerEstadoCuentaCliente)
+	v1CuentasCliente.Post("", middleware.VerifyPermission("cuenta_cliente:crear"), s.handlers.CuentaCliente.RegistrarCuentaCliente)
+	v1CuentasCliente.Put("/:cuentaId", middleware.VerifyPermission("cuenta_cliente:editar"), s.handlers.CuentaCliente.ModificarCuentaCliente)
+	v1CuentasCliente.Post("/:cuentaId/recargas", middleware.VerifyPermission("cuenta_cliente:recargar"), idempotency, s.handlers.CuentaCliente.RecargarCuentaCliente)
+
+	// ==========================================
+	// PROGRAMA DE PUNTOS (Recurso: puntos)
+	// ==========================================
+	v1Puntos := v1.Group("/puntos")
+	v1Puntos.Use(middleware.HostnameMiddleware)
+	v1Puntos.Get("/programas/:sucursalId", middleware.VerifyPermission("puntos:ver"), s.handlers.Puntos.ObtenerProgramaPuntos)
+	v1Puntos.Put("/programas/:sucursalId", middleware.VerifyPermission("puntos:editar"), s.handlers.Puntos.ModificarProgramaPuntos)
+	v1Puntos.Get("/clientes/:clienteId", middleware.VerifyPermission("puntos:ver"), s.handlers.Puntos.ObtenerPuntosCliente)
+
+	// ==========================================
+	// TARJETAS DE REGALO (Recurso: tarjeta_regalo)
+	// ==========================================
+	v1TarjetasRegalo := v1.Group("/tarjetas-regalo")
+	v1TarjetasRegalo.Use(middleware.HostnameMiddleware)
+	v1TarjetasRegalo.Get("", middleware.VerifyPermission("tarjeta_regalo:ver"), s.handlers.TarjetaRegalo.ListarTarjetasRegalo)
+	v1TarjetasRegalo.Get("/:codigo", middleware.VerifyPermission("tarjeta_regalo:ver"), s.handlers.TarjetaRegalo.ObtenerTarjetaRegaloByCodigo)
+
+	// ==========================================
+	// IMPUESTOS (Recurso: impuesto)
+	// ==========================================
+	v1Impuestos := v1.Group("/impuestos")
+	v1Impuestos.Use(middleware.HostnameMiddleware)
+	v1Impuestos.Get("", middleware.VerifyPermission("impuesto:ver"), s.handlers.Impuesto.ListarImpuestos)
+	v1Impuestos.Get("/:impuestoId", middleware.VerifyPermission("impuesto:ver"), s.handlers.Impuesto.ObtenerImpuestoById)
+	v1Impuestos.Post("", middleware.VerifyPermission("impuesto:crear"), s.handlers.Impuesto.RegistrarImpuesto)
+	v1Impuestos.Put("/:impuestoId", middleware.VerifyPermission("impuesto:editar"), s.handlers.Impuesto.ModificarImpuesto)
+
+	// ==========================================
+	// IMPRESORAS TÉRMICAS (Recurso: impresora)
+	// ==========================================
+	v1Impresoras := v1.Group("/impresoras")
+	v1Impresoras.Use(middleware.HostnameMiddleware)
+	v1Impresoras.Get("", middleware.VerifyPermission("impresora:ver"), s.handlers.Impresora.ListarImpresoras)
+	v1Impresoras.Get("/trabajos", middleware.VerifyPermission("impresora:ver"), s.handlers.Impresora.ListarTrabajosImpresion)
+	v1Impresoras.Post("/trabajos/:trabajoId/reintentar", middleware.VerifyPermission("impresora:imprimir"), s.handlers.Impresora.ReintentarTrabajoImpresion)
+	v1Impresoras.Post("/trabajos/:trabajoId/cancelar", middleware.VerifyPermission("impresora:imprimir"), s.handlers.Impresora.CancelarTrabajoImpresion)
+	v1Impresoras.Get("/:impresoraId", middleware.VerifyPermission("impresora:ver"), s.handlers.Impresora.ObtenerImpresoraById)
+	v1Impresoras.Post("", middleware.VerifyPermission("impresora:crear"), s.handlers.Impresora.RegistrarImpresora)
+	v1Impresoras.Put("/:impresoraId", middleware.VerifyPermission("impresora:editar"), s.handlers.Impresora.ModificarImpresora)
+	v1Impresoras.Post("/:impresoraId/prueba", middleware.VerifyPermission("impresora:editar"), s.handlers.Impresora.ProbarImpresora)
+
+	// ==========================================
+	// AUTORIZACIONES DE SUPERVISOR (Recurso: venta)
+	// ==========================================
+	v1Autorizaciones := v1.Group("/autorizaciones")
+	v1Autorizaciones.Use(middleware.HostnameMiddleware)
+	v1Autorizaciones.Get("", middleware.VerifyPermission("venta:ver"), s.handlers.Autorizacion.ListarAutorizacionesVenta)
+	v1Autorizaciones.Put("/pin", middleware.VerifyPermission("venta:autorizar"), s.handlers.Autorizacion.RegistrarPinSupervisor)
+	v1Autorizaciones.Post("/tokens", middleware.VerifyPermission("venta:autorizar"), s.handlers.Autorizacion.GenerarTokenAutorizacion)
 
 	v1Reportes := v1.Group("/reportes")
 	v1Reportes.Use(middleware.HostnameMiddleware)
 	v1Reportes.Get("/ventas", middleware.VerifyPermission("venta:ver"), s.handlers.Reporte.ReportePDFVentas)
+	v1Reportes.Get("/excepciones", middleware.VerifyPermission("venta:ver"), s.handlers.Reporte.ReportePDFExcepciones)
+	v1Reportes.Get("/kardex", middleware.VerifyPermission("inventario:ver"), s.handlers.Reporte.ReportePDFKardex)
+	v1Reportes.Get("/lotes-por-vencer", middleware.VerifyPermission("inventario:ver"), s.handlers.Reporte.ReportePDFLotesPorVencer)
+	v1Reportes.Get("/ajustes-por-tipo", middleware.VerifyPermission("ajuste_inventario:ver"), s.handlers.Reporte.ReportePDFAjustesPorTipo)
 	v1Reportes.Get("/productos/ventas", middleware.VerifyPermission("venta:ver"), s.handlers.Reporte.ReportePDFProductosVendidos)
+	v1Reportes.Get("/productos/margenes", middleware.VerifyPermission("venta:ver"), s.handlers.Reporte.ReportePDFMargenes)
+	v1Reportes.Get("/productos/etiquetas", middleware.VerifyPermission("producto:ver"), s.handlers.Reporte.ReportePDFEtiquetasProductos)
+	v1Reportes.Get("/cuentas-cliente/saldos-pendientes", middleware.VerifyPermission("cuenta_cliente:ver"), s.handlers.Reporte.ReportePDFSaldosPendientes)
 	// Metodos de Pagos
 	v1MetodosPagos := v1.Group("/metodos-pago")
 	v1MetodosPagos.Get("", middleware.VerifyPermission("metodo_pago:ver"), s.handlers.MetodoPago.ListarMetodosPago)
@@ -194,4 +327,8 @@ func (s *Server) endPointsWS(api fiber.Router) {
 	// Reemplazado ADMIN por permiso de ver sala
 	v1Salas.Get("", middleware.VerifyPermission("sala:ver"), websocket.New(s.handlers.SalaWS.UsoSalas))
 	v1Salas.Get("/:salaId", middleware.VerifyPermission("sala:ver"), websocket.New(s.handlers.SalaWS.UsoSala))
+
+	// Alertas de stock bajo por sucursal (Admin)
+	v1Inventario := api.Group("/inventario")
+	v1Inventario.Get("/alertas/:sucursalId", middleware.VerifyPermission("inventario:ver"), websocket.New(s.handlers.InventarioWS.AlertasStock))
 }
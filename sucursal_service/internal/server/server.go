@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"multiroom/sucursal-service/internal/server/middleware"
 	"multiroom/sucursal-service/internal/server/setup"
 	"os"
 	"os/signal"
@@ -68,6 +69,7 @@ func createCORSConfig() cors.Config {
 			fiber.HeaderAcceptLanguage,
 			fiber.HeaderConnection,
 			fiber.HeaderUserAgent,
+			middleware.HeaderIdempotencyKey,
 		}, ","),
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
 		AllowCredentials: allowCredentials,
@@ -2,7 +2,9 @@ package setup
 
 import (
 	"log"
+	"multiroom/sucursal-service/internal/adapter/fiscal"
 	httpHandler "multiroom/sucursal-service/internal/adapter/handler/http"
+	"multiroom/sucursal-service/internal/adapter/impresora"
 	"multiroom/sucursal-service/internal/adapter/repository"
 	"multiroom/sucursal-service/internal/core/port"
 	"multiroom/sucursal-service/internal/core/service"
@@ -17,52 +19,91 @@ import (
 )
 
 type Repository struct {
-	Pais              port.PaisRepository
-	Sucursal          port.SucursalRepository
-	Sala              port.SalaRepository
-	AppVersion        port.AppVersionRepository
-	Proveedor         port.ProveedorRepository
-	Producto          port.ProductoRepository
-	Ubicacion         port.UbicacionRepository
-	Compra            port.CompraRepository
-	Inventario        port.InventarioRepository
-	Venta             port.VentaRepository
-	MetodoPago        port.MetodoPagoRepository
-	ProductoCategoria port.ProductoCategoriaRepository
+	Pais                  port.PaisRepository
+	Sucursal              port.SucursalRepository
+	Sala                  port.SalaRepository
+	AppVersion            port.AppVersionRepository
+	Proveedor             port.ProveedorRepository
+	Producto              port.ProductoRepository
+	Ubicacion             port.UbicacionRepository
+	TipoAjuste            port.TipoAjusteRepository
+	Compra                port.CompraRepository
+	Inventario            port.InventarioRepository
+	ConteoInventario      port.ConteoInventarioRepository
+	Venta                 port.VentaRepository
+	MetodoPago            port.MetodoPagoRepository
+	ProductoCategoria     port.ProductoCategoriaRepository
+	Idempotencia          port.IdempotenciaRepository
+	Promocion             port.PromocionRepository
+	CuentaCliente         port.CuentaClienteRepository
+	Puntos                port.PuntosRepository
+	TarjetaRegalo         port.TarjetaRegaloRepository
+	Impuesto              port.ImpuestoRepository
+	PlantillaComprobante  port.PlantillaComprobanteRepository
+	Impresora             port.ImpresoraRepository
+	ConfiguracionSucursal port.ConfiguracionSucursalRepository
+	Catalogo              port.CatalogoRepository
+	Autorizacion          port.AutorizacionRepository
 }
 
 type Service struct {
-	Pais              port.PaisService
-	Sucursal          port.SucursalService
-	Sala              port.SalaService
-	RabbitMQ          port.RabbitMQService
-	AppVersion        port.AppVersionService
-	Proveedor         port.ProveedorService
-	Producto          port.ProductoService
-	Ubicacion         port.UbicacionService
-	Compra            port.CompraService
-	Inventario        port.InventarioService
-	Venta             port.VentaService
-	MetodoPago        port.MetodoPagoService
-	ProductoCategoria port.ProductoCategoriaService
-	Reporte           port.ReporteService
+	Pais                  port.PaisService
+	Sucursal              port.SucursalService
+	Sala                  port.SalaService
+	RabbitMQ              port.RabbitMQService
+	AppVersion            port.AppVersionService
+	Proveedor             port.ProveedorService
+	Producto              port.ProductoService
+	Ubicacion             port.UbicacionService
+	TipoAjuste            port.TipoAjusteService
+	Compra                port.CompraService
+	Inventario            port.InventarioService
+	ConteoInventario      port.ConteoInventarioService
+	Venta                 port.VentaService
+	MetodoPago            port.MetodoPagoService
+	ProductoCategoria     port.ProductoCategoriaService
+	Reporte               port.ReporteService
+	Idempotencia          port.IdempotenciaService
+	Promocion             port.PromocionService
+	CuentaCliente         port.CuentaClienteService
+	Puntos                port.PuntosService
+	TarjetaRegalo         port.TarjetaRegaloService
+	Impuesto              port.ImpuestoService
+	PlantillaComprobante  port.PlantillaComprobanteService
+	Impresora             port.ImpresoraService
+	ConfiguracionSucursal port.ConfiguracionSucursalService
+	Catalogo              port.CatalogoService
+	Autorizacion          port.AutorizacionService
 }
 
 type Handler struct {
-	Pais              port.PaisHandler
-	Sucursal          port.SucursalHandler
-	Sala              port.SalaHandler
-	SalaWS            port.SalaHandlerWS
-	AppVersion        port.AppVersionHandler
-	Proveedor         port.ProveedorHandler
-	Producto          port.ProductoHandler
-	Ubicacion         port.UbicacionHandler
-	Compra            port.CompraHandler
-	Inventario        port.InventarioHandler
-	Venta             port.VentaHandler
-	MetodoPago        port.MetodoPagoHandler
-	ProductoCategoria port.ProductoCategoriaHandler
-	Reporte           port.ReporteHandler
+	Pais                  port.PaisHandler
+	Sucursal              port.SucursalHandler
+	Sala                  port.SalaHandler
+	SalaWS                port.SalaHandlerWS
+	InventarioWS          port.InventarioHandlerWS
+	AppVersion            port.AppVersionHandler
+	Proveedor             port.ProveedorHandler
+	Producto              port.ProductoHandler
+	Ubicacion             port.UbicacionHandler
+	TipoAjuste            port.TipoAjusteHandler
+	Compra                port.CompraHandler
+	Inventario            port.InventarioHandler
+	ConteoInventario      port.ConteoInventarioHandler
+	Venta                 port.VentaHandler
+	MetodoPago            port.MetodoPagoHandler
+	ProductoCategoria     port.ProductoCategoriaHandler
+	Reporte               port.ReporteHandler
+	Promocion             port.PromocionHandler
+	CuentaCliente         port.CuentaClienteHandler
+	Puntos                port.PuntosHandler
+	TarjetaRegalo         port.TarjetaRegaloHandler
+	Impuesto              port.ImpuestoHandler
+	PlantillaComprobante  port.PlantillaComprobanteHandler
+	Impresora             port.ImpresoraHandler
+	ConfiguracionSucursal port.ConfiguracionSucursalHandler
+	Catalogo              port.CatalogoHandler
+	Autorizacion          port.AutorizacionHandler
 }
 
 type Dependencies struct {
@@ -119,11 +160,24 @@ func Init() {
 		repositories.Proveedor = repository.NewProveedorRepository(pool)
 		repositories.Producto = repository.NewProductoRepository(pool)
 		repositories.Ubicacion = repository.NewUbicacionRepository(pool)
+		repositories.TipoAjuste = repository.NewTipoAjusteRepository(pool)
 		repositories.Compra = repository.NewCompraRepository(pool)
 		repositories.Inventario = repository.NewInventarioRepository(pool)
+		repositories.ConteoInventario = repository.NewConteoInventarioRepository(pool)
 		repositories.Venta = repository.NewVentaRepository(pool)
 		repositories.MetodoPago = repository.NewMetodoPagoRepository(pool)
 		repositories.ProductoCategoria = repository.NewProductoCategoriaRepository(pool)
+		repositories.Idempotencia = repository.NewIdempotenciaRepository(pool)
+		repositories.Promocion = repository.NewPromocionRepository(pool)
+		repositories.CuentaCliente = repository.NewCuentaClienteRepository(pool)
+		repositories.Puntos = repository.NewPuntosRepository(pool)
+		repositories.TarjetaRegalo = repository.NewTarjetaRegaloRepository(pool)
+		repositories.Impuesto = repository.NewImpuestoRepository(pool)
+		repositories.PlantillaComprobante = repository.NewPlantillaComprobanteRepository(pool)
+		repositories.Impresora = repository.NewImpresoraRepository(pool)
+		repositories.ConfiguracionSucursal = repository.NewConfiguracionSucursalRepository(pool)
+		repositories.Catalogo = repository.NewCatalogoRepository(pool)
+		repositories.Autorizacion = repository.NewAutorizacionRepository(pool)
 		// Services
 		services.RabbitMQ = service.NewRabbitMQService(os.Getenv("RABBITMQ_URL"))
 		services.Pais = service.NewPaisService(repositories.Pais)
@@ -133,12 +187,25 @@ func Init() {
 		services.Proveedor = service.NewProveedorService(repositories.Proveedor)
 		services.Producto = service.NewProductoService(repositories.Producto)
 		services.Ubicacion = service.NewUbicacionService(repositories.Ubicacion)
+		services.TipoAjuste = service.NewTipoAjusteService(repositories.TipoAjuste)
 		services.Compra = service.NewCompraService(repositories.Compra)
-		services.Inventario = service.NewInventarioService(repositories.Inventario)
-		services.Venta = service.NewVentaService(repositories.Venta)
+		services.Inventario = service.NewInventarioService(repositories.Inventario, repositories.TipoAjuste, services.RabbitMQ)
+		services.ConteoInventario = service.NewConteoInventarioService(repositories.ConteoInventario)
+		services.Impresora = service.NewImpresoraService(repositories.Impresora, repositories.Venta, repositories.PlantillaComprobante, impresora.NewEscPosImpresora())
+		services.Venta = service.NewVentaService(repositories.Venta, fiscal.NewProveedorFiscal(os.Getenv("FISCAL_PROVIDER"), repositories.Venta), services.Impresora)
 		services.MetodoPago = service.NewMetodoPagoService(repositories.MetodoPago)
 		services.ProductoCategoria = service.NewProductoCategoriaService(repositories.ProductoCategoria)
-		services.Reporte = service.NewReporteService(repositories.Venta, repositories.Sucursal, repositories.Producto)
+		services.Reporte = service.NewReporteService(repositories.Venta, repositories.Sucursal, repositories.Producto, repositories.Promocion, repositories.CuentaCliente, repositories.PlantillaComprobante, repositories.Autorizacion, repositories.Inventario)
+		services.Idempotencia = service.NewIdempotenciaService(repositories.Idempotencia)
+		services.Promocion = service.NewPromocionService(repositories.Promocion)
+		services.CuentaCliente = service.NewCuentaClienteService(repositories.CuentaCliente)
+		services.Puntos = service.NewPuntosService(repositories.Puntos)
+		services.TarjetaRegalo = service.NewTarjetaRegaloService(repositories.TarjetaRegalo)
+		services.Impuesto = service.NewImpuestoService(repositories.Impuesto)
+		services.PlantillaComprobante = service.NewPlantillaComprobanteService(repositories.PlantillaComprobante)
+		services.ConfiguracionSucursal = service.NewConfiguracionSucursalService(repositories.ConfiguracionSucursal)
+		services.Catalogo = service.NewCatalogoService(repositories.Catalogo)
+		services.Autorizacion = service.NewAutorizacionService(repositories.Autorizacion)
 		// Handlers
 		handlers.Pais = httpHandler.NewPaisHandler(services.Pais)
 		handlers.Sucursal = httpHandler.NewSucursalHandler(services.Sucursal)
@@ -148,12 +215,25 @@ func Init() {
 		handlers.Proveedor = httpHandler.NewProveedorHandler(services.Proveedor)
 		handlers.Producto = httpHandler.NewProductoHandler(services.Producto)
 		handlers.Ubicacion = httpHandler.NewUbicacionHandler(services.Ubicacion)
+		handlers.TipoAjuste = httpHandler.NewTipoAjusteHandler(services.TipoAjuste)
 		handlers.Compra = httpHandler.NewCompraHandler(services.Compra)
 		handlers.Inventario = httpHandler.NewInventarioHandler(services.Inventario)
+		handlers.ConteoInventario = httpHandler.NewConteoInventarioHandler(services.ConteoInventario)
+		handlers.InventarioWS = wsHandler.NewInventarioHandlerWS(services.RabbitMQ)
 		handlers.Venta = httpHandler.NewVentaHandler(services.Venta)
 		handlers.MetodoPago = httpHandler.NewMetodoPagoHandler(services.MetodoPago)
 		handlers.ProductoCategoria = httpHandler.NewProductoCategoriaHandler(services.ProductoCategoria)
 		handlers.Reporte = httpHandler.NewReporteHandler(services.Reporte)
+		handlers.Promocion = httpHandler.NewPromocionHandler(services.Promocion)
+		handlers.CuentaCliente = httpHandler.NewCuentaClienteHandler(services.CuentaCliente)
+		handlers.Puntos = httpHandler.NewPuntosHandler(services.Puntos)
+		handlers.TarjetaRegalo = httpHandler.NewTarjetaRegaloHandler(services.TarjetaRegalo)
+		handlers.Impuesto = httpHandler.NewImpuestoHandler(services.Impuesto)
+		handlers.PlantillaComprobante = httpHandler.NewPlantillaComprobanteHandler(services.PlantillaComprobante)
+		handlers.Impresora = httpHandler.NewImpresoraHandler(services.Impresora)
+		handlers.ConfiguracionSucursal = httpHandler.NewConfiguracionSucursalHandler(services.ConfiguracionSucursal)
+		handlers.Catalogo = httpHandler.NewCatalogoHandler(services.Catalogo)
+		handlers.Autorizacion = httpHandler.NewAutorizacionHandler(services.Autorizacion)
 		instance = d
 	})
 }